@@ -21,8 +21,13 @@
 
 package gosuki
 
+// FolderSep separates the folders of the hierarchical tags built from browser
+// folders, ex: Work/ProjectA/Docs
+const FolderSep = "/"
+
 // Bookmark type
 type Bookmark struct {
+	ID       uint64   `json:"id,omitempty"`
 	URL      string   `json:"url"`
 	Title    string   `json:"metadata"`
 	Tags     []string `json:"tags"`
@@ -30,6 +35,30 @@ type Bookmark struct {
 	Module   string   `json:"module"`
 	Version  uint64   `json:"version"`
 	Modified uint64   `json:"modified"`
+	Created  uint64   `json:"created"`
 	Xhsum    string   `json:"xhsum"`
+
+	// http status of the last link check, zero when not checked
+	LinkStatus int `json:"link_status,omitempty"`
+
+	// Browser profiles the bookmark was found in
+	Sources []Source `json:"sources,omitempty"`
 	//flags int
 }
+
+// Source is a browser profile holding a bookmark
+type Source struct {
+	// module instance, ex: firefox_default
+	Module  string `json:"module"`
+	Flavour string `json:"flavour,omitempty"`
+	Profile string `json:"profile,omitempty"`
+
+	// folder path of the bookmark in the browser, see [FolderSep]
+	Folder string `json:"folder,omitempty"`
+
+	// comma separated keywords opening the bookmark from the address bar
+	Keyword string `json:"keyword,omitempty"`
+
+	FirstSeen uint64 `json:"first_seen,omitempty"`
+	LastSeen  uint64 `json:"last_seen,omitempty"`
+}
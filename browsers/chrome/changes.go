@@ -0,0 +1,167 @@
+//
+// Copyright (c) 2023-2025 Chakib Ben Ziane <contact@blob42.xyz> and [`GoSuki` contributors]
+// (https://github.com/blob42/gosuki/graphs/contributors).
+//
+// All rights reserved.
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+//
+// This file is part of GoSuki.
+//
+// GoSuki is free software: you can redistribute it and/or modify it under the terms of
+// the GNU Affero General Public License as published by the Free Software Foundation,
+// either version 3 of the License, or (at your option) any later version.
+//
+// GoSuki is distributed in the hope that it will be useful, but WITHOUT ANY WARRANTY;
+// without even the implied warranty of MERCHANTABILITY or FITNESS FOR A PARTICULAR
+// PURPOSE.  See the GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License along with
+// gosuki.  If not, see <http://www.gnu.org/licenses/>.
+
+package chrome
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/OneOfOne/xxhash"
+
+	"github.com/blob42/gosuki/pkg/tree"
+)
+
+// bookmarkEntry is a node of the Bookmarks file tracked across runs
+type bookmarkEntry struct {
+	node   *tree.Node
+	parent string // key of the parent node, empty for the root folders
+	folder string // folder path of url nodes
+	hash   uint64 // hash of the title, url and date_modified
+}
+
+// entries indexes the nodes of the built tree by guid. Nodes without a guid,
+// written by old Chrome versions, are keyed by their position in the tree.
+func (b *treeBuilder) entries() map[string]*bookmarkEntry {
+	entries := make(map[string]*bookmarkEntry, len(b.info))
+
+	var walk func(parent *tree.Node, parentKey string)
+	walk = func(parent *tree.Node, parentKey string) {
+		for i, node := range parent.Children {
+			info := b.info[node]
+			key := info.guid
+			if _, dup := entries[key]; key == "" || dup {
+				key = fmt.Sprintf("%s/%d", parentKey, i)
+			}
+
+			entry := &bookmarkEntry{
+				node:   node,
+				parent: parentKey,
+				hash:   xxhash.ChecksumString64(node.Title + "\x00" + node.URL + "\x00" + info.dateModified),
+			}
+			if node.Type == tree.URLNode && parent.Type == tree.FolderNode {
+				entry.folder = node.FolderPath()
+			}
+			entries[key] = entry
+
+			walk(node, key)
+		}
+	}
+	walk(b.root, "")
+
+	return entries
+}
+
+type changeKind int
+
+const (
+	nodeAdded changeKind = iota
+	nodeChanged
+	nodeMoved
+	nodeRemoved
+)
+
+func (k changeKind) String() string {
+	return [...]string{"added", "changed", "moved", "removed"}[k]
+}
+
+// nodeChange is a node added, changed, moved or removed since the last run
+type nodeChange struct {
+	kind changeKind
+	key  string
+
+	// entry of the current run, of the last run for removed nodes
+	entry *bookmarkEntry
+}
+
+// diffEntries compares the nodes found by two runs. Nodes with a new parent
+// are moved and urls are changed when the path of their folder changed.
+func diffEntries(last, current map[string]*bookmarkEntry) []nodeChange {
+	var changes []nodeChange
+
+	for key, entry := range current {
+		prev, ok := last[key]
+		switch {
+		case !ok:
+			changes = append(changes, nodeChange{nodeAdded, key, entry})
+		case prev.parent != entry.parent:
+			changes = append(changes, nodeChange{nodeMoved, key, entry})
+		case prev.hash != entry.hash || prev.folder != entry.folder:
+			changes = append(changes, nodeChange{nodeChanged, key, entry})
+		}
+	}
+
+	for key, entry := range last {
+		if _, ok := current[key]; !ok {
+			changes = append(changes, nodeChange{nodeRemoved, key, entry})
+		}
+	}
+
+	return changes
+}
+
+// applyChanges prepares the url nodes of the current run and returns the ones
+// that need to be saved. Hooks only run on new urls and changed titles,
+// unchanged urls keep the tags of the last run.
+func (ch *Chrome) applyChanges(current map[string]*bookmarkEntry, changes []nodeChange) []*tree.Node {
+	kinds := make(map[string]changeKind, len(changes))
+	for _, change := range changes {
+		kinds[change.key] = change.kind
+	}
+
+	var updated []*tree.Node
+	for key, entry := range current {
+		node := entry.node
+		if node.Type != tree.URLNode {
+			continue
+		}
+
+		prev := ch.entries[key]
+		kind, changed := kinds[key]
+		if !changed {
+			// hooks may have rewritten the title, keep their output
+			node.Title, node.Tags, node.NameHash = prev.node.Title, prev.node.Tags, prev.node.NameHash
+			continue
+		}
+
+		node.NameHash = xxhash.ChecksumString64(node.Title)
+		if kind == nodeAdded || prev.node.NameHash != node.NameHash {
+			if err := ch.CallHooks(node); err != nil {
+				log.Error("running hooks", "url", node.URL, "err", err)
+			}
+		} else {
+			// keep the title and tags produced by the hooks
+			node.Title = prev.node.Title
+			node.Tags = slices.DeleteFunc(slices.Clone(prev.node.Tags), func(tag string) bool {
+				return tag == prev.folder
+			})
+		}
+
+		if entry.folder != "" {
+			node.Tags = append(node.Tags, entry.folder)
+		}
+
+		ch.URLIndex.Insert(node.URL, node)
+		updated = append(updated, node)
+	}
+
+	return updated
+}
@@ -31,12 +31,10 @@ package chrome
 
 import (
 	"fmt"
-	"os"
 	"path/filepath"
 	"strings"
 	"time"
 
-	"github.com/OneOfOne/xxhash"
 	"github.com/fsnotify/fsnotify"
 
 	"github.com/buger/jsonparser"
@@ -57,10 +55,6 @@ var (
 	log = logging.GetLogger("chrome")
 )
 
-// Type of the func used recursively on each json node entry by [jsonparser.ArrayEach]
-type ParseChildJSONFunc func([]byte, jsonparser.ValueType, int, error)
-type RecursiveParseJSONFunc func([]byte, []byte, jsonparser.ValueType, int) error
-
 var jsonNodeTypes = map[string]tree.NodeType{
 	"folder": tree.FolderNode,
 	"url":    tree.URLNode,
@@ -77,6 +71,14 @@ type RawNode struct {
 	url          []byte
 	children     []byte
 	childrenType jsonparser.ValueType
+	dateAdded    []byte
+	dateModified []byte
+	guid         []byte
+	id           []byte
+	metaInfo     []byte
+
+	// folder written by gosuki
+	writeBack bool
 }
 
 func (rawNode *RawNode) parseItems(nodeData []byte) {
@@ -87,6 +89,11 @@ func (rawNode *RawNode) parseItems(nodeData []byte) {
 		{"name"}, // Title of page
 		{"url"},
 		{"children"},
+		{"meta_info"},
+		{"date_added"},
+		{"date_modified"},
+		{"guid"},
+		{"id"},
 	}
 
 	jsonparser.EachKey(nodeData, func(idx int, value []byte, vt jsonparser.ValueType, err error) {
@@ -94,6 +101,13 @@ func (rawNode *RawNode) parseItems(nodeData []byte) {
 			log.Error("error parsing node items")
 		}
 
+		// the streaming parser sees unescaped strings
+		if vt == jsonparser.String {
+			if value, err = jsonparser.Unescape(value, nil); err != nil {
+				log.Error("unescaping node item", "err", err)
+			}
+		}
+
 		switch idx {
 		case 0:
 			rawNode.nType = value
@@ -106,13 +120,28 @@ func (rawNode *RawNode) parseItems(nodeData []byte) {
 			rawNode.url = value
 		case 3:
 			rawNode.children, rawNode.childrenType = value, vt
+		case 4:
+			rawNode.setMetaInfo(value)
+		case 5:
+			rawNode.dateAdded = value
+		case 6:
+			rawNode.dateModified = value
+		case 7:
+			rawNode.guid = value
+		case 8:
+			rawNode.id = value
 		}
 	}, paths...)
 }
 
-// Returns *tree.Node from *RawNode
-func (rawNode *RawNode) getNode(ch *Chrome) *tree.Node {
-	node := new(tree.Node)
+func (rawNode *RawNode) setMetaInfo(value []byte) {
+	rawNode.metaInfo = value
+	_, _, _, err := jsonparser.Get(value, writeBackMetaKey)
+	rawNode.writeBack = err == nil
+}
+
+// Sets the fields of a *tree.Node from *RawNode
+func (rawNode *RawNode) fillNode(ch *Chrome, node *tree.Node) {
 	nType, ok := jsonNodeTypes[string(rawNode.nType)]
 	if !ok {
 		log.Errorf("unknown node type: %s", rawNode.nType)
@@ -120,18 +149,30 @@ func (rawNode *RawNode) getNode(ch *Chrome) *tree.Node {
 	node.Type = nType
 
 	node.Title = string(rawNode.title)
-	modName := ch.Name
+	node.Created = fromChromeTime(string(rawNode.dateAdded))
+	if nType == tree.URLNode {
+		node.URL = string(rawNode.url)
+	}
+	if ch.activeFlavour != nil {
+		node.Flavour = ch.activeFlavour.Flavour
+	}
+	if ch.activeProfile != nil {
+		node.Profile = ch.activeProfile.Name
+	}
 
+	node.Module = ch.moduleName()
+}
+
+// source module of the bookmarks, ex: chrome_Default
+func (ch *Chrome) moduleName() string {
+	modName := ch.Name
 	if ch.activeFlavour != nil && ch.activeFlavour.Flavour != ch.Name {
 		modName = fmt.Sprintf("%s_%s", modName, ch.activeFlavour.Flavour)
 	}
 	if ch.activeProfile != nil {
 		modName = fmt.Sprintf("%s_%s", modName, ch.activeProfile.Name)
 	}
-
-	node.Module = modName
-
-	return node
+	return modName
 }
 
 // Chrome browser module
@@ -145,6 +186,9 @@ type Chrome struct {
 	activeProfile *profiles.Profile
 
 	activeFlavour *browsers.BrowserDef
+
+	// nodes found by the last run indexed by guid
+	entries map[string]*bookmarkEntry
 }
 
 func (ch *Chrome) Init(ctx *modules.Context, p *profiles.Profile) error {
@@ -266,13 +310,6 @@ func (ch *Chrome) Run() {
 func (ch *Chrome) run(runTask bool) {
 	startRun := time.Now()
 
-	// Rebuild node tree
-	ch.NodeTree = &tree.Node{
-		Title:  RootNodeName,
-		Parent: nil,
-		Type:   tree.RootNode,
-	}
-
 	// Load bookmark file
 	bookmarkPath, err := ch.BookmarkPath()
 	if err != nil {
@@ -280,202 +317,13 @@ func (ch *Chrome) run(runTask bool) {
 		return
 	}
 
-	var parseChildren ParseChildJSONFunc
-	var jsonParseRecursive RecursiveParseJSONFunc
-
-	parseChildren = func(childVal []byte,
-		dataType jsonparser.ValueType,
-		offset int,
-		err error) {
-		if err != nil {
-			log.Fatal(err)
-		}
-
-		err = jsonParseRecursive(nil, childVal, dataType, offset)
-		if err != nil {
-			log.Error(err)
-		}
-
-	}
-
-	// Needed to store the parent of each child node
-	var parentNodes []*tree.Node
-
-	jsonParseRoots := func(key []byte,
-		node []byte,
-		dataType jsonparser.ValueType,
-		offset int,
-	) error {
-
-		// If node type is string ignore (needed for sync_transaction_version)
-		if dataType == jsonparser.String {
-			return nil
-		}
-
-		ch.IncNodeCount()
-		rawNode := new(RawNode)
-		rawNode.parseItems(node)
-
-		//log.Debugf("Parsing root folder %s", rawNode.name)
-
-		currentNode := rawNode.getNode(ch)
-
-		// Process this node as parent node later
-		parentNodes = append(parentNodes, currentNode)
-
-		// add the root node as parent to this node
-		currentNode.Parent = ch.NodeTree
-
-		// Add this root node as a child of the root node
-		ch.NodeTree.Children = append(ch.NodeTree.Children, currentNode)
-
-		// Call recursive parsing of this node which must
-		// a root folder node
-		jsonparser.ArrayEach(node, parseChildren, jsonNodePaths.Children)
-
-		// Finished parsing this root, it is not anymore a parent
-		_, parentNodes = parentNodes[len(parentNodes)-1],
-			parentNodes[:len(parentNodes)-1]
-
-		//log.Debugf("Parsed root %s folder", rawNode.name)
-
-		return nil
-	}
-
-	// Main recursive parsing underneath each root folder
-	jsonParseRecursive = func(_ []byte, //key
-		node []byte,
-		dataType jsonparser.ValueType,
-		_ int, //offset
-	) error {
-
-		// If node type is string ignore (needed for sync_transaction_version)
-		if dataType == jsonparser.String {
-			return nil
-		}
-
-		ch.IncNodeCount()
-
-		rawNode := new(RawNode)
-		rawNode.parseItems(node)
-
-		currentNode := rawNode.getNode(ch)
-		//log.Debugf("parsing node %s", currentNode.Name)
-
-		// if parents array is not empty
-		if len(parentNodes) != 0 {
-			parent := parentNodes[len(parentNodes)-1]
-			//log.Debugf("Adding current node to parent %s", parent.Name)
-
-			// Add current node to closest parent
-			currentNode.Parent = parent
-
-			// Add current node as child to parent
-			currentNode.Parent.Children = append(currentNode.Parent.Children, currentNode)
-		}
-
-		// if node is a folder with children
-		if rawNode.childrenType == jsonparser.Array && len(rawNode.children) > 2 { // if len(children) > len("[]")
-
-			//log.Debugf("Started folder %s", rawNode.name)
-			parentNodes = append(parentNodes, currentNode)
-
-			// Recursively process all child nodes of this folder node
-			jsonparser.ArrayEach(node, parseChildren, jsonNodePaths.Children)
-
-			//log.Debugf("Finished folder %s", rawNode.name)
-			_, parentNodes = parentNodes[len(parentNodes)-1], parentNodes[:len(parentNodes)-1]
-
-		}
-
-		// if node is url(leaf), handle the url
-		if currentNode.Type == tree.URLNode {
-			currentNode.URL = string(rawNode.url)
-			ch.IncURLCount()
-
-			progress := ch.Progress()
-			if progress-ch.lastSentProgress >= 0.05 || progress == 1 {
-				ch.lastSentProgress = progress
-				go func() {
-					msg := events.ProgressUpdateMsg{
-						ID:           ch.ModInfo().ID,
-						Instance:     ch,
-						CurrentCount: ch.URLCount(),
-						Total:        ch.Total(),
-					}
-					if runTask {
-						msg.NewBk = true
-					}
-					events.TUIBus <- msg
-				}()
-			}
-
-			// Check if url-node already in index
-			var nodeVal *tree.Node
-			iVal, found := ch.URLIndex.Get(currentNode.URL)
-
-			nameHash := xxhash.ChecksumString64(currentNode.Title)
-
-			// If node url not in index, add it to index
-			if !found {
-				//log.Debugf("Not found")
-
-				// store hash(name)
-				currentNode.NameHash = nameHash
-
-				// The value in the index will be a
-				// pointer to currentNode
-				log.Tracef("Inserting url %s to index", currentNode.URL)
-				ch.URLIndex.Insert(currentNode.URL, currentNode)
-
-				// Run registered bookmark parsing hooks
-				err = ch.CallHooks(currentNode)
-				if err != nil {
-					return err
-				}
-
-				// If we find the node already in index
-				// we check if the hash(name) changed  meaning
-				// the data changed
-			} else {
-				// log.Debugf("URL Found in index")
-				nodeVal = iVal.(*tree.Node)
-
-				// hash(name) is different meaning new commands/tags could
-				// be added, we need to process the parsing hoos
-				if nodeVal.NameHash != nameHash {
-					// log.Debugf("URL name changed !")
-
-					// Run parse hooks on node
-					ch.CallHooks(currentNode)
-				}
-
-				// Else we do nothing, the node will not
-				// change
-			}
-
-			//If parent is folder, add it as tag and add current node as child
-			//And add this link as child
-			if currentNode.Parent.Type == tree.FolderNode {
-				// log.Debug("Parent is folder, parsing as tag ...")
-				currentNode.Tags = append(currentNode.Tags, currentNode.Parent.Title)
-			}
-		}
-
-		return nil
-	}
-
-	f, err := os.ReadFile(bookmarkPath)
-	if err != nil {
-		log.Error(err)
+	// Start a new node tree building job
+	builder := newTreeBuilder(ch, runTask)
+	if err = builder.parseBookmarks(bookmarkPath); err != nil {
+		log.Errorf("<%s> parsing bookmarks: %s", ch.Name, err)
 		return
 	}
-
-	// starts from the "roots" key of chrome json bookmark file
-	rootsData, _, _, _ := jsonparser.Get(f, "roots")
-
-	// Start a new node tree building job
-	jsonparser.ObjectEach(rootsData, jsonParseRoots)
+	entries := builder.entries()
 	ch.SetLastTreeParseRuntime(time.Since(startRun))
 	log.Debugf("<%s> parsed tree in %s", ch.Name, ch.LastFullTreeParseRT())
 	// Finished node tree building job
@@ -483,25 +331,41 @@ func (ch *Chrome) run(runTask bool) {
 	// Debug walk tree
 	//go PrintTree(ch.NodeTree)
 
-	// Reset the index to represent the nodetree
-	ch.RebuildIndex()
+	// Only the nodes changed since the last run are processed
+	changes := diffEntries(ch.entries, entries)
+	updated := ch.applyChanges(entries, changes)
+	ch.NodeTree = builder.root
+	ch.entries = entries
+
+	// Detect bookmarks removed since the last run
+	var urls []string
+	for _, entry := range entries {
+		if entry.node.Type == tree.URLNode {
+			urls = append(urls, entry.node.URL)
+		}
+	}
+	ch.TrackDeletions(ch.moduleName(), urls, ChromeCfg.Deletions)
 
 	// Finished parsing
-	log.Debugf("<%s> parsed %d bookmarks and %d nodes", ch.Name, ch.URLCount(), ch.NodeCount())
+	log.Debugf("<%s> parsed %d bookmarks and %d nodes, %d changes",
+		ch.Name, ch.URLCount(), ch.NodeCount(), len(changes))
 
-	//Add nodeTree to Cache
-	//log.Debugf("<%s> buffer content", ch.Name)
-	//ch.BufferDB.Print()
+	if len(changes) == 0 {
+		ch.SetLastWatchRuntime(time.Since(startRun))
+		return
+	}
 
 	log.Debugf("<%s> syncing to buffer", ch.Name)
-	database.SyncTreeToBuffer(ch.NodeTree, ch.BufferDB)
+	for _, node := range updated {
+		if err = ch.BufferDB.UpsertBookmark(node.GetBookmark()); err != nil {
+			log.Errorf("db upsert: %s", node.URL)
+		}
+	}
 	log.Debugf("<%s> tree synced to buffer", ch.Name)
 
-	//ch.BufferDB.Print()
-
 	// database.Cache represents bookmarks across all browsers
-	// From browsers it should support: add/update
-	// Delete method should only be possible through admin interface
+	// From browsers it supports: add/update and tombstones for deleted
+	// bookmarks depending on the `deletions` option
 	//NOTE: We could have an @ignore command to ignore a bookmark
 
 	// URLIndex is a hashmap index of all URLS representing current state
@@ -509,11 +373,6 @@ func (ch *Chrome) run(runTask bool) {
 
 	// nodeTree is current state of the browser as tree
 
-	// Buffer is the current state of the browser represetned by
-	// URLIndex and nodeTree
-
-	// debugPrint("%d", BufferDB.Count())
-
 	// If the cache is empty just copy buffer to cache
 	// until local db is already populated and preloaded
 	if err = ch.BufferDB.SyncToCache(); err != nil {
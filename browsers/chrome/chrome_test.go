@@ -1,10 +1,19 @@
 package chrome
 
 import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
 	"os"
+	"path/filepath"
 	"testing"
+	"unicode/utf16"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/blob42/gosuki/internal/database"
 	"github.com/blob42/gosuki/internal/index"
@@ -19,6 +28,9 @@ import (
 
 const statePath = "testdata/Local State"
 
+//go:generate go run ../../internal/scripts/gen-chrome-bookmarks.go -amt 50000 -o testdata/Bookmarks.50k
+const bookmarks50k = "testdata/Bookmarks.50k"
+
 var ch Chrome
 
 func setupChrome() {
@@ -57,6 +69,7 @@ func TestMain(m *testing.M) {
 	}
 
 	database.Cache = &database.CacheDB{DB: cacheDB}
+	database.Clock = &database.LamportClock{}
 
 	setupChrome()
 	exitVal := m.Run()
@@ -114,8 +127,342 @@ func TestPreCount(t *testing.T) {
 	assert.EqualValues(t, 2007, int(total), "wrong # of url count")
 }
 
+func newTestChrome(tb testing.TB, bkDir string) *Chrome {
+	tb.Helper()
+	bufDB, err := database.NewBuffer("chrome_test")
+	require.NoError(tb, err)
+	tb.Cleanup(func() { bufDB.Close() })
+
+	return &Chrome{
+		ChromeConfig: &ChromeConfig{
+			BrowserConfig: &modules.BrowserConfig{
+				Name:     "chrome",
+				BkDir:    bkDir,
+				BkFile:   "Bookmarks",
+				BufferDB: bufDB,
+				URLIndex: index.NewIndex(),
+				NodeTree: &tree.Node{
+					Title: RootNodeName,
+					Type:  tree.RootNode,
+				},
+			},
+		},
+		Counter: &parsing.BrowserCounter{},
+	}
+}
+
+// nodes of the tree in depth first order
+func flattenTree(node *tree.Node) []string {
+	var nodes []string
+	for _, child := range node.Children {
+		nodes = append(nodes, fmt.Sprintf("%d %s %s", child.Type, child.Title, child.URL))
+		nodes = append(nodes, flattenTree(child)...)
+	}
+	return nodes
+}
+
+// parses data with both parsers and checks that they build the same tree and
+// count the same nodes
+func requireSameParse(t *testing.T, data []byte) *treeBuilder {
+	t.Helper()
+	parsed := newTreeBuilder(newTestChrome(t, ""), false)
+	require.NoError(t, parsed.parseJSON(data))
+
+	streamed := newTreeBuilder(newTestChrome(t, ""), false)
+	require.NoError(t, streamed.parseStream(bytes.NewReader(data)))
+
+	require.Equal(t, flattenTree(parsed.root), flattenTree(streamed.root))
+	require.Equal(t, parsed.ch.NodeCount(), streamed.ch.NodeCount(), "node count")
+	require.Equal(t, parsed.ch.URLCount(), streamed.ch.URLCount(), "url count")
+	require.Equal(t, len(parsed.info), len(streamed.info))
+	return streamed
+}
+
+func TestParseStream(t *testing.T) {
+	data, err := os.ReadFile("testdata/Bookmarks")
+	require.NoError(t, err)
+
+	streamed := requireSameParse(t, data)
+	assert.Len(t, streamed.entries(), 2007+1909)
+	assert.EqualValues(t, 2007+1909, streamed.ch.NodeCount())
+	assert.EqualValues(t, 2007, streamed.ch.URLCount())
+
+	t.Run("write back folder", func(t *testing.T) {
+		data := []byte(`{"roots": {"other": {"children": [
+			{"children": [
+				{"name": "a", "type": "url", "url": "https://a.com"},
+				{"children": [{"name": "c", "type": "url", "url": "https://c.com"}],
+				 "name": "sub", "type": "folder"}
+			 ], "meta_info": {"gosuki": "write-back"}, "name": "gosuki", "type": "folder"},
+			{"children": [
+				{"children": [{"name": "d", "type": "url", "url": "https://d.com"}],
+				 "meta_info": {"gosuki": "write-back"}, "name": "gosuki", "type": "folder"}
+			 ], "name": "folder", "type": "folder"},
+			{"name": "b \"quoted\"", "type": "url", "url": "https://b.com"}
+		], "name": "Other bookmarks", "type": "folder"}}}`)
+
+		streamed := requireSameParse(t, data)
+		want := []string{"2 Other bookmarks ", "2 folder ", `1 b "quoted" https://b.com`}
+		assert.Equal(t, want, flattenTree(streamed.root))
+		assert.EqualValues(t, 3, streamed.ch.NodeCount())
+		assert.EqualValues(t, 1, streamed.ch.URLCount())
+	})
+}
+
+func bookmark(guid, name, url string) jsonNode {
+	return jsonNode{"guid": guid, "name": name, "type": "url", "url": url, "date_added": "0"}
+}
+
+func folder(guid, name string, children ...any) jsonNode {
+	return jsonNode{"guid": guid, "name": name, "type": "folder", "children": children}
+}
+
+func writeTestBookmarks(t *testing.T, dir string, bar, other jsonNode) {
+	t.Helper()
+	data, err := json.Marshal(jsonNode{"roots": jsonNode{"bookmark_bar": bar, "other": other}})
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "Bookmarks"), data, 0o644))
+}
+
+func TestIncrementalRun(t *testing.T) {
+	dir := t.TempDir()
+	ch := newTestChrome(t, dir)
+
+	writeTestBookmarks(t, dir,
+		folder("bar", "Bookmarks bar",
+			bookmark("go", "Go", "https://go.dev"),
+			folder("work", "Work", bookmark("gosuki", "GoSuki", "https://gosuki.net")),
+		),
+		folder("other", "Other bookmarks", bookmark("old", "Old", "https://old.com")),
+	)
+	ch.Run()
+	require.Len(t, ch.entries, 6)
+
+	writeTestBookmarks(t, dir,
+		folder("bar", "Bookmarks bar",
+			folder("work", "Projects", bookmark("gosuki", "GoSuki", "https://gosuki.net")),
+			bookmark("new", "New", "https://new.com"),
+		),
+		folder("other", "Other bookmarks", bookmark("go", "Go", "https://go.dev")),
+	)
+
+	builder := newTreeBuilder(ch, true)
+	require.NoError(t, builder.parseBookmarks(filepath.Join(dir, "Bookmarks")))
+
+	kinds := map[string]string{}
+	for _, change := range diffEntries(ch.entries, builder.entries()) {
+		kinds[change.key] = change.kind.String()
+	}
+	assert.Equal(t, map[string]string{
+		"new":    "added",
+		"work":   "changed",
+		"gosuki": "changed", // folder renamed
+		"go":     "moved",
+		"old":    "removed",
+	}, kinds)
+
+	ch.Run()
+
+	var tags string
+	require.NoError(t, ch.BufferDB.Handle.Get(&tags,
+		`SELECT tags FROM gskbookmarks WHERE url = ?`, "https://gosuki.net"))
+	assert.Contains(t, tags, "Projects")
+
+	// tombstones leave the buffer once synced to the cache
+	var deleted bool
+	require.NoError(t, database.Cache.Handle.Get(&deleted,
+		`SELECT deleted FROM gskbookmarks WHERE url = ?`, "https://old.com"))
+	assert.True(t, deleted)
+
+	t.Run("unchanged", func(t *testing.T) {
+		builder := newTreeBuilder(ch, true)
+		require.NoError(t, builder.parseBookmarks(filepath.Join(dir, "Bookmarks")))
+		assert.Empty(t, diffEntries(ch.entries, builder.entries()))
+	})
+}
+
+func TestReadHistory(t *testing.T) {
+	dir := t.TempDir()
+	hist, err := sql.Open("sqlite3", filepath.Join(dir, HistoryFile))
+	assert.NoError(t, err)
+	_, err = hist.Exec(`CREATE TABLE urls (url TEXT, visit_count INTEGER, last_visit_time INTEGER);
+		INSERT INTO urls VALUES ('https://go.dev', 3, 13316659423000000),
+			('https://unvisited.org', 0, 0)`)
+	assert.NoError(t, err)
+	assert.NoError(t, hist.Close())
+
+	profile := Chrome{ChromeConfig: &ChromeConfig{
+		BrowserConfig: &modules.BrowserConfig{BkDir: dir},
+	}}
+	visits, err := profile.ReadHistory(context.Background(), nil)
+	assert.NoError(t, err)
+	if assert.Len(t, visits, 1) {
+		assert.Equal(t, "https://go.dev", visits[0].URL)
+		assert.EqualValues(t, 3, visits[0].Count)
+		assert.EqualValues(t, 1672185823, visits[0].LastVisit)
+	}
+}
+
+// snssFile builds a session file from commands
+type snssFile struct {
+	data []byte
+}
+
+func newSNSS() *snssFile {
+	return &snssFile{data: binary.LittleEndian.AppendUint32([]byte("SNSS"), 3)}
+}
+
+func (f *snssFile) command(id byte, payload []byte) *snssFile {
+	f.data = binary.LittleEndian.AppendUint16(f.data, uint16(len(payload)+1))
+	f.data = append(append(f.data, id), payload...)
+	return f
+}
+
+func int32s(values ...int32) []byte {
+	b := []byte{}
+	for _, v := range values {
+		b = binary.LittleEndian.AppendUint32(b, uint32(v))
+	}
+	return b
+}
+
+func pad(b []byte) []byte {
+	for len(b)%4 != 0 {
+		b = append(b, 0)
+	}
+	return b
+}
+
+func pickled(fields ...any) []byte {
+	b := []byte{}
+	for _, field := range fields {
+		switch v := field.(type) {
+		case int32:
+			b = append(b, int32s(v)...)
+		case string:
+			b = pad(append(append(b, int32s(int32(len(v)))...), v...))
+		case []uint16:
+			b = append(b, int32s(int32(len(v)))...)
+			for _, u := range v {
+				b = binary.LittleEndian.AppendUint16(b, u)
+			}
+			b = pad(b)
+		}
+	}
+	return append(int32s(int32(len(b))), b...)
+}
+
+func navigation(tab, index int32, url, title string) []byte {
+	return pickled(tab, index, url, utf16.Encode([]rune(title)))
+}
+
+func TestParseSNSS(t *testing.T) {
+	closed := append(int32s(12, 0), make([]byte, 8)...)
+	f := newSNSS().
+		command(cmdSetTabWindow, int32s(1, 10)).
+		command(cmdSetTabWindow, int32s(1, 11)).
+		command(cmdSetTabIndexInWindow, int32s(10, 1)).
+		command(cmdSetTabIndexInWindow, int32s(11, 0)).
+		command(cmdUpdateTabNavigation, navigation(10, 0, "https://a.com", "A")).
+		command(cmdUpdateTabNavigation, navigation(10, 1, "https://b.com", "B")).
+		command(cmdSetSelectedNavigationIndex, int32s(10, 0)).
+		command(cmdUpdateTabNavigation, navigation(11, 0, "https://c.com", "Ça va")).
+		command(cmdSetWindowUserTitle, pickled(int32(1), "Work")).
+		// closed tab
+		command(cmdSetTabWindow, int32s(1, 12)).
+		command(cmdUpdateTabNavigation, navigation(12, 0, "https://closed.com", "")).
+		command(cmdTabClosed, closed).
+		// closed window
+		command(cmdSetTabWindow, int32s(2, 13)).
+		command(cmdUpdateTabNavigation, navigation(13, 0, "https://window.com", "")).
+		command(cmdWindowClosed, int32s(2, 0, 0, 0))
+
+	// partially written command
+	data := append(f.data, 20, 0, cmdSetTabWindow, 1)
+
+	session, err := parseSNSS(data)
+	assert.NoError(t, err)
+	if assert.Len(t, session.Windows, 1) {
+		assert.Equal(t, "Work", session.Windows[0].Name)
+		assert.Equal(t, []*database.SessionTab{
+			{URL: "https://c.com", Title: "Ça va"},
+			{URL: "https://a.com", Title: "A"},
+		}, session.Windows[0].Tabs)
+	}
+
+	_, err = parseSNSS([]byte("{}"))
+	assert.ErrorIs(t, err, ErrNotSNSS)
+}
+
 func BenchmarkRun(b *testing.B) {
 	for i := 0; i < b.N; i++ {
 		ch.Run()
 	}
 }
+
+// Compares a full run with incremental runs on 50k bookmarks, the fixture is
+// created with go generate. Saving all the bookmarks of the full run takes
+// several minutes.
+func BenchmarkRun50k(b *testing.B) {
+	data, err := os.ReadFile(bookmarks50k)
+	if err != nil {
+		b.Skipf("%s not found, create it with go generate", bookmarks50k)
+	}
+	bkPath := filepath.Join(b.TempDir(), "Bookmarks")
+	require.NoError(b, os.WriteFile(bkPath, data, 0o644))
+	logging.SetLevel(logging.Silent)
+
+	// same file with one renamed bookmark
+	renamed := bytes.Replace(data, []byte(`"name": "`), []byte(`"name": "renamed `), 1)
+
+	ch := newTestChrome(b, filepath.Dir(bkPath))
+
+	b.Run("full", func(b *testing.B) {
+		for b.Loop() {
+			ch.entries = nil
+			ch.Run()
+		}
+	})
+
+	b.Run("incremental", func(b *testing.B) {
+		if ch.entries == nil {
+			ch.Run()
+		}
+		for b.Loop() {
+			ch.Run()
+		}
+	})
+
+	b.Run("incremental-rename", func(b *testing.B) {
+		if ch.entries == nil {
+			ch.Run()
+		}
+		for i := 0; b.Loop(); i++ {
+			b.StopTimer()
+			next := data
+			if i%2 == 0 {
+				next = renamed
+			}
+			require.NoError(b, os.WriteFile(bkPath, next, 0o644))
+			b.StartTimer()
+
+			ch.Run()
+		}
+	})
+
+	b.Run("parse-json", func(b *testing.B) {
+		for b.Loop() {
+			if err := newTreeBuilder(ch, false).parseJSON(data); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("parse-stream", func(b *testing.B) {
+		for b.Loop() {
+			if err := newTreeBuilder(ch, false).parseStream(bytes.NewReader(data)); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
@@ -38,6 +38,12 @@ type ChromeConfig struct {
 	*modules.BrowserConfig `toml:"-"`
 	modules.ProfilePrefs   `toml:"profile-options" mapstructure:"profile-options"`
 	CustomProfiles         []profiles.CustomProfile `toml:"custom-profiles" mapstructure:"custom-profiles"`
+
+	// What to do with bookmarks deleted from the browser: "mirror" or "archive"
+	Deletions modules.DeletionMode `toml:"deletions" mapstructure:"deletions"`
+
+	// Write gosuki bookmarks into a dedicated browser folder
+	WriteBack modules.WriteBackConfig `toml:"write-back" mapstructure:"write-back"`
 }
 
 var (
@@ -64,6 +70,8 @@ func NewChromeConfig() *ChromeConfig {
 			Profile:          DefaultProfile,
 			WatchAllProfiles: true,
 		},
+		Deletions: modules.MirrorDeletions,
+		WriteBack: modules.DefaultWriteBack(),
 	}
 
 	return config
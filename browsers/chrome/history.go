@@ -0,0 +1,73 @@
+//
+// Copyright (c) 2023-2025 Chakib Ben Ziane <contact@blob42.xyz> and [`GoSuki` contributors]
+// (https://github.com/blob42/gosuki/graphs/contributors).
+//
+// All rights reserved.
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+//
+// This file is part of GoSuki.
+//
+// GoSuki is free software: you can redistribute it and/or modify it under the terms of
+// the GNU Affero General Public License as published by the Free Software Foundation,
+// either version 3 of the License, or (at your option) any later version.
+//
+// GoSuki is distributed in the hope that it will be useful, but WITHOUT ANY WARRANTY;
+// without even the implied warranty of MERCHANTABILITY or FITNESS FOR A PARTICULAR
+// PURPOSE.  See the GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License along with
+// gosuki.  If not, see <http://www.gnu.org/licenses/>.
+
+package chrome
+
+import (
+	"context"
+	"path/filepath"
+
+	"github.com/blob42/gosuki/internal/database"
+	"github.com/blob42/gosuki/pkg/modules"
+	"github.com/blob42/gosuki/pkg/profiles"
+)
+
+// history database in the profile directory
+const HistoryFile = "History"
+
+// visited urls of the History database. Chrome times are in microseconds
+// since 1601-01-01.
+const qHistory = `
+	SELECT url, visit_count,
+		CASE WHEN last_visit_time > 0
+		THEN last_visit_time / 1000000 - 11644473600 ELSE 0 END AS last_visit
+	FROM urls WHERE visit_count > 0`
+
+// ReadHistory returns the visited urls of the profile from a copy of its
+// History database
+func (ch *Chrome) ReadHistory(ctx context.Context, p *profiles.Profile) ([]*database.Visit, error) {
+	dir, err := ch.profileDir(p)
+	if err != nil {
+		return nil, err
+	}
+
+	hist, done, err := modules.OpenHistoryCopy(filepath.Join(dir, HistoryFile))
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+
+	visits := []*database.Visit{}
+	if err = hist.Handle.SelectContext(ctx, &visits, qHistory); err != nil {
+		return nil, err
+	}
+	return visits, nil
+}
+
+// profileDir returns the directory of profile p, or of the profile in use
+func (ch *Chrome) profileDir(p *profiles.Profile) (string, error) {
+	if p == nil {
+		return ch.BkDir, nil
+	}
+	return p.AbsolutePath()
+}
+
+var _ modules.HistoryReader = (*Chrome)(nil)
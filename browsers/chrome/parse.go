@@ -0,0 +1,399 @@
+//
+// Copyright (c) 2023-2025 Chakib Ben Ziane <contact@blob42.xyz> and [`GoSuki` contributors]
+// (https://github.com/blob42/gosuki/graphs/contributors).
+//
+// All rights reserved.
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+//
+// This file is part of GoSuki.
+//
+// GoSuki is free software: you can redistribute it and/or modify it under the terms of
+// the GNU Affero General Public License as published by the Free Software Foundation,
+// either version 3 of the License, or (at your option) any later version.
+//
+// GoSuki is distributed in the hope that it will be useful, but WITHOUT ANY WARRANTY;
+// without even the implied warranty of MERCHANTABILITY or FITNESS FOR A PARTICULAR
+// PURPOSE.  See the GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License along with
+// gosuki.  If not, see <http://www.gnu.org/licenses/>.
+
+package chrome
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/buger/jsonparser"
+
+	"github.com/blob42/gosuki/pkg/events"
+	"github.com/blob42/gosuki/pkg/tree"
+)
+
+// Bookmarks files larger than this are parsed in streaming mode instead of
+// being loaded in memory.
+const streamParseSize = 8 << 20
+
+// nodeInfo holds the fields of a parsed node used to track it across runs
+type nodeInfo struct {
+	guid         string
+	dateModified string
+}
+
+// treeBuilder builds the node tree of a Bookmarks file from the nodes found by
+// the json or the streaming parser. Nodes are opened with begin before their
+// children are parsed and completed with end.
+type treeBuilder struct {
+	ch      *Chrome
+	runTask bool
+
+	root    *tree.Node
+	parents []*tree.Node
+	info    map[*tree.Node]nodeInfo
+
+	// completed nodes not counted yet of each open node, in the order of
+	// parents
+	pending []nodeCount
+}
+
+// nodeCount holds the number of nodes of a subtree. The nodes of a folder
+// opened before knowing whether gosuki wrote it back are only counted once the
+// folder is completed.
+type nodeCount struct {
+	nodes, urls int
+	known       bool // the node is not written back
+}
+
+func newTreeBuilder(ch *Chrome, runTask bool) *treeBuilder {
+	root := &tree.Node{
+		Title:  RootNodeName,
+		Parent: nil,
+		Type:   tree.RootNode,
+	}
+	return &treeBuilder{
+		ch:      ch,
+		runTask: runTask,
+		root:    root,
+		parents: []*tree.Node{root},
+		info:    make(map[*tree.Node]nodeInfo),
+		pending: []nodeCount{{known: true}},
+	}
+}
+
+// begin adds a new node to the current folder and makes it the current folder.
+// known tells whether the node is already known not to be written back.
+func (b *treeBuilder) begin(known bool) *tree.Node {
+	parent := b.parents[len(b.parents)-1]
+	node := &tree.Node{Parent: parent}
+	parent.Children = append(parent.Children, node)
+	b.parents = append(b.parents, node)
+	b.pending = append(b.pending, nodeCount{known: known})
+	return node
+}
+
+// end completes the current node with the fields of rawNode. Folders written
+// back by gosuki are dropped with their children.
+func (b *treeBuilder) end(rawNode *RawNode) {
+	node := b.parents[len(b.parents)-1]
+	b.parents = b.parents[:len(b.parents)-1]
+	count := b.pending[len(b.pending)-1]
+	b.pending = b.pending[:len(b.pending)-1]
+
+	// the node is the last child, its siblings come after it
+	if rawNode.writeBack {
+		parent := node.Parent
+		parent.Children = parent.Children[:len(parent.Children)-1]
+		b.forget(node)
+		return
+	}
+
+	rawNode.fillNode(b.ch, node)
+	b.info[node] = nodeInfo{
+		guid:         string(rawNode.guid),
+		dateModified: string(rawNode.dateModified),
+	}
+
+	count.nodes++
+	if node.Type == tree.URLNode {
+		count.urls++
+	}
+
+	parent := &b.pending[len(b.pending)-1]
+	if !parent.known {
+		parent.nodes += count.nodes
+		parent.urls += count.urls
+		return
+	}
+
+	for range count.nodes {
+		b.ch.IncNodeCount()
+	}
+	for range count.urls {
+		b.ch.IncURLCount()
+	}
+	if count.urls > 0 {
+		b.sendProgress()
+	}
+}
+
+// forget drops the info of the children of a node removed from the tree
+func (b *treeBuilder) forget(node *tree.Node) {
+	for _, child := range node.Children {
+		delete(b.info, child)
+		b.forget(child)
+	}
+}
+
+func (b *treeBuilder) sendProgress() {
+	ch := b.ch
+	progress := ch.Progress()
+	if progress-ch.lastSentProgress < 0.05 && progress != 1 {
+		return
+	}
+	ch.lastSentProgress = progress
+	go func() {
+		msg := events.ProgressUpdateMsg{
+			ID:           ch.ModInfo().ID,
+			Instance:     ch,
+			CurrentCount: ch.URLCount(),
+			Total:        ch.Total(),
+		}
+		if b.runTask {
+			msg.NewBk = true
+		}
+		events.TUIBus <- msg
+	}()
+}
+
+// parseBookmarks parses the Bookmarks file at path into b. Large files are
+// streamed.
+func (b *treeBuilder) parseBookmarks(path string) error {
+	stat, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+
+	if stat.Size() > streamParseSize {
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		return b.parseStream(f)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return b.parseJSON(data)
+}
+
+// parseJSON parses a Bookmarks file loaded in memory
+func (b *treeBuilder) parseJSON(data []byte) error {
+	// starts from the "roots" key of chrome json bookmark file
+	rootsData, _, _, err := jsonparser.Get(data, "roots")
+	if err != nil {
+		return fmt.Errorf("parsing roots: %w", err)
+	}
+
+	var parseNode func(node []byte, dataType jsonparser.ValueType)
+	parseNode = func(node []byte, dataType jsonparser.ValueType) {
+		// If node type is string ignore (needed for sync_transaction_version)
+		if dataType != jsonparser.Object {
+			return
+		}
+
+		rawNode := new(RawNode)
+		rawNode.parseItems(node)
+
+		// bookmarks written back by gosuki are not part of the browser state
+		if rawNode.writeBack {
+			return
+		}
+
+		b.begin(true)
+		if rawNode.childrenType == jsonparser.Array && len(rawNode.children) > 2 { // if len(children) > len("[]")
+			_, err := jsonparser.ArrayEach(node,
+				func(child []byte, dataType jsonparser.ValueType, _ int, err error) {
+					if err != nil {
+						log.Error(err)
+						return
+					}
+					parseNode(child, dataType)
+				}, jsonNodePaths.Children)
+			if err != nil {
+				log.Error(err)
+			}
+		}
+		b.end(rawNode)
+	}
+
+	return jsonparser.ObjectEach(rootsData,
+		func(_ []byte, node []byte, dataType jsonparser.ValueType, _ int) error {
+			parseNode(node, dataType)
+			return nil
+		})
+}
+
+var errUnexpectedToken = errors.New("unexpected json token")
+
+// parseStream parses a Bookmarks file one token at a time. Chrome writes the
+// children of a folder before its name so folders are completed once their
+// closing brace is read.
+func (b *treeBuilder) parseStream(r io.Reader) error {
+	dec := json.NewDecoder(bufio.NewReader(r))
+
+	if err := expectDelim(dec, '{'); err != nil {
+		return err
+	}
+	for dec.More() {
+		key, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		if key != "roots" {
+			if err := skipValue(dec); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if err := expectDelim(dec, '{'); err != nil {
+			return err
+		}
+		for dec.More() {
+			// root name
+			if _, err := dec.Token(); err != nil {
+				return err
+			}
+			if err := b.streamNode(dec); err != nil {
+				return err
+			}
+		}
+		if err := expectDelim(dec, '}'); err != nil {
+			return err
+		}
+	}
+	return expectDelim(dec, '}')
+}
+
+// streamNode parses the next value of dec as a node. Values other than
+// objects are skipped.
+func (b *treeBuilder) streamNode(dec *json.Decoder) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if tok != json.Delim('{') {
+		return skipToken(dec, tok)
+	}
+
+	// meta_info comes after the children, whether the node is written back
+	// is only known once it is complete
+	rawNode := new(RawNode)
+	b.begin(false)
+	for dec.More() {
+		key, err := dec.Token()
+		if err != nil {
+			return err
+		}
+
+		switch key {
+		case "children":
+			if err := expectDelim(dec, '['); err != nil {
+				return err
+			}
+			for dec.More() {
+				if err := b.streamNode(dec); err != nil {
+					return err
+				}
+			}
+			if err := expectDelim(dec, ']'); err != nil {
+				return err
+			}
+		case "meta_info":
+			var meta json.RawMessage
+			if err := dec.Decode(&meta); err != nil {
+				return err
+			}
+			rawNode.setMetaInfo(meta)
+		case "type", "name", "url", "date_added", "date_modified", "guid", "id":
+			var value string
+			if err := dec.Decode(&value); err != nil {
+				return err
+			}
+			switch key {
+			case "type":
+				rawNode.nType = []byte(value)
+			case "name":
+				rawNode.title = []byte(value)
+			case "url":
+				rawNode.url = []byte(value)
+			case "date_added":
+				rawNode.dateAdded = []byte(value)
+			case "date_modified":
+				rawNode.dateModified = []byte(value)
+			case "guid":
+				rawNode.guid = []byte(value)
+			case "id":
+				rawNode.id = []byte(value)
+			}
+		default:
+			if err := skipValue(dec); err != nil {
+				return err
+			}
+		}
+	}
+	if err := expectDelim(dec, '}'); err != nil {
+		return err
+	}
+
+	b.end(rawNode)
+	return nil
+}
+
+func expectDelim(dec *json.Decoder, delim json.Delim) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if tok != delim {
+		return fmt.Errorf("%w: %v, expected %v", errUnexpectedToken, tok, delim)
+	}
+	return nil
+}
+
+// skipValue skips the next value of dec
+func skipValue(dec *json.Decoder) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	return skipToken(dec, tok)
+}
+
+// skipToken skips the rest of the value starting with tok
+func skipToken(dec *json.Decoder, tok json.Token) error {
+	if tok != json.Delim('{') && tok != json.Delim('[') {
+		return nil
+	}
+	for depth := 1; depth > 0; {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		switch tok {
+		case json.Delim('{'), json.Delim('['):
+			depth++
+		case json.Delim('}'), json.Delim(']'):
+			depth--
+		}
+	}
+	return nil
+}
@@ -0,0 +1,286 @@
+//
+// Copyright (c) 2023-2025 Chakib Ben Ziane <contact@blob42.xyz> and [`GoSuki` contributors]
+// (https://github.com/blob42/gosuki/graphs/contributors).
+//
+// All rights reserved.
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+//
+// This file is part of GoSuki.
+//
+// GoSuki is free software: you can redistribute it and/or modify it under the terms of
+// the GNU Affero General Public License as published by the Free Software Foundation,
+// either version 3 of the License, or (at your option) any later version.
+//
+// GoSuki is distributed in the hope that it will be useful, but WITHOUT ANY WARRANTY;
+// without even the implied warranty of MERCHANTABILITY or FITNESS FOR A PARTICULAR
+// PURPOSE.  See the GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License along with
+// gosuki.  If not, see <http://www.gnu.org/licenses/>.
+
+package chrome
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"unicode/utf16"
+
+	"github.com/blob42/gosuki/internal/database"
+	"github.com/blob42/gosuki/pkg/modules"
+	"github.com/blob42/gosuki/pkg/profiles"
+)
+
+const (
+	// directory of the session files in the profile
+	SessionsDir = "Sessions"
+
+	// session file of older Chromium versions
+	CurrentSessionFile = "Current Session"
+)
+
+// Session files (SNSS) are a log of commands replayed to restore the
+// windows. Only the commands needed to rebuild the open tabs are read, see
+// components/sessions/core/session_service_commands.cc in Chromium.
+const (
+	cmdSetTabWindow               = 0
+	cmdSetTabIndexInWindow        = 2
+	cmdUpdateTabNavigation        = 6
+	cmdSetSelectedNavigationIndex = 7
+	cmdTabClosed                  = 16
+	cmdWindowClosed               = 17
+	cmdSetWindowUserTitle         = 31
+)
+
+var snssMagic = []byte("SNSS")
+
+var ErrNotSNSS = errors.New("not a session file")
+
+type snssTab struct {
+	window     int32
+	index      int32
+	selected   int32
+	navigation map[int32]*database.SessionTab
+}
+
+// SessionPath returns the directory holding the session files of the profile
+func (ch *Chrome) SessionPath(p *profiles.Profile) (string, error) {
+	dir, err := ch.profileDir(p)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, SessionsDir), nil
+}
+
+// ReadSession returns the windows open in the profile from its latest
+// session file
+func (ch *Chrome) ReadSession(ctx context.Context, p *profiles.Profile) (*database.Session, error) {
+	dir, err := ch.profileDir(p)
+	if err != nil {
+		return nil, err
+	}
+	files, err := filepath.Glob(filepath.Join(dir, SessionsDir, "Session_*"))
+	if err != nil {
+		return nil, err
+	}
+
+	// named after their creation time
+	path := filepath.Join(dir, CurrentSessionFile)
+	if len(files) > 0 {
+		path = slices.Max(files)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	session, err := parseSNSS(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	if info, err := os.Stat(path); err == nil {
+		session.TakenAt = uint64(info.ModTime().Unix())
+	}
+	return session, nil
+}
+
+func parseSNSS(data []byte) (*database.Session, error) {
+	if len(data) < 8 || !bytes.HasPrefix(data, snssMagic) {
+		return nil, ErrNotSNSS
+	}
+
+	var windows []int32
+	windowTitles := map[int32]string{}
+	tabs := map[int32]*snssTab{}
+	var tabOrder []int32
+
+	tab := func(id int32) *snssTab {
+		if t, ok := tabs[id]; ok {
+			return t
+		}
+		t := &snssTab{index: -1, selected: -1, navigation: map[int32]*database.SessionTab{}}
+		tabs[id] = t
+		tabOrder = append(tabOrder, id)
+		return t
+	}
+
+	// the file ends with a partially written command when the browser crashed
+	for i := 8; i+3 <= len(data); {
+		size := int(binary.LittleEndian.Uint16(data[i:]))
+		if size == 0 || i+2+size > len(data) {
+			break
+		}
+		id := data[i+2]
+		payload := data[i+3 : i+2+size]
+		i += 2 + size
+
+		switch id {
+		case cmdSetTabWindow:
+			if len(payload) < 8 {
+				continue
+			}
+			window := readInt32(payload, 0)
+			tab(readInt32(payload, 4)).window = window
+			if !slices.Contains(windows, window) {
+				windows = append(windows, window)
+			}
+
+		case cmdSetTabIndexInWindow:
+			if len(payload) < 8 {
+				continue
+			}
+			tab(readInt32(payload, 0)).index = readInt32(payload, 4)
+
+		case cmdSetSelectedNavigationIndex:
+			if len(payload) < 8 {
+				continue
+			}
+			tab(readInt32(payload, 0)).selected = readInt32(payload, 4)
+
+		case cmdUpdateTabNavigation:
+			p := pickle{data: payload}
+			tabID, index := p.int32(), p.int32()
+			url, title := p.string(), p.string16()
+			if p.err != nil {
+				continue
+			}
+			tab(tabID).navigation[index] = &database.SessionTab{URL: url, Title: title}
+
+		case cmdSetWindowUserTitle:
+			p := pickle{data: payload}
+			window, title := p.int32(), p.string()
+			if p.err == nil {
+				windowTitles[window] = title
+			}
+
+		case cmdTabClosed:
+			if len(payload) < 4 {
+				continue
+			}
+			delete(tabs, readInt32(payload, 0))
+
+		case cmdWindowClosed:
+			if len(payload) < 4 {
+				continue
+			}
+			closed := readInt32(payload, 0)
+			windows = slices.DeleteFunc(windows, func(w int32) bool { return w == closed })
+		}
+	}
+
+	session := &database.Session{}
+	for _, w := range windows {
+		var ids []int32
+		for _, id := range tabOrder {
+			// ids of closed tabs can be reused
+			if slices.Contains(ids, id) {
+				continue
+			}
+			if t, ok := tabs[id]; ok && t.window == w && len(t.navigation) > 0 {
+				ids = append(ids, id)
+			}
+		}
+		// tabs without an index keep the order of the file
+		slices.SortStableFunc(ids, func(a, b int32) int {
+			return int(tabs[a].index) - int(tabs[b].index)
+		})
+
+		window := &database.SessionWindow{Name: windowTitles[w]}
+		for _, id := range ids {
+			window.Tabs = append(window.Tabs, tabs[id].current())
+		}
+		if len(window.Tabs) > 0 {
+			session.Windows = append(session.Windows, window)
+		}
+	}
+
+	return session, nil
+}
+
+// current returns the selected navigation entry of the tab, or its last one
+func (t *snssTab) current() *database.SessionTab {
+	if nav, ok := t.navigation[t.selected]; ok {
+		return nav
+	}
+	last := slices.Max(slices.Collect(maps.Keys(t.navigation)))
+	return t.navigation[last]
+}
+
+func readInt32(b []byte, off int) int32 {
+	return int32(binary.LittleEndian.Uint32(b[off:]))
+}
+
+// pickle reads the fields of a Chromium base::Pickle. The payload starts
+// with its uint32 size and fields are aligned on 4 bytes.
+type pickle struct {
+	data []byte
+	off  int
+	err  error
+}
+
+func (p *pickle) next(n int) []byte {
+	if p.err != nil {
+		return nil
+	}
+	if p.off == 0 {
+		p.off = 4
+	}
+	if n < 0 || p.off+n > len(p.data) {
+		p.err = errors.New("pickle: out of bounds")
+		return nil
+	}
+	b := p.data[p.off : p.off+n]
+	p.off += (n + 3) &^ 3
+	return b
+}
+
+func (p *pickle) int32() int32 {
+	b := p.next(4)
+	if b == nil {
+		return 0
+	}
+	return readInt32(b, 0)
+}
+
+func (p *pickle) string() string {
+	return string(p.next(int(p.int32())))
+}
+
+func (p *pickle) string16() string {
+	b := p.next(2 * int(p.int32()))
+	units := make([]uint16, len(b)/2)
+	for i := range units {
+		units[i] = binary.LittleEndian.Uint16(b[2*i:])
+	}
+	return string(utf16.Decode(units))
+}
+
+var _ modules.SessionReader = (*Chrome)(nil)
@@ -0,0 +1,327 @@
+//
+// Copyright (c) 2023-2025 Chakib Ben Ziane <contact@blob42.xyz> and [`GoSuki` contributors]
+// (https://github.com/blob42/gosuki/graphs/contributors).
+//
+// All rights reserved.
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+//
+// This file is part of GoSuki.
+//
+// GoSuki is free software: you can redistribute it and/or modify it under the terms of
+// the GNU Affero General Public License as published by the Free Software Foundation,
+// either version 3 of the License, or (at your option) any later version.
+//
+// GoSuki is distributed in the hope that it will be useful, but WITHOUT ANY WARRANTY;
+// without even the implied warranty of MERCHANTABILITY or FITNESS FOR A PARTICULAR
+// PURPOSE.  See the GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License along with
+// gosuki.  If not, see <http://www.gnu.org/licenses/>.
+
+package chrome
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"time"
+	"unicode/utf16"
+
+	"github.com/gofrs/uuid"
+
+	"github.com/blob42/gosuki"
+	"github.com/blob42/gosuki/internal/utils"
+	"github.com/blob42/gosuki/pkg/modules"
+)
+
+// Writing bookmarks back to Chrome.
+//
+// Chrome keeps its bookmarks in memory and overwrites the Bookmarks file on
+// every change, it does not reload the file. Bookmarks are only written while
+// the browser is closed. The file holds an md5 checksum of the bookmark tree
+// that must be updated, Chrome discards the file otherwise.
+//
+// The gosuki folder is created under "Other bookmarks". It is marked with a
+// `meta_info` key so it can be found again if renamed or moved by the user.
+
+const (
+	// meta_info key marking the folder written by gosuki
+	writeBackMetaKey = "gosuki"
+
+	// Chrome timestamps are microseconds since 1601-01-01 UTC
+	chromeEpochOffset = 11644473600000000
+)
+
+// checksummed roots in the order used by Chrome
+var checksumRoots = []string{"bookmark_bar", "other", "synced"}
+
+// files held by a running Chrome in its user data directory
+var singletonFiles = []string{"SingletonLock", "lockfile"}
+
+type jsonNode = map[string]any
+
+func (ch *Chrome) WriteConfig() *modules.WriteBackConfig {
+	return &ChromeCfg.WriteBack
+}
+
+// WriteBookmarks replaces the content of the gosuki folder in the Bookmarks
+// file. Returns [modules.ErrBrowserBusy] if Chrome is running.
+func (ch *Chrome) WriteBookmarks(bks []*gosuki.Bookmark) error {
+	if isRunning(ch.BkDir) {
+		return modules.ErrBrowserBusy
+	}
+
+	bkPath, err := ch.BookmarkPath()
+	if err != nil {
+		return err
+	}
+
+	data, err := os.ReadFile(bkPath)
+	if err != nil {
+		return err
+	}
+
+	out, changed, err := writeFolder(data, ChromeCfg.WriteBack.FolderName(), bks, time.Now())
+	if err != nil {
+		return fmt.Errorf("%s: %w", bkPath, err)
+	}
+	if !changed {
+		return nil
+	}
+
+	log.Debug("writing bookmarks", "file", bkPath)
+	return utils.WriteFileAtomic(bkPath, out)
+}
+
+// Chrome holds a lock in the user data directory, the parent of the profile
+// directory
+func isRunning(profileDir string) bool {
+	userDataDir := filepath.Dir(profileDir)
+	for _, name := range singletonFiles {
+		if _, err := os.Lstat(filepath.Join(userDataDir, name)); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+func chromeTime(t time.Time) string {
+	return strconv.FormatInt(t.UnixMicro()+chromeEpochOffset, 10)
+}
+
+// returns the unix time of a Chrome timestamp or 0 if invalid
+func fromChromeTime(s string) uint64 {
+	us, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || us <= chromeEpochOffset {
+		return 0
+	}
+	return uint64((us - chromeEpochOffset) / 1_000_000)
+}
+
+func str(v any) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	}
+	return ""
+}
+
+func children(node jsonNode) []any {
+	c, _ := node["children"].([]any)
+	return c
+}
+
+func isWriteBackFolder(node jsonNode) bool {
+	meta, ok := node["meta_info"].(map[string]any)
+	return ok && str(node["type"]) == "folder" && meta[writeBackMetaKey] != nil
+}
+
+// walk calls fn on node and its descendants. The children of the gosuki
+// folder are not visited.
+func walk(node jsonNode, fn func(node, parent jsonNode)) {
+	var rec func(node, parent jsonNode)
+	rec = func(node, parent jsonNode) {
+		fn(node, parent)
+		if isWriteBackFolder(node) {
+			return
+		}
+		for _, c := range children(node) {
+			if child, ok := c.(jsonNode); ok {
+				rec(child, node)
+			}
+		}
+	}
+	rec(node, nil)
+}
+
+// writeFolder returns the Bookmarks file data with the gosuki folder holding
+// bks. Bookmarks already present in the file are skipped. The folder is
+// removed when there is nothing to write.
+func writeFolder(data []byte, folder string, bks []*gosuki.Bookmark, now time.Time) ([]byte, bool, error) {
+	var doc jsonNode
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(&doc); err != nil {
+		return nil, false, err
+	}
+
+	roots, ok := doc["roots"].(jsonNode)
+	if !ok {
+		return nil, false, errors.New("missing roots")
+	}
+	other, ok := roots["other"].(jsonNode)
+	if !ok {
+		return nil, false, errors.New("missing other bookmarks root")
+	}
+
+	var maxID int64
+	var current, currentParent jsonNode
+	known := map[string]bool{}
+
+	for _, key := range checksumRoots {
+		root, ok := roots[key].(jsonNode)
+		if !ok {
+			continue
+		}
+		walk(root, func(node, parent jsonNode) {
+			if id, err := strconv.ParseInt(str(node["id"]), 10, 64); err == nil {
+				maxID = max(maxID, id)
+			}
+			if isWriteBackFolder(node) {
+				current, currentParent = node, parent
+			} else if str(node["type"]) == "url" {
+				known[str(node["url"])] = true
+			}
+		})
+	}
+
+	type entry struct{ url, title string }
+
+	var have, want []entry
+	if current != nil {
+		for _, c := range children(current) {
+			if node, ok := c.(jsonNode); ok {
+				have = append(have, entry{str(node["url"]), str(node["name"])})
+			}
+		}
+	}
+	for _, bk := range bks {
+		if known[bk.URL] {
+			continue
+		}
+		known[bk.URL] = true
+		want = append(want, entry{bk.URL, bk.Title})
+	}
+
+	if slices.Equal(have, want) && (current == nil || str(current["name"]) == folder) {
+		return data, false, nil
+	}
+
+	// detach the current folder, it is rebuilt with new ids
+	if current != nil {
+		currentParent["children"] = slices.DeleteFunc(children(currentParent), func(c any) bool {
+			node, ok := c.(jsonNode)
+			return ok && isWriteBackFolder(node)
+		})
+	}
+
+	if len(want) > 0 {
+		nextID := func() string {
+			maxID++
+			return strconv.FormatInt(maxID, 10)
+		}
+
+		added := chromeTime(now)
+		parent := other
+		fNode := jsonNode{
+			"date_added":    added,
+			"date_modified": added,
+			"guid":          uuid.Must(uuid.NewV4()).String(),
+			"id":            nextID(),
+			"meta_info":     jsonNode{writeBackMetaKey: "write-back"},
+			"name":          folder,
+			"type":          "folder",
+		}
+		if current != nil {
+			// keep the identity and position chosen by the user
+			fNode["date_added"] = current["date_added"]
+			fNode["guid"] = current["guid"]
+			parent = currentParent
+		}
+
+		items := make([]any, 0, len(want))
+		for _, e := range want {
+			items = append(items, jsonNode{
+				"date_added": added,
+				"guid":       uuid.Must(uuid.NewV4()).String(),
+				"id":         nextID(),
+				"name":       e.title,
+				"type":       "url",
+				"url":        e.url,
+			})
+		}
+		fNode["children"] = items
+		parent["children"] = append(children(parent), fNode)
+	}
+
+	doc["checksum"] = checksum(roots)
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "   ")
+	if err := enc.Encode(doc); err != nil {
+		return nil, false, err
+	}
+
+	return buf.Bytes(), true, nil
+}
+
+// checksum computes the checksum of the bookmark roots the same way Chrome
+// does (see components/bookmarks/browser/bookmark_codec.cc)
+func checksum(roots jsonNode) string {
+	h := md5.New()
+
+	for _, key := range checksumRoots {
+		if root, ok := roots[key].(jsonNode); ok {
+			checksumNode(h, root)
+		}
+	}
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func checksumNode(h hash.Hash, node jsonNode) {
+	h.Write([]byte(str(node["id"])))
+
+	// titles are hashed as UTF-16 strings
+	for _, r := range utf16.Encode([]rune(str(node["name"]))) {
+		h.Write([]byte{byte(r), byte(r >> 8)})
+	}
+
+	if str(node["type"]) == "url" {
+		h.Write([]byte("url"))
+		h.Write([]byte(str(node["url"])))
+		return
+	}
+
+	h.Write([]byte("folder"))
+	for _, c := range children(node) {
+		if child, ok := c.(jsonNode); ok {
+			checksumNode(h, child)
+		}
+	}
+}
+
+var _ modules.BookmarkWriter = (*Chrome)(nil)
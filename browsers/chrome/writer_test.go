@@ -0,0 +1,99 @@
+package chrome
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/blob42/gosuki"
+)
+
+func decodeBookmarks(t *testing.T, data []byte) jsonNode {
+	t.Helper()
+	var doc jsonNode
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	require.NoError(t, dec.Decode(&doc))
+	return doc
+}
+
+const writeBackBookmarks = "testdata/writeback/Bookmarks"
+
+func TestChecksum(t *testing.T) {
+	data, err := os.ReadFile(writeBackBookmarks)
+	require.NoError(t, err)
+
+	doc := decodeBookmarks(t, data)
+	assert.Equal(t, doc["checksum"], checksum(doc["roots"].(jsonNode)))
+}
+
+func TestWriteFolder(t *testing.T) {
+	data, err := os.ReadFile(writeBackBookmarks)
+	require.NoError(t, err)
+
+	bks := []*gosuki.Bookmark{
+		{URL: "https://go.dev/", Title: "already in chrome"},
+		{URL: "https://gosuki.net", Title: "GoSuki"},
+		{URL: "https://example.com", Title: "Exämple"},
+	}
+	now := time.Now()
+
+	out, changed, err := writeFolder(data, "gosuki", bks, now)
+	require.NoError(t, err)
+	require.True(t, changed)
+
+	doc := decodeBookmarks(t, out)
+	roots := doc["roots"].(jsonNode)
+	assert.Equal(t, doc["checksum"], checksum(roots))
+
+	other := roots["other"].(jsonNode)
+	otherChildren := children(other)
+	folder := otherChildren[len(otherChildren)-1].(jsonNode)
+	assert.True(t, isWriteBackFolder(folder))
+	assert.Equal(t, "gosuki", folder["name"])
+
+	items := children(folder)
+	require.Len(t, items, 2)
+	assert.Equal(t, "https://gosuki.net", items[0].(jsonNode)["url"])
+	assert.Equal(t, "Exämple", items[1].(jsonNode)["name"])
+
+	t.Run("up to date", func(t *testing.T) {
+		_, changed, err := writeFolder(out, "gosuki", bks, now)
+		require.NoError(t, err)
+		assert.False(t, changed)
+	})
+
+	t.Run("parser skips folder", func(t *testing.T) {
+		raw, err := json.Marshal(folder)
+		require.NoError(t, err)
+
+		rawNode := new(RawNode)
+		rawNode.parseItems(raw)
+		assert.True(t, rawNode.writeBack)
+	})
+
+	t.Run("remove folder", func(t *testing.T) {
+		out, changed, err := writeFolder(out, "gosuki", nil, now)
+		require.NoError(t, err)
+		require.True(t, changed)
+
+		// back to the original tree
+		doc := decodeBookmarks(t, out)
+		assert.Equal(t, "3ffabfab8fdea05ba18d6a4643cd66bd", doc["checksum"])
+		assert.Empty(t, children(doc["roots"].(jsonNode)["other"].(jsonNode)))
+	})
+}
+
+func TestFromChromeTime(t *testing.T) {
+	assert.EqualValues(t, 1725526400, fromChromeTime("13370000000000000"))
+	assert.Zero(t, fromChromeTime("0"))
+	assert.Zero(t, fromChromeTime(""))
+
+	now := time.Unix(time.Now().Unix(), 0)
+	assert.EqualValues(t, now.Unix(), fromChromeTime(chromeTime(now)))
+}
@@ -0,0 +1,286 @@
+//
+// Copyright (c) 2023-2025 Chakib Ben Ziane <contact@blob42.xyz> and [`GoSuki` contributors]
+// (https://github.com/blob42/gosuki/graphs/contributors).
+//
+// All rights reserved.
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+//
+// This file is part of GoSuki.
+//
+// GoSuki is free software: you can redistribute it and/or modify it under the terms of
+// the GNU Affero General Public License as published by the Free Software Foundation,
+// either version 3 of the License, or (at your option) any later version.
+//
+// GoSuki is distributed in the hope that it will be useful, but WITHOUT ANY WARRANTY;
+// without even the implied warranty of MERCHANTABILITY or FITNESS FOR A PARTICULAR
+// PURPOSE.  See the GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License along with
+// gosuki.  If not, see <http://www.gnu.org/licenses/>.
+
+// Package epiphany implements the GNOME Web (Epiphany) browser module.
+//
+// Epiphany keeps its bookmarks in the `bookmarks` table of a gvdb file, keyed
+// by url. Bookmarks have no folders, their tags are kept as gosuki tags.
+package epiphany
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+
+	"github.com/blob42/gosuki"
+	"github.com/blob42/gosuki/hooks"
+	"github.com/blob42/gosuki/internal/database"
+	"github.com/blob42/gosuki/internal/utils"
+	"github.com/blob42/gosuki/pkg/events"
+	"github.com/blob42/gosuki/pkg/modules"
+	"github.com/blob42/gosuki/pkg/parsing"
+	"github.com/blob42/gosuki/pkg/watch"
+)
+
+// Epiphany browser module
+type Epiphany struct {
+	// holds browsers.BrowserConfig
+	*EpiphanyConfig
+	parsing.Counter
+}
+
+// ReadBookmarks reads the bookmarks of the gvdb file at path. Bookmark values
+// are (xssdbas) tuples: time added in microseconds, title, sync id, server
+// modification time, uploaded flag and tags.
+func ReadBookmarks(path string) ([]*gosuki.Bookmark, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	root, err := openGVDB(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	table, err := root.table("bookmarks")
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	items, err := table.items()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	var result []*gosuki.Bookmark
+	for _, item := range items {
+		if item.typ != 'v' {
+			continue
+		}
+
+		value, err := table.value(item)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s: %w", path, item.key, err)
+		}
+
+		bk, err := parseBookmark(item.key, value)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		result = append(result, bk)
+	}
+
+	return result, nil
+}
+
+func parseBookmark(url string, value any) (*gosuki.Bookmark, error) {
+	fields, ok := value.([]any)
+	if !ok || len(fields) < 2 {
+		return nil, fmt.Errorf("%s: unexpected bookmark value: %v", url, value)
+	}
+
+	bk := &gosuki.Bookmark{URL: url}
+
+	if added, ok := fields[0].(int64); ok && added > 0 {
+		bk.Created = uint64(added / int64(time.Second/time.Microsecond))
+	}
+	bk.Title, _ = fields[1].(string)
+
+	// tags are the last field
+	if tags, ok := fields[len(fields)-1].([]any); ok {
+		for _, tag := range tags {
+			if tag, ok := tag.(string); ok && tag != "" {
+				bk.Tags = append(bk.Tags, tag)
+			}
+		}
+	}
+
+	return bk, nil
+}
+
+// Detect implements modules.Detector.
+func (ep *Epiphany) Detect() ([]modules.Detected, error) {
+	res := []modules.Detected{}
+	bPath, err := utils.ExpandOnly(ep.BaseDir)
+	if err != nil {
+		return res, err
+	}
+	exist, err := utils.DirExists(bPath)
+	if err != nil && exist {
+		return res, err
+	} else if exist {
+		res = append(res, modules.Detected{
+			Flavour:  BrowserName,
+			BasePath: bPath,
+		})
+	}
+
+	return res, nil
+}
+
+func (ep *Epiphany) Init(_ *modules.Context) error {
+	var err error
+
+	ep.BkDir, err = utils.ExpandPath(ep.BkDir)
+	if err != nil {
+		return fmt.Errorf("expanding %s : %w", ep.BkDir, err)
+	}
+
+	return ep.setupWatchers()
+}
+
+func (ep *Epiphany) setupWatchers() error {
+	bookmarkPath, err := ep.BookmarkPath()
+	if err != nil {
+		return err
+	}
+
+	// the bookmark file is replaced on each change
+	w := &watch.Watch{
+		Path:       ep.BkDir,
+		EventTypes: []fsnotify.Op{fsnotify.Create, fsnotify.Write},
+		EventNames: []string{bookmarkPath},
+	}
+
+	ok, err := modules.SetupWatchers(ep.BrowserConfig, w)
+	if err != nil {
+		log.Error(err)
+		return modules.ErrWatcherSetup
+	}
+	if !ok {
+		return modules.ErrWatcherSetup
+	}
+
+	return nil
+}
+
+func (ep Epiphany) Config() *modules.BrowserConfig {
+	return ep.BrowserConfig
+}
+
+func (ep Epiphany) ModInfo() modules.ModInfo {
+	return modules.ModInfo{
+		ID: modules.ModID(ep.Name),
+		New: func() modules.Module {
+			return NewEpiphany()
+		},
+	}
+}
+
+func (ep *Epiphany) Run() {
+	if err := ep.load(true); err != nil {
+		log.Error(err)
+	}
+}
+
+func (ep *Epiphany) load(runTask bool) error {
+	startWork := time.Now()
+
+	bkPath, err := ep.BookmarkPath()
+	if err != nil {
+		return err
+	}
+
+	bookmarks, err := ReadBookmarks(bkPath)
+	if err != nil {
+		return err
+	}
+	ep.SetLastTreeParseRuntime(time.Since(startWork))
+
+	urls := make([]string, 0, len(bookmarks))
+	for _, bk := range bookmarks {
+		bk.Module = ep.Name
+		bk.Sources = []gosuki.Source{{Module: ep.Name}}
+
+		if err = ep.CallHooks(bk); err != nil {
+			return err
+		}
+
+		if err = ep.BufferDB.UpsertBookmark(bk); err != nil {
+			log.Errorf("db upsert: %s", bk.URL)
+		}
+		urls = append(urls, bk.URL)
+		ep.IncURLCount()
+	}
+
+	ep.SetTotal(uint(len(urls)))
+	go func() {
+		events.TUIBus <- events.ProgressUpdateMsg{
+			ID:           ep.ModInfo().ID,
+			Instance:     ep,
+			CurrentCount: ep.URLCount(),
+			Total:        ep.Total(),
+			NewBk:        runTask,
+		}
+	}()
+
+	ep.TrackDeletions(ep.Name, urls, ep.Deletions)
+	log.Debugf("<%s> loaded %d bookmarks in %s", ep.Name, len(urls), ep.LastFullTreeParseRT())
+
+	err = ep.BufferDB.SyncToCache()
+	if err != nil {
+		log.Errorf("<%s>: %v", ep.Name, err)
+	}
+
+	database.ScheduleBackupToDisk()
+	ep.SetLastWatchRuntime(time.Since(startWork))
+
+	return err
+}
+
+func (ep *Epiphany) PreLoad(_ *modules.Context) error {
+	return ep.load(false)
+}
+
+func (ep *Epiphany) Watch() *watch.WatchDescriptor {
+	// calls modules.BrowserConfig.GetWatcher()
+	return ep.GetWatcher()
+}
+
+// Implement modules.Shutdowner
+func (ep *Epiphany) Shutdown() error {
+	return nil
+}
+
+func NewEpiphany() *Epiphany {
+	return &Epiphany{
+		EpiphanyConfig: EpiphanyCfg,
+		Counter:        &parsing.BrowserCounter{},
+	}
+}
+
+func init() {
+	modules.RegisterBrowser(Epiphany{EpiphanyConfig: EpiphanyCfg})
+}
+
+// interface guards
+
+var _ modules.BrowserModule = (*Epiphany)(nil)
+var _ modules.Initializer = (*Epiphany)(nil)
+
+var _ modules.Detector = (*Epiphany)(nil)
+var _ watch.WatchRunner = (*Epiphany)(nil)
+var _ modules.PreLoader = (*Epiphany)(nil)
+var _ parsing.Counter = (*Epiphany)(nil)
+var _ hooks.HookRunner = (*Epiphany)(nil)
@@ -0,0 +1,110 @@
+package epiphany
+
+import (
+	"context"
+	"encoding/binary"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/blob42/gosuki/internal/database"
+	"github.com/blob42/gosuki/pkg/logging"
+	"github.com/blob42/gosuki/pkg/modules"
+)
+
+const testBookmarks = "testdata/bookmarks.gvdb"
+
+func TestMain(m *testing.M) {
+	database.RegisterSqliteHooks()
+
+	cacheDB, err := database.NewDB(database.CacheName, "", database.DBTypeCacheDSN).Init()
+	if err != nil {
+		log.Fatal(err)
+	}
+	database.Cache = &database.CacheDB{DB: cacheDB}
+
+	os.Exit(m.Run())
+}
+
+func TestDecodeVariant(t *testing.T) {
+	le := binary.LittleEndian
+
+	for _, tc := range []struct {
+		typ   string
+		data  []byte
+		value any
+	}{
+		{"b", []byte{1}, true},
+		{"x", []byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}, int64(-1)},
+		{"s", []byte("gosuki\x00"), "gosuki"},
+		{"au", []byte{1, 0, 0, 0, 2, 0, 0, 0}, []any{uint32(1), uint32(2)}},
+		{"as", []byte("a\x00bc\x00\x02\x05"), []any{"a", "bc"}},
+		{"as", []byte{}, []any{}},
+		{"ms", []byte("a\x00\x00"), []any{"a"}},
+		{"(sy)", []byte("ab\x00\x07\x03"), []any{"ab", uint8(7)}},
+		{"(ys)", []byte("\x07ab\x00"), []any{uint8(7), "ab"}},
+		{"(ssu)", []byte("a\x00b\x00\x2a\x00\x00\x00\x04\x02"), []any{"a", "b", uint32(42)}},
+		{"a(sx)", []byte("a\x00\x00\x00\x00\x00\x00\x00\x01\x00\x00\x00\x00\x00\x00\x00\x02\x11"),
+			[]any{[]any{"a", int64(1)}}},
+		{"v", []byte("ab\x00\x00s"), "ab"},
+	} {
+		value, err := decodeVariant(tc.typ, tc.data, le)
+		require.NoError(t, err, tc.typ)
+		assert.Equal(t, tc.value, value, tc.typ)
+	}
+
+	for typ, data := range map[string][]byte{
+		"x":     {1, 2},
+		"s":     []byte("ab"),
+		"as":    []byte("a\x00\x09"),
+		"(ss)":  []byte("a\x00b\x00\x09"),
+		"(s":    {},
+		"z":     {},
+		"a{sv}": []byte("\x00"),
+	} {
+		_, err := decodeVariant(typ, data, le)
+		assert.Error(t, err, typ)
+	}
+}
+
+func TestReadBookmarks(t *testing.T) {
+	bookmarks, err := ReadBookmarks(testBookmarks)
+	require.NoError(t, err)
+	require.Len(t, bookmarks, 3)
+
+	gnome := bookmarks[0]
+	assert.Equal(t, "https://gnome.org", gnome.URL)
+	assert.Equal(t, "GNOME", gnome.Title)
+	assert.Equal(t, []string{"Favorites", "desktop"}, gnome.Tags)
+	assert.EqualValues(t, 1673778600, gnome.Created)
+
+	assert.Equal(t, "Go #lang", bookmarks[1].Title)
+	assert.Empty(t, bookmarks[1].Tags)
+
+	assert.Zero(t, bookmarks[2].Created)
+	assert.Equal(t, []string{"desktop"}, bookmarks[2].Tags)
+
+	t.Run("not a gvdb file", func(t *testing.T) {
+		_, err := ReadBookmarks("epiphany.go")
+		assert.ErrorIs(t, err, ErrNotGVDB)
+	})
+}
+
+func TestLoad(t *testing.T) {
+	logging.SetLevel(logging.Silent)
+
+	ep := &Epiphany{
+		EpiphanyConfig: NewEpiphanyConfig(),
+		Counter:        NewEpiphany().Counter,
+	}
+	ep.BkDir = "testdata"
+	require.NoError(t, modules.SetupBrowser(ep, &modules.Context{}, nil))
+	assert.EqualValues(t, 3, ep.URLCount())
+
+	bk, err := ep.BufferDB.BookmarkByURL(context.Background(), "https://go.dev")
+	require.NoError(t, err)
+	assert.Equal(t, "epiphany", bk.Module)
+	assert.Equal(t, ",lang,", bk.Tags)
+}
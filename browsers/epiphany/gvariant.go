@@ -0,0 +1,331 @@
+//
+// Copyright (c) 2023-2025 Chakib Ben Ziane <contact@blob42.xyz> and [`GoSuki` contributors]
+// (https://github.com/blob42/gosuki/graphs/contributors).
+//
+// All rights reserved.
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+//
+// This file is part of GoSuki.
+//
+// GoSuki is free software: you can redistribute it and/or modify it under the terms of
+// the GNU Affero General Public License as published by the Free Software Foundation,
+// either version 3 of the License, or (at your option) any later version.
+//
+// GoSuki is distributed in the hope that it will be useful, but WITHOUT ANY WARRANTY;
+// without even the implied warranty of MERCHANTABILITY or FITNESS FOR A PARTICULAR
+// PURPOSE.  See the GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License along with
+// gosuki.  If not, see <http://www.gnu.org/licenses/>.
+
+package epiphany
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+)
+
+var errGVariantType = errors.New("gvariant: invalid type")
+
+// decodeVariant decodes serialized GVariant data of type typ. Numbers are
+// returned with their Go type (int64 for `x`, float64 for `d` ...), strings as
+// string, arrays, maybes and tuples as []any and variants as their value.
+// Framing offsets are always little endian, order applies to the numbers.
+//
+// See https://developer.gnome.org/documentation/specifications/gvariant-specification-1.0.html
+func decodeVariant(typ string, data []byte, order binary.ByteOrder) (any, error) {
+	if typ == "" {
+		return nil, errGVariantType
+	}
+
+	if _, size, err := typeInfo(typ); err != nil {
+		return nil, err
+	} else if size > 0 && len(data) != size {
+		return nil, fmt.Errorf("gvariant: %s: expected %d bytes, got %d", typ, size, len(data))
+	}
+
+	switch typ[0] {
+	case 'b':
+		return data[0] != 0, nil
+	case 'y':
+		return data[0], nil
+	case 'n':
+		return int16(order.Uint16(data)), nil
+	case 'q':
+		return order.Uint16(data), nil
+	case 'i', 'h':
+		return int32(order.Uint32(data)), nil
+	case 'u':
+		return order.Uint32(data), nil
+	case 'x':
+		return int64(order.Uint64(data)), nil
+	case 't':
+		return order.Uint64(data), nil
+	case 'd':
+		return math.Float64frombits(order.Uint64(data)), nil
+
+	case 's', 'o', 'g':
+		if len(data) == 0 {
+			return "", nil
+		}
+		if data[len(data)-1] != 0 {
+			return nil, fmt.Errorf("gvariant: unterminated string")
+		}
+		return string(data[:len(data)-1]), nil
+
+	case 'v':
+		sep := bytes.LastIndexByte(data, 0)
+		if sep < 0 {
+			return nil, fmt.Errorf("gvariant: variant without type")
+		}
+		return decodeVariant(string(data[sep+1:]), data[:sep], order)
+
+	case 'm':
+		if len(data) == 0 {
+			return []any{}, nil
+		}
+		elem := typ[1:]
+		if _, size, _ := typeInfo(elem); size == 0 {
+			data = data[:len(data)-1]
+		}
+		value, err := decodeVariant(elem, data, order)
+		return []any{value}, err
+
+	case 'a':
+		return decodeArray(typ[1:], data, order)
+
+	case '(', '{':
+		members, err := splitTypes(typ[1 : len(typ)-1])
+		if err != nil {
+			return nil, err
+		}
+		return decodeTuple(members, data, order)
+	}
+
+	return nil, fmt.Errorf("%w: %s", errGVariantType, typ)
+}
+
+func decodeArray(elem string, data []byte, order binary.ByteOrder) ([]any, error) {
+	align, size, err := typeInfo(elem)
+	if err != nil {
+		return nil, err
+	}
+
+	result := []any{}
+
+	if size > 0 {
+		if len(data)%size != 0 {
+			return nil, fmt.Errorf("gvariant: a%s: invalid size %d", elem, len(data))
+		}
+		for i := 0; i < len(data); i += size {
+			value, err := decodeVariant(elem, data[i:i+size], order)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, value)
+		}
+		return result, nil
+	}
+
+	if len(data) == 0 {
+		return result, nil
+	}
+
+	offSize := offsetSize(len(data))
+	tableStart := readOffset(data[len(data)-offSize:])
+	if tableStart > len(data) || (len(data)-tableStart)%offSize != 0 {
+		return nil, fmt.Errorf("gvariant: a%s: invalid framing offsets", elem)
+	}
+
+	start := 0
+	for i := tableStart; i < len(data); i += offSize {
+		end := readOffset(data[i : i+offSize])
+		start = alignUp(start, align)
+		if start > end || end > tableStart {
+			return nil, fmt.Errorf("gvariant: a%s: invalid framing offsets", elem)
+		}
+
+		value, err := decodeVariant(elem, data[start:end], order)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, value)
+		start = end
+	}
+
+	return result, nil
+}
+
+// The end of each variable size member but the last one is stored at the end
+// of the tuple, in reverse order.
+func decodeTuple(members []string, data []byte, order binary.ByteOrder) ([]any, error) {
+	offSize := offsetSize(len(data))
+	tableStart := len(data)
+	result := make([]any, 0, len(members))
+
+	pos := 0
+	for i, member := range members {
+		align, size, err := typeInfo(member)
+		if err != nil {
+			return nil, err
+		}
+		pos = alignUp(pos, align)
+
+		var end int
+		switch {
+		case size > 0:
+			end = pos + size
+		case i == len(members)-1:
+			end = tableStart
+		default:
+			tableStart -= offSize
+			if tableStart < 0 {
+				return nil, fmt.Errorf("gvariant: tuple: invalid framing offsets")
+			}
+			end = readOffset(data[tableStart : tableStart+offSize])
+		}
+
+		if pos > end || end > tableStart {
+			return nil, fmt.Errorf("gvariant: tuple: member %d out of bounds", i)
+		}
+
+		value, err := decodeVariant(member, data[pos:end], order)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, value)
+		pos = end
+	}
+
+	return result, nil
+}
+
+// typeInfo returns the alignment and the fixed size of typ. The size is zero
+// for variable size types.
+func typeInfo(typ string) (align int, size int, err error) {
+	if typ == "" {
+		return 0, 0, errGVariantType
+	}
+
+	switch typ[0] {
+	case 'b', 'y':
+		return 1, 1, nil
+	case 'n', 'q':
+		return 2, 2, nil
+	case 'i', 'u', 'h':
+		return 4, 4, nil
+	case 'x', 't', 'd':
+		return 8, 8, nil
+	case 's', 'o', 'g':
+		return 1, 0, nil
+	case 'v':
+		return 8, 0, nil
+	case 'a', 'm':
+		align, _, err := typeInfo(typ[1:])
+		return align, 0, err
+	case '(', '{':
+		members, err := splitTypes(typ[1 : len(typ)-1])
+		if err != nil {
+			return 0, 0, err
+		}
+
+		align, size := 1, 0
+		fixed := true
+		for _, member := range members {
+			a, s, err := typeInfo(member)
+			if err != nil {
+				return 0, 0, err
+			}
+			align = max(align, a)
+			fixed = fixed && s > 0
+			size = alignUp(size, a) + s
+		}
+		if !fixed {
+			return align, 0, nil
+		}
+
+		// the unit type takes one byte
+		return align, max(alignUp(size, align), 1), nil
+	}
+
+	return 0, 0, fmt.Errorf("%w: %s", errGVariantType, typ)
+}
+
+// splitTypes splits a signature into its complete types: "xsas" -> x, s, as
+func splitTypes(sig string) ([]string, error) {
+	var types []string
+	for sig != "" {
+		n, err := typeLen(sig)
+		if err != nil {
+			return nil, err
+		}
+		types = append(types, sig[:n])
+		sig = sig[n:]
+	}
+	return types, nil
+}
+
+// length of the first complete type of sig
+func typeLen(sig string) (int, error) {
+	if sig == "" {
+		return 0, errGVariantType
+	}
+
+	switch c := sig[0]; {
+	case strings.IndexByte("bynqiuxthdsogv", c) >= 0:
+		return 1, nil
+	case c == 'a' || c == 'm':
+		n, err := typeLen(sig[1:])
+		return n + 1, err
+	case c == '(' || c == '{':
+		closing := byte(')')
+		if c == '{' {
+			closing = '}'
+		}
+		n := 1
+		for n < len(sig) && sig[n] != closing {
+			m, err := typeLen(sig[n:])
+			if err != nil {
+				return 0, err
+			}
+			n += m
+		}
+		if n >= len(sig) {
+			return 0, fmt.Errorf("%w: %s", errGVariantType, sig)
+		}
+		return n + 1, nil
+	}
+
+	return 0, fmt.Errorf("%w: %s", errGVariantType, sig)
+}
+
+func offsetSize(size int) int {
+	switch {
+	case size > math.MaxUint32:
+		return 8
+	case size > math.MaxUint16:
+		return 4
+	case size > math.MaxUint8:
+		return 2
+	case size > 0:
+		return 1
+	}
+	return 0
+}
+
+func readOffset(b []byte) int {
+	var off uint64
+	for i := len(b) - 1; i >= 0; i-- {
+		off = off<<8 | uint64(b[i])
+	}
+	return int(off)
+}
+
+func alignUp(n, align int) int {
+	return (n + align - 1) &^ (align - 1)
+}
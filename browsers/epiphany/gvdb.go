@@ -0,0 +1,180 @@
+//
+// Copyright (c) 2023-2025 Chakib Ben Ziane <contact@blob42.xyz> and [`GoSuki` contributors]
+// (https://github.com/blob42/gosuki/graphs/contributors).
+//
+// All rights reserved.
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+//
+// This file is part of GoSuki.
+//
+// GoSuki is free software: you can redistribute it and/or modify it under the terms of
+// the GNU Affero General Public License as published by the Free Software Foundation,
+// either version 3 of the License, or (at your option) any later version.
+//
+// GoSuki is distributed in the hope that it will be useful, but WITHOUT ANY WARRANTY;
+// without even the implied warranty of MERCHANTABILITY or FITNESS FOR A PARTICULAR
+// PURPOSE.  See the GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License along with
+// gosuki.  If not, see <http://www.gnu.org/licenses/>.
+
+package epiphany
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// GVDB is the GLib variant database format. A file holds hash tables of
+// GVariant values, the tables can be nested. Only reading is supported and the
+// hash buckets are ignored, the tables are small enough to be scanned.
+//
+// See https://gitlab.gnome.org/GNOME/gvdb
+
+const (
+	gvdbHeaderSize = 24
+	gvdbItemSize   = 24
+	gvdbNoParent   = 0xffffffff
+)
+
+var (
+	ErrNotGVDB     = errors.New("not a gvdb file")
+	errGVDBCorrupt = errors.New("gvdb: corrupt file")
+)
+
+// gvdbTable is a hash table of a gvdb file
+type gvdbTable struct {
+	data  []byte
+	order binary.ByteOrder
+
+	// hash items of the table
+	hashItems []byte
+}
+
+type gvdbItem struct {
+	key string
+
+	// 'v' for values, 'H' for tables and 'L' for lists
+	typ byte
+
+	start, end uint32
+}
+
+// openGVDB returns the root table of the gvdb file data
+func openGVDB(data []byte) (*gvdbTable, error) {
+	if len(data) < gvdbHeaderSize {
+		return nil, ErrNotGVDB
+	}
+
+	var order binary.ByteOrder
+	switch string(data[:8]) {
+	case "GVariant":
+		order = binary.LittleEndian
+	case "raVGtnai":
+		order = binary.BigEndian
+	default:
+		return nil, ErrNotGVDB
+	}
+
+	return newGVDBTable(data, order, order.Uint32(data[16:]), order.Uint32(data[20:]))
+}
+
+func newGVDBTable(data []byte, order binary.ByteOrder, start, end uint32) (*gvdbTable, error) {
+	if start > end || int(end) > len(data) || end-start < 8 {
+		return nil, errGVDBCorrupt
+	}
+	table := data[start:end]
+
+	// the top 5 bits of the bloom header hold the bloom shift
+	nBloom := uint64(order.Uint32(table) & (1<<27 - 1))
+	nBuckets := uint64(order.Uint32(table[4:]))
+	itemsStart := 8 + 4*(nBloom+nBuckets)
+	if itemsStart > uint64(len(table)) {
+		return nil, errGVDBCorrupt
+	}
+
+	items := table[itemsStart:]
+	return &gvdbTable{
+		data:      data,
+		order:     order,
+		hashItems: items[:len(items)/gvdbItemSize*gvdbItemSize],
+	}, nil
+}
+
+// items returns the items of the table with their full key
+func (t *gvdbTable) items() ([]gvdbItem, error) {
+	n := len(t.hashItems) / gvdbItemSize
+	result := make([]gvdbItem, 0, n)
+	for i := range n {
+		key, err := t.key(i, n)
+		if err != nil {
+			return nil, err
+		}
+
+		raw := t.hashItems[i*gvdbItemSize:]
+		result = append(result, gvdbItem{
+			key:   key,
+			typ:   raw[14],
+			start: t.order.Uint32(raw[16:]),
+			end:   t.order.Uint32(raw[20:]),
+		})
+	}
+	return result, nil
+}
+
+// the key of an item is appended to the key of its parent, depth guards
+// against loops
+func (t *gvdbTable) key(i int, depth int) (string, error) {
+	if depth < 0 {
+		return "", errGVDBCorrupt
+	}
+
+	raw := t.hashItems[i*gvdbItemSize:]
+	parent := t.order.Uint32(raw[4:])
+	start := uint64(t.order.Uint32(raw[8:]))
+	end := start + uint64(t.order.Uint16(raw[12:]))
+	if end > uint64(len(t.data)) {
+		return "", errGVDBCorrupt
+	}
+	key := string(t.data[start:end])
+
+	if parent == gvdbNoParent {
+		return key, nil
+	}
+	if int(parent) >= len(t.hashItems)/gvdbItemSize {
+		return "", errGVDBCorrupt
+	}
+
+	prefix, err := t.key(int(parent), depth-1)
+	return prefix + key, err
+}
+
+// table returns the nested table at key
+func (t *gvdbTable) table(key string) (*gvdbTable, error) {
+	items, err := t.items()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, item := range items {
+		if item.key == key && item.typ == 'H' {
+			return newGVDBTable(t.data, t.order, item.start, item.end)
+		}
+	}
+
+	return nil, fmt.Errorf("gvdb: no table %q", key)
+}
+
+// value decodes the value of item
+func (t *gvdbTable) value(item gvdbItem) (any, error) {
+	if item.typ != 'v' {
+		return nil, fmt.Errorf("gvdb: %q is not a value", item.key)
+	}
+	if item.start > item.end || int(item.end) > len(t.data) {
+		return nil, errGVDBCorrupt
+	}
+
+	return decodeVariant("v", t.data[item.start:item.end], t.order)
+}
@@ -0,0 +1,72 @@
+//
+// Copyright (c) 2023-2025 Chakib Ben Ziane <contact@blob42.xyz> and [`GoSuki` contributors]
+// (https://github.com/blob42/gosuki/graphs/contributors).
+//
+// All rights reserved.
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+//
+// This file is part of GoSuki.
+//
+// GoSuki is free software: you can redistribute it and/or modify it under the terms of
+// the GNU Affero General Public License as published by the Free Software Foundation,
+// either version 3 of the License, or (at your option) any later version.
+//
+// GoSuki is distributed in the hope that it will be useful, but WITHOUT ANY WARRANTY;
+// without even the implied warranty of MERCHANTABILITY or FITNESS FOR A PARTICULAR
+// PURPOSE.  See the GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License along with
+// gosuki.  If not, see <http://www.gnu.org/licenses/>.
+
+package falkon
+
+import (
+	"github.com/blob42/gosuki/pkg/config"
+	"github.com/blob42/gosuki/pkg/logging"
+	"github.com/blob42/gosuki/pkg/modules"
+	"github.com/blob42/gosuki/pkg/tree"
+)
+
+const (
+	BrowserName  = "falkon"
+	RootNodeName = "ROOT"
+)
+
+var (
+	FalkonCfg = NewFalkonConfig()
+	log       = logging.GetLogger("falkon")
+)
+
+type FalkonConfig struct {
+	*modules.BrowserConfig `toml:"-"`
+
+	// An empty profile selects the start profile of profiles.ini
+	modules.ProfilePrefs `toml:"profile-options" mapstructure:"profile-options"`
+
+	// What to do with bookmarks deleted from the browser: "mirror" or "archive"
+	Deletions modules.DeletionMode `toml:"deletions" mapstructure:"deletions"`
+}
+
+func NewFalkonConfig() *FalkonConfig {
+	return &FalkonConfig{
+		BrowserConfig: &modules.BrowserConfig{
+			Name:   BrowserName,
+			BkFile: BookmarksFile,
+			NodeTree: &tree.Node{
+				Title: RootNodeName,
+				Type:  tree.RootNode,
+			},
+			UseFileWatcher: true,
+			UseHooks:       []string{"node_tags_from_name"},
+		},
+		ProfilePrefs: modules.ProfilePrefs{
+			WatchAllProfiles: true,
+		},
+		Deletions: modules.MirrorDeletions,
+	}
+}
+
+func init() {
+	config.RegisterConfigurator(BrowserName, config.AsConfigurator(FalkonCfg))
+}
@@ -0,0 +1,358 @@
+//
+// Copyright (c) 2023-2025 Chakib Ben Ziane <contact@blob42.xyz> and [`GoSuki` contributors]
+// (https://github.com/blob42/gosuki/graphs/contributors).
+//
+// All rights reserved.
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+//
+// This file is part of GoSuki.
+//
+// GoSuki is free software: you can redistribute it and/or modify it under the terms of
+// the GNU Affero General Public License as published by the Free Software Foundation,
+// either version 3 of the License, or (at your option) any later version.
+//
+// GoSuki is distributed in the hope that it will be useful, but WITHOUT ANY WARRANTY;
+// without even the implied warranty of MERCHANTABILITY or FITNESS FOR A PARTICULAR
+// PURPOSE.  See the GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License along with
+// gosuki.  If not, see <http://www.gnu.org/licenses/>.
+
+// Package falkon implements the Falkon browser module.
+//
+// Falkon keeps the bookmarks of each profile in a json file with a tree of
+// folders under the toolbar, menu and unsorted roots. Folders are kept as
+// hierarchical tags and bookmark keywords are saved with the bookmark sources.
+package falkon
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+
+	"github.com/blob42/gosuki/hooks"
+	"github.com/blob42/gosuki/internal/database"
+	"github.com/blob42/gosuki/pkg/browsers"
+	"github.com/blob42/gosuki/pkg/events"
+	"github.com/blob42/gosuki/pkg/modules"
+	"github.com/blob42/gosuki/pkg/parsing"
+	"github.com/blob42/gosuki/pkg/profiles"
+	"github.com/blob42/gosuki/pkg/tree"
+	"github.com/blob42/gosuki/pkg/watch"
+)
+
+// order of the roots of bookmarks.json
+var rootNames = []string{"bookmark_bar", "bookmark_menu", "other"}
+
+// entry of bookmarks.json
+type jsonNode struct {
+	Type        string     `json:"type"`
+	Name        string     `json:"name"`
+	URL         string     `json:"url"`
+	Description string     `json:"description"`
+	Keyword     string     `json:"keyword"`
+	Children    []jsonNode `json:"children"`
+}
+
+type jsonBookmarks struct {
+	Roots map[string]jsonNode `json:"roots"`
+}
+
+// parseBookmarks reads a Falkon bookmark file into nodes under root. Each root
+// of the file is a folder node. It returns the number of bookmarks read.
+func parseBookmarks(data []byte, root *tree.Node) (int, error) {
+	var bookmarks jsonBookmarks
+	if err := json.Unmarshal(data, &bookmarks); err != nil {
+		return 0, err
+	}
+
+	names := make([]string, 0, len(bookmarks.Roots))
+	for name := range bookmarks.Roots {
+		if !slices.Contains(rootNames, name) {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+
+	count := 0
+	for _, name := range append(slices.Clone(rootNames), names...) {
+		jsonRoot, ok := bookmarks.Roots[name]
+		if !ok {
+			continue
+		}
+		if jsonRoot.Name == "" {
+			jsonRoot.Name = name
+		}
+		jsonRoot.Type = "folder"
+		count += addNode(jsonRoot, root)
+	}
+
+	return count, nil
+}
+
+func addNode(entry jsonNode, parent *tree.Node) int {
+	switch entry.Type {
+	case "url":
+		if entry.URL == "" {
+			return 0
+		}
+		parent.Children = append(parent.Children, &tree.Node{
+			Type:    tree.URLNode,
+			Title:   entry.Name,
+			URL:     entry.URL,
+			Desc:    entry.Description,
+			Keyword: entry.Keyword,
+			Parent:  parent,
+		})
+		return 1
+
+	case "folder":
+		folder := &tree.Node{
+			Type:   tree.FolderNode,
+			Title:  entry.Name,
+			Parent: parent,
+		}
+		parent.Children = append(parent.Children, folder)
+
+		count := 0
+		for _, child := range entry.Children {
+			count += addNode(child, folder)
+		}
+		return count
+	}
+
+	// separators
+	return 0
+}
+
+// Falkon browser module
+type Falkon struct {
+	// holds browsers.BrowserConfig
+	*FalkonConfig
+
+	parsing.Counter
+
+	activeProfile *profiles.Profile
+
+	activeFlavour *browsers.BrowserDef
+}
+
+// Init sets up the bookmark dir of profile p, or of the configured profile
+// when p is nil. The instance gets its own copy of the config as each profile
+// has its own bookmark file, buffer and watcher.
+func (f *Falkon) Init(_ *modules.Context, p *profiles.Profile) error {
+	cfg := *f.FalkonConfig
+	bConf := *f.BrowserConfig
+	cfg.BrowserConfig = &bConf
+	f.FalkonConfig = &cfg
+
+	if f.activeFlavour == nil {
+		flv := browsers.Defined(browsers.Falkon)[BrowserName]
+		f.activeFlavour = &flv
+	}
+
+	if p == nil {
+		var err error
+		if p, err = f.configuredProfile(); err != nil {
+			return err
+		}
+		f.activeProfile = p
+	}
+
+	bookmarkDir, err := p.AbsolutePath()
+	if err != nil {
+		return err
+	}
+	f.BkDir = bookmarkDir
+
+	log.Infof("initializing <%s>", f.moduleName())
+	return f.setupWatchers()
+}
+
+func (f *Falkon) configuredProfile() (*profiles.Profile, error) {
+	var err error
+	name := f.Profile
+	if name == "" {
+		if name, err = StartProfile(f.activeFlavour.Flavour); err != nil {
+			return nil, err
+		}
+	}
+
+	profs, err := f.GetProfiles(f.activeFlavour.Flavour)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, p := range profs {
+		if p.Name == name {
+			return p, nil
+		}
+	}
+
+	return nil, fmt.Errorf("profile %s not found", name)
+}
+
+// source module of the bookmarks, ex: falkon_default
+func (f *Falkon) moduleName() string {
+	modName := f.Name
+	if f.activeFlavour != nil && f.activeFlavour.Flavour != f.Name {
+		modName = fmt.Sprintf("%s_%s", modName, f.activeFlavour.Flavour)
+	}
+	if f.activeProfile != nil {
+		modName = fmt.Sprintf("%s_%s", modName, f.activeProfile.Name)
+	}
+	return modName
+}
+
+func (f *Falkon) setupWatchers() error {
+	bookmarkPath := filepath.Join(f.BkDir, f.BkFile)
+	log.Debugf("Watching path: %s", f.BkDir)
+
+	// bookmarks.json is replaced on save
+	w := &watch.Watch{
+		Path:       f.BkDir,
+		EventTypes: []fsnotify.Op{fsnotify.Create, fsnotify.Write},
+		EventNames: []string{bookmarkPath},
+	}
+
+	ok, err := modules.SetupWatchers(f.BrowserConfig, w)
+	if err != nil {
+		log.Error(err)
+		return modules.ErrWatcherSetup
+	}
+	if !ok {
+		return modules.ErrWatcherSetup
+	}
+
+	return nil
+}
+
+// Returns a pointer to an initialized browser config
+func (f Falkon) Config() *modules.BrowserConfig {
+	return f.BrowserConfig
+}
+
+func (f Falkon) ModInfo() modules.ModInfo {
+	return modules.ModInfo{
+		ID: modules.ModID(f.Name),
+		New: func() modules.Module {
+			return NewFalkon()
+		},
+	}
+}
+
+func (f *Falkon) Watch() *watch.WatchDescriptor {
+	// calls modules.BrowserConfig.GetWatcher()
+	return f.GetWatcher()
+}
+
+func (f *Falkon) Run() {
+	if err := f.load(true); err != nil {
+		log.Errorf("<%s>: %v", f.moduleName(), err)
+	}
+}
+
+func (f *Falkon) load(runTask bool) error {
+	startRun := time.Now()
+
+	bookmarkPath, err := f.BookmarkPath()
+	if err != nil {
+		return err
+	}
+
+	data, err := os.ReadFile(bookmarkPath)
+	if err != nil {
+		return err
+	}
+
+	root := &tree.Node{Title: RootNodeName, Type: tree.RootNode}
+	if _, err = parseBookmarks(data, root); err != nil {
+		return fmt.Errorf("%s: %w", bookmarkPath, err)
+	}
+	f.NodeTree = root
+	f.SetLastTreeParseRuntime(time.Since(startRun))
+
+	module := f.moduleName()
+	var urls []string
+	tree.MapNodeFunc(root, tree.URLNode, func(node *tree.Node) {
+		node.Module = module
+		if f.activeFlavour != nil {
+			node.Flavour = f.activeFlavour.Flavour
+		}
+		if f.activeProfile != nil {
+			node.Profile = f.activeProfile.Name
+		}
+
+		if err := f.CallHooks(node); err != nil {
+			log.Errorf("<%s> hooks: %s", module, err)
+		}
+
+		if folder := node.FolderPath(); folder != "" {
+			node.Tags = append(node.Tags, folder)
+		}
+
+		urls = append(urls, node.URL)
+		f.IncURLCount()
+	})
+
+	f.SetTotal(uint(len(urls)))
+	go func() {
+		events.TUIBus <- events.ProgressUpdateMsg{
+			ID:           f.ModInfo().ID,
+			Instance:     f,
+			CurrentCount: f.URLCount(),
+			Total:        f.Total(),
+			NewBk:        runTask,
+		}
+	}()
+
+	f.TrackDeletions(module, urls, f.Deletions)
+	log.Debugf("<%s> parsed %d bookmarks in %s", module, len(urls), f.LastFullTreeParseRT())
+
+	database.SyncTreeToBuffer(f.NodeTree, f.BufferDB)
+	if err = f.BufferDB.SyncToCache(); err != nil {
+		log.Errorf("<%s>: %v", module, err)
+	}
+
+	database.ScheduleBackupToDisk()
+	f.SetLastWatchRuntime(time.Since(startRun))
+
+	return err
+}
+
+// PreLoad() will be called right after a browser is initialized
+func (f *Falkon) PreLoad(_ *modules.Context) error {
+	return f.load(false)
+}
+
+// Implement modules.Shutdowner
+func (f *Falkon) Shutdown() error {
+	return nil
+}
+
+func NewFalkon() *Falkon {
+	return &Falkon{
+		FalkonConfig: FalkonCfg,
+		Counter:      &parsing.BrowserCounter{},
+	}
+}
+
+func init() {
+	modules.RegisterBrowser(Falkon{FalkonConfig: FalkonCfg})
+}
+
+// interface guards
+
+var _ modules.BrowserModule = (*Falkon)(nil)
+var _ modules.ProfileInitializer = (*Falkon)(nil)
+var _ modules.PreLoader = (*Falkon)(nil)
+var _ watch.WatchRunner = (*Falkon)(nil)
+var _ hooks.HookRunner = (*Falkon)(nil)
+var _ parsing.Counter = (*Falkon)(nil)
+var _ profiles.ProfileManager = (*Falkon)(nil)
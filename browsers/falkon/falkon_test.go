@@ -0,0 +1,129 @@
+package falkon
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/blob42/gosuki/internal/database"
+	"github.com/blob42/gosuki/pkg/browsers"
+	"github.com/blob42/gosuki/pkg/logging"
+	"github.com/blob42/gosuki/pkg/modules"
+	"github.com/blob42/gosuki/pkg/tree"
+)
+
+func TestMain(m *testing.M) {
+	database.RegisterSqliteHooks()
+
+	cacheDB, err := database.NewDB(database.CacheName, "", database.DBTypeCacheDSN).Init()
+	if err != nil {
+		log.Fatal(err)
+	}
+	database.Cache = &database.CacheDB{DB: cacheDB}
+	database.Clock = &database.LamportClock{}
+
+	browsers.AddBrowserDef(browsers.BrowserDef{
+		Flavour: BrowserName,
+		Family:  browsers.Falkon,
+		BaseDir: "testdata",
+	})
+
+	os.Exit(m.Run())
+}
+
+func TestParseBookmarks(t *testing.T) {
+	data, err := os.ReadFile("testdata/profiles/default/bookmarks.json")
+	require.NoError(t, err)
+
+	root := &tree.Node{Type: tree.RootNode}
+	count, err := parseBookmarks(data, root)
+	require.NoError(t, err)
+	assert.Equal(t, 3, count)
+
+	// roots are kept in order, separators are skipped
+	require.Len(t, root.Children, 3)
+	toolbar := root.Children[0]
+	assert.Equal(t, "Bookmarks Toolbar", toolbar.Title)
+	require.Len(t, toolbar.Children, 2)
+
+	falkon := toolbar.Children[0]
+	assert.Equal(t, "https://www.falkon.org/", falkon.URL)
+	assert.Equal(t, "KDE web browser", falkon.Desc)
+	assert.Equal(t, "falkon", falkon.Keyword)
+
+	qt := toolbar.Children[1].Children[0].Children[0]
+	assert.Equal(t, "Dev/Docs", qt.FolderPath())
+
+	assert.Equal(t, "Bookmarks Menu", root.Children[1].Children[0].FolderPath())
+	assert.Empty(t, root.Children[2].Children, "bookmarks without url are skipped")
+
+	_, err = parseBookmarks([]byte("{"), root)
+	assert.Error(t, err)
+}
+
+func TestGetProfiles(t *testing.T) {
+	f := NewFalkon()
+	profs, err := f.GetProfiles(BrowserName)
+	require.NoError(t, err)
+
+	// the fresh profile has no bookmarks
+	names := []string{}
+	for _, p := range profs {
+		names = append(names, p.Name)
+	}
+	assert.ElementsMatch(t, []string{"default", "work"}, names)
+
+	start, err := StartProfile(BrowserName)
+	require.NoError(t, err)
+	assert.Equal(t, "work", start)
+}
+
+func TestLoad(t *testing.T) {
+	logging.SetLevel(logging.Silent)
+	ctx := context.Background()
+
+	t.Run("start profile", func(t *testing.T) {
+		f := NewFalkon()
+		require.NoError(t, modules.SetupBrowser(f, &modules.Context{}, nil))
+		assert.EqualValues(t, 1, f.URLCount())
+
+		bk, err := f.BufferDB.BookmarkByURL(ctx, "https://github.com/blob42/gosuki")
+		require.NoError(t, err)
+		assert.Equal(t, "falkon_work", bk.Module)
+
+		// the shared config is left untouched
+		assert.Empty(t, FalkonCfg.BkDir)
+	})
+
+	t.Run("all profiles", func(t *testing.T) {
+		f := NewFalkon()
+		profs, err := f.GetProfiles(BrowserName)
+		require.NoError(t, err)
+		def := profs[0]
+		for _, p := range profs {
+			if p.Name == "default" {
+				def = p
+			}
+		}
+
+		flv := browsers.Defined(browsers.Falkon)[BrowserName]
+		require.NoError(t, f.UseProfile(def, &flv))
+		require.NoError(t, modules.SetupBrowser(f, &modules.Context{}, def))
+		assert.EqualValues(t, 3, f.URLCount())
+
+		bk, err := f.BufferDB.BookmarkByURL(ctx, "https://doc.qt.io/")
+		require.NoError(t, err)
+		assert.Equal(t, "falkon_default", bk.Module)
+		assert.Equal(t, ",Dev/Docs,qt,", bk.Tags)
+
+		sources, err := f.BufferDB.URLSources(ctx, "https://www.falkon.org/")
+		require.NoError(t, err)
+		require.Len(t, sources, 1)
+		assert.Equal(t, "falkon", sources[0].Keyword)
+		assert.Equal(t, "default", sources[0].Profile)
+		assert.Equal(t, "Bookmarks Toolbar", sources[0].Folder)
+	})
+}
@@ -0,0 +1,160 @@
+//
+// Copyright (c) 2023-2025 Chakib Ben Ziane <contact@blob42.xyz> and [`GoSuki` contributors]
+// (https://github.com/blob42/gosuki/graphs/contributors).
+//
+// All rights reserved.
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+//
+// This file is part of GoSuki.
+//
+// GoSuki is free software: you can redistribute it and/or modify it under the terms of
+// the GNU Affero General Public License as published by the Free Software Foundation,
+// either version 3 of the License, or (at your option) any later version.
+//
+// GoSuki is distributed in the hope that it will be useful, but WITHOUT ANY WARRANTY;
+// without even the implied warranty of MERCHANTABILITY or FITNESS FOR A PARTICULAR
+// PURPOSE.  See the GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License along with
+// gosuki.  If not, see <http://www.gnu.org/licenses/>.
+
+package falkon
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/go-ini/ini"
+
+	"github.com/blob42/gosuki/internal/utils"
+	"github.com/blob42/gosuki/pkg/browsers"
+	"github.com/blob42/gosuki/pkg/profiles"
+)
+
+const (
+	// Falkon profiles are the directories of ProfilesDir
+	ProfilesDir  = "profiles"
+	ProfilesFile = "profiles.ini"
+
+	BookmarksFile = "bookmarks.json"
+
+	// used when profiles.ini has no start profile
+	DefaultProfile = "default"
+)
+
+// GetProfiles returns the profiles holding a bookmark file. Profiles without
+// bookmarks are skipped, Falkon only creates the file once a bookmark is added.
+func (*Falkon) GetProfiles(flavour string) ([]*profiles.Profile, error) {
+	profilesDir, err := profilesPath(flavour)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := os.ReadDir(profilesDir)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []*profiles.Profile
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+
+		exists, err := utils.CheckFileExists(filepath.Join(profilesDir, entry.Name(), BookmarksFile))
+		if err != nil || !exists {
+			continue
+		}
+
+		result = append(result, &profiles.Profile{
+			ID:         entry.Name(),
+			Name:       entry.Name(),
+			Path:       entry.Name(),
+			IsRelative: true,
+			BaseDir:    profilesDir,
+		})
+	}
+
+	if len(result) == 0 {
+		return nil, fmt.Errorf("no falkon profile with bookmarks in %s", profilesDir)
+	}
+
+	return result, nil
+}
+
+// StartProfile returns the name of the profile Falkon starts with
+func StartProfile(flavour string) (string, error) {
+	profilesDir, err := profilesPath(flavour)
+	if err != nil {
+		return "", err
+	}
+
+	pFile, err := ini.Load(filepath.Join(profilesDir, ProfilesFile))
+	if err != nil {
+		return "", err
+	}
+
+	if name := pFile.Section("Profiles").Key("startProfile").String(); name != "" {
+		return name, nil
+	}
+
+	return DefaultProfile, nil
+}
+
+func profilesPath(flavour string) (string, error) {
+	flv, ok := browsers.Defined(browsers.Falkon)[flavour]
+	if !ok {
+		return "", fmt.Errorf("unknown flavour <%s>", flavour)
+	}
+
+	baseDir, err := flv.ExpandBaseDir()
+	if err != nil {
+		return "", fmt.Errorf("expanding base directory: %w", err)
+	}
+
+	return filepath.Join(baseDir, ProfilesDir), nil
+}
+
+// Returns all flavours supported by this browser
+func (*Falkon) ListFlavours() []browsers.BrowserDef {
+	var result []browsers.BrowserDef
+
+	// detect local flavours
+	for _, v := range browsers.Defined(browsers.Falkon) {
+		if v.Detect() {
+			result = append(result, v)
+		}
+	}
+
+	return result
+}
+
+// get current active flavour
+func (f *Falkon) GetCurFlavour() *browsers.BrowserDef {
+	return f.activeFlavour
+}
+
+// If should watch all profiles
+func (f *Falkon) WatchAllProfiles() bool {
+	return f.FalkonConfig.WatchAllProfiles
+}
+
+// Notifies the module to use a custom profile. The bookmark dir is set when
+// the profile is initialized.
+func (f *Falkon) UseProfile(p *profiles.Profile, flv *browsers.BrowserDef) error {
+	if p != nil {
+		f.activeProfile = p
+	}
+
+	if flv != nil {
+		f.activeFlavour = flv
+	}
+
+	return nil
+}
+
+func (f *Falkon) GetProfile() *profiles.Profile {
+	return f.activeProfile
+}
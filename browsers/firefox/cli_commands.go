@@ -23,6 +23,9 @@ package firefox
 
 import (
 	"context"
+	"fmt"
+	"io"
+	"os"
 
 	"github.com/blob42/gosuki/cmd"
 	"github.com/blob42/gosuki/pkg/browsers/mozilla"
@@ -43,6 +46,7 @@ var (
 
 	ffCheckVFSCmd = cli.Command{
 		Name:    "check",
+		Usage:   "Show the VFS lock state and read strategy of each profile",
 		Aliases: []string{"c"},
 		Action:  ffCheckVFS,
 	}
@@ -68,18 +72,45 @@ var FirefoxCmds = &cli.Command{
 
 func init() {
 	cmd.RegisterModCommand(BrowserName, FirefoxCmds)
+	cmd.RegisterDebugInfo(BrowserName, func(w io.Writer) error {
+		fmt.Fprintln(w, "Firefox VFS lock:")
+		return writeVFSReport(w)
+	})
 }
 
+// writeVFSReport writes the VFS lock state of every detected profile
+func writeVFSReport(w io.Writer) error {
+	for _, flav := range FirefoxProfileManager.ListFlavours() {
+		profs, err := FirefoxProfileManager.GetProfiles(flav.Flavour)
+		if err != nil {
+			fflog.Debug("listing profiles", "flavour", flav.Flavour, "err", err)
+			continue
+		}
 
-func ffCheckVFS(_ context.Context, _ *cli.Command) error {
-	err := mozilla.CheckVFSLock("path to profile")
-	if err != nil {
-		return err
+		for _, p := range profs {
+			name := fmt.Sprintf("%s:%s", flav.Flavour, p.Name)
+			dir, err := p.AbsolutePath()
+			if err != nil {
+				fmt.Fprintf(w, " %-30s %s\n", name, err)
+				continue
+			}
+
+			state, err := mozilla.CheckVFSLock(dir)
+			if err != nil {
+				fmt.Fprintf(w, " %-30s %s\n", name, err)
+				continue
+			}
+			fmt.Fprintf(w, " %-30s %s\n", name, state)
+		}
 	}
 
 	return nil
 }
 
+func ffCheckVFS(_ context.Context, _ *cli.Command) error {
+	return writeVFSReport(os.Stdout)
+}
+
 func ffUnlockVFS(_ context.Context, _ *cli.Command) error {
 	err := mozilla.UnlockPlaces("path to profile")
 	if err != nil {
@@ -71,6 +71,12 @@ type FirefoxConfig struct {
 
 	CustomProfiles []profiles.CustomProfile `toml:"custom-profiles" mapstructure:"custom-profiles"`
 
+	// What to do with bookmarks deleted from the browser: "mirror" or "archive"
+	Deletions modules.DeletionMode `toml:"deletions" mapstructure:"deletions"`
+
+	// Write gosuki bookmarks into a dedicated browser folder
+	WriteBack modules.WriteBackConfig `toml:"write-back" mapstructure:"write-back"`
+
 	//TEST: ignore this field in config.Configurator interface
 	// Embed base browser config
 	*modules.BrowserConfig `toml:"-"`
@@ -105,6 +111,9 @@ func NewFirefoxConfig() *FirefoxConfig {
 			Profile:          DefaultProfile,
 			WatchAllProfiles: true,
 		},
+
+		Deletions: modules.MirrorDeletions,
+		WriteBack: modules.DefaultWriteBack(),
 	}
 
 	return cfg
@@ -22,9 +22,12 @@
 package firefox
 
 import (
+	"database/sql"
+	"errors"
 	"fmt"
 	"path"
 	"path/filepath"
+	"slices"
 	"strings"
 	"time"
 
@@ -67,9 +70,15 @@ type MozFolder = mozilla.MozFolder
 type Firefox struct {
 	*FirefoxConfig
 
-	// sqlite con to places.sqlite
+	// sqlite con to places.sqlite, kept open across runs
 	places *database.DB
 
+	// copy of places.sqlite read when places cannot be read in place
+	snapshot *mozilla.PlacesSnapshot
+
+	// how places.sqlite is read, selected from the VFS lock state
+	readStrategy mozilla.ReadStrategy
+
 	// All elements stored in URLIndex
 	URLIndexList []string
 
@@ -86,6 +95,9 @@ type Firefox struct {
 	// internal folder map used for scanning
 	folderScanMap map[sqlid]*MozFolder
 
+	// id of the folder holding the bookmarks written by gosuki
+	writeBackFolder sqlid
+
 	lastRunAt time.Time
 
 	activeProfile *profiles.Profile
@@ -131,6 +143,16 @@ func (f *Firefox) scanFolders(since timestamp) ([]*MozFolder, error) {
 		return nil, err
 	}
 
+	// the gosuki write back folder is not part of the browser bookmarks
+	f.writeBackFolder = 0
+	wbErr := f.places.Handle.Get(&f.writeBackFolder, mozilla.QWriteBackFolderID)
+	if wbErr != nil && !errors.Is(wbErr, sql.ErrNoRows) {
+		return nil, wbErr
+	}
+	folders = slices.DeleteFunc(folders, func(folder *MozFolder) bool {
+		return f.writeBackFolder != 0 && folder.ID == f.writeBackFolder
+	})
+
 	// store all folders in a hashmap for easier tree construction
 	for _, folder := range folders {
 		f.folderScanMap[folder.ID] = folder
@@ -151,8 +173,19 @@ func (f *Firefox) scanFolders(since timestamp) ([]*MozFolder, error) {
 func (f *Firefox) loadBookmarksToTree(bookmarks []*MozBookmark, runTask bool) {
 
 	for _, bkEntry := range bookmarks {
+		// skip bookmarks written by gosuki
+		if f.writeBackFolder != 0 && bkEntry.ParentID == f.writeBackFolder {
+			continue
+		}
+
 		// Create/Update URL node and apply tag node
 		created, urlNode := f.addURLNode(bkEntry.URL, bkEntry.Title, bkEntry.PlDesc)
+		urlNode.Keyword = bkEntry.Keyword
+
+		// places timestamps are in microseconds
+		if bkEntry.DateAdded > 0 {
+			urlNode.Created = uint64(bkEntry.DateAdded / 1_000_000)
+		}
 		if !created {
 			log.Debugf("url <%s> already in url index", bkEntry.URL)
 		} else {
@@ -381,6 +414,13 @@ func (f *Firefox) Init(ctx *modules.Context, p *profiles.Profile) error {
 func (f *Firefox) init(ctx *modules.Context) error {
 	log.Debugf("initializing <%s>", f.fullID())
 
+	if vfs, err := mozilla.CheckVFSLock(f.BkDir); err != nil {
+		log.Warn("checking VFS lock, reading a copy of places", "profile", f.fullID(), "err", err)
+	} else {
+		f.readStrategy = vfs.Strategy
+		log.Info("places read strategy", "profile", f.fullID(), "strategy", vfs.Strategy)
+	}
+
 	watchedPath := f.BkDir
 	log.Debugf("Watching path: %s", watchedPath)
 
@@ -424,17 +464,11 @@ func (f Firefox) Config() *modules.BrowserConfig {
 // Firefox custom logic for preloading the bookmarks when the browser module
 // starts. Implements modules.PreLoader interface.
 func (f *Firefox) PreLoad(_ *modules.Context) error {
-	pc, err := f.initPlacesCopy()
+	err := f.openPlaces()
 	if err != nil {
 		return err
 	}
 
-	defer func() {
-		if err = pc.Clean(); err != nil {
-			log.Errorf("error cleaning tmp places file: %s", err)
-		}
-	}()
-
 	// load all bookmarks
 	start := time.Now()
 	bookmarks, err := f.scanBookmarks()
@@ -453,6 +487,11 @@ func (f *Firefox) PreLoad(_ *modules.Context) error {
 
 	f.loadBookmarksToTree(bookmarks, false)
 
+	// record the initial bookmark urls to detect future deletions
+	if err = f.trackDeletions(); err != nil {
+		return err
+	}
+
 	f.SetLastTreeParseRuntime(time.Since(start))
 	f.lastRunAt = time.Now().UTC()
 
@@ -479,21 +518,18 @@ func (f *Firefox) PreLoad(_ *modules.Context) error {
 	//DEBUG:
 	// tree.PrintTree(f.NodeTree)
 
-	// Close the copy places.sqlite
-	err = f.places.Close()
-
-	return err
+	return nil
 }
 
 // Implements modules.Runner interface
 func (ff *Firefox) Run() {
 	startRun := time.Now()
 
-	pc, err := ff.initPlacesCopy()
+	err := ff.openPlaces()
 	if err != nil {
 		log.Error(err)
+		return
 	}
-	defer pc.Clean()
 
 	// go one step back in time to avoid missing changes
 	scanSince := ff.lastRunAt.Add(-1 * time.Second)
@@ -510,25 +546,70 @@ func (ff *Firefox) Run() {
 	ff.loadBookmarksToTree(bookmarks, true)
 	// tree.PrintTree(ff.NodeTree)
 
+	if err = ff.trackDeletions(); err != nil {
+		log.Error(err)
+	}
+
 	//NOTE: we don't rebuild the index from the tree here as the source of
 	// truth is the URLIndex and not the tree. The tree is only used for
 	// reprensenting the bookmark hierarchy in a conveniant way.
 
 	database.SyncURLIndexToBuffer(ff.URLIndexList, ff.URLIndex, ff.BufferDB)
-	ff.BufferDB.SyncTo(database.Cache.DB)
+	if err = ff.BufferDB.SyncToCache(); err != nil {
+		log.Error("syncing buffer to cache", "err", err)
+	}
 	database.ScheduleBackupToDisk()
 
 	ff.SetLastWatchRuntime(time.Since(startRun))
 	ff.lastRunAt = time.Now().UTC()
 }
 
+// Detects bookmarks deleted from places.sqlite since the last run. Modified
+// bookmarks queries cannot see deleted rows so the full list of bookmarked urls
+// is compared with the previous one.
+func (f *Firefox) trackDeletions() error {
+	var urls []string
+	if err := f.places.Handle.Select(&urls, mozilla.QBookmarkedURLs); err != nil {
+		return fmt.Errorf("listing bookmarked urls: %w", err)
+	}
+
+	deleted := f.TrackDeletions(f.moduleName(), urls, FFConfig.Deletions)
+	if len(deleted) == 0 {
+		return nil
+	}
+
+	// deleted urls are not in the URLIndex anymore
+	removed := make(map[string]struct{}, len(deleted))
+	for _, url := range deleted {
+		removed[url] = struct{}{}
+	}
+	f.URLIndexList = slices.DeleteFunc(f.URLIndexList, func(url string) bool {
+		_, ok := removed[url]
+		return ok
+	})
+
+	return nil
+}
+
+// source module of the bookmarks, ex: firefox_default
+func (f *Firefox) moduleName() string {
+	modName := f.Name
+	if f.activeFlavour != nil && f.activeFlavour.Flavour != f.Name {
+		modName = fmt.Sprintf("%s_%s", modName, f.activeFlavour.Flavour)
+	}
+	if f.activeProfile != nil {
+		modName = fmt.Sprintf("%s_%s", modName, f.activeProfile.Name)
+	}
+	return modName
+}
+
 // Implement modules.Shutdowner
 func (f *Firefox) Shutdown() error {
-	var err error
-	if f.places != nil {
-		err = f.places.Close()
+	f.closePlaces()
+	if f.snapshot != nil {
+		return f.snapshot.Clean()
 	}
-	return err
+	return nil
 }
 
 // and only pass extra details about tag/url along in some data structure
@@ -548,13 +629,7 @@ func (f *Firefox) addURLNode(url, title, desc string) (bool, *tree.Node) {
 
 	iURLNode, exists := f.URLIndex.Get(url)
 
-	modName := f.Name
-	if f.activeFlavour != nil && f.activeFlavour.Flavour != f.Name {
-		modName = fmt.Sprintf("%s_%s", modName, f.activeFlavour.Flavour)
-	}
-	if f.activeProfile != nil {
-		modName = fmt.Sprintf("%s_%s", modName, f.activeProfile.Name)
-	}
+	modName := f.moduleName()
 
 	if !exists {
 		urlNode = &tree.Node{
@@ -564,6 +639,12 @@ func (f *Firefox) addURLNode(url, title, desc string) (bool, *tree.Node) {
 			Desc:   desc,
 			Module: modName, // module which created this node
 		}
+		if f.activeFlavour != nil {
+			urlNode.Flavour = f.activeFlavour.Flavour
+		}
+		if f.activeProfile != nil {
+			urlNode.Profile = f.activeProfile.Name
+		}
 
 		log.Tracef("inserting url %s in url index", url)
 		f.URLIndex.Insert(url, urlNode)
@@ -686,29 +767,63 @@ func (f *Firefox) addFolderNode(folder MozFolder) (bool, *tree.Node) {
 	return true, folderNode
 }
 
-// Copies places.sqlite to a tmp dir to read a VFS lock sqlite db
-func (f *Firefox) initPlacesCopy() (mozilla.PlaceCopyJob, error) {
-	// create a new copy job
-	pc := mozilla.NewPlaceCopyJob()
+// openPlaces prepares f.places for reading places.sqlite. The connection is
+// kept across runs. With the live strategy the database is read in place,
+// otherwise from a snapshot that is only copied again in full after Firefox
+// checkpointed its WAL. A live read falls back to a snapshot if the database
+// got locked since the strategy was selected.
+func (f *Firefox) openPlaces() error {
+	var err error
 
-	err := utils.CopyFilesToTmpFolder(path.Join(f.BkDir, f.BkFile+"*"), pc.Path())
-	if err != nil {
-		return pc, fmt.Errorf("could not copy places.sqlite to tmp folder: %w", err)
+	if f.readStrategy == mozilla.ReadLive {
+		if f.places != nil {
+			return nil
+		}
+		f.places, err = database.NewDB("places",
+			path.Join(f.BkDir, f.BkFile),
+			database.DBTypeFileDSN,
+			database.DsnOptions{"mode": "ro", "_busy_timeout": "1000"}).Init()
+		if err == nil {
+			return nil
+		}
+		log.Warn("reading places in place failed, using a copy", "profile", f.fullID(), "err", err)
+		f.readStrategy = mozilla.ReadCopy
 	}
 
-	opts := FFConfig.PlacesDSN
+	if f.snapshot == nil {
+		if f.snapshot, err = mozilla.NewPlacesSnapshot(path.Join(f.BkDir, f.BkFile)); err != nil {
+			return err
+		}
+	}
 
-	f.places, err = database.NewDB("places",
-		// using the copied places file instead of the original to avoid
-		// sqlite vfs lock errors
-		path.Join(pc.Path(), f.BkFile),
-		database.DBTypeFileDSN, opts).Init()
+	// the refresh replaces the WAL and the shared memory index of the
+	// snapshot, an open connection would read them as corrupted
+	f.closePlaces()
 
+	full, err := f.snapshot.Refresh()
 	if err != nil {
-		return pc, err
+		return fmt.Errorf("could not copy places.sqlite: %w", err)
 	}
+	log.Debug("refreshed places snapshot", "profile", f.fullID(), "full", full)
+
+	// read only to leave the copied WAL as is for the next refresh
+	f.places, err = database.NewDB("places",
+		f.snapshot.Path(),
+		database.DBTypeFileDSN,
+		FFConfig.PlacesDSN,
+		database.DsnOptions{"mode": "ro"}).Init()
+
+	return err
+}
 
-	return pc, nil
+func (f *Firefox) closePlaces() {
+	if f.places == nil {
+		return
+	}
+	if err := f.places.Close(); err != nil {
+		log.Error("closing places", "err", err)
+	}
+	f.places = nil
 }
 
 // init is required to register the module as a plugin when it is imported
@@ -1,13 +1,18 @@
 package firefox
 
 import (
+	"context"
+	"encoding/binary"
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
 	"github.com/chenhg5/collection"
+	"github.com/jmoiron/sqlx"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/blob42/gosuki/internal/database"
 	"github.com/blob42/gosuki/internal/index"
@@ -483,6 +488,17 @@ func Test_scanBookmarks(t *testing.T) {
 			}
 		})
 
+		t.Run("keywords", func(t *testing.T) {
+			node, exists := ff.URLIndex.Get("https://go.dev/")
+			assert.True(t, exists, "url missing in URLIndex")
+			urlNode := node.(*tree.Node)
+			assert.Equal(t, "golang", urlNode.Keyword)
+			assert.Equal(t, "golang", urlNode.GetBookmark().Sources[0].Keyword)
+
+			node, _ = ff.URLIndex.Get("https://www.rust-lang.org/")
+			assert.Empty(t, node.(*tree.Node).Keyword)
+		})
+
 		t.Run("url underneath the right folders", func(t *testing.T) {
 			for _, bk := range bookmarks {
 				// folder, folderScanned := ff.folderScanMap[bk.ParentId]
@@ -708,6 +724,139 @@ func Test_FindModifiedBookmarks(t *testing.T) {
 	})
 }
 
+func Test_FindTagOnlyChanges(t *testing.T) {
+	bkDir, bkFile := ff.BkDir, ff.BkFile
+	defer func() { ff.BkDir, ff.BkFile = bkDir, bkFile }()
+
+	ff.BkDir = t.TempDir()
+	ff.BkFile = mozilla.PlacesFile
+	err := utils.CopyFileToDst("../../pkg/browsers/mozilla/testdata/places.sqlite",
+		ff.BkDir+"/"+mozilla.PlacesFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// tag rust-lang.org with the existing golang tag (27), only the new tag
+	// entry is modified
+	places, err := database.NewDB("places_copy", ff.BkDir+"/"+mozilla.PlacesFile,
+		database.DBTypeFileDSN).Init()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = places.Handle.Exec(`INSERT INTO moz_bookmarks(type, fk, parent, dateAdded, lastModified)
+		VALUES (1, 16, 27, 1680000000000000, 1680000000000000)`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	places.Close()
+
+	runPlacesTest("tag only change", t, func(t *testing.T) {
+		bookmarks, err := ff.scanModifiedBookmarks(1670207248342000)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		urls := []string{}
+		for _, bk := range bookmarks {
+			urls = append(urls, bk.URL)
+			if bk.URL == "https://www.rust-lang.org/" {
+				assert.Contains(t, strings.Split(bk.Tags, ","), "golang")
+			}
+		}
+		assert.Equal(t, []string{"https://www.rust-lang.org/"}, urls)
+	})
+}
+
+func TestOpenPlaces(t *testing.T) {
+	dir := t.TempDir()
+	err := utils.CopyFileToDst("../../pkg/browsers/mozilla/testdata/places.sqlite",
+		filepath.Join(dir, mozilla.PlacesFile))
+	require.NoError(t, err)
+
+	f := &Firefox{
+		FirefoxConfig: &FirefoxConfig{
+			BrowserConfig: &modules.BrowserConfig{
+				BkDir:  dir,
+				BkFile: mozilla.PlacesFile,
+			},
+		},
+	}
+
+	countBookmarks := func(t *testing.T) {
+		var count int
+		require.NoError(t, f.places.Handle.Get(&count, "SELECT count(*) FROM moz_bookmarks"))
+		assert.Positive(t, count)
+	}
+
+	t.Run("live", func(t *testing.T) {
+		defer f.closePlaces()
+		f.readStrategy = mozilla.ReadLive
+		require.NoError(t, f.openPlaces())
+		assert.Contains(t, f.places.Path, dir)
+		countBookmarks(t)
+
+		// the connection is reused by the next run
+		places := f.places
+		require.NoError(t, f.openPlaces())
+		assert.Same(t, places, f.places)
+	})
+
+	t.Run("copy", func(t *testing.T) {
+		defer f.closePlaces()
+		f.readStrategy = mozilla.ReadCopy
+		require.NoError(t, f.openPlaces())
+		assert.NotContains(t, f.places.Path, dir)
+		countBookmarks(t)
+
+		// changes written to the WAL of places.sqlite show up in the snapshot
+		db, err := sqlx.Open("sqlite3",
+			"file:"+filepath.Join(dir, mozilla.PlacesFile)+"?_journal_mode=WAL")
+		require.NoError(t, err)
+		defer db.Close()
+		db.SetMaxOpenConns(1)
+		_, err = db.Exec("PRAGMA wal_autocheckpoint=0")
+		require.NoError(t, err)
+
+		for _, want := range []string{"snapshot test", "snapshot test 2"} {
+			_, err = db.Exec("UPDATE moz_bookmarks SET title = ? WHERE id = 1", want)
+			require.NoError(t, err)
+
+			// the refresh replaces the files under the connection
+			places := f.places
+			require.NoError(t, f.openPlaces())
+			assert.NotSame(t, places, f.places)
+			var title string
+			require.NoError(t, f.places.Handle.Get(&title, "SELECT title FROM moz_bookmarks WHERE id = 1"))
+			assert.Equal(t, want, title)
+		}
+
+		// a checkpoint copies the database again
+		_, err = db.Exec("PRAGMA wal_checkpoint(TRUNCATE)")
+		require.NoError(t, err)
+		require.NoError(t, f.openPlaces())
+		countBookmarks(t)
+	})
+
+	t.Run("live falls back to copy when locked", func(t *testing.T) {
+		defer f.closePlaces()
+		db, err := sqlx.Open("sqlite3", "file:"+filepath.Join(dir, mozilla.PlacesFile))
+		require.NoError(t, err)
+		defer db.Close()
+		db.SetMaxOpenConns(1)
+		_, err = db.Exec("PRAGMA locking_mode=EXCLUSIVE")
+		require.NoError(t, err)
+		_, err = db.Exec("UPDATE moz_bookmarks SET title = title WHERE id = 1")
+		require.NoError(t, err)
+
+		f.readStrategy = mozilla.ReadLive
+		require.NoError(t, f.openPlaces())
+		assert.Equal(t, mozilla.ReadCopy, f.readStrategy)
+		countBookmarks(t)
+	})
+
+	require.NoError(t, f.Shutdown())
+}
+
 func TestBrowserImplProfileManager(t *testing.T) {
 	assert.Implements(t, (*profiles.ProfileManager)(nil), NewFirefox())
 }
@@ -715,3 +864,78 @@ func TestBrowserImplProfileManager(t *testing.T) {
 // func Test_FindModifiedFolders(t *testing.T) {
 // 	t.Skip("modified folder names should change the corresponding bookmark tags")
 // }
+
+func TestReadHistory(t *testing.T) {
+	f := &Firefox{
+		FirefoxConfig: &FirefoxConfig{
+			BrowserConfig: &modules.BrowserConfig{
+				BkDir:  "../../pkg/browsers/mozilla/testdata",
+				BkFile: mozilla.PlacesFile,
+			},
+		},
+	}
+
+	visits, err := f.ReadHistory(context.Background(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	byURL := map[string]*database.Visit{}
+	for _, v := range visits {
+		byURL[v.URL] = v
+	}
+	assert.Equal(t, &database.Visit{URL: "https://go.dev/", Count: 6, LastVisit: 1670185823},
+		byURL["https://go.dev/"])
+	assert.Equal(t, uint64(5), byURL["https://www.rust-lang.org/"].Count)
+}
+
+func TestReadSession(t *testing.T) {
+	session := `{"windows": [
+		{"tabs": [
+			{"entries": [{"url": "https://go.dev/", "title": "Go"},
+				{"url": "https://go.dev/doc/", "title": "Documentation"}], "index": 1},
+			{"entries": [{"url": "about:newtab"}], "index": 1},
+			{"entries": [], "index": 0}
+		]},
+		{"tabs": [{"entries": [{"url": "https://www.rust-lang.org/", "title": "Rust"}], "index": 2}]}
+	], "session": {"lastUpdate": 1670185823000}}`
+
+	// a single lz4 block of literals: 15 + 255... + remainder
+	block := []byte{0xf0}
+	n := len(session) - 15
+	for ; n >= 255; n -= 255 {
+		block = append(block, 255)
+	}
+	block = append(append(block, byte(n)), session...)
+	data := []byte("mozLz40\x00")
+	data = binary.LittleEndian.AppendUint32(data, uint32(len(session)))
+	data = append(data, block...)
+
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "sessionstore-backups"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, SessionStoreFile), data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	f := &Firefox{FirefoxConfig: &FirefoxConfig{BrowserConfig: &modules.BrowserConfig{BkDir: dir}}}
+	s, err := f.ReadSession(context.Background(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, uint64(1670185823), s.TakenAt)
+	if assert.Len(t, s.Windows, 2) {
+		assert.Equal(t, []*database.SessionTab{
+			{URL: "https://go.dev/", Title: "Go"},
+			{URL: "about:newtab"},
+		}, s.Windows[0].Tabs, "current entry of each tab")
+		assert.Equal(t, "https://www.rust-lang.org/", s.Windows[1].Tabs[0].URL,
+			"out of range index uses the last entry")
+	}
+
+	path, err := f.SessionPath(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, RecoveryFile), path)
+}
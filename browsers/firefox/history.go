@@ -0,0 +1,67 @@
+//
+// Copyright (c) 2023-2025 Chakib Ben Ziane <contact@blob42.xyz> and [`GoSuki` contributors]
+// (https://github.com/blob42/gosuki/graphs/contributors).
+//
+// All rights reserved.
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+//
+// This file is part of GoSuki.
+//
+// GoSuki is free software: you can redistribute it and/or modify it under the terms of
+// the GNU Affero General Public License as published by the Free Software Foundation,
+// either version 3 of the License, or (at your option) any later version.
+//
+// GoSuki is distributed in the hope that it will be useful, but WITHOUT ANY WARRANTY;
+// without even the implied warranty of MERCHANTABILITY or FITNESS FOR A PARTICULAR
+// PURPOSE.  See the GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License along with
+// gosuki.  If not, see <http://www.gnu.org/licenses/>.
+
+package firefox
+
+import (
+	"context"
+	"path/filepath"
+
+	"github.com/blob42/gosuki/internal/database"
+	"github.com/blob42/gosuki/pkg/modules"
+	"github.com/blob42/gosuki/pkg/profiles"
+)
+
+// visited urls of places.sqlite, last_visit_date is in microseconds
+const qHistory = `
+	SELECT url, visit_count, ifnull(last_visit_date, 0) / 1000000 AS last_visit
+	FROM moz_places WHERE visit_count > 0`
+
+// ReadHistory returns the visited urls of the profile from a copy of
+// places.sqlite
+func (f *Firefox) ReadHistory(ctx context.Context, p *profiles.Profile) ([]*database.Visit, error) {
+	dir, err := f.profileDir(p)
+	if err != nil {
+		return nil, err
+	}
+
+	places, done, err := modules.OpenHistoryCopy(filepath.Join(dir, f.BkFile))
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+
+	visits := []*database.Visit{}
+	if err = places.Handle.SelectContext(ctx, &visits, qHistory); err != nil {
+		return nil, err
+	}
+	return visits, nil
+}
+
+// profileDir returns the directory of profile p, or of the profile in use
+func (f *Firefox) profileDir(p *profiles.Profile) (string, error) {
+	if p == nil {
+		return f.BkDir, nil
+	}
+	return p.AbsolutePath()
+}
+
+var _ modules.HistoryReader = (*Firefox)(nil)
@@ -0,0 +1,120 @@
+//
+// Copyright (c) 2023-2025 Chakib Ben Ziane <contact@blob42.xyz> and [`GoSuki` contributors]
+// (https://github.com/blob42/gosuki/graphs/contributors).
+//
+// All rights reserved.
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+//
+// This file is part of GoSuki.
+//
+// GoSuki is free software: you can redistribute it and/or modify it under the terms of
+// the GNU Affero General Public License as published by the Free Software Foundation,
+// either version 3 of the License, or (at your option) any later version.
+//
+// GoSuki is distributed in the hope that it will be useful, but WITHOUT ANY WARRANTY;
+// without even the implied warranty of MERCHANTABILITY or FITNESS FOR A PARTICULAR
+// PURPOSE.  See the GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License along with
+// gosuki.  If not, see <http://www.gnu.org/licenses/>.
+
+package firefox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+
+	"github.com/blob42/gosuki/internal/database"
+	"github.com/blob42/gosuki/pkg/browsers/mozilla"
+	"github.com/blob42/gosuki/pkg/modules"
+	"github.com/blob42/gosuki/pkg/profiles"
+)
+
+const (
+	// session written by a running browser, relative to the profile
+	RecoveryFile = "sessionstore-backups/recovery.jsonlz4"
+
+	// session saved when the browser is closed
+	SessionStoreFile = "sessionstore.jsonlz4"
+)
+
+// subset of the session store
+type mozSession struct {
+	Windows []struct {
+		Tabs []struct {
+			Entries []struct {
+				URL   string `json:"url"`
+				Title string `json:"title"`
+			} `json:"entries"`
+
+			// current entry of the tab history, starts at 1
+			Index int `json:"index"`
+		} `json:"tabs"`
+	} `json:"windows"`
+
+	Session struct {
+		LastUpdate uint64 `json:"lastUpdate"` // milliseconds
+	} `json:"session"`
+}
+
+// SessionPath returns the recovery file of the profile, rewritten by Firefox
+// whenever its windows change
+func (f *Firefox) SessionPath(p *profiles.Profile) (string, error) {
+	dir, err := f.profileDir(p)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, RecoveryFile), nil
+}
+
+// ReadSession returns the windows open in the profile. The session saved at
+// shutdown is read when the browser is not running.
+func (f *Firefox) ReadSession(ctx context.Context, p *profiles.Profile) (*database.Session, error) {
+	dir, err := f.profileDir(p)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := mozilla.ReadMozLz4(filepath.Join(dir, RecoveryFile))
+	if errors.Is(err, os.ErrNotExist) {
+		data, err = mozilla.ReadMozLz4(filepath.Join(dir, SessionStoreFile))
+	}
+	if err != nil {
+		return nil, err
+	}
+	return parseSession(data)
+}
+
+func parseSession(data []byte) (*database.Session, error) {
+	moz := mozSession{}
+	if err := json.Unmarshal(data, &moz); err != nil {
+		return nil, err
+	}
+
+	session := &database.Session{TakenAt: moz.Session.LastUpdate / 1000}
+	for _, win := range moz.Windows {
+		window := &database.SessionWindow{}
+		for _, tab := range win.Tabs {
+			if len(tab.Entries) == 0 {
+				continue
+			}
+			current := tab.Entries[len(tab.Entries)-1]
+			if tab.Index > 0 && tab.Index <= len(tab.Entries) {
+				current = tab.Entries[tab.Index-1]
+			}
+			window.Tabs = append(window.Tabs, &database.SessionTab{
+				URL:   current.URL,
+				Title: current.Title,
+			})
+		}
+		session.Windows = append(session.Windows, window)
+	}
+
+	return session, nil
+}
+
+var _ modules.SessionReader = (*Firefox)(nil)
@@ -0,0 +1,81 @@
+//
+// Copyright (c) 2023-2025 Chakib Ben Ziane <contact@blob42.xyz> and [`GoSuki` contributors]
+// (https://github.com/blob42/gosuki/graphs/contributors).
+//
+// All rights reserved.
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+//
+// This file is part of GoSuki.
+//
+// GoSuki is free software: you can redistribute it and/or modify it under the terms of
+// the GNU Affero General Public License as published by the Free Software Foundation,
+// either version 3 of the License, or (at your option) any later version.
+//
+// GoSuki is distributed in the hope that it will be useful, but WITHOUT ANY WARRANTY;
+// without even the implied warranty of MERCHANTABILITY or FITNESS FOR A PARTICULAR
+// PURPOSE.  See the GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License along with
+// gosuki.  If not, see <http://www.gnu.org/licenses/>.
+
+package firefox
+
+import (
+	"errors"
+	"path/filepath"
+	"time"
+
+	"github.com/blob42/gosuki"
+	"github.com/blob42/gosuki/internal/database"
+	"github.com/blob42/gosuki/pkg/browsers/mozilla"
+	"github.com/blob42/gosuki/pkg/modules"
+)
+
+func (f *Firefox) WriteConfig() *modules.WriteBackConfig {
+	return &FFConfig.WriteBack
+}
+
+// WriteBookmarks replaces the content of the gosuki folder in places.sqlite.
+// The write is delayed while the database is used by a running browser, even
+// with the VFS lock disabled (see [mozilla.UnlockPlaces]).
+func (f *Firefox) WriteBookmarks(bks []*gosuki.Bookmark) error {
+	err := mozilla.CheckWritable(f.BkDir)
+	if errors.Is(err, mozilla.ErrPlacesLocked) {
+		return modules.ErrBrowserBusy
+	} else if err != nil {
+		return err
+	}
+
+	places, err := database.NewDB("places_writer",
+		filepath.Join(f.BkDir, f.BkFile),
+		database.DBTypeFileDSN,
+		database.DsnOptions{
+			"_journal_mode": "WAL",
+			"_busy_timeout": "5000",
+		}).Init()
+	if errors.Is(err, database.ErrVfsLocked) {
+		return modules.ErrBrowserBusy
+	} else if err != nil {
+		return err
+	}
+	defer places.Close()
+
+	entries := make([]mozilla.WriteBackEntry, 0, len(bks))
+	for _, bk := range bks {
+		entries = append(entries, mozilla.WriteBackEntry{URL: bk.URL, Title: bk.Title})
+	}
+
+	changed, err := mozilla.WriteBackFolder(places.Handle,
+		FFConfig.WriteBack.FolderName(), entries, time.Now())
+	if err != nil {
+		return err
+	}
+	if changed {
+		log.Debug("wrote bookmarks", "profile", f.Profile, "count", len(entries))
+	}
+
+	return nil
+}
+
+var _ modules.BookmarkWriter = (*Firefox)(nil)
@@ -23,7 +23,11 @@
 package qute
 
 import (
+	"path/filepath"
+
+	"github.com/blob42/gosuki/internal/utils"
 	"github.com/blob42/gosuki/pkg/browsers"
+	"github.com/blob42/gosuki/pkg/config"
 	"github.com/blob42/gosuki/pkg/logging"
 	"github.com/blob42/gosuki/pkg/modules"
 )
@@ -44,12 +48,31 @@ type QuteConfig struct {
 	quickmarksPath         string `toml:"-"`
 	*modules.BrowserConfig `toml:"-"`
 	modules.ProfilePrefs   `toml:"profile_options" mapstructure:"profile_options"`
+
+	// What to do with bookmarks deleted from the browser: "mirror" or "archive"
+	Deletions modules.DeletionMode `toml:"deletions" mapstructure:"deletions"`
+
+	// Write gosuki bookmarks into a dedicated browser folder
+	WriteBack modules.WriteBackConfig `toml:"write-back" mapstructure:"write-back"`
+
+	// Path to history.sqlite, read by the browser-history module
+	HistoryFile string `toml:"history-file" mapstructure:"history-file"`
+
+	// Directory of the saved sessions, read by the sessions module
+	SessionsDir string `toml:"sessions-dir" mapstructure:"sessions-dir"`
 }
 
 func NewQuteConfig() *QuteConfig {
 
 	baseDir := QuteBrowser.GetBaseDir()
 
+	// qutebrowser keeps its history and sessions in the data dir
+	var historyFile, sessionsDir string
+	if dataDir, err := utils.GetDataDir(); err == nil {
+		historyFile = filepath.Join(dataDir, "qutebrowser", "history.sqlite")
+		sessionsDir = filepath.Join(dataDir, "qutebrowser", "sessions")
+	}
+
 	config := &QuteConfig{
 		quickmarksPath: baseDir + "/quickmarks",
 		BrowserConfig: &modules.BrowserConfig{
@@ -63,7 +86,15 @@ func NewQuteConfig() *QuteConfig {
 		ProfilePrefs: modules.ProfilePrefs{
 			Profile: DefaultProfile,
 		},
+		Deletions:   modules.MirrorDeletions,
+		WriteBack:   modules.DefaultWriteBack(),
+		HistoryFile: historyFile,
+		SessionsDir: sessionsDir,
 	}
 
 	return config
 }
+
+func init() {
+	config.RegisterConfigurator(BrowserName, config.AsConfigurator(QuteCfg))
+}
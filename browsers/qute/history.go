@@ -0,0 +1,59 @@
+//
+//  Copyright (c) 2024-2025 Chakib Ben Ziane <contact@blob42.xyz>  and [`gosuki` contributors](https://github.com/blob42/gosuki/graphs/contributors).
+//  All rights reserved.
+//
+//  SPDX-License-Identifier: AGPL-3.0-or-later
+//
+//  This file is part of GoSuki.
+//
+//  GoSuki is free software: you can redistribute it and/or modify
+//  it under the terms of the GNU Affero General Public License as
+//  published by the Free Software Foundation, either version 3 of the
+//  License, or (at your option) any later version.
+//
+//  GoSuki is distributed in the hope that it will be useful,
+//  but WITHOUT ANY WARRANTY; without even the implied warranty of
+//  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU Affero General Public License for more details.
+//
+//  You should have received a copy of the GNU Affero General Public License
+//  along with gosuki.  If not, see <http://www.gnu.org/licenses/>.
+//
+
+package qute
+
+import (
+	"context"
+	"errors"
+
+	"github.com/blob42/gosuki/internal/database"
+	"github.com/blob42/gosuki/pkg/modules"
+	"github.com/blob42/gosuki/pkg/profiles"
+)
+
+// visited urls of history.sqlite, redirects are not counted
+const qHistory = `
+	SELECT url, count(*) AS visit_count, max(atime) AS last_visit
+	FROM History WHERE redirect = 0 GROUP BY url`
+
+// ReadHistory returns the visited urls from a copy of history.sqlite.
+// qutebrowser has a single profile.
+func (qu *Qute) ReadHistory(ctx context.Context, _ *profiles.Profile) ([]*database.Visit, error) {
+	if qu.HistoryFile == "" {
+		return nil, errors.New("qutebrowser history file not set")
+	}
+
+	hist, done, err := modules.OpenHistoryCopy(qu.HistoryFile)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+
+	visits := []*database.Visit{}
+	if err = hist.Handle.SelectContext(ctx, &visits, qHistory); err != nil {
+		return nil, err
+	}
+	return visits, nil
+}
+
+var _ modules.HistoryReader = (*Qute)(nil)
@@ -50,6 +50,9 @@ type Qute struct {
 	*QuteConfig
 	parsing.Counter
 	lastSentProgress float64
+
+	// urls loaded by the current run
+	urls []string
 }
 
 // PreCount implements parsing.Counter.
@@ -229,18 +232,25 @@ func (qu *Qute) loadBookmarks(runTask bool) error {
 
 		fields := strings.Fields(line)
 
+		// skip empty lines and the bookmarks written by gosuki
+		if len(fields) == 0 || isWriteBackLine(fields, qu.WriteBack.FolderName()) {
+			continue
+		}
+
 		bk := &gosuki.Bookmark{
 			URL: strings.TrimSpace(fields[0]),
 			Title: strings.TrimSpace(
 				strings.Join(fields[1:], " "),
 			),
-			Desc:   "",
-			Module: qu.Name,
+			Desc:    "",
+			Module:  qu.Name,
+			Sources: []gosuki.Source{{Module: qu.Name}},
 		}
 
 		qu.CallHooks(bk)
 
 		qu.BufferDB.UpsertBookmark(bk)
+		qu.urls = append(qu.urls, bk.URL)
 		qu.IncURLCount()
 		qu.trackProgress(runTask)
 	}
@@ -273,9 +283,10 @@ func (qu *Qute) loadQuickMarks(runTask bool) error {
 		fields := strings.Fields(line)
 
 		bk := &gosuki.Bookmark{
-			URL:    strings.TrimSpace(fields[len(fields)-1]), // Last field is the URL
-			Tags:   fields[:len(fields)-1],
-			Module: qu.Name,
+			URL:     strings.TrimSpace(fields[len(fields)-1]), // Last field is the URL
+			Tags:    fields[:len(fields)-1],
+			Module:  qu.Name,
+			Sources: []gosuki.Source{{Module: qu.Name}},
 		}
 
 		// Call hooks on bookmark instead of node
@@ -288,6 +299,7 @@ func (qu *Qute) loadQuickMarks(runTask bool) error {
 		if err != nil {
 			log.Errorf("db upsert: %s", bk.URL)
 		}
+		qu.urls = append(qu.urls, bk.URL)
 	}
 
 	return nil
@@ -322,6 +334,7 @@ func (qu *Qute) load(runTask bool) error {
 
 	// Loading logic
 	startWork := time.Now()
+	qu.urls = qu.urls[:0]
 	err := qu.loadBookmarks(runTask)
 	if err != nil {
 		return err
@@ -332,6 +345,8 @@ func (qu *Qute) load(runTask bool) error {
 		return err
 	}
 
+	qu.TrackDeletions(qu.Name, qu.urls, QuteCfg.Deletions)
+
 	qu.SetLastTreeParseRuntime(time.Since(startWork))
 	log.Debugf("<%s> loaded bookmarks in %s", qu.Name, qu.LastFullTreeParseRT())
 
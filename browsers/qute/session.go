@@ -0,0 +1,128 @@
+//
+//  Copyright (c) 2024-2025 Chakib Ben Ziane <contact@blob42.xyz>  and [`gosuki` contributors](https://github.com/blob42/gosuki/graphs/contributors).
+//  All rights reserved.
+//
+//  SPDX-License-Identifier: AGPL-3.0-or-later
+//
+//  This file is part of GoSuki.
+//
+//  GoSuki is free software: you can redistribute it and/or modify
+//  it under the terms of the GNU Affero General Public License as
+//  published by the Free Software Foundation, either version 3 of the
+//  License, or (at your option) any later version.
+//
+//  GoSuki is distributed in the hope that it will be useful,
+//  but WITHOUT ANY WARRANTY; without even the implied warranty of
+//  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU Affero General Public License for more details.
+//
+//  You should have received a copy of the GNU Affero General Public License
+//  along with gosuki.  If not, see <http://www.gnu.org/licenses/>.
+//
+
+package qute
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/blob42/gosuki/internal/database"
+	"github.com/blob42/gosuki/pkg/modules"
+	"github.com/blob42/gosuki/pkg/profiles"
+)
+
+// subset of a saved session
+type quteSession struct {
+	Windows []struct {
+		Tabs []struct {
+			History []struct {
+				URL    string `yaml:"url"`
+				Title  string `yaml:"title"`
+				Active bool   `yaml:"active"`
+			} `yaml:"history"`
+		} `yaml:"tabs"`
+	} `yaml:"windows"`
+}
+
+// SessionPath returns the directory of the saved sessions
+func (qu *Qute) SessionPath(_ *profiles.Profile) (string, error) {
+	if qu.SessionsDir == "" {
+		return "", errors.New("qutebrowser sessions dir not set")
+	}
+	return qu.SessionsDir, nil
+}
+
+// ReadSession returns the windows of the last saved session, usually the
+// autosave of the running browser
+func (qu *Qute) ReadSession(ctx context.Context, p *profiles.Profile) (*database.Session, error) {
+	dir, err := qu.SessionPath(p)
+	if err != nil {
+		return nil, err
+	}
+	files, err := filepath.Glob(filepath.Join(dir, "*.yml"))
+	if err != nil {
+		return nil, err
+	}
+
+	var path string
+	var info os.FileInfo
+	for _, file := range files {
+		fi, err := os.Stat(file)
+		if err != nil {
+			continue
+		}
+		if info == nil || fi.ModTime().After(info.ModTime()) {
+			path, info = file, fi
+		}
+	}
+	if info == nil {
+		return nil, errors.New("no qutebrowser session found")
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	session, err := parseSession(data)
+	if err != nil {
+		return nil, err
+	}
+	session.TakenAt = uint64(info.ModTime().Unix())
+	return session, nil
+}
+
+func parseSession(data []byte) (*database.Session, error) {
+	qs := quteSession{}
+	if err := yaml.Unmarshal(data, &qs); err != nil {
+		return nil, err
+	}
+
+	session := &database.Session{}
+	for _, win := range qs.Windows {
+		window := &database.SessionWindow{}
+		for _, tab := range win.Tabs {
+			if len(tab.History) == 0 {
+				continue
+			}
+			current := tab.History[len(tab.History)-1]
+			for _, entry := range tab.History {
+				if entry.Active {
+					current = entry
+				}
+			}
+			window.Tabs = append(window.Tabs, &database.SessionTab{
+				URL:   current.URL,
+				Title: current.Title,
+			})
+		}
+		session.Windows = append(session.Windows, window)
+	}
+
+	return session, nil
+}
+
+var _ modules.SessionReader = (*Qute)(nil)
@@ -0,0 +1,57 @@
+package qute
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/blob42/gosuki/internal/database"
+)
+
+const autosave = `windows:
+- active: true
+  geometry: !!binary |
+    AdnQywADAAAAAAAAAAAAAAAABw8AAAQjAAAAAAAAAAAAAAcPAAAEIwAAAAACAAAAB4A=
+  tabs:
+  - active: true
+    history:
+    - active: false
+      title: Go
+      url: https://go.dev/
+      zoom: 1.0
+    - active: true
+      title: Documentation
+      url: https://go.dev/doc/
+      zoom: 1.0
+  - history:
+    - title: qutebrowser
+      url: qute://help/index.html
+`
+
+func TestReadSession(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "default.yml"), []byte("windows: []\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "_autosave.yml"), []byte(autosave), 0o644))
+
+	// the most recent session is read
+	old := time.Now().Add(-time.Hour)
+	require.NoError(t, os.Chtimes(filepath.Join(dir, "default.yml"), old, old))
+
+	qu := &Qute{QuteConfig: &QuteConfig{SessionsDir: dir}}
+	s, err := qu.ReadSession(context.Background(), nil)
+	require.NoError(t, err)
+	require.Len(t, s.Windows, 1)
+	assert.Equal(t, []*database.SessionTab{
+		{URL: "https://go.dev/doc/", Title: "Documentation"},
+		{URL: "qute://help/index.html", Title: "qutebrowser"},
+	}, s.Windows[0].Tabs)
+	assert.NotZero(t, s.TakenAt)
+
+	_, err = (&Qute{QuteConfig: &QuteConfig{SessionsDir: t.TempDir()}}).ReadSession(context.Background(), nil)
+	assert.Error(t, err)
+}
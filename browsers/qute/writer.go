@@ -0,0 +1,151 @@
+//
+//  Copyright (c) 2024-2025 Chakib Ben Ziane <contact@blob42.xyz>  and [`gosuki` contributors](https://github.com/blob42/gosuki/graphs/contributors).
+//  All rights reserved.
+//
+//  SPDX-License-Identifier: AGPL-3.0-or-later
+//
+//  This file is part of GoSuki.
+//
+//  GoSuki is free software: you can redistribute it and/or modify
+//  it under the terms of the GNU Affero General Public License as
+//  published by the Free Software Foundation, either version 3 of the
+//  License, or (at your option) any later version.
+//
+//  GoSuki is distributed in the hope that it will be useful,
+//  but WITHOUT ANY WARRANTY; without even the implied warranty of
+//  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU Affero General Public License for more details.
+//
+//  You should have received a copy of the GNU Affero General Public License
+//  along with gosuki.  If not, see <http://www.gnu.org/licenses/>.
+//
+
+package qute
+
+import (
+	"bufio"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/blob42/gosuki"
+	"github.com/blob42/gosuki/internal/utils"
+	"github.com/blob42/gosuki/pkg/modules"
+)
+
+// qutebrowser has no bookmark folders. Bookmarks written by gosuki are
+// appended to the `urls` file with their title prefixed by the folder name in
+// brackets: `https://example.com [gosuki] Example`.
+
+func folderMarker(folder string) string {
+	return "[" + folder + "]"
+}
+
+// isWriteBackLine reports whether the fields of a `urls` line belong to a
+// bookmark written by gosuki
+func isWriteBackLine(fields []string, folder string) bool {
+	return len(fields) > 1 && fields[1] == folderMarker(folder)
+}
+
+func (qu *Qute) WriteConfig() *modules.WriteBackConfig {
+	return &qu.WriteBack
+}
+
+// WriteBookmarks replaces the gosuki bookmarks in the qutebrowser `urls` file.
+// Quickmarks are left untouched.
+func (qu *Qute) WriteBookmarks(bks []*gosuki.Bookmark) error {
+	bkPath, err := qu.BookmarkPath()
+	if err != nil {
+		return err
+	}
+
+	data, err := os.ReadFile(bkPath)
+	if err != nil {
+		return err
+	}
+
+	quickmarks, err := quickmarkURLs(qu.quickmarksPath)
+	if err != nil {
+		return err
+	}
+
+	out, changed := writeURLs(data, quickmarks, qu.WriteBack.FolderName(), bks)
+	if !changed {
+		return nil
+	}
+
+	log.Debug("writing bookmarks", "file", bkPath)
+	return utils.WriteFileAtomic(bkPath, out)
+}
+
+// writeURLs returns the content of the `urls` file with the gosuki lines
+// replaced by bks. Bookmarks found in the file or in `quickmarks` are skipped.
+func writeURLs(data []byte, quickmarks []string, folder string, bks []*gosuki.Bookmark) ([]byte, bool) {
+	var kept, current, wanted []string
+
+	known := map[string]bool{}
+	for _, url := range quickmarks {
+		known[url] = true
+	}
+
+	scanner := bufio.NewScanner(strings.NewReader(string(data)))
+	for scanner.Scan() {
+		line := scanner.Text()
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		if isWriteBackLine(fields, folder) {
+			current = append(current, line)
+			continue
+		}
+
+		kept = append(kept, line)
+		known[fields[0]] = true
+	}
+
+	for _, bk := range bks {
+		if known[bk.URL] || strings.ContainsAny(bk.URL, " \t\n") {
+			continue
+		}
+		known[bk.URL] = true
+
+		line := bk.URL + " " + folderMarker(folder)
+		if title := strings.Join(strings.Fields(bk.Title), " "); title != "" {
+			line += " " + title
+		}
+		wanted = append(wanted, line)
+	}
+
+	if slices.Equal(current, wanted) {
+		return data, false
+	}
+
+	var sb strings.Builder
+	for _, line := range append(kept, wanted...) {
+		sb.WriteString(line)
+		sb.WriteByte('\n')
+	}
+
+	return []byte(sb.String()), true
+}
+
+func quickmarkURLs(path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	var urls []string
+	for line := range strings.Lines(string(data)) {
+		if fields := strings.Fields(line); len(fields) > 0 {
+			urls = append(urls, fields[len(fields)-1])
+		}
+	}
+	return urls, nil
+}
+
+var _ modules.BookmarkWriter = (*Qute)(nil)
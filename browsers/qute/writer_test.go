@@ -0,0 +1,40 @@
+package qute
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/blob42/gosuki"
+)
+
+func TestWriteURLs(t *testing.T) {
+	urls := "https://example.com Example\n" +
+		"https://old.org [gosuki] Old\n" +
+		"https://qute.org qutebrowser\n"
+
+	bks := []*gosuki.Bookmark{
+		{URL: "https://example.com", Title: "already in urls"},
+		{URL: "https://quick.org", Title: "already a quickmark"},
+		{URL: "https://new.org", Title: "New\ntitle"},
+		{URL: "https://notitle.org"},
+	}
+
+	out, changed := writeURLs([]byte(urls), []string{"https://quick.org"}, "gosuki", bks)
+	assert.True(t, changed)
+	assert.Equal(t, "https://example.com Example\n"+
+		"https://qute.org qutebrowser\n"+
+		"https://new.org [gosuki] New title\n"+
+		"https://notitle.org [gosuki]\n", string(out))
+
+	t.Run("up to date", func(t *testing.T) {
+		_, changed := writeURLs(out, []string{"https://quick.org"}, "gosuki", bks)
+		assert.False(t, changed)
+	})
+
+	t.Run("remove all", func(t *testing.T) {
+		out, changed := writeURLs(out, nil, "gosuki", nil)
+		assert.True(t, changed)
+		assert.Equal(t, "https://example.com Example\nhttps://qute.org qutebrowser\n", string(out))
+	})
+}
@@ -0,0 +1,149 @@
+//
+// Copyright (c) 2023-2025 Chakib Ben Ziane <contact@blob42.xyz> and [`GoSuki` contributors]
+// (https://github.com/blob42/gosuki/graphs/contributors).
+//
+// All rights reserved.
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+//
+// This file is part of GoSuki.
+//
+// GoSuki is free software: you can redistribute it and/or modify it under the terms of
+// the GNU Affero General Public License as published by the Free Software Foundation,
+// either version 3 of the License, or (at your option) any later version.
+//
+// GoSuki is distributed in the hope that it will be useful, but WITHOUT ANY WARRANTY;
+// without even the implied warranty of MERCHANTABILITY or FITNESS FOR A PARTICULAR
+// PURPOSE.  See the GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License along with
+// gosuki.  If not, see <http://www.gnu.org/licenses/>.
+
+package xbel
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/blob42/gosuki/pkg/tree"
+)
+
+// ParseXBEL reads an XBEL document into nodes under parent: folders become
+// folder nodes and bookmarks url nodes. Separators, aliases and the info
+// metadata are skipped. The document is decoded as a stream of tokens. It
+// returns the number of bookmarks read.
+func ParseXBEL(r io.Reader, parent *tree.Node) (int, error) {
+	dec := xml.NewDecoder(r)
+
+	stack := []*tree.Node{parent}
+	var text *string // title or desc being read
+	var buf strings.Builder
+	count := 0
+
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return count, fmt.Errorf("xbel: %w", err)
+		}
+
+		top := stack[len(stack)-1]
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "xbel":
+
+			case "folder":
+				node := &tree.Node{Type: tree.FolderNode, Parent: top}
+				top.Children = append(top.Children, node)
+				stack = append(stack, node)
+
+			case "bookmark":
+				node := &tree.Node{
+					Type:    tree.URLNode,
+					URL:     attr(t, "href"),
+					Created: parseTime(attr(t, "added")),
+					Parent:  top,
+				}
+				stack = append(stack, node)
+
+			// the title of the document itself is skipped
+			case "title", "desc":
+				if top == parent {
+					if err = dec.Skip(); err != nil {
+						return count, fmt.Errorf("xbel: %w", err)
+					}
+					continue
+				}
+				text = &top.Title
+				if t.Name.Local == "desc" {
+					text = &top.Desc
+				}
+				buf.Reset()
+
+			default:
+				if err = dec.Skip(); err != nil {
+					return count, fmt.Errorf("xbel: %w", err)
+				}
+			}
+
+		case xml.CharData:
+			if text != nil {
+				buf.Write(t)
+			}
+
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "title", "desc":
+				if text != nil {
+					*text = strings.TrimSpace(buf.String())
+					text = nil
+				}
+
+			case "folder":
+				stack = stack[:len(stack)-1]
+
+			case "bookmark":
+				stack = stack[:len(stack)-1]
+				if top.URL != "" {
+					top.Parent.Children = append(top.Parent.Children, top)
+					count++
+				}
+			}
+		}
+	}
+
+	return count, nil
+}
+
+func attr(el xml.StartElement, name string) string {
+	for _, a := range el.Attr {
+		if a.Name.Local == name {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+// parseTime reads the added attribute of a bookmark, a date in the ISO 8601
+// format or a unix timestamp depending on the browser
+func parseTime(value string) uint64 {
+	if value == "" {
+		return 0
+	}
+	if ts, err := strconv.ParseUint(value, 10, 64); err == nil {
+		return ts
+	}
+	for _, layout := range []string{time.RFC3339, "2006-01-02T15:04:05", time.DateOnly} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return uint64(t.Unix())
+		}
+	}
+	return 0
+}
@@ -0,0 +1,334 @@
+//
+// Copyright (c) 2023-2025 Chakib Ben Ziane <contact@blob42.xyz> and [`GoSuki` contributors]
+// (https://github.com/blob42/gosuki/graphs/contributors).
+//
+// All rights reserved.
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+//
+// This file is part of GoSuki.
+//
+// GoSuki is free software: you can redistribute it and/or modify it under the terms of
+// the GNU Affero General Public License as published by the Free Software Foundation,
+// either version 3 of the License, or (at your option) any later version.
+//
+// GoSuki is distributed in the hope that it will be useful, but WITHOUT ANY WARRANTY;
+// without even the implied warranty of MERCHANTABILITY or FITNESS FOR A PARTICULAR
+// PURPOSE.  See the GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License along with
+// gosuki.  If not, see <http://www.gnu.org/licenses/>.
+
+// Package xbel implements a module for the browsers storing their bookmarks
+// in the XML Bookmark Exchange Language: Konqueror, Midori and Otter.
+//
+// XBEL browsers are listed in browsers.yaml under the xbel family. Each one is
+// handled as a flavour with a single profile, folders are kept as tags.
+package xbel
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+
+	"github.com/blob42/gosuki/hooks"
+	"github.com/blob42/gosuki/internal/database"
+	"github.com/blob42/gosuki/internal/utils"
+	"github.com/blob42/gosuki/pkg/browsers"
+	"github.com/blob42/gosuki/pkg/events"
+	"github.com/blob42/gosuki/pkg/modules"
+	"github.com/blob42/gosuki/pkg/parsing"
+	"github.com/blob42/gosuki/pkg/profiles"
+	"github.com/blob42/gosuki/pkg/tree"
+	"github.com/blob42/gosuki/pkg/watch"
+)
+
+// XBEL browser module
+type XBEL struct {
+	// holds browsers.BrowserConfig
+	*XBELConfig
+	parsing.Counter
+
+	activeProfile *profiles.Profile
+
+	activeFlavour *browsers.BrowserDef
+}
+
+// Returns all the defined XBEL browsers found on the system
+func (*XBEL) ListFlavours() []browsers.BrowserDef {
+	var result []browsers.BrowserDef
+
+	for _, v := range browsers.Defined(browsers.XBEL) {
+		if v.Detect() {
+			result = append(result, v)
+		}
+	}
+
+	slices.SortFunc(result, func(a, b browsers.BrowserDef) int {
+		return strings.Compare(a.Flavour, b.Flavour)
+	})
+
+	return result
+}
+
+// XBEL browsers have a single bookmark file and no profiles. The flavour is
+// used as profile name.
+func (*XBEL) GetProfiles(flavour string) ([]*profiles.Profile, error) {
+	def, ok := browsers.Defined(browsers.XBEL)[flavour]
+	if !ok {
+		return nil, fmt.Errorf("unknown xbel browser: %s", flavour)
+	}
+
+	baseDir, err := def.ExpandBaseDir()
+	if err != nil {
+		return nil, err
+	}
+
+	exists, err := utils.CheckFileExists(filepath.Join(baseDir, bookmarkFile(&def)))
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, fmt.Errorf("<%s> bookmark file not found", flavour)
+	}
+
+	return []*profiles.Profile{{
+		ID:      flavour,
+		Name:    flavour,
+		Path:    baseDir,
+		BaseDir: baseDir,
+	}}, nil
+}
+
+// Every detected XBEL browser is watched
+func (*XBEL) WatchAllProfiles() bool {
+	return true
+}
+
+// UseProfile points the instance to the bookmark file of the flavour. The
+// instance gets its own copy of the config as each flavour has its own
+// bookmark file, buffer and watcher.
+func (x *XBEL) UseProfile(p *profiles.Profile, flv *browsers.BrowserDef) error {
+	cfg := *x.XBELConfig
+	bConf := *x.BrowserConfig
+	cfg.BrowserConfig = &bConf
+	x.XBELConfig = &cfg
+
+	if p != nil {
+		x.activeProfile = p
+
+		bookmarkDir, err := p.AbsolutePath()
+		if err != nil {
+			return err
+		}
+		x.BkDir = bookmarkDir
+	}
+
+	if flv != nil {
+		x.activeFlavour = flv
+		x.BkFile = bookmarkFile(flv)
+	}
+
+	return nil
+}
+
+func (x *XBEL) GetProfile() *profiles.Profile {
+	return x.activeProfile
+}
+
+func (x *XBEL) GetCurFlavour() *browsers.BrowserDef {
+	return x.activeFlavour
+}
+
+func bookmarkFile(def *browsers.BrowserDef) string {
+	if def.BkFile != "" {
+		return def.BkFile
+	}
+	return DefaultBkFile
+}
+
+// source module of the bookmarks, ex: xbel_konqueror
+func (x *XBEL) moduleName() string {
+	if x.activeFlavour == nil {
+		return x.Name
+	}
+	return fmt.Sprintf("%s_%s", x.Name, x.activeFlavour.Flavour)
+}
+
+func (x *XBEL) Init(_ *modules.Context) error {
+	if x.activeFlavour == nil {
+		return errors.New("no xbel browser selected")
+	}
+
+	log.Infof("initializing <%s>", x.moduleName())
+	return x.setupWatchers()
+}
+
+func (x *XBEL) setupWatchers() error {
+	bookmarkPath, err := x.BookmarkPath()
+	if err != nil {
+		return err
+	}
+
+	// browsers usually replace the file on save
+	w := &watch.Watch{
+		Path:       x.BkDir,
+		EventTypes: []fsnotify.Op{fsnotify.Create, fsnotify.Write},
+		EventNames: []string{bookmarkPath},
+	}
+
+	ok, err := modules.SetupWatchers(x.BrowserConfig, w)
+	if err != nil {
+		log.Error(err)
+		return modules.ErrWatcherSetup
+	}
+	if !ok {
+		return modules.ErrWatcherSetup
+	}
+
+	return nil
+}
+
+// Returns a pointer to an initialized browser config
+func (x XBEL) Config() *modules.BrowserConfig {
+	return x.BrowserConfig
+}
+
+func (x XBEL) ModInfo() modules.ModInfo {
+	return modules.ModInfo{
+		ID: modules.ModID(x.Name),
+		New: func() modules.Module {
+			return NewXBEL()
+		},
+	}
+}
+
+func (x *XBEL) Watch() *watch.WatchDescriptor {
+	// calls modules.BrowserConfig.GetWatcher()
+	return x.GetWatcher()
+}
+
+func (x *XBEL) Run() {
+	if err := x.load(true); err != nil {
+		log.Errorf("<%s>: %v", x.moduleName(), err)
+	}
+}
+
+// parseTree reads the bookmark file into a new node tree. The bookmarks are
+// parsed under an unnamed folder so that the top level XBEL folders are kept
+// in the folder tags.
+func (x *XBEL) parseTree() (*tree.Node, error) {
+	bookmarkPath, err := x.BookmarkPath()
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(bookmarkPath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	root := &tree.Node{Title: RootNodeName, Type: tree.RootNode}
+	top := &tree.Node{Type: tree.FolderNode, Parent: root}
+	root.Children = []*tree.Node{top}
+
+	if _, err = ParseXBEL(f, top); err != nil {
+		return nil, fmt.Errorf("%s: %w", bookmarkPath, err)
+	}
+
+	return root, nil
+}
+
+func (x *XBEL) load(runTask bool) error {
+	startRun := time.Now()
+
+	root, err := x.parseTree()
+	if err != nil {
+		return err
+	}
+	x.NodeTree = root
+	x.SetLastTreeParseRuntime(time.Since(startRun))
+
+	module := x.moduleName()
+	var urls []string
+	tree.MapNodeFunc(root, tree.URLNode, func(node *tree.Node) {
+		node.Module = module
+		if x.activeFlavour != nil {
+			node.Flavour = x.activeFlavour.Flavour
+		}
+
+		if err := x.CallHooks(node); err != nil {
+			log.Errorf("<%s> hooks: %s", module, err)
+		}
+
+		if folder := node.FolderPath(); folder != "" {
+			node.Tags = append(node.Tags, folder)
+		}
+
+		urls = append(urls, node.URL)
+		x.IncURLCount()
+	})
+
+	x.SetTotal(uint(len(urls)))
+	go func() {
+		msg := events.ProgressUpdateMsg{
+			ID:           x.ModInfo().ID,
+			Instance:     x,
+			CurrentCount: x.URLCount(),
+			Total:        x.Total(),
+			NewBk:        runTask,
+		}
+		events.TUIBus <- msg
+	}()
+
+	x.TrackDeletions(module, urls, x.Deletions)
+
+	log.Debugf("<%s> parsed %d bookmarks in %s", module, len(urls), x.LastFullTreeParseRT())
+
+	database.SyncTreeToBuffer(x.NodeTree, x.BufferDB)
+	if err = x.BufferDB.SyncToCache(); err != nil {
+		log.Errorf("<%s>: %v", module, err)
+	}
+
+	database.ScheduleBackupToDisk()
+	x.SetLastWatchRuntime(time.Since(startRun))
+
+	return err
+}
+
+func (x *XBEL) PreLoad(_ *modules.Context) error {
+	return x.load(false)
+}
+
+// Implement modules.Shutdowner
+func (x *XBEL) Shutdown() error {
+	return nil
+}
+
+func NewXBEL() *XBEL {
+	return &XBEL{
+		XBELConfig: XBELCfg,
+		Counter:    &parsing.BrowserCounter{},
+	}
+}
+
+func init() {
+	modules.RegisterBrowser(XBEL{XBELConfig: XBELCfg})
+}
+
+// interface guards
+
+var _ modules.BrowserModule = (*XBEL)(nil)
+var _ modules.Initializer = (*XBEL)(nil)
+var _ modules.PreLoader = (*XBEL)(nil)
+var _ watch.WatchRunner = (*XBEL)(nil)
+var _ hooks.HookRunner = (*XBEL)(nil)
+var _ parsing.Counter = (*XBEL)(nil)
+var _ profiles.ProfileManager = (*XBEL)(nil)
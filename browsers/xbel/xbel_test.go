@@ -0,0 +1,131 @@
+package xbel
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/blob42/gosuki/internal/database"
+	"github.com/blob42/gosuki/internal/index"
+	"github.com/blob42/gosuki/pkg/browsers"
+	"github.com/blob42/gosuki/pkg/logging"
+	"github.com/blob42/gosuki/pkg/tree"
+)
+
+const konquerorBookmarks = "testdata/konqueror/bookmarks.xml"
+
+func TestMain(m *testing.M) {
+	database.RegisterSqliteHooks()
+
+	cacheDB, err := database.NewDB(database.CacheName, "", database.DBTypeCacheDSN).Init()
+	if err != nil {
+		log.Fatal(err)
+	}
+	database.Cache = &database.CacheDB{DB: cacheDB}
+
+	browsers.AddBrowserDef(browsers.XBELBrowser("konqueror", "testdata/konqueror", "bookmarks.xml"))
+	browsers.AddBrowserDef(browsers.XBELBrowser("midori", "testdata/midori", ""))
+
+	os.Exit(m.Run())
+}
+
+func TestParseXBEL(t *testing.T) {
+	f, err := os.Open(konquerorBookmarks)
+	require.NoError(t, err)
+	defer f.Close()
+
+	root := &tree.Node{Type: tree.RootNode}
+	count, err := ParseXBEL(f, root)
+	require.NoError(t, err)
+	assert.Equal(t, 4, count)
+
+	// the document title, info and separator are skipped
+	require.Len(t, root.Children, 3)
+	assert.Empty(t, root.Title)
+
+	kde := root.Children[0]
+	assert.Equal(t, tree.URLNode, kde.Type)
+	assert.Equal(t, "https://kde.org", kde.URL)
+	assert.Equal(t, "KDE Community", kde.Title)
+	assert.EqualValues(t, 1673778600, kde.Created)
+
+	dev := root.Children[1]
+	assert.Equal(t, tree.FolderNode, dev.Type)
+	assert.Equal(t, "Dev", dev.Title)
+	require.Len(t, dev.Children, 2)
+	assert.Equal(t, "go docs & blog", dev.Children[0].Desc)
+
+	// bookmarks without href are dropped
+	tools := dev.Children[1]
+	require.Len(t, tools.Children, 1)
+	assert.EqualValues(t, 1700000000, tools.Children[0].Created)
+	assert.Same(t, tools, tools.Children[0].Parent)
+
+	// aliases are skipped
+	assert.Len(t, root.Children[2].Children, 1)
+
+	t.Run("malformed", func(t *testing.T) {
+		_, err := ParseXBEL(strings.NewReader("<xbel><folder><title>x</folder>"), &tree.Node{})
+		assert.Error(t, err)
+	})
+}
+
+func TestGetProfiles(t *testing.T) {
+	x := NewXBEL()
+
+	profs, err := x.GetProfiles("konqueror")
+	require.NoError(t, err)
+	require.Len(t, profs, 1)
+	assert.Equal(t, "konqueror", profs[0].Name)
+
+	// no bookmarks.xbel in the midori base dir
+	_, err = x.GetProfiles("midori")
+	assert.Error(t, err)
+
+	_, err = x.GetProfiles("unknown")
+	assert.Error(t, err)
+}
+
+func TestLoad(t *testing.T) {
+	logging.SetLevel(logging.Silent)
+
+	def := browsers.Defined(browsers.XBEL)["konqueror"]
+	x := NewXBEL()
+	profs, err := x.GetProfiles(def.Flavour)
+	require.NoError(t, err)
+	require.NoError(t, x.UseProfile(profs[0], &def))
+
+	// the shared config is left untouched
+	assert.Empty(t, XBELCfg.BkFile)
+	assert.Equal(t, "bookmarks.xml", x.BkFile)
+
+	x.BufferDB, err = database.NewBuffer("xbel_test")
+	require.NoError(t, err)
+	x.URLIndex = index.NewIndex()
+
+	require.NoError(t, x.load(false))
+	assert.EqualValues(t, 4, x.URLCount())
+
+	ctx := context.Background()
+	for url, tags := range map[string]string{
+		"https://kde.org":                  ",",
+		"https://go.dev":                   ",Dev,",
+		"https://github.com/blob42/gosuki": ",Dev/Tools,",
+		"https://lwn.net":                  ",Reading,",
+	} {
+		bk, err := x.BufferDB.BookmarkByURL(ctx, url)
+		require.NoError(t, err, url)
+		assert.Equal(t, tags, bk.Tags, url)
+		assert.Equal(t, "xbel_konqueror", bk.Module, url)
+	}
+
+	sources, err := x.BufferDB.URLSources(ctx, "https://github.com/blob42/gosuki")
+	require.NoError(t, err)
+	require.Len(t, sources, 1)
+	assert.Equal(t, "konqueror", sources[0].Flavour)
+	assert.Equal(t, "Dev/Tools", sources[0].Folder)
+}
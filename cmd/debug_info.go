@@ -24,17 +24,40 @@ package cmd
 
 import (
 	"context"
+	"io"
+	"maps"
+	"os"
+	"slices"
 
 	"github.com/urfave/cli/v3"
 
 	"github.com/blob42/gosuki/pkg/build"
 )
 
+// DebugInfoFunc writes module specific details to the debug-info output
+type DebugInfoFunc func(w io.Writer) error
+
+var debugInfos = map[string]DebugInfoFunc{}
+
+// RegisterDebugInfo adds the output of fn to the debug-info command
+func RegisterDebugInfo(modID string, fn DebugInfoFunc) {
+	if fn == nil {
+		log.Fatalf("cannot register nil debug info for <%s>", modID)
+	}
+	debugInfos[modID] = fn
+}
+
 var DebugInfoCmd = &cli.Command{
 	Name:   "debug-info",
 	Hidden: true,
 	Action: func(_ context.Context, _ *cli.Command) error {
 		build.DebugInfo()
+		for _, modID := range slices.Sorted(maps.Keys(debugInfos)) {
+			println()
+			if err := debugInfos[modID](os.Stderr); err != nil {
+				log.Error("debug info", "module", modID, "err", err)
+			}
+		}
 		return nil
 	},
 }
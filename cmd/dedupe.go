@@ -0,0 +1,120 @@
+//
+//  Copyright (c) 2025 Chakib Ben Ziane <contact@blob42.xyz>  and [`gosuki` contributors](https://github.com/blob42/gosuki/graphs/contributors).
+//  All rights reserved.
+//
+//  SPDX-License-Identifier: AGPL-3.0-or-later
+//
+//  This file is part of GoSuki.
+//
+//  GoSuki is free software: you can redistribute it and/or modify
+//  it under the terms of the GNU Affero General Public License as
+//  published by the Free Software Foundation, either version 3 of the
+//  License, or (at your option) any later version.
+//
+//  GoSuki is distributed in the hope that it will be useful,
+//  but WITHOUT ANY WARRANTY; without even the implied warranty of
+//  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU Affero General Public License for more details.
+//
+//  You should have received a copy of the GNU Affero General Public License
+//  along with gosuki.  If not, see <http://www.gnu.org/licenses/>.
+//
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/urfave/cli/v3"
+
+	db "github.com/blob42/gosuki/internal/database"
+)
+
+var DedupeCmd = &cli.Command{
+	Name:  "dedupe",
+	Usage: "Find and merge duplicate bookmarks",
+	Description: `Lists the groups of bookmarks whose urls only differ by scheme, host case, www.,
+trailing slash, tracking parameters or fragment. The rules are set in the
+[database.canonical-url] section of the config.
+
+With --merge, each group is merged into its first bookmark: tags are combined and
+the longest title and description are kept. The other bookmarks are deleted and
+not restored when browsers sync them again.
+
+Merging is refused while the gosuki daemon is running.`,
+	Flags: []cli.Flag{
+		&cli.BoolFlag{
+			Name:    "merge",
+			Aliases: []string{"m"},
+			Usage:   "merge the duplicates instead of only listing them",
+		},
+	},
+	Action: dedupeAction,
+}
+
+func dedupeAction(ctx context.Context, cmd *cli.Command) error {
+	db.Init(ctx, cmd)
+	defer db.DiskDB.Close()
+
+	if cmd.Bool("merge") {
+		if err := db.CheckDaemonStopped(); err != nil {
+			return err
+		}
+	}
+
+	// the rules may have changed since the keys were computed
+	if _, err := db.DiskDB.RefreshURLKeys(); err != nil {
+		return err
+	}
+
+	groups, err := db.DiskDB.DuplicateGroups(ctx)
+	if err != nil {
+		return err
+	}
+
+	if len(groups) == 0 {
+		fmt.Println("no duplicate bookmarks")
+		return nil
+	}
+
+	duplicates := 0
+	for _, group := range groups {
+		printDuplicateGroup(group)
+		duplicates += len(group.Bookmarks) - 1
+	}
+
+	if !cmd.Bool("merge") {
+		fmt.Printf("%d duplicates in %d groups, run with --merge to merge them\n",
+			duplicates, len(groups))
+		return nil
+	}
+
+	for _, group := range groups {
+		if err = db.DiskDB.MergeDuplicates(ctx, group); err != nil {
+			return fmt.Errorf("merging %s: %w", group.Key, err)
+		}
+	}
+
+	fmt.Printf("merged %d duplicates in %d groups\n", duplicates, len(groups))
+	return nil
+}
+
+func printDuplicateGroup(group *db.DuplicateGroup) {
+	merged := group.Merged()
+
+	fmt.Println(group.Key)
+	for i, raw := range group.Bookmarks {
+		action := "merge"
+		if i == 0 {
+			action = "keep"
+		}
+		fmt.Printf("  %-6s %s\n", action, raw.URL)
+	}
+	fmt.Printf("  title: %s\n", merged.Metadata)
+	if tags := strings.Trim(merged.Tags, db.TagSep); tags != "" {
+		fmt.Printf("  tags:  %s\n", tags)
+	}
+	fmt.Println()
+}
@@ -145,7 +145,7 @@ func exportToFormat(format int) cli.ActionFunc {
 		db.Init(ctx, cmd)
 		if rows, err = db.DiskDB.Handle.QueryxContext(
 			ctx,
-			`SELECT * FROM gskbookmarks`,
+			`SELECT * FROM gskbookmarks WHERE `+db.WhereNotDeleted,
 		); err != nil {
 			return err
 		}
@@ -46,6 +46,12 @@ var startDaemonCmd = &cli.Command{
 	Action: startDaemon,
 }
 
+// browserUnit holds the manager units started for one browser instance
+type browserUnit struct {
+	runner watch.WatchRunner
+	units  []*manager.WorkUnitManager
+}
+
 // runBrowserModule executes a browser module by setting up its context,
 // creating a browser instance, applying profile configuration if provided, and
 // registering it with the manager for execution. It handles module setup,
@@ -56,7 +62,7 @@ func runBrowserModule(m *manager.Manager,
 	cmd *cli.Command,
 	browserMod modules.BrowserModule,
 	pfl *profiles.Profile,
-	flav *browsers.BrowserDef) error {
+	flav *browsers.BrowserDef) (*browserUnit, error) {
 	var profileName string
 	mod := browserMod.ModInfo()
 
@@ -69,7 +75,7 @@ func runBrowserModule(m *manager.Manager,
 	//Create a browser instance
 	browser, ok := mod.New().(modules.BrowserModule)
 	if !ok {
-		return fmt.Errorf("module <%s> is not a BrowserModule", mod.ID)
+		return nil, fmt.Errorf("module <%s> is not a BrowserModule", mod.ID)
 	}
 	config := browser.Config()
 	log.Debugf("created browser instance <%s>", config.Name)
@@ -87,34 +93,34 @@ func runBrowserModule(m *manager.Manager,
 			err := fmt.Errorf("<%s> does not implement profiles.ProfileManager",
 				config.Name)
 			log.Error(err)
-			return err
+			return nil, err
 		}
 		if err := bpm.UseProfile(pfl, flav); err != nil {
 			log.Warnf("unable to load profile <%s.%s>: %s", mod.ID, pfl.Name, err)
-			return &modules.ErrModDisabled{Err: err}
+			return nil, &modules.ErrModDisabled{Err: err}
 		}
 		profileName = pfl.Name
 	}
 
 	runner, ok := browser.(watch.WatchRunner)
 	if !ok {
-		return errors.New("must implement watch.WatchRunner interface")
+		return nil, errors.New("must implement watch.WatchRunner interface")
 	}
 
-	go func() {
-		events.TUIBus <- events.RunnerStarted{WatchRunner: runner}
-	}()
-
 	// calls the setup logic for each browser instance
 	//PERF:
 	if err := modules.SetupBrowser(browser, modContext, pfl); err != nil {
-		return err
+		return nil, err
 	}
 
 	w := runner.Watch()
 	if w == nil {
-		return errors.New("must return a valid watch descriptor")
+		return nil, errors.New("must return a valid watch descriptor")
 	}
+
+	go func() {
+		events.TUIBus <- events.RunnerStarted{WatchRunner: runner}
+	}()
 	log.Debugf("adding watch runner <%s>", runner.Watch().ID)
 
 	// create the worker name
@@ -127,9 +133,18 @@ func runBrowserModule(m *manager.Manager,
 		WatchRunner: runner,
 	}
 
-	m.AddUnit(worker, unitName)
+	bu := &browserUnit{runner: runner}
+	bu.units = append(bu.units, m.AddUnit(worker, unitName))
 
-	return nil
+	// write gosuki bookmarks back into the browser
+	if writer, ok := browser.(modules.BookmarkWriter); ok && writer.WriteConfig().Enabled {
+		log.Debug("enabling write back", "browser", unitName,
+			"folder", writer.WriteConfig().FolderName())
+		bu.units = append(bu.units,
+			m.AddUnit(modules.WriteBackWork{Ctx: ctx, BookmarkWriter: writer}, unitName+"-writeback"))
+	}
+
+	return bu, nil
 }
 
 // bootstrapModules initializes and starts all available modules using the
@@ -227,6 +242,12 @@ func bootstrapModules(ctx context.Context, cmd *cli.Command, mngr *manager.Manag
 
 	registeredBrowsers := modules.GetBrowserModules()
 
+	// picks up profiles and browsers that appear after startup
+	supervisor, err := newProfileSupervisor(ctx, cmd, mngr)
+	if err != nil {
+		log.Warn("new browser profiles will not be detected", "err", err)
+	}
+
 	// start all registered browser modules
 	for _, browserMod := range registeredBrowsers {
 		mod := browserMod.ModInfo()
@@ -253,7 +274,7 @@ func bootstrapModules(ctx context.Context, cmd *cli.Command, mngr *manager.Manag
 					}
 					for _, p := range profs {
 						log.Debug("", "flavour", flav.Flavour, "profile", p.Name)
-						err = runBrowserModule(mngr, ctx, cmd, browserMod, p, &flav)
+						bu, err := runBrowserModule(mngr, ctx, cmd, browserMod, p, &flav)
 						if err != nil {
 							if errDisabled, errDisable := err.(*modules.ErrModDisabled); errDisable {
 								log.Warn(
@@ -266,17 +287,19 @@ func bootstrapModules(ctx context.Context, cmd *cli.Command, mngr *manager.Manag
 									errDisabled.Reason,
 								)
 								modules.Disable(browserMod.ModInfo().ID)
+								supervisor.disable(mod.ID, flav.Flavour, p)
 							} else {
 								log.Error(err, "browser", flav.Flavour)
 							}
 							continue
 						}
+						supervisor.track(mod.ID, flav.Flavour, p, bu)
 					}
 				}
 			} else {
 				log.Debugf("profile manager <%s> not watching all profiles",
 					browser.Config().Name)
-				err := runBrowserModule(mngr, ctx, cmd, browserMod, nil, nil)
+				_, err := runBrowserModule(mngr, ctx, cmd, browserMod, nil, nil)
 				if err != nil {
 					if _, errDisable := err.(*modules.ErrModDisabled); errDisable {
 						log.Warn("disabling browser", "mod", browserMod.ModInfo().ID)
@@ -290,7 +313,7 @@ func bootstrapModules(ctx context.Context, cmd *cli.Command, mngr *manager.Manag
 		} else {
 			log.Info("not implemented profiles.ProfileManager", "browser",
 				browser.Config().Name)
-			if err := runBrowserModule(mngr, ctx, cmd, browserMod, nil, nil); err != nil {
+			if _, err := runBrowserModule(mngr, ctx, cmd, browserMod, nil, nil); err != nil {
 				if _, errDisable := err.(*modules.ErrModDisabled); errDisable {
 					log.Warn("disabling browser", "mod", browserMod.ModInfo().ID)
 					modules.Disable(browserMod.ModInfo().ID)
@@ -302,5 +325,9 @@ func bootstrapModules(ctx context.Context, cmd *cli.Command, mngr *manager.Manag
 		}
 	}
 
+	if supervisor != nil {
+		mngr.AddUnit(supervisor, "profile-supervisor")
+	}
+
 	return nil
 }
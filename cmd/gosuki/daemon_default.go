@@ -45,6 +45,13 @@ func startDaemon(ctx context.Context, cmd *cli.Command) error {
 	// Initialize database and caches
 	db.Init(ctx, cmd)
 
+	// commands writing the database file refuse to run while the daemon does
+	release, err := db.ClaimDaemon()
+	if err != nil {
+		return err
+	}
+	defer release()
+
 	//TUI MODE
 	if cmd.Bool("tui") && isatty.IsTerminal(os.Stdout.Fd()) {
 		manager := initManager(true)
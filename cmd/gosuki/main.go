@@ -39,8 +39,11 @@ import (
 	"github.com/blob42/gosuki/cmd"
 
 	_ "github.com/blob42/gosuki/browsers/chrome"
+	_ "github.com/blob42/gosuki/browsers/epiphany"
+	_ "github.com/blob42/gosuki/browsers/falkon"
 	_ "github.com/blob42/gosuki/browsers/firefox"
 	_ "github.com/blob42/gosuki/browsers/qute"
+	_ "github.com/blob42/gosuki/browsers/xbel"
 
 	_ "github.com/blob42/gosuki/mods"
 )
@@ -146,6 +149,8 @@ func main() {
 		cmd.ModuleCmds,
 		cmd.ImportCmds,
 		cmd.ExportCmds,
+		cmd.DedupeCmd,
+		cmd.TagsCmds,
 		cmd.DebugInfoCmd,
 	}...)
 
@@ -0,0 +1,404 @@
+//
+// Copyright (c) 2023-2025 Chakib Ben Ziane <contact@blob42.xyz> and [`GoSuki` contributors]
+// (https://github.com/blob42/gosuki/graphs/contributors).
+//
+// All rights reserved.
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+//
+// This file is part of GoSuki.
+//
+// GoSuki is free software: you can redistribute it and/or modify it under the terms of
+// the GNU Affero General Public License as published by the Free Software Foundation,
+// either version 3 of the License, or (at your option) any later version.
+//
+// GoSuki is distributed in the hope that it will be useful, but WITHOUT ANY WARRANTY;
+// without even the implied warranty of MERCHANTABILITY or FITNESS FOR A PARTICULAR
+// PURPOSE.  See the GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License along with
+// gosuki.  If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/urfave/cli/v3"
+
+	"github.com/blob42/gosuki/browsers/chrome"
+	"github.com/blob42/gosuki/internal/utils"
+	"github.com/blob42/gosuki/pkg/browsers"
+	"github.com/blob42/gosuki/pkg/browsers/mozilla"
+	"github.com/blob42/gosuki/pkg/config"
+	"github.com/blob42/gosuki/pkg/events"
+	"github.com/blob42/gosuki/pkg/manager"
+	"github.com/blob42/gosuki/pkg/modules"
+	"github.com/blob42/gosuki/pkg/profiles"
+)
+
+// Time to wait for the file system to settle before rescanning profiles.
+// Browsers write several files when a profile is created or removed.
+const supervisorDelay = 2 * time.Second
+
+// profileUnit is a running browser profile tracked by the supervisor
+type profileUnit struct {
+	*browserUnit
+	mod     modules.ModID
+	flavour string
+	profile string // profile name
+	dir     string // profile directory
+}
+
+// profileSupervisor starts and stops browser units when profiles are added to
+// or removed from disk while the daemon runs. It watches the base dir of every
+// defined browser, which holds files like `profiles.ini` and Chrome's
+// `Local State`, and the closest existing parent of base dirs that do not
+// exist yet to catch newly installed browsers. On changes every profile
+// manager is asked again for its flavours and profiles.
+type profileSupervisor struct {
+	ctx  context.Context
+	cmd  *cli.Command
+	mngr *manager.Manager
+
+	w *fsnotify.Watcher
+
+	// watched dirs, true for dirs only watched as the parent of a browser
+	// base dir that does not exist yet
+	dirs map[string]bool
+
+	units map[string]*profileUnit
+
+	// profiles that could not be started yet, eg. a new Firefox profile
+	// without places.sqlite. Their dir is watched to retry on changes.
+	pending map[string]string
+
+	// profiles refused by their module, they are not retried until they
+	// are removed
+	disabled map[string]bool
+}
+
+func newProfileSupervisor(ctx context.Context,
+	cmd *cli.Command,
+	mngr *manager.Manager,
+) (*profileSupervisor, error) {
+	w, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("creating fsnotify watcher: %w", err)
+	}
+	return &profileSupervisor{
+		ctx:      ctx,
+		cmd:      cmd,
+		mngr:     mngr,
+		w:        w,
+		dirs:     map[string]bool{},
+		units:    map[string]*profileUnit{},
+		pending:  map[string]string{},
+		disabled: map[string]bool{},
+	}, nil
+}
+
+// profileDir returns the expanded directory of a profile without requiring
+// it to exist.
+func profileDir(p *profiles.Profile) string {
+	dir := p.Path
+	if p.IsRelative {
+		dir = filepath.Join(p.BaseDir, p.Path)
+	}
+	if expanded, err := utils.ExpandOnly(dir); err == nil {
+		return expanded
+	}
+	return dir
+}
+
+func profileKey(mod modules.ModID, flavour string, p *profiles.Profile) string {
+	return fmt.Sprintf("%s|%s|%s", mod, flavour, profileDir(p))
+}
+
+// track registers a profile started during bootstrap
+func (s *profileSupervisor) track(mod modules.ModID,
+	flavour string,
+	p *profiles.Profile,
+	bu *browserUnit,
+) {
+	if s == nil {
+		return
+	}
+	s.units[profileKey(mod, flavour, p)] = &profileUnit{
+		browserUnit: bu,
+		mod:         mod,
+		flavour:     flavour,
+		profile:     p.Name,
+		dir:         profileDir(p),
+	}
+}
+
+// disable registers a profile refused by its module during bootstrap
+func (s *profileSupervisor) disable(mod modules.ModID, flavour string, p *profiles.Profile) {
+	if s == nil {
+		return
+	}
+	s.disabled[profileKey(mod, flavour, p)] = true
+}
+
+// reconcile starts units for new profiles and stops the units of profiles
+// that disappeared.
+func (s *profileSupervisor) reconcile() {
+	seen := map[string]bool{}
+
+	// flavours whose profiles could not be listed, their units are kept
+	// running as the profile list could be in the middle of a rewrite
+	unknown := map[string]bool{}
+
+	for _, browserMod := range modules.GetBrowserModules() {
+		mod := browserMod.ModInfo()
+		pm, ok := mod.New().(profiles.ProfileManager)
+		if !ok || !(pm.WatchAllProfiles() || config.GlobalConfig.WatchAll) {
+			continue
+		}
+
+		for _, flav := range pm.ListFlavours() {
+			profs, err := pm.GetProfiles(flav.Flavour)
+			if err != nil {
+				log.Debug("listing profiles", "flavour", flav.Flavour, "err", err)
+				unknown[string(mod.ID)+"|"+flav.Flavour] = true
+				continue
+			}
+
+			for _, p := range profs {
+				key := profileKey(mod.ID, flav.Flavour, p)
+				seen[key] = true
+				if _, running := s.units[key]; running || s.disabled[key] {
+					continue
+				}
+
+				bu, err := runBrowserModule(s.mngr, s.ctx, s.cmd, browserMod, p, &flav)
+				if errDisabled, ok := err.(*modules.ErrModDisabled); ok {
+					log.Warn("disabling new profile", "flavour", flav.Flavour,
+						"profile", p.Name, "reason", errDisabled.Reason)
+					delete(s.pending, key)
+					s.disabled[key] = true
+					continue
+				} else if err != nil {
+					if _, retried := s.pending[key]; !retried {
+						log.Warn("could not start new profile", "flavour", flav.Flavour,
+							"profile", p.Name, "err", err)
+					}
+					s.pending[key] = profileDir(p)
+					continue
+				}
+
+				log.Info("watching new profile", "flavour", flav.Flavour, "profile", p.Name)
+				delete(s.pending, key)
+				s.track(mod.ID, flav.Flavour, p, bu)
+			}
+		}
+	}
+
+	for key, u := range s.units {
+		// the profiles of a module disabled during bootstrap are not listed
+		if seen[key] || unknown[string(u.mod)+"|"+u.flavour] || modules.Disabled(u.mod) {
+			continue
+		}
+		s.stop(key, u)
+	}
+
+	for key := range s.pending {
+		if !seen[key] {
+			delete(s.pending, key)
+		}
+	}
+
+	// a removed profile is retried if it is created again
+	for key := range s.disabled {
+		if !seen[key] {
+			delete(s.disabled, key)
+		}
+	}
+}
+
+func (s *profileSupervisor) stop(key string, u *profileUnit) {
+	log.Info("profile removed, stopping", "flavour", u.flavour, "profile", u.profile)
+	for _, wum := range u.units {
+		s.mngr.StopUnit(wum)
+	}
+	delete(s.units, key)
+
+	go func() {
+		events.TUIBus <- events.RunnerStopped{WatchRunner: u.runner}
+	}()
+}
+
+// baseDirs returns the expanded base dirs of all defined browsers, including
+// their snap and flatpak variants.
+func baseDirs() []string {
+	var res []string
+	for _, bd := range browsers.DefinedBrowsers {
+		for _, dir := range []string{bd.BaseDir, bd.SnapDir, bd.FlatpakDir} {
+			if dir == "" {
+				continue
+			}
+			expanded, err := utils.ExpandOnly(dir)
+			if err != nil {
+				continue
+			}
+			res = append(res, filepath.Clean(expanded))
+		}
+	}
+	return res
+}
+
+func isDir(path string) bool {
+	ok, _ := utils.DirExists(path)
+	return ok
+}
+
+// rewatch updates the watched dirs after profiles or browsers changed
+func (s *profileSupervisor) rewatch() {
+	dirs := map[string]bool{}
+
+	for _, dir := range baseDirs() {
+		if isDir(dir) {
+			dirs[dir] = false
+			continue
+		}
+
+		// wait for the browser to be installed
+		for parent := filepath.Dir(dir); parent != dir; dir, parent = parent, filepath.Dir(parent) {
+			if isDir(parent) {
+				if _, isBase := dirs[parent]; !isBase {
+					dirs[parent] = true
+				}
+				break
+			}
+		}
+	}
+
+	// profiles stored outside of the base dir or in a sub directory
+	for _, u := range s.units {
+		if parent := filepath.Dir(u.dir); isDir(parent) {
+			dirs[parent] = false
+		}
+	}
+	for _, dir := range s.pending {
+		if isDir(dir) {
+			dirs[dir] = false
+		}
+	}
+
+	for dir := range s.dirs {
+		if _, keep := dirs[dir]; !keep {
+			// the dir might be gone already
+			_ = s.w.Remove(dir)
+		}
+	}
+	for dir := range dirs {
+		if _, watched := s.dirs[dir]; watched {
+			continue
+		}
+		if err := s.w.Add(dir); err != nil {
+			log.Debug("watching dir", "dir", dir, "err", err)
+			delete(dirs, dir)
+		}
+	}
+
+	s.dirs = dirs
+}
+
+// relevant reports whether an event can change the list of profiles
+func (s *profileSupervisor) relevant(ev fsnotify.Event) bool {
+	parent := filepath.Dir(ev.Name)
+	if s.dirs[parent] {
+		// only a path leading to a browser base dir matters
+		for _, dir := range baseDirs() {
+			if dir == ev.Name || strings.HasPrefix(dir, ev.Name+string(os.PathSeparator)) {
+				return true
+			}
+		}
+		return false
+	}
+
+	// files listing the profiles or holding the bookmarks of a browser
+	// without profiles
+	switch name := filepath.Base(ev.Name); name {
+	case mozilla.ProfilesFile, chrome.StateFile:
+		return true
+	default:
+		for _, bd := range browsers.DefinedBrowsers {
+			if bd.BkFile != "" && bd.BkFile == name {
+				return true
+			}
+		}
+	}
+
+	switch {
+	case ev.Has(fsnotify.Create):
+		// new profile dir or first files of a pending profile
+		return isDir(ev.Name) || slices.Contains(slices.Collect(maps.Values(s.pending)), parent)
+	case ev.Has(fsnotify.Remove), ev.Has(fsnotify.Rename):
+		if _, watched := s.dirs[ev.Name]; watched {
+			return true
+		}
+		for _, u := range s.units {
+			if u.dir == ev.Name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (s *profileSupervisor) Run(m manager.UnitManager) {
+	s.rewatch()
+
+	rescan := time.NewTimer(supervisorDelay)
+	rescan.Stop()
+
+	// set to nil once closed, a closed channel is always ready
+	events, errs := s.w.Events, s.w.Errors
+
+	for {
+		select {
+		case ev, ok := <-events:
+			if !ok {
+				events = nil
+				continue
+			}
+			if s.relevant(ev) {
+				log.Trace("profile supervisor", "event", ev)
+				rescan.Reset(supervisorDelay)
+			}
+
+		case err, ok := <-errs:
+			if !ok {
+				errs = nil
+				continue
+			}
+			if err != nil {
+				log.Error("profile supervisor", "err", err)
+			}
+
+		case <-rescan.C:
+			s.reconcile()
+			s.rewatch()
+
+		case <-m.ShouldStop():
+			rescan.Stop()
+			if err := s.w.Close(); err != nil {
+				log.Error("closing profile supervisor", "err", err)
+			}
+			m.Done()
+			return
+		}
+	}
+}
+
+var _ manager.WorkUnit = (*profileSupervisor)(nil)
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/stretchr/testify/require"
+
+	"github.com/blob42/gosuki/pkg/browsers"
+	"github.com/blob42/gosuki/pkg/modules"
+)
+
+// newTestSupervisor returns a supervisor for a single browser whose base dir
+// is inside a temporary dir
+func newTestSupervisor(t *testing.T) (*profileSupervisor, string) {
+	t.Helper()
+	root := t.TempDir()
+	baseDir := filepath.Join(root, "browser")
+
+	defined := browsers.DefinedBrowsers
+	browsers.DefinedBrowsers = []browsers.BrowserDef{{Flavour: "test", BaseDir: baseDir}}
+	t.Cleanup(func() { browsers.DefinedBrowsers = defined })
+
+	s, err := newProfileSupervisor(t.Context(), nil, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { s.w.Close() })
+	return s, baseDir
+}
+
+// nextEvent returns the next event on path
+func nextEvent(t *testing.T, s *profileSupervisor, path string) fsnotify.Event {
+	t.Helper()
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case ev := <-s.w.Events:
+			if ev.Name == path {
+				return ev
+			}
+		case err := <-s.w.Errors:
+			require.NoError(t, err)
+		case <-timeout:
+			t.Fatalf("no event on %s", path)
+		}
+	}
+}
+
+func TestProfileSupervisor(t *testing.T) {
+	s, baseDir := newTestSupervisor(t)
+
+	// the browser is not installed yet, its parent is watched
+	s.rewatch()
+	require.Equal(t, map[string]bool{filepath.Dir(baseDir): true}, s.dirs)
+
+	require.NoError(t, os.Mkdir(baseDir, 0o755))
+	require.True(t, s.relevant(nextEvent(t, s, baseDir)))
+
+	other := filepath.Join(filepath.Dir(baseDir), "other")
+	require.NoError(t, os.Mkdir(other, 0o755))
+	require.False(t, s.relevant(nextEvent(t, s, other)))
+
+	s.rewatch()
+	require.Equal(t, map[string]bool{baseDir: false}, s.dirs)
+
+	t.Run("profile dir created", func(t *testing.T) {
+		dir := filepath.Join(baseDir, "profile")
+		require.NoError(t, os.Mkdir(dir, 0o755))
+		require.True(t, s.relevant(nextEvent(t, s, dir)))
+
+		file := filepath.Join(baseDir, "notes.txt")
+		require.NoError(t, os.WriteFile(file, nil, 0o644))
+		require.False(t, s.relevant(nextEvent(t, s, file)))
+	})
+
+	t.Run("profile dir removed", func(t *testing.T) {
+		dir := filepath.Join(baseDir, "profile")
+		s.units["test|profile"] = &profileUnit{
+			browserUnit: &browserUnit{},
+			flavour:     "test",
+			profile:     "profile",
+			dir:         dir,
+		}
+		t.Cleanup(func() { delete(s.units, "test|profile") })
+
+		require.NoError(t, os.Remove(dir))
+		require.True(t, s.relevant(nextEvent(t, s, dir)))
+
+		file := filepath.Join(baseDir, "notes.txt")
+		require.NoError(t, os.Remove(file))
+		require.False(t, s.relevant(nextEvent(t, s, file)))
+	})
+
+	t.Run("browser removed", func(t *testing.T) {
+		require.NoError(t, os.Remove(baseDir))
+		require.True(t, s.relevant(nextEvent(t, s, baseDir)))
+
+		s.rewatch()
+		require.Equal(t, map[string]bool{filepath.Dir(baseDir): true}, s.dirs)
+	})
+}
+
+type testUnitManager struct {
+	stop chan bool
+	done chan struct{}
+}
+
+func (m *testUnitManager) ShouldStop() <-chan bool { return m.stop }
+func (m *testUnitManager) Done()                   { close(m.done) }
+func (m *testUnitManager) Panic(err any)           { panic(err) }
+func (m *testUnitManager) RequestShutdown()        {}
+
+func TestProfileSupervisorClosedWatcher(t *testing.T) {
+	s, _ := newTestSupervisor(t)
+	m := &testUnitManager{stop: make(chan bool), done: make(chan struct{})}
+
+	go s.Run(m)
+	require.NoError(t, s.w.Close())
+
+	select {
+	case m.stop <- true:
+	case <-time.After(5 * time.Second):
+		t.Fatal("supervisor is not waiting for the manager")
+	}
+	select {
+	case <-m.done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("supervisor did not stop")
+	}
+}
+
+func TestProfileSupervisorDisabled(t *testing.T) {
+	s, baseDir := newTestSupervisor(t)
+
+	// a module disabled during bootstrap is not listed anymore
+	modules.Disable("disabled-test")
+	key := "disabled-test|test|" + filepath.Join(baseDir, "profile")
+	s.units[key] = &profileUnit{
+		browserUnit: &browserUnit{},
+		mod:         "disabled-test",
+		flavour:     "test",
+		profile:     "profile",
+		dir:         filepath.Join(baseDir, "profile"),
+	}
+
+	// a refused profile that was removed since
+	removed := "firefox|test|" + filepath.Join(baseDir, "removed")
+	s.disabled[removed] = true
+
+	s.reconcile()
+	require.Contains(t, s.units, key)
+	require.NotContains(t, s.disabled, removed)
+}
@@ -314,6 +314,23 @@ func formatSyncPeers(m tuiModel) string {
 
 }
 
+// removeModProgress forgets a browser instance that is no longer running
+func removeModProgress(m tuiModel, r watch.WatchRunner) {
+	br, ok := r.(modules.BrowserModule)
+	if !ok {
+		return
+	}
+
+	b, exists := m.browsers[string(br.ModInfo().ID)]
+	if !exists {
+		return
+	}
+	b.instances = slices.DeleteFunc(b.instances, func(inst modules.BrowserModule) bool {
+		return inst == br
+	})
+	delete(b.profileStates, br)
+}
+
 func setupModProgress(m tuiModel, r watch.WatchRunner) (tea.Model, tea.Cmd) {
 	mod, ok := r.(modules.Module)
 	if !ok {
@@ -420,6 +437,10 @@ func (m tuiModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case events.RunnerStarted:
 		return setupModProgress(m, msg.WatchRunner)
 
+	case events.RunnerStopped:
+		removeModProgress(m, msg.WatchRunner)
+		return m, nil
+
 	case events.StartedLoadingMsg:
 		_, isBr := m.browsers[string(msg.ID)]
 
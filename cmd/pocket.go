@@ -121,7 +121,7 @@ func importFromPocketCSV(ctx context.Context, c *cli.Command) error {
 		timeAdded := row[2]
 		tags := row[4]
 
-		modified, err := strconv.ParseUint(string(timeAdded), 10, 64)
+		added, err := strconv.ParseUint(string(timeAdded), 10, 64)
 		if err != nil {
 			panic(err)
 		}
@@ -131,7 +131,8 @@ func importFromPocketCSV(ctx context.Context, c *cli.Command) error {
 			Title:    title,
 			Tags:     strings.Split(tags, "|"),
 			Module:   PocketImporterID,
-			Modified: modified,
+			Modified: added,
+			Created:  added,
 		}
 
 		if err = DB.UpsertBookmark(bookmark); err != nil {
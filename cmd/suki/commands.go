@@ -26,13 +26,16 @@ import (
 	"fmt"
 	"html/template"
 	"os"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/urfave/cli/v3"
 
 	"github.com/blob42/gosuki"
-	"github.com/blob42/gosuki/internal/api"
 	db "github.com/blob42/gosuki/internal/database"
+	"github.com/blob42/gosuki/internal/search"
 )
 
 type searchOpts struct {
@@ -65,7 +68,7 @@ var FuzzySearchCmd = &cli.Command{
 	Aliases: []string{"f"},
 	Usage:   "fuzzy search anywhere",
 	UsageText: "Uses fuzzy search algorithm on any of the `URL`, `Title` and `Metadata`." +
-		"Supports :tag syntax like search command for tag filtering.",
+		"Supports the query syntax of the search command.",
 	Description: "",
 	ArgsUsage:   "",
 	Category:    "",
@@ -80,14 +83,91 @@ var FuzzySearchCmd = &cli.Command{
 var TagSearchCmd = &cli.Command{
 	Name:    "search",
 	Aliases: []string{"s"},
-	Usage:   "search bookmarks by tags with AND/OR operators",
-	UsageText: "suki search \"term :linux,kernel\" - searches for text + both tags\n" +
-		"suki search \":OR linux kernel\" - searches for either tag (case-insensitive)",
+	Usage:   "search bookmarks with the query syntax",
+	UsageText: "suki search \"term tag:linux tag:kernel\" - searches for text + both tags\n" +
+		"suki search \"tag:linux OR tag:kernel\" - searches for either tag (case-insensitive)\n" +
+		"suki search \"title:go -site:github.com modified:>=2025-01-01\"",
 	Action: func(ctx context.Context, cmd *cli.Command) error {
 		return searchBookmarks(ctx, cmd, searchOpts{false}, cmd.Args().Slice()...)
 	},
 }
 
+var HistoryCmd = &cli.Command{
+	Name:      "history",
+	Aliases:   []string{"h"},
+	Usage:     "show the changes made to a bookmark",
+	ArgsUsage: "URL",
+	UsageText: "suki history https://example.com\n\n" +
+		"Revisions are listed most recent first. A revision can be restored with\n" +
+		"POST /api/bookmarks/{id}/history/{revision}/restore on the gosuki web UI.",
+	Action: func(ctx context.Context, cmd *cli.Command) error {
+		if !cmd.Args().Present() {
+			return errors.New("missing bookmark url")
+		}
+		return printHistory(ctx, cmd.Args().First())
+	},
+}
+
+var WhereCmd = &cli.Command{
+	Name:      "where",
+	Aliases:   []string{"w"},
+	Usage:     "show the browser profiles and folders holding a bookmark",
+	ArgsUsage: "URL",
+	UsageText: "suki where https://example.com",
+	Action: func(ctx context.Context, cmd *cli.Command) error {
+		if !cmd.Args().Present() {
+			return errors.New("missing bookmark url")
+		}
+		return printSources(ctx, cmd.Args().First())
+	},
+}
+
+var TabsCmd = &cli.Command{
+	Name:  "tabs",
+	Usage: "list the snapshots of the browser windows and tabs",
+	UsageText: "suki tabs [--source firefox_default]\n" +
+		"suki tabs show ID\n" +
+		"suki tabs diff ID [ID]\n\n" +
+		"Snapshots are taken by the sessions module of the gosuki daemon when\n" +
+		"the open tabs change. diff compares a snapshot with the previous one of\n" +
+		"the same browser profile unless a second snapshot is given.",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:  "source",
+			Usage: "only list the snapshots of a browser profile",
+		},
+	},
+	Action: func(ctx context.Context, cmd *cli.Command) error {
+		return printSessions(ctx, cmd.String("source"))
+	},
+	Commands: []*cli.Command{
+		{
+			Name:      "show",
+			Usage:     "show the windows and tabs of a snapshot",
+			ArgsUsage: "ID",
+			Action: func(ctx context.Context, cmd *cli.Command) error {
+				ids, err := sessionIDs(cmd.Args().Slice(), 1)
+				if err != nil {
+					return err
+				}
+				return printSession(ctx, ids[0])
+			},
+		},
+		{
+			Name:      "diff",
+			Usage:     "show the tabs opened and closed between two snapshots",
+			ArgsUsage: "ID [ID]",
+			Action: func(ctx context.Context, cmd *cli.Command) error {
+				ids, err := sessionIDs(cmd.Args().Slice(), 2)
+				if err != nil {
+					return err
+				}
+				return printSessionDiff(ctx, ids...)
+			},
+		},
+	},
+}
+
 func formatMark(format string) (string, error) {
 	outFormat := strings.Clone(format)
 
@@ -162,10 +242,14 @@ func searchBookmarks(ctx context.Context, cmd *cli.Command, opts searchOpts, key
 	}
 
 	fullQuery := strings.Join(keyword, " ")
-	query := api.ParseSearchQuery(fullQuery)
-
-	var result *db.QueryResult
-	var err error
+	q, err := search.NewQuery(fullQuery, search.Options{Fuzzy: opts.fuzzy})
+	if err != nil {
+		var parseErr *search.ParseError
+		if errors.As(err, &parseErr) {
+			return fmt.Errorf("%w\n%s", err, parseErr.Caret())
+		}
+		return err
+	}
 
 	sortBy, sortAsc := parseSortFlag(cmd.String("sort"))
 	pageParms := db.PaginationParams{
@@ -175,27 +259,194 @@ func searchBookmarks(ctx context.Context, cmd *cli.Command, opts searchOpts, key
 		SortAsc: sortAsc,
 	}
 
-	if len(query.Tags) > 0 {
-		result, err = db.QueryBookmarksByTags(
-			ctx,
-			query.TextQuery,
-			query.Tags,
-			query.TagCond,
-			opts.fuzzy,
-			&pageParms,
-		)
-	} else {
-		result, err = db.QueryBookmarks(
-			ctx,
-			query.TextQuery,
-			opts.fuzzy,
-			&pageParms,
-		)
+	if !opts.fuzzy && pageParms.SortBy == "" {
+		pageParms.SortBy = db.SortRelevance
 	}
 
+	result, err := q.Run(ctx, &pageParms)
 	if err != nil {
 		return err
 	}
 
 	return formatPrint(ctx, cmd, result.Bookmarks)
 }
+
+func printHistory(ctx context.Context, url string) error {
+	revs, err := db.URLHistory(ctx, url)
+	if err != nil {
+		return err
+	}
+	if len(revs) == 0 {
+		return fmt.Errorf("no history for %s", url)
+	}
+
+	for _, rev := range revs {
+		changedAt := time.Unix(int64(rev.ChangedAt), 0).Format("2006-01-02 15:04:05")
+		fmt.Printf("#%d  %s  %-7s  bookmark:%d  v%d  %s\n",
+			rev.ID, changedAt, rev.Action, rev.BookmarkID, rev.Version, rev.Module)
+
+		if rev.OldTitle != rev.NewTitle {
+			fmt.Printf("    title: %q -> %q\n", rev.OldTitle, rev.NewTitle)
+		}
+		if added, removed := diffTags(rev.OldTagList(), rev.NewTagList()); len(added)+len(removed) > 0 {
+			changes := make([]string, 0, len(added)+len(removed))
+			for _, tag := range added {
+				changes = append(changes, "+"+tag)
+			}
+			for _, tag := range removed {
+				changes = append(changes, "-"+tag)
+			}
+			fmt.Printf("    tags: %s\n", strings.Join(changes, " "))
+		}
+		if rev.OldDesc != rev.NewDesc {
+			fmt.Printf("    desc: %q -> %q\n", rev.OldDesc, rev.NewDesc)
+		}
+	}
+
+	return nil
+}
+
+func printSources(ctx context.Context, url string) error {
+	sources, err := db.DiskDB.URLSources(ctx, url)
+	if err != nil {
+		return err
+	}
+	if len(sources) == 0 {
+		return fmt.Errorf("%s was not found in any browser", url)
+	}
+
+	for _, src := range sources {
+		lastSeen := time.Unix(int64(src.LastSeen), 0).Format("2006-01-02 15:04:05")
+		firstSeen := time.Unix(int64(src.FirstSeen), 0).Format("2006-01-02")
+		folder := src.Folder
+		if folder == "" {
+			folder = "-"
+		}
+		fmt.Printf("%-24s %-24s since %s  last seen %s", src.Module, folder, firstSeen, lastSeen)
+		if src.Keyword != "" {
+			fmt.Printf("  keyword %s", src.Keyword)
+		}
+		fmt.Println()
+	}
+
+	visits, err := db.DiskDB.URLVisits(ctx, url)
+	if err != nil {
+		return err
+	}
+	if visits.Count > 0 {
+		lastVisit := time.Unix(int64(visits.LastVisit), 0).Format("2006-01-02 15:04:05")
+		fmt.Printf("visited %d times, last visit %s\n", visits.Count, lastVisit)
+	}
+
+	return nil
+}
+
+// sessionIDs parses between 1 and max snapshot ids
+func sessionIDs(args []string, max int) ([]int64, error) {
+	if len(args) == 0 || len(args) > max {
+		return nil, fmt.Errorf("expected %d snapshot id(s)", max)
+	}
+	ids := make([]int64, len(args))
+	for i, arg := range args {
+		id, err := strconv.ParseInt(strings.TrimPrefix(arg, "#"), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid snapshot id: %s", arg)
+		}
+		ids[i] = id
+	}
+	return ids, nil
+}
+
+func formatUnix(ts uint64) string {
+	return time.Unix(int64(ts), 0).Format("2006-01-02 15:04:05")
+}
+
+func printSessions(ctx context.Context, source string) error {
+	sessions, err := db.DiskDB.Sessions(ctx, source)
+	if err != nil {
+		return err
+	}
+	if len(sessions) == 0 {
+		return errors.New("no session snapshot, enable the sessions module of gosuki")
+	}
+
+	for _, s := range sessions {
+		fmt.Printf("#%-5d %s  %-24s %d tabs\n", s.ID, formatUnix(s.TakenAt), s.Source, s.Tabs)
+	}
+	return nil
+}
+
+func printSession(ctx context.Context, id int64) error {
+	s, err := db.DiskDB.SessionByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("#%d  %s  %s\n", s.ID, formatUnix(s.TakenAt), s.Source)
+	for n, w := range s.Windows {
+		fmt.Printf("\n%s (%d tabs)\n", s.WindowName(n), len(w.Tabs))
+		for _, tab := range w.Tabs {
+			fmt.Printf("    %s  %s\n", tab.URL, tab.Title)
+		}
+	}
+	return nil
+}
+
+// printSessionDiff compares two snapshots, or a snapshot with the previous one
+// of its source
+func printSessionDiff(ctx context.Context, ids ...int64) error {
+	to, err := db.DiskDB.SessionByID(ctx, ids[len(ids)-1])
+	if err != nil {
+		return err
+	}
+
+	var from *db.Session
+	if len(ids) == 2 {
+		if from, err = db.DiskDB.SessionByID(ctx, ids[0]); err != nil {
+			return err
+		}
+	} else {
+		sessions, err := db.DiskDB.Sessions(ctx, to.Source)
+		if err != nil {
+			return err
+		}
+		i := slices.IndexFunc(sessions, func(s *db.Session) bool { return s.ID == to.ID })
+		if i < 0 || i+1 == len(sessions) {
+			return fmt.Errorf("no snapshot of %s before #%d", to.Source, to.ID)
+		}
+		if from, err = db.DiskDB.SessionByID(ctx, sessions[i+1].ID); err != nil {
+			return err
+		}
+	}
+
+	fmt.Printf("#%d %s %s -> #%d %s %s\n",
+		from.ID, from.Source, formatUnix(from.TakenAt),
+		to.ID, to.Source, formatUnix(to.TakenAt))
+
+	diff := db.DiffSessions(from, to)
+	for _, tab := range diff.Opened {
+		fmt.Printf("+ %s  %s\n", tab.URL, tab.Title)
+	}
+	for _, tab := range diff.Closed {
+		fmt.Printf("- %s  %s\n", tab.URL, tab.Title)
+	}
+	if len(diff.Opened)+len(diff.Closed) == 0 {
+		fmt.Println("same tabs")
+	}
+	return nil
+}
+
+// diffTags returns the tags added and removed between two tag lists
+func diffTags(old, new []string) (added, removed []string) {
+	for _, tag := range new {
+		if !slices.Contains(old, tag) {
+			added = append(added, tag)
+		}
+	}
+	for _, tag := range old {
+		if !slices.Contains(new, tag) {
+			removed = append(removed, tag)
+		}
+	}
+	return added, removed
+}
@@ -41,6 +41,21 @@ GLOBAL OPTIONS:{{template "visibleFlagCategoryTemplate" .}}{{else if .VisibleFla
 
 GLOBAL OPTIONS:{{template "visibleFlagTemplate" .}}{{end}}
 
+QUERY SYNTAX:
+   golang rust           bookmarks matching all the words
+   "rust book"           quoted phrase
+   go OR rust            either word, AND is implicit between terms
+   (go OR rust) -video   grouping and negation with - or NOT
+   title:go url:github   match a field: title, url, desc, tag, module, keyword, site
+   site:github.com       bookmarks of a domain and its subdomains
+   module:chrome         bookmarks found in a module, its flavours or profiles (chrome_brave_Work)
+   tag:Work/ProjectA     folder tags also match their sub folders (Work/ProjectA/Docs)
+   keyword:gh           bookmarks opened with an address bar keyword in a browser
+   modified:>2025-01-01  date comparisons: >, >=, <, <=, a day or a range (2025-01-01..2025-02-01)
+   created:<2024-06-01   same comparisons on the time the bookmark was first saved
+   status:dead           last link check: ok, dead, redirect, error, unchecked or an http code
+   golang :web,dev       legacy tag list, :OR web,dev matches any tag
+
 OUTPUT FORMATTING:
    You can customize the output format using the following placeholders:
 
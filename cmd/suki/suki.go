@@ -72,8 +72,8 @@ Usage examples:
 		&cli.StringFlag{
 			Name:        "sort",
 			Aliases:     []string{"s"},
-			Usage:       "Sort results: modified[:asc|desc], title[:asc|desc], url[:asc|desc]",
-			DefaultText: "none (insertion order)",
+			Usage:       "Sort results: modified[:asc|desc], created[:asc|desc], title[:asc|desc], url[:asc|desc], relevance, frecency",
+			DefaultText: "relevance for searches, none (insertion order) otherwise",
 		},
 	}
 	app.Flags = append(app.Flags, cmd.MainFlags...)
@@ -104,6 +104,9 @@ Usage examples:
 	app.Commands = []*cli.Command{
 		FuzzySearchCmd,
 		TagSearchCmd,
+		HistoryCmd,
+		WhereCmd,
+		TabsCmd,
 	}
 
 	app.ExitErrHandler = func(ctx context.Context, cli *cli.Command, err error) {
@@ -133,7 +136,7 @@ Usage examples:
 			firstKw = firstKw[1:]
 		}
 
-		return searchBookmarks(ctx, cmd, opts, firstKw)
+		return searchBookmarks(ctx, cmd, opts, append([]string{firstKw}, cmd.Args().Tail()...)...)
 	}
 
 	if err := app.Run(context.Background(), os.Args); err != nil {
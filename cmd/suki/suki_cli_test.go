@@ -97,3 +97,18 @@ func TestParseSortFlag_JustColon(t *testing.T) {
 	require.Equal(t, "", sortBy, "colon with no field should return empty sortBy")
 	require.False(t, sortAsc)
 }
+
+func TestSessionIDs(t *testing.T) {
+	ids, err := sessionIDs([]string{"12", "#14"}, 2)
+	require.NoError(t, err)
+	require.Equal(t, []int64{12, 14}, ids)
+
+	_, err = sessionIDs([]string{"12", "14"}, 1)
+	require.Error(t, err)
+
+	_, err = sessionIDs(nil, 2)
+	require.Error(t, err)
+
+	_, err = sessionIDs([]string{"latest"}, 1)
+	require.Error(t, err)
+}
@@ -0,0 +1,191 @@
+//
+//  Copyright (c) 2025 Chakib Ben Ziane <contact@blob42.xyz>  and [`gosuki` contributors](https://github.com/blob42/gosuki/graphs/contributors).
+//  All rights reserved.
+//
+//  SPDX-License-Identifier: AGPL-3.0-or-later
+//
+//  This file is part of GoSuki.
+//
+//  GoSuki is free software: you can redistribute it and/or modify
+//  it under the terms of the GNU Affero General Public License as
+//  published by the Free Software Foundation, either version 3 of the
+//  License, or (at your option) any later version.
+//
+//  GoSuki is distributed in the hope that it will be useful,
+//  but WITHOUT ANY WARRANTY; without even the implied warranty of
+//  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU Affero General Public License for more details.
+//
+//  You should have received a copy of the GNU Affero General Public License
+//  along with gosuki.  If not, see <http://www.gnu.org/licenses/>.
+//
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/urfave/cli/v3"
+
+	db "github.com/blob42/gosuki/internal/database"
+)
+
+var TagsCmds = &cli.Command{
+	Name:  "tags",
+	Usage: "List, rename, merge and alias tags",
+	Description: `Manage the tags of the bookmarks in the database. Renamed, merged and deleted
+tags become aliases so that browsers syncing the old tags again do not bring
+them back. Aliases are also applied to tag searches.
+
+Changing tags is refused while the gosuki daemon is running.`,
+	Commands: []*cli.Command{
+		tagsListCmd,
+		tagsRenameCmd,
+		tagsMergeCmd,
+		tagsAliasCmd,
+		tagsDeleteCmd,
+	},
+}
+
+var tagsListCmd = &cli.Command{
+	Name:   "list",
+	Usage:  "List tags with their number of bookmarks",
+	Action: tagsListAction,
+}
+
+var tagsRenameCmd = &cli.Command{
+	Name:      "rename",
+	Usage:     "Rename a tag in all bookmarks",
+	ArgsUsage: "<old> <new>",
+	Action: func(ctx context.Context, cmd *cli.Command) error {
+		if cmd.Args().Len() != 2 {
+			return errors.New("expected the old and new tag names")
+		}
+		return replaceTags(ctx, cmd, cmd.Args().Get(1), cmd.Args().Get(0))
+	},
+}
+
+var tagsMergeCmd = &cli.Command{
+	Name:      "merge",
+	Usage:     "Merge tags into one",
+	ArgsUsage: "<into> <tag>...",
+	Action: func(ctx context.Context, cmd *cli.Command) error {
+		if cmd.Args().Len() < 2 {
+			return errors.New("expected the target tag and the tags to merge")
+		}
+		return replaceTags(ctx, cmd, cmd.Args().First(), cmd.Args().Tail()...)
+	},
+}
+
+var tagsDeleteCmd = &cli.Command{
+	Name:      "delete",
+	Usage:     "Remove a tag from all bookmarks",
+	ArgsUsage: "<tag>",
+	Action: func(ctx context.Context, cmd *cli.Command) error {
+		if cmd.Args().Len() != 1 {
+			return errors.New("expected the tag to delete")
+		}
+		return replaceTags(ctx, cmd, "", cmd.Args().First())
+	},
+}
+
+var tagsAliasCmd = &cli.Command{
+	Name:  "alias",
+	Usage: "List or set tag aliases",
+	Description: `Without arguments, lists the aliases. With <alias> <tag>, incoming bookmarks
+tagged with <alias> get <tag> instead and searching <tag> also matches <alias>.
+Existing bookmarks are not changed, use the merge command for that.`,
+	ArgsUsage: "[<alias> <tag>]",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:    "remove",
+			Aliases: []string{"r"},
+			Usage:   "remove the given alias",
+		},
+	},
+	Action: tagsAliasAction,
+}
+
+func tagsListAction(ctx context.Context, cmd *cli.Command) error {
+	db.Init(ctx, cmd)
+	defer db.DiskDB.Close()
+
+	counts, err := db.DiskDB.TagCounts(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, tc := range counts {
+		if len(tc.Aliases) > 0 {
+			fmt.Printf("%6d  %s (%s)\n", tc.Count, tc.Name, strings.Join(tc.Aliases, ", "))
+		} else {
+			fmt.Printf("%6d  %s\n", tc.Count, tc.Name)
+		}
+	}
+	return nil
+}
+
+// replaces the from tags with into, or deletes them if into is empty
+func replaceTags(ctx context.Context, cmd *cli.Command, into string, from ...string) error {
+	db.Init(ctx, cmd)
+	defer db.DiskDB.Close()
+
+	if err := db.CheckDaemonStopped(); err != nil {
+		return err
+	}
+
+	var n int
+	var err error
+	if into == "" {
+		n, err = db.DiskDB.DeleteTag(ctx, from[0])
+	} else {
+		n, err = db.DiskDB.MergeTags(ctx, into, from...)
+	}
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("updated %d bookmarks\n", n)
+	return nil
+}
+
+func tagsAliasAction(ctx context.Context, cmd *cli.Command) error {
+	db.Init(ctx, cmd)
+	defer db.DiskDB.Close()
+
+	if cmd.String("remove") != "" || cmd.Args().Len() > 0 {
+		if err := db.CheckDaemonStopped(); err != nil {
+			return err
+		}
+	}
+
+	if alias := cmd.String("remove"); alias != "" {
+		return db.DiskDB.RemoveTagAlias(ctx, alias)
+	}
+
+	switch cmd.Args().Len() {
+	case 0:
+	case 2:
+		return db.DiskDB.SetTagAlias(ctx, cmd.Args().Get(0), cmd.Args().Get(1))
+	default:
+		return errors.New("expected an alias and its tag")
+	}
+
+	aliases, err := db.DiskDB.TagAliases(ctx)
+	if err != nil {
+		return err
+	}
+	for _, alias := range slices.Sorted(maps.Keys(aliases)) {
+		tag := aliases[alias]
+		if tag == "" {
+			tag = "(dropped)"
+		}
+		fmt.Printf("%s -> %s\n", alias, tag)
+	}
+	return nil
+}
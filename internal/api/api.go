@@ -21,6 +21,7 @@ package api
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
@@ -30,6 +31,7 @@ import (
 
 	"github.com/blob42/gosuki"
 	db "github.com/blob42/gosuki/internal/database"
+	"github.com/blob42/gosuki/internal/search"
 )
 
 type Bookmark = gosuki.Bookmark
@@ -80,7 +82,7 @@ func GetPaginationParams(r *http.Request) *db.PaginationParams {
 func GetAPIBookmarks(w http.ResponseWriter, r *http.Request) {
 	bookmarks, total, err := GetBookmarks(r)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		http.Error(w, err.Error(), ErrorStatus(err))
 		return
 	}
 	pageParams := GetPaginationParams(r)
@@ -96,10 +98,16 @@ func GetAPIBookmarks(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-func GetBookmarks(r *http.Request) ([]*gosuki.Bookmark, uint, error) {
-	var qResult *db.QueryResult
-	var err error
+// ErrorStatus returns the http status code of an error returned by [GetBookmarks]
+func ErrorStatus(err error) int {
+	var parseErr *search.ParseError
+	if errors.As(err, &parseErr) {
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
 
+func GetBookmarks(r *http.Request) ([]*gosuki.Bookmark, uint, error) {
 	r = trackFuzzySearch(r)
 
 	urlQuery := r.URL.Query()
@@ -118,56 +126,31 @@ func GetBookmarks(r *http.Request) ([]*gosuki.Bookmark, uint, error) {
 	}
 
 	pageParams := GetPaginationParams(r)
-	var (
-		searchByQuery = query != ""
-		searchByTag   = tag != ""
-	)
-
-	// Search with query AND tags
-	if searchByQuery && searchByTag {
-		if strings.Contains(tag, ",") {
-			// Query with multiple tags
-			qResult, err = db.QueryBookmarksByTags(
-				r.Context(),
-				query,
-				strings.Split(tag, ","),
-				db.TagAnd, // AND tags
-				IsFuzzy(r),
-				pageParams,
-			)
-		} else {
-			qResult, err = db.QueryBookmarksByTag(r.Context(),
-				query,
-				tag,
-				IsFuzzy(r),
-				pageParams,
-			)
-		}
+	fuzzy := IsFuzzy(r)
 
-		// Tag based search mode
-	} else if searchByTag {
-		//query with many tags
-		if strings.Contains(tag, ",") {
-			qResult, err = db.BookmarksByTags(
-				r.Context(),
-				strings.Split(tag, ","),
-				db.TagAnd,
-				pageParams,
-			)
-		} else {
-			qResult, err = db.BookmarksByTag(r.Context(), tag, pageParams)
-		}
-	} else if searchByQuery {
-		qResult, err = db.QueryBookmarks(r.Context(), query, IsFuzzy(r), pageParams)
+	q, err := search.NewQuery(query, search.Options{Fuzzy: fuzzy})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// tags of the url are AND-ed with the query
+	if tag != "" {
+		q.Where(db.MatchTags(db.TagAnd, false, strings.Split(tag, ",")...))
+	}
 
-		// No filter mode, list all bookmarks paginated
-	} else {
-		qResult, err = db.ListBookmarks(r.Context(), pageParams)
+	// best matches first unless another order was asked
+	if query != "" && !fuzzy && pageParams.SortBy == "" {
+		pageParams.SortBy = db.SortRelevance
 	}
 
+	qResult, err := q.Run(r.Context(), pageParams)
 	if err != nil {
 		return nil, 0, fmt.Errorf("database query failed: %w", err)
 	}
 
+	if err = db.DiskDB.LoadSources(r.Context(), qResult.Bookmarks); err != nil {
+		return nil, 0, fmt.Errorf("loading sources: %w", err)
+	}
+
 	return qResult.Bookmarks, qResult.Total, nil
 }
@@ -23,6 +23,7 @@ func TestGetPaginationParams_SortBasic(t *testing.T) {
 		{"title desc", "title:desc", "title", false},
 		{"url asc", "url:asc", "url", true},
 		{"url desc", "url:desc", "url", false},
+		{"frecency", "frecency", "frecency", false},
 	}
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
@@ -74,3 +75,11 @@ func TestGetPaginationParams_NoSortParam(t *testing.T) {
 	require.False(t, params.SortAsc)
 	require.Equal(t, 1, params.Page)
 }
+
+func TestGetAPIBookmarks_QuerySyntaxError(t *testing.T) {
+	r := httptest.NewRequest("GET", `/api/bookmarks?query=title:"go`, nil)
+	w := httptest.NewRecorder()
+	GetAPIBookmarks(w, r)
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	require.Contains(t, w.Body.String(), "unterminated quoted string at position 7")
+}
@@ -0,0 +1,149 @@
+//
+// Copyright (c) 2023-2025 Chakib Ben Ziane <contact@blob42.xyz> and [`GoSuki` contributors]
+// (https://github.com/blob42/gosuki/graphs/contributors).
+//
+// All rights reserved.
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+//
+// This file is part of GoSuki.
+//
+// GoSuki is free software: you can redistribute it and/or modify it under the terms of
+// the GNU Affero General Public License as published by the Free Software Foundation,
+// either version 3 of the License, or (at your option) any later version.
+//
+// GoSuki is distributed in the hope that it will be useful, but WITHOUT ANY WARRANTY;
+// without even the implied warranty of MERCHANTABILITY or FITNESS FOR A PARTICULAR
+// PURPOSE.  See the GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License along with
+// gosuki.  If not, see <http://www.gnu.org/licenses/>.
+
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+
+	db "github.com/blob42/gosuki/internal/database"
+)
+
+// Revision is an entry of the history of a bookmark
+type Revision struct {
+	ID         uint64   `json:"id"`
+	BookmarkID uint64   `json:"bookmark_id"`
+	URL        string   `json:"url"`
+	Action     string   `json:"action"`
+	OldTitle   string   `json:"old_metadata"`
+	NewTitle   string   `json:"new_metadata"`
+	OldTags    []string `json:"old_tags"`
+	NewTags    []string `json:"new_tags"`
+	OldDesc    string   `json:"old_desc"`
+	NewDesc    string   `json:"new_desc"`
+	Module     string   `json:"module"`
+	Version    uint64   `json:"version"`
+	ChangedAt  uint64   `json:"changed_at"`
+}
+
+func asRevision(rev *db.Revision) *Revision {
+	return &Revision{
+		ID:         rev.ID,
+		BookmarkID: rev.BookmarkID,
+		URL:        rev.URL,
+		Action:     rev.Action,
+		OldTitle:   rev.OldTitle,
+		NewTitle:   rev.NewTitle,
+		OldTags:    rev.OldTagList(),
+		NewTags:    rev.NewTagList(),
+		OldDesc:    rev.OldDesc,
+		NewDesc:    rev.NewDesc,
+		Module:     rev.Module,
+		Version:    rev.Version,
+		ChangedAt:  rev.ChangedAt,
+	}
+}
+
+// GetAPIBookmarkHistory lists the revisions of a bookmark, most recent first.
+// The history of deleted bookmarks is kept.
+func GetAPIBookmarkHistory(w http.ResponseWriter, r *http.Request) {
+	id, err := bookmarkID(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	revs, err := db.BookmarkHistory(r.Context(), id)
+	if err != nil {
+		writeDBError(w, err)
+		return
+	}
+
+	// bookmarks synced before the history was added have no revisions
+	if len(revs) == 0 {
+		if _, err = db.BookmarkByID(r.Context(), id); err != nil {
+			writeDBError(w, err)
+			return
+		}
+	}
+
+	result := make([]*Revision, 0, len(revs))
+	for _, rev := range revs {
+		result = append(result, asRevision(rev))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	payload := Payload{
+		Total:   uint(len(result)),
+		Page:    1,
+		PerPage: len(result),
+		Result:  result,
+	}
+	if err = json.NewEncoder(w).Encode(payload); err != nil {
+		log.Error("encoding payload", "err", err)
+	}
+}
+
+// PostAPIRestoreRevision restores the title, tags and description a bookmark
+// had after the given revision.
+func PostAPIRestoreRevision(w http.ResponseWriter, r *http.Request) {
+	id, err := bookmarkID(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	revID, err := strconv.ParseUint(chi.URLParam(r, "rev"), 10, 64)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("invalid revision id: %s", chi.URLParam(r, "rev")),
+			http.StatusBadRequest)
+		return
+	}
+
+	rev, err := db.RevisionByID(r.Context(), revID)
+	if err == nil && rev.BookmarkID != id {
+		err = db.ErrRevisionNotFound
+	}
+	if err != nil {
+		writeDBError(w, err)
+		return
+	}
+
+	if err = db.RestoreRevision(r.Context(), rev); err != nil {
+		writeDBError(w, err)
+		return
+	}
+	db.ScheduleBackupToDisk()
+
+	restored, err := cachedBookmark(r, rev.URL)
+	if err != nil {
+		writeDBError(w, err)
+		return
+	}
+	restored.ID = id
+
+	writePayload(w, http.StatusOK, restored)
+}
@@ -0,0 +1,135 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/require"
+
+	db "github.com/blob42/gosuki/internal/database"
+)
+
+func getHistory(t *testing.T, id string) (int, []*Revision) {
+	t.Helper()
+	router := chi.NewRouter()
+	router.Get("/bookmarks/{id}/history", GetAPIBookmarkHistory)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/bookmarks/"+id+"/history", nil))
+	if w.Code >= 400 {
+		return w.Code, nil
+	}
+
+	var result []*Revision
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&Payload{Result: &result}))
+	return w.Code, result
+}
+
+func restore(t *testing.T, id, rev string) int {
+	t.Helper()
+	router := chi.NewRouter()
+	router.Post("/bookmarks/{id}/history/{rev}/restore", PostAPIRestoreRevision)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost,
+		"/bookmarks/"+id+"/history/"+rev+"/restore", nil))
+	return w.Code
+}
+
+func TestBookmarkHistory(t *testing.T) {
+	setupCaches(t)
+	ctx := context.Background()
+	sync := func() { db.Cache.SyncTo(db.L2Cache.DB) }
+
+	w, _ := doRequest(t, http.MethodPost, "/bookmarks",
+		`{"url": "https://example.com", "metadata": "Example", "tags": ["foo"]}`)
+	require.Equal(t, http.StatusCreated, w.Code)
+	sync()
+
+	code, revs := getHistory(t, "1")
+	require.Equal(t, http.StatusOK, code)
+	require.Len(t, revs, 1)
+	require.Equal(t, db.HistoryInsert, revs[0].Action)
+	require.Equal(t, "Example", revs[0].NewTitle)
+	require.Equal(t, []string{"foo"}, revs[0].NewTags)
+	require.Equal(t, ModuleName, revs[0].Module)
+	require.NotZero(t, revs[0].Version)
+	require.NotZero(t, revs[0].ChangedAt)
+
+	t.Run("unchanged bookmarks are not recorded", func(t *testing.T) {
+		sync()
+		_, revs := getHistory(t, "1")
+		require.Len(t, revs, 1)
+	})
+
+	t.Run("updates", func(t *testing.T) {
+		w, _ := doRequest(t, http.MethodPatch, "/bookmarks/1", `{"metadata": "Example Domain"}`)
+		require.Equal(t, http.StatusOK, w.Code)
+		sync()
+
+		_, revs := getHistory(t, "1")
+		require.Len(t, revs, 2)
+		require.Equal(t, db.HistoryUpdate, revs[0].Action)
+		require.Equal(t, "Example", revs[0].OldTitle)
+		require.Equal(t, "Example Domain", revs[0].NewTitle)
+		require.Greater(t, revs[0].Version, revs[1].Version)
+	})
+
+	t.Run("replaced tags", func(t *testing.T) {
+		w, _ := doRequest(t, http.MethodPatch, "/bookmarks/1", `{"tags": ["bar"]}`)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		_, revs := getHistory(t, "1")
+		require.Len(t, revs, 3)
+		require.Equal(t, []string{"foo"}, revs[0].OldTags)
+		require.Equal(t, []string{"bar"}, revs[0].NewTags)
+		require.Greater(t, revs[0].Version, revs[1].Version)
+
+		sync()
+		_, revs = getHistory(t, "1")
+		require.Len(t, revs, 3)
+	})
+
+	t.Run("deletes", func(t *testing.T) {
+		w, _ := doRequest(t, http.MethodDelete, "/bookmarks/1", "")
+		require.Equal(t, http.StatusOK, w.Code)
+		sync()
+
+		// the history of deleted bookmarks is kept
+		_, revs := getHistory(t, "1")
+		require.Len(t, revs, 4)
+		require.Equal(t, db.HistoryDelete, revs[0].Action)
+	})
+
+	t.Run("restore", func(t *testing.T) {
+		_, revs := getHistory(t, "1")
+		inserted := revs[len(revs)-1]
+
+		require.Equal(t, http.StatusOK, restore(t, "1", "1"))
+		sync()
+
+		raw, err := db.L2Cache.BookmarkByURL(ctx, "https://example.com")
+		require.NoError(t, err)
+		require.False(t, raw.Deleted)
+		require.Equal(t, inserted.NewTitle, raw.Metadata)
+		require.Equal(t, ",foo,", raw.Tags)
+
+		_, revs = getHistory(t, "1")
+		require.Len(t, revs, 5)
+		require.Equal(t, db.HistoryRestore, revs[0].Action)
+		require.Equal(t, "Example Domain", revs[0].OldTitle)
+		require.NotZero(t, revs[0].Version)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		code, _ := getHistory(t, "42")
+		require.Equal(t, http.StatusNotFound, code)
+		require.Equal(t, http.StatusNotFound, restore(t, "1", "42"))
+		require.Equal(t, http.StatusNotFound, restore(t, "42", "1"))
+		require.Equal(t, http.StatusBadRequest, restore(t, "1", "abc"))
+	})
+}
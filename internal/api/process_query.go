@@ -25,19 +25,10 @@ package api
 import (
 	"context"
 	"net/http"
-	"strings"
-
-	db "github.com/blob42/gosuki/internal/database"
 )
 
 type ReqIsFuzzy struct{}
 
-type searchQueryParts struct {
-	TextQuery string
-	Tags      []string
-	TagCond   db.TagCond
-}
-
 func IsFuzzy(r *http.Request) bool {
 	fuzzy := r.Context().Value(ReqIsFuzzy{})
 
@@ -66,50 +57,3 @@ func trackFuzzySearch(r *http.Request) *http.Request {
 	rCtx := context.WithValue(r.Context(), ReqIsFuzzy{}, fuzzy)
 	return r.WithContext(rCtx)
 }
-
-// ParseSearchQuery parses a search query string into its components.
-// The query consists of an optional text search term followed by optional tag filters.
-// The text search term matches against URL and Title fields and comes before any filters.
-// Tag filters start with a colon (:) followed by either:
-// 1. A comma-separated list of tags (AND logic - all tags must be present)
-// 2. ":OR" followed by a comma-separated list of tag names (OR logic - any tag can match)
-//
-// Example queries:
-//   - "golang": text query "golang" with no tags
-//   - "golang :web,programming": text query "golang" with AND tags "web" and "programming"
-//   - "golang :OR web,programming": text query "golang" with OR tags "web" and "programming"
-func ParseSearchQuery(query string) searchQueryParts {
-	var result searchQueryParts
-	result.TagCond = db.TagAnd
-
-	if !strings.Contains(query, ":") {
-		result.TextQuery = strings.TrimSpace(query)
-		return result
-	}
-
-	if query == ":" {
-		return result
-	}
-
-	splitCount := strings.Count(query, ":")
-	parts := strings.SplitN(query, ":", splitCount+1)
-
-	textQuery := strings.Join(parts[:splitCount], ":")
-
-	result.TextQuery = strings.TrimSpace(textQuery)
-
-	tagPart := strings.TrimSpace(strings.Join(parts[splitCount:], ":"))
-
-	if strings.HasPrefix(tagPart, "OR ") {
-		result.TagCond = db.TagOr
-		result.Tags = strings.Split(strings.TrimPrefix(tagPart, "OR "), ",")
-	} else if tagPart != "" {
-		result.Tags = strings.Split(tagPart, ",")
-	}
-
-	for i, tag := range result.Tags {
-		result.Tags[i] = strings.TrimSpace(tag)
-	}
-
-	return result
-}
@@ -0,0 +1,71 @@
+//
+// Copyright (c) 2023-2025 Chakib Ben Ziane <contact@blob42.xyz> and [`GoSuki` contributors]
+// (https://github.com/blob42/gosuki/graphs/contributors).
+//
+// All rights reserved.
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+//
+// This file is part of GoSuki.
+//
+// GoSuki is free software: you can redistribute it and/or modify it under the terms of
+// the GNU Affero General Public License as published by the Free Software Foundation,
+// either version 3 of the License, or (at your option) any later version.
+//
+// GoSuki is distributed in the hope that it will be useful, but WITHOUT ANY WARRANTY;
+// without even the implied warranty of MERCHANTABILITY or FITNESS FOR A PARTICULAR
+// PURPOSE.  See the GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License along with
+// gosuki.  If not, see <http://www.gnu.org/licenses/>.
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	db "github.com/blob42/gosuki/internal/database"
+)
+
+// Tag is a tag with the number of bookmarks using it
+type Tag struct {
+	Name    string   `json:"name"`
+	Count   uint     `json:"count"`
+	Aliases []string `json:"aliases"`
+}
+
+// GetAPITags lists the tags of all bookmarks, most used first
+func GetAPITags(w http.ResponseWriter, r *http.Request) {
+	// the L2 cache holds the bookmarks not yet written to disk
+	src := db.DiskDB
+	if db.L2Cache.DB != nil {
+		src = db.L2Cache.DB
+	}
+
+	counts, err := src.TagCounts(r.Context())
+	if err != nil {
+		writeDBError(w, err)
+		return
+	}
+
+	result := make([]*Tag, 0, len(counts))
+	for _, tc := range counts {
+		tag := &Tag{Name: tc.Name, Count: tc.Count, Aliases: tc.Aliases}
+		if tag.Aliases == nil {
+			tag.Aliases = []string{}
+		}
+		result = append(result, tag)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	payload := Payload{
+		Total:   uint(len(result)),
+		Page:    1,
+		PerPage: len(result),
+		Result:  result,
+	}
+	if err = json.NewEncoder(w).Encode(payload); err != nil {
+		log.Error("encoding payload", "err", err)
+	}
+}
@@ -0,0 +1,45 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	db "github.com/blob42/gosuki/internal/database"
+)
+
+func TestGetAPITags(t *testing.T) {
+	setupCaches(t)
+
+	seedBookmark(t, &Bookmark{URL: "https://a.com", Tags: []string{"go", "web"}, Module: "chrome"})
+	seedBookmark(t, &Bookmark{URL: "https://b.com", Tags: []string{"go"}, Module: "chrome"})
+
+	ctx := context.Background()
+	require.NoError(t, db.L2Cache.SetTagAlias(ctx, "golang", "go"))
+	t.Cleanup(func() {
+		require.NoError(t, db.L2Cache.RemoveTagAlias(ctx, "golang"))
+	})
+
+	// aliases are applied to new bookmarks
+	w, _ := doRequest(t, http.MethodPost, "/bookmarks",
+		`{"url": "https://c.com", "tags": ["golang"]}`)
+	require.Equal(t, http.StatusCreated, w.Code)
+	bk, err := db.Cache.BookmarkByURL(ctx, "https://c.com")
+	require.NoError(t, err)
+	require.Equal(t, ",go,", bk.Tags)
+
+	w = httptest.NewRecorder()
+	GetAPITags(w, httptest.NewRequest(http.MethodGet, "/tags", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var tags []*Tag
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&Payload{Result: &tags}))
+	require.Equal(t, []*Tag{
+		{Name: "go", Count: 3, Aliases: []string{"golang"}},
+		{Name: "web", Count: 1, Aliases: []string{}},
+	}, tags)
+}
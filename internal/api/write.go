@@ -0,0 +1,376 @@
+//
+// Copyright (c) 2023-2025 Chakib Ben Ziane <contact@blob42.xyz> and [`GoSuki` contributors]
+// (https://github.com/blob42/gosuki/graphs/contributors).
+//
+// All rights reserved.
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+//
+// This file is part of GoSuki.
+//
+// GoSuki is free software: you can redistribute it and/or modify it under the terms of
+// the GNU Affero General Public License as published by the Free Software Foundation,
+// either version 3 of the License, or (at your option) any later version.
+//
+// GoSuki is distributed in the hope that it will be useful, but WITHOUT ANY WARRANTY;
+// without even the implied warranty of MERCHANTABILITY or FITNESS FOR A PARTICULAR
+// PURPOSE.  See the GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License along with
+// gosuki.  If not, see <http://www.gnu.org/licenses/>.
+
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+
+	db "github.com/blob42/gosuki/internal/database"
+	"github.com/blob42/gosuki/pkg/logging"
+)
+
+// Module name of bookmarks created through the API
+const ModuleName = "api"
+
+var log = logging.GetLogger("api")
+
+// Partial update of a bookmark. Nil fields are left untouched.
+type BookmarkPatch struct {
+	Title *string   `json:"metadata"`
+	Desc  *string   `json:"desc"`
+	Tags  *[]string `json:"tags"`
+}
+
+// Bulk tag operation applied on a list of bookmark ids
+type TagsOp struct {
+	IDs    []uint64 `json:"ids"`
+	Add    []string `json:"add"`
+	Remove []string `json:"remove"`
+}
+
+func writePayload(w http.ResponseWriter, status int, bookmarks ...*Bookmark) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	payload := Payload{
+		Total:   uint(len(bookmarks)),
+		Page:    1,
+		PerPage: len(bookmarks),
+		Result:  bookmarks,
+	}
+	if err := json.NewEncoder(w).Encode(payload); err != nil {
+		log.Error("encoding payload", "err", err)
+	}
+}
+
+func writeDBError(w http.ResponseWriter, err error) {
+	if errors.Is(err, db.ErrBookmarkNotFound) || errors.Is(err, db.ErrRevisionNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	http.Error(w, err.Error(), http.StatusInternalServerError)
+}
+
+func bookmarkID(r *http.Request) (uint64, error) {
+	id, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid bookmark id: %s", chi.URLParam(r, "id"))
+	}
+	return id, nil
+}
+
+// WriteGuard protects the write routes from requests sent by other web sites.
+// Bodies must be sent as json, which browsers do not allow cross origin
+// without a CORS preflight, the Origin header must match the host and the host
+// must be an address or localhost, a name could point to this server through
+// DNS rebinding.
+func WriteGuard(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !allowedHost(r.Host) {
+			http.Error(w, "invalid host", http.StatusForbidden)
+			return
+		}
+
+		if origin := r.Header.Get("Origin"); origin != "" {
+			u, err := url.Parse(origin)
+			if err != nil || u.Host != r.Host {
+				http.Error(w, "cross origin request", http.StatusForbidden)
+				return
+			}
+		}
+
+		// DELETE is never sent cross origin without a preflight
+		if r.Method != http.MethodDelete {
+			mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+			if err != nil || mediaType != "application/json" {
+				http.Error(w, "expected application/json", http.StatusUnsupportedMediaType)
+				return
+			}
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func allowedHost(host string) bool {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	host = strings.Trim(host, "[]")
+	return host == "localhost" || net.ParseIP(host) != nil
+}
+
+// flushCache syncs the L1 cache to the L2 cache, where the ids used by the
+// API are assigned, and schedules a backup to disk.
+func flushCache() {
+	db.Cache.SyncTo(db.L2Cache.DB)
+	db.ScheduleBackupToDisk()
+}
+
+// syncBookmarks writes bookmarks to the L1 cache through a temporary buffer,
+// the same path used by modules, then flushes the cache. Tags are merged with
+// existing ones.
+func syncBookmarks(bookmarks ...*Bookmark) error {
+	buffer, err := db.NewBuffer(ModuleName)
+	if err != nil {
+		return err
+	}
+	defer buffer.Close()
+
+	for _, bk := range bookmarks {
+		if err = buffer.UpsertBookmark(bk); err != nil {
+			return fmt.Errorf("upsert %s: %w", bk.URL, err)
+		}
+	}
+
+	if err = buffer.SyncToCache(); err != nil {
+		return err
+	}
+
+	flushCache()
+	return nil
+}
+
+// current state of a bookmark in the L2 cache, with its id
+func cachedBookmark(r *http.Request, url string) (*Bookmark, error) {
+	raw, err := db.L2Cache.BookmarkByURL(r.Context(), url)
+	if err != nil {
+		return nil, err
+	}
+	bk := raw.AsBookmark()
+	bk.ID = raw.ID
+	return bk, nil
+}
+
+// PostAPIBookmark creates a new bookmark or merges it with an existing one.
+func PostAPIBookmark(w http.ResponseWriter, r *http.Request) {
+	bk := &Bookmark{}
+	if err := json.NewDecoder(r.Body).Decode(bk); err != nil {
+		http.Error(w, fmt.Sprintf("invalid bookmark: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	bk.URL = strings.TrimSpace(bk.URL)
+	if bk.URL == "" {
+		http.Error(w, "missing bookmark url", http.StatusBadRequest)
+		return
+	}
+	bk.Module = ModuleName
+	bk.Sources = nil
+
+	if err := syncBookmarks(bk); err != nil {
+		writeDBError(w, err)
+		return
+	}
+
+	created, err := cachedBookmark(r, bk.URL)
+	if err != nil {
+		writeDBError(w, err)
+		return
+	}
+
+	w.Header().Set("Location", fmt.Sprintf("%s/%d", r.URL.Path, created.ID))
+	writePayload(w, http.StatusCreated, created)
+}
+
+// PatchAPIBookmark updates the title, description or tags of a bookmark.
+// Tags given in the patch replace the existing tags.
+func PatchAPIBookmark(w http.ResponseWriter, r *http.Request) {
+	id, err := bookmarkID(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	patch := BookmarkPatch{}
+	if err = json.NewDecoder(r.Body).Decode(&patch); err != nil {
+		http.Error(w, fmt.Sprintf("invalid patch: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	raw, err := db.BookmarkByID(r.Context(), id)
+	if err != nil {
+		writeDBError(w, err)
+		return
+	}
+
+	if patch.Title != nil || patch.Desc != nil {
+		bk := &Bookmark{
+			URL:    raw.URL,
+			Title:  raw.Metadata,
+			Desc:   raw.Desc,
+			Module: raw.Module,
+		}
+		if patch.Title != nil {
+			bk.Title = *patch.Title
+		}
+		if patch.Desc != nil {
+			bk.Desc = *patch.Desc
+		}
+
+		if err = syncBookmarks(bk); err != nil {
+			writeDBError(w, err)
+			return
+		}
+	}
+
+	if patch.Tags != nil {
+		if err = db.SetBookmarkTags(r.Context(), raw.URL, *patch.Tags); err != nil {
+			writeDBError(w, err)
+			return
+		}
+		flushCache()
+	}
+
+	updated, err := cachedBookmark(r, raw.URL)
+	if err != nil {
+		writeDBError(w, err)
+		return
+	}
+
+	writePayload(w, http.StatusOK, updated)
+}
+
+// DeleteAPIBookmark tombstones a bookmark.
+func DeleteAPIBookmark(w http.ResponseWriter, r *http.Request) {
+	id, err := bookmarkID(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	raw, err := db.BookmarkByID(r.Context(), id)
+	if err != nil {
+		writeDBError(w, err)
+		return
+	}
+
+	if err = db.Cache.MarkDeleted(raw.URL); err != nil {
+		writeDBError(w, err)
+		return
+	}
+	flushCache()
+
+	writePayload(w, http.StatusOK, raw.AsBookmark())
+}
+
+// PostAPIBookmarksTags adds and removes tags on many bookmarks at once.
+func PostAPIBookmarksTags(w http.ResponseWriter, r *http.Request) {
+	op := TagsOp{}
+	if err := json.NewDecoder(r.Body).Decode(&op); err != nil {
+		http.Error(w, fmt.Sprintf("invalid tags operation: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	if len(op.IDs) == 0 {
+		http.Error(w, "missing bookmark ids", http.StatusBadRequest)
+		return
+	}
+
+	if len(op.Add) == 0 && len(op.Remove) == 0 {
+		http.Error(w, "no tags to add or remove", http.StatusBadRequest)
+		return
+	}
+
+	urls := make([]string, 0, len(op.IDs))
+	for _, id := range op.IDs {
+		raw, err := db.BookmarkByID(r.Context(), id)
+		if err != nil {
+			writeDBError(w, fmt.Errorf("bookmark %d: %w", id, err))
+			return
+		}
+		urls = append(urls, raw.URL)
+	}
+
+	// Added tags are merged through the regular sync path
+	if len(op.Add) > 0 {
+		toSync := make([]*Bookmark, 0, len(urls))
+		for _, url := range urls {
+			bk, err := cachedBookmark(r, url)
+			if err != nil {
+				writeDBError(w, err)
+				return
+			}
+			bk.Tags = op.Add
+			toSync = append(toSync, bk)
+		}
+
+		if err := syncBookmarks(toSync...); err != nil {
+			writeDBError(w, err)
+			return
+		}
+	}
+
+	// Removing tags needs to replace the tag list
+	if len(op.Remove) > 0 {
+		for _, url := range urls {
+			bk, err := cachedBookmark(r, url)
+			if err != nil {
+				writeDBError(w, err)
+				return
+			}
+
+			tags := make([]string, 0, len(bk.Tags))
+			for _, tag := range bk.Tags {
+				if !containsTag(op.Remove, tag) {
+					tags = append(tags, tag)
+				}
+			}
+
+			if err = db.SetBookmarkTags(r.Context(), url, tags); err != nil {
+				writeDBError(w, err)
+				return
+			}
+		}
+		flushCache()
+	}
+
+	result := make([]*Bookmark, 0, len(urls))
+	for _, url := range urls {
+		bk, err := cachedBookmark(r, url)
+		if err != nil {
+			writeDBError(w, err)
+			return
+		}
+		result = append(result, bk)
+	}
+
+	writePayload(w, http.StatusOK, result...)
+}
+
+func containsTag(tags []string, tag string) bool {
+	for _, t := range tags {
+		if strings.EqualFold(strings.TrimSpace(t), tag) {
+			return true
+		}
+	}
+	return false
+}
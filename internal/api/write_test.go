@@ -0,0 +1,221 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/require"
+
+	db "github.com/blob42/gosuki/internal/database"
+)
+
+func TestMain(m *testing.M) {
+	db.Clock = &db.LamportClock{}
+	db.RegisterSqliteHooks()
+	os.Exit(m.Run())
+}
+
+func setupCaches(t *testing.T) {
+	t.Helper()
+	var err error
+
+	db.Cache.DB, err = db.NewDB(db.CacheName, "", db.DBTypeCacheDSN).Init()
+	require.NoError(t, err)
+	require.NoError(t, db.Cache.InitSchema(context.Background()))
+
+	db.L2Cache.DB, err = db.NewDB(db.L2CacheName, "", db.DBTypeCacheDSN).Init()
+	require.NoError(t, err)
+	require.NoError(t, db.L2Cache.InitSchema(context.Background()))
+
+	t.Cleanup(func() {
+		db.Cache.Close()
+		db.L2Cache.Close()
+		db.Cache.DB = nil
+		db.L2Cache.DB = nil
+	})
+}
+
+// seeds both cache levels as if the bookmark was already synced
+func seedBookmark(t *testing.T, bk *Bookmark) {
+	t.Helper()
+	require.NoError(t, db.Cache.UpsertBookmark(bk))
+	require.NoError(t, db.L2Cache.UpsertBookmark(bk))
+}
+
+func newWriteRouter() http.Handler {
+	router := chi.NewRouter()
+	router.Post("/bookmarks", PostAPIBookmark)
+	router.Post("/bookmarks/tags", PostAPIBookmarksTags)
+	router.Patch("/bookmarks/{id}", PatchAPIBookmark)
+	router.Delete("/bookmarks/{id}", DeleteAPIBookmark)
+	return router
+}
+
+func doRequest(t *testing.T, method, target, body string) (*httptest.ResponseRecorder, []*Bookmark) {
+	t.Helper()
+	r := httptest.NewRequest(method, target, strings.NewReader(body))
+	w := httptest.NewRecorder()
+	newWriteRouter().ServeHTTP(w, r)
+
+	if w.Code >= 400 {
+		return w, nil
+	}
+
+	var result []*Bookmark
+	payload := Payload{Result: &result}
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&payload))
+	require.Equal(t, uint(len(result)), payload.Total)
+	return w, result
+}
+
+func TestPostAPIBookmark(t *testing.T) {
+	setupCaches(t)
+
+	w, result := doRequest(t, http.MethodPost, "/bookmarks",
+		`{"url": "https://example.com", "metadata": "Example", "tags": ["foo"], "module": "other"}`)
+	require.Equal(t, http.StatusCreated, w.Code)
+	require.Len(t, result, 1)
+	require.Equal(t, "https://example.com", result[0].URL)
+	require.Equal(t, "Example", result[0].Title)
+	require.Equal(t, []string{"foo"}, result[0].Tags)
+	require.Equal(t, ModuleName, result[0].Module)
+
+	raw, err := db.Cache.BookmarkByURL(context.Background(), "https://example.com")
+	require.NoError(t, err)
+	require.Equal(t, ModuleName, raw.Module)
+
+	// the created bookmark can be changed right away
+	require.NotZero(t, result[0].ID)
+	location := w.Header().Get("Location")
+	require.Equal(t, fmt.Sprintf("/bookmarks/%d", result[0].ID), location)
+	w, result = doRequest(t, http.MethodPatch, location, `{"tags": ["bar"]}`)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, []string{"bar"}, result[0].Tags)
+
+	t.Run("invalid", func(t *testing.T) {
+		w, _ := doRequest(t, http.MethodPost, "/bookmarks", `{"metadata": "no url"}`)
+		require.Equal(t, http.StatusBadRequest, w.Code)
+
+		w, _ = doRequest(t, http.MethodPost, "/bookmarks", `not json`)
+		require.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestPatchAPIBookmarkTags(t *testing.T) {
+	setupCaches(t)
+	seedBookmark(t, &Bookmark{
+		URL:    "https://example.com",
+		Title:  "Example",
+		Tags:   []string{"foo", "bar"},
+		Module: "test",
+	})
+
+	w, result := doRequest(t, http.MethodPatch, "/bookmarks/1", `{"tags": ["baz"]}`)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Len(t, result, 1)
+	require.Equal(t, uint64(1), result[0].ID)
+	require.Equal(t, []string{"baz"}, result[0].Tags)
+
+	// the change goes through the cache sync
+	raw, err := db.L2Cache.BookmarkByURL(context.Background(), "https://example.com")
+	require.NoError(t, err)
+	require.Equal(t, ",baz,", raw.Tags)
+	require.NotZero(t, raw.Version)
+
+	revs, err := db.BookmarkHistory(context.Background(), 1)
+	require.NoError(t, err)
+	require.NotEmpty(t, revs)
+	require.Equal(t, ",bar,foo,", revs[0].OldTags)
+	require.Equal(t, ",baz,", revs[0].NewTags)
+
+	w, _ = doRequest(t, http.MethodPatch, "/bookmarks/42", `{"tags": ["baz"]}`)
+	require.Equal(t, http.StatusNotFound, w.Code)
+
+	w, _ = doRequest(t, http.MethodPatch, "/bookmarks/abc", `{"tags": ["baz"]}`)
+	require.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestDeleteAPIBookmark(t *testing.T) {
+	setupCaches(t)
+	seedBookmark(t, &Bookmark{
+		URL:    "https://example.com",
+		Title:  "Example",
+		Module: "test",
+	})
+
+	w, result := doRequest(t, http.MethodDelete, "/bookmarks/1", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Len(t, result, 1)
+	require.Equal(t, "https://example.com", result[0].URL)
+
+	raw, err := db.Cache.BookmarkByURL(context.Background(), "https://example.com")
+	require.NoError(t, err)
+	require.True(t, raw.Deleted)
+}
+
+func TestPostAPIBookmarksTagsRemove(t *testing.T) {
+	setupCaches(t)
+	seedBookmark(t, &Bookmark{URL: "https://a.com", Tags: []string{"foo", "bar"}, Module: "test"})
+	seedBookmark(t, &Bookmark{URL: "https://b.com", Tags: []string{"foo"}, Module: "test"})
+
+	w, result := doRequest(t, http.MethodPost, "/bookmarks/tags",
+		`{"ids": [1, 2], "remove": ["foo"]}`)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Len(t, result, 2)
+	require.Equal(t, []string{"bar"}, result[0].Tags)
+	require.Empty(t, result[1].Tags)
+
+	w, _ = doRequest(t, http.MethodPost, "/bookmarks/tags", `{"ids": [1]}`)
+	require.Equal(t, http.StatusBadRequest, w.Code)
+
+	w, _ = doRequest(t, http.MethodPost, "/bookmarks/tags", `{"ids": [3], "remove": ["foo"]}`)
+	require.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestWriteGuard(t *testing.T) {
+	handler := WriteGuard(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	tests := []struct {
+		name        string
+		method      string
+		host        string
+		origin      string
+		contentType string
+		code        int
+	}{
+		{"json", http.MethodPost, "127.0.0.1:2025", "", "application/json", http.StatusOK},
+		{"json charset", http.MethodPatch, "localhost:2025", "", "application/json; charset=utf-8", http.StatusOK},
+		{"same origin", http.MethodPost, "127.0.0.1:2025", "http://127.0.0.1:2025", "application/json", http.StatusOK},
+		{"delete", http.MethodDelete, "[::1]:2025", "", "", http.StatusOK},
+		{"form", http.MethodPost, "127.0.0.1:2025", "", "text/plain", http.StatusUnsupportedMediaType},
+		{"no content type", http.MethodPost, "127.0.0.1:2025", "", "", http.StatusUnsupportedMediaType},
+		{"cross origin", http.MethodPost, "127.0.0.1:2025", "http://evil.com", "application/json", http.StatusForbidden},
+		{"cross origin delete", http.MethodDelete, "127.0.0.1:2025", "http://evil.com", "", http.StatusForbidden},
+		{"host name", http.MethodPost, "evil.com:2025", "", "application/json", http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(tt.method, "/bookmarks", strings.NewReader(`{}`))
+			r.Host = tt.host
+			if tt.origin != "" {
+				r.Header.Set("Origin", tt.origin)
+			}
+			if tt.contentType != "" {
+				r.Header.Set("Content-Type", tt.contentType)
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+			require.Equal(t, tt.code, w.Code)
+		})
+	}
+}
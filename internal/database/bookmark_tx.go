@@ -22,10 +22,14 @@
 package database
 
 import (
+	"context"
+	"errors"
 	"html"
 
 	"github.com/blob42/gosuki"
 	"github.com/blob42/gosuki/internal/utils"
+	"github.com/gofrs/uuid"
+	"github.com/jmoiron/sqlx"
 	sqlite3 "github.com/mattn/go-sqlite3"
 )
 
@@ -46,7 +50,16 @@ func cleanup(f func() error) {
 // NOTE: We don't use sql UPSERT as we need to do a manual merge of some columns
 // such as `tags`.
 // NOTE: This function is always called against a buffer db
+//
+// The sources of bk are recorded in bookmark_sources, see [DB.RecordSources].
 func (db *DB) UpsertBookmark(bk *Bookmark) error {
+	if err := db.upsertBookmark(bk); err != nil {
+		return err
+	}
+	return db.RecordSources(bk.URL, bk.Sources...)
+}
+
+func (db *DB) upsertBookmark(bk *Bookmark) error {
 
 	var sqlite3Err sqlite3.Error
 	var isSqlite3Err bool
@@ -64,9 +77,11 @@ func (db *DB) UpsertBookmark(bk *Bookmark) error {
 				desc,
 				flags,
 				module,
-				xhsum
+				xhsum,
+				created,
+				url_key
 			)
-			VALUES (?, ?, ?, ?, ?, ?, ?)`,
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
 	)
 	if err != nil {
 		log.Errorf("%s: %s", err, bk.URL)
@@ -82,7 +97,10 @@ func (db *DB) UpsertBookmark(bk *Bookmark) error {
 			desc = CASE WHEN ? != '' THEN ? ELSE desc END,
 			tags=?,
 			modified=strftime('%s'),
-			xhsum=?
+			xhsum=?,
+			deleted=0,
+			deleted_at=0,
+			created = CASE WHEN ? > 0 AND ? < created THEN ? ELSE created END
 		WHERE url=?`,
 	)
 	defer cleanup(updateBk.Close)
@@ -116,6 +134,7 @@ func (db *DB) UpsertBookmark(bk *Bookmark) error {
 	// sanitize tags
 	// avoid using the delim in the query
 	// ex: [ "tag,1", "t,g2", "tag3" ] -> [ "tag--1", "t--g2", "tag3" ]
+	bk.Tags = applyTagAliases(bk.Tags)
 	tags := NewTags(bk.Tags, TagSep).PreSanitize().Sort()
 
 	tagListText := tags.String(true)
@@ -134,6 +153,11 @@ func (db *DB) UpsertBookmark(bk *Bookmark) error {
 
 		// empty xhash: it will be calculated in the cache
 		"",
+
+		// zero defaults to the modified time
+		bk.Created,
+
+		CanonicalURL(bk.URL),
 	)
 
 	if err != nil {
@@ -156,14 +180,24 @@ func (db *DB) UpsertBookmark(bk *Bookmark) error {
 
 		// Get existing xhashsum of bookmark
 		var targetXHSum string
-		err = tx.QueryRowx("SELECT xhsum FROM gskbookmarks WHERE url = ?", bk.URL).Scan(&targetXHSum)
+		var targetDeleted bool
+		var targetFlags int
+		err = tx.QueryRowx("SELECT xhsum, deleted, flags FROM gskbookmarks WHERE url = ?", bk.URL).
+			Scan(&targetXHSum, &targetDeleted, &targetFlags)
 		if err != nil {
 			log.Error("%s", err, "url", bk.URL)
 			return err
 		}
 
-		// We will only update the bookmark if the xhsum changed
-		if targetXHSum == xhsum(bk.URL, bk.Title, tagListText, bk.Desc) {
+		// merged duplicates stay tombstoned, see [DB.MergeDuplicates]
+		if targetFlags&FlagMerged != 0 {
+			log.Trace("upsert: merged duplicate skipping", "url", bk.URL)
+			return tx.Rollback()
+		}
+
+		// We will only update the bookmark if the xhsum changed or if it
+		// needs to be restored from a tombstone
+		if !targetDeleted && targetXHSum == xhsum(bk.URL, bk.Title, tagListText, bk.Desc) {
 			log.Trace("upsert: same hash skipping", "url", bk.URL)
 			return tx.Rollback()
 		}
@@ -207,6 +241,11 @@ func (db *DB) UpsertBookmark(bk *Bookmark) error {
 			// xhsum calculated in cache
 			"",
 
+			// keep the earliest known creation time
+			bk.Created,
+			bk.Created,
+			bk.Created,
+
 			// where clause
 			bk.URL,
 		)
@@ -219,3 +258,103 @@ func (db *DB) UpsertBookmark(bk *Bookmark) error {
 
 	return tx.Commit()
 }
+
+// RemoveBookmarks deletes the bookmarks matching the given urls without
+// leaving a tombstone. It is used on module buffers to forget bookmarks that
+// must not be deleted from the cache.
+func (db *DB) RemoveBookmarks(urls ...string) error {
+	if len(urls) == 0 {
+		return nil
+	}
+
+	unescaped := make([]string, 0, len(urls))
+	for _, url := range urls {
+		unescaped = append(unescaped, html.UnescapeString(url))
+	}
+
+	query, args, err := sqlx.In("DELETE FROM gskbookmarks WHERE URL IN (?)", unescaped)
+	if err != nil {
+		return err
+	}
+	if _, err = db.Handle.Exec(query, args...); err != nil {
+		return DBError{DBName: db.Name, Err: err}
+	}
+	return nil
+}
+
+// MarkDeleted tombstones the bookmarks matching the given urls. Tombstoned
+// bookmarks are kept in the database with their deletion time but are hidden
+// from queries. A later upsert of the same url restores the bookmark.
+func (db *DB) MarkDeleted(urls ...string) error {
+	if len(urls) == 0 {
+		return nil
+	}
+
+	tx, err := db.Handle.Beginx()
+	if err != nil {
+		return DBError{DBName: db.Name, Err: err}
+	}
+
+	stmt, err := tx.Preparex(
+		`UPDATE gskbookmarks
+		SET
+			deleted = 1,
+			deleted_at = strftime('%s'),
+			modified = strftime('%s')
+		WHERE URL = ? AND deleted = 0`,
+	)
+	if err != nil {
+		tx.Rollback()
+		return DBError{DBName: db.Name, Err: err}
+	}
+	defer cleanup(stmt.Close)
+
+	for _, url := range urls {
+		url = html.UnescapeString(url)
+		if _, err = stmt.Exec(url); err != nil {
+			tx.Rollback()
+			return DBError{DBName: db.Name, Err: err}
+		}
+		log.Trace("tombstoned", "url", url, "db", db.Name)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return DBError{DBName: db.Name, Err: err}
+	}
+
+	return nil
+}
+
+// SetBookmarkTags replaces the tags of the bookmark matching url. Unlike
+// [DB.UpsertBookmark], which merges tags, this allows removing tags from a
+// bookmark.
+//
+// The change goes through an authoritative buffer synced to the L1 cache like
+// the changes of modules. The next sync to the L2 cache bumps its version,
+// records it in the bookmark history and calls the update hooks.
+func SetBookmarkTags(ctx context.Context, url string, tags []string) error {
+	if Cache.DB == nil {
+		return errors.New("cache is not initialized")
+	}
+
+	raw, err := Cache.BookmarkByURL(ctx, url)
+	if err != nil {
+		return err
+	}
+
+	buffer, err := NewBuffer("tags")
+	if err != nil {
+		return err
+	}
+	defer buffer.Close()
+	buffer.Authoritative = true
+
+	raw.Tags = NewTags(applyTagAliases(tags), TagSep).PreSanitize().Sort().StringWrap()
+	// a change made by this node
+	raw.NodeID = UUID(uuid.Nil)
+	if err = buffer.InsertRawBookmarks(ctx, raw); err != nil {
+		return err
+	}
+
+	return buffer.SyncToCache()
+}
@@ -0,0 +1,140 @@
+package database
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/blob42/gosuki/test/fixtures"
+)
+
+func TestMarkDeleted(t *testing.T) {
+	db, cleanup := newTestDB(t)
+	defer cleanup()
+	defer seedDB(t, db, fixtures.DefaultSeedSet())()
+
+	ctx := context.Background()
+	err := db.MarkDeleted("https://alpha.com", "https://not-bookmarked.com")
+	require.NoError(t, err)
+
+	var deleted bool
+	var deletedAt uint64
+	err = db.Handle.QueryRowx(
+		"SELECT deleted, deleted_at FROM gskbookmarks WHERE URL = ?",
+		"https://alpha.com",
+	).Scan(&deleted, &deletedAt)
+	require.NoError(t, err)
+	require.True(t, deleted)
+	require.NotZero(t, deletedAt)
+
+	t.Run("tombstones are hidden from queries", func(t *testing.T) {
+		result, err := ListBookmarks(ctx, &PaginationParams{Page: 1, Size: -1})
+		require.NoError(t, err)
+		require.Equal(t, 4, len(result.Bookmarks))
+		require.Equal(t, uint(4), result.Total)
+		for _, bk := range result.Bookmarks {
+			require.NotEqual(t, "https://alpha.com", bk.URL)
+		}
+
+		result, err = NewBookmarkQuery(MatchText("Alpha", false)).Run(ctx, DefaultPagination())
+		require.NoError(t, err)
+		require.Empty(t, result.Bookmarks)
+		require.Zero(t, result.Total)
+
+		result, err = NewBookmarkQuery(MatchTag("a", false)).Run(ctx, DefaultPagination())
+		require.NoError(t, err)
+		require.Empty(t, result.Bookmarks)
+	})
+
+	t.Run("upsert restores a tombstoned bookmark", func(t *testing.T) {
+		err := db.UpsertBookmark(&Bookmark{
+			URL:    "https://alpha.com",
+			Title:  "Alpha",
+			Tags:   []string{"a"},
+			Module: "test",
+		})
+		require.NoError(t, err)
+
+		result, err := NewBookmarkQuery(MatchText("Alpha", false)).Run(ctx, DefaultPagination())
+		require.NoError(t, err)
+		require.Equal(t, 1, len(result.Bookmarks))
+	})
+}
+
+func TestSyncTombstones(t *testing.T) {
+	// updated bookmarks are sent to the hooks queue
+	if hooksQueue == nil {
+		startSchedulers()
+	}
+
+	buffer := getBuffer(t)
+	cache := getCache(t, "test_tombstone_cache")
+
+	bk := &Bookmark{
+		URL:    "https://example.com",
+		Title:  "Example Homepage",
+		Tags:   []string{"example", "homepage"},
+		Desc:   "The example domain homepage",
+		Module: "default",
+	}
+	require.NoError(t, buffer.UpsertBookmark(bk))
+	require.NoError(t, buffer.MarkDeleted(bk.URL))
+
+	isDeleted := func() bool {
+		var deleted bool
+		err := cache.Handle.Get(&deleted,
+			"SELECT deleted FROM gskbookmarks WHERE URL = ?", bk.URL)
+		require.NoError(t, err)
+		return deleted
+	}
+
+	// same content, only the tombstone differs
+	buffer.SyncTo(cache)
+	require.True(t, isDeleted())
+
+	// the bookmark shows up again in the browser
+	require.NoError(t, buffer.UpsertBookmark(bk))
+	buffer.SyncTo(cache)
+	require.False(t, isDeleted())
+}
+
+func TestUpsertKeepsEarliestCreated(t *testing.T) {
+	db, cleanup := newTestDB(t)
+	defer cleanup()
+	defer seedDB(t, db, fixtures.DefaultSeedSet())()
+
+	created := func(url string) uint64 {
+		t.Helper()
+		var c uint64
+		err := db.Handle.QueryRow("SELECT created FROM gskbookmarks WHERE URL = ?", url).Scan(&c)
+		require.NoError(t, err)
+		return c
+	}
+
+	bk := &Bookmark{URL: "https://beta.com", Title: "Beta", Tags: []string{"b"}, Module: "test"}
+
+	// unknown creation time
+	require.NoError(t, db.UpsertBookmark(bk))
+	require.Equal(t, uint64(2000), created(bk.URL))
+
+	// a later creation time from another source
+	bk.Created = 3000
+	require.NoError(t, db.UpsertBookmark(bk))
+	require.Equal(t, uint64(2000), created(bk.URL))
+
+	bk.Created = 500
+	require.NoError(t, db.UpsertBookmark(bk))
+	require.Equal(t, uint64(500), created(bk.URL))
+
+	t.Run("new bookmark without creation time", func(t *testing.T) {
+		bk := &Bookmark{URL: "https://zeta.com", Title: "Zeta", Module: "test"}
+		require.NoError(t, db.UpsertBookmark(bk))
+
+		var modified uint64
+		err := db.Handle.QueryRow("SELECT modified FROM gskbookmarks WHERE URL = ?", bk.URL).Scan(&modified)
+		require.NoError(t, err)
+		require.NotZero(t, modified)
+		require.Equal(t, modified, created(bk.URL))
+	})
+}
@@ -0,0 +1,220 @@
+//
+// Copyright (c) 2023-2025 Chakib Ben Ziane <contact@blob42.xyz> and [`GoSuki` contributors]
+// (https://github.com/blob42/gosuki/graphs/contributors).
+//
+// All rights reserved.
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+//
+// This file is part of GoSuki.
+//
+// GoSuki is free software: you can redistribute it and/or modify it under the terms of
+// the GNU Affero General Public License as published by the Free Software Foundation,
+// either version 3 of the License, or (at your option) any later version.
+//
+// GoSuki is distributed in the hope that it will be useful, but WITHOUT ANY WARRANTY;
+// without even the implied warranty of MERCHANTABILITY or FITNESS FOR A PARTICULAR
+// PURPOSE.  See the GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License along with
+// gosuki.  If not, see <http://www.gnu.org/licenses/>.
+
+package database
+
+import (
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Query parameters added by analytics and ad platforms, removed by default
+var DefaultTrackingParams = []string{
+	"utm_*",
+	"fbclid",
+	"gclid",
+	"dclid",
+	"gbraid",
+	"wbraid",
+	"msclkid",
+	"yclid",
+	"igshid",
+	"mc_cid",
+	"mc_eid",
+	"mkt_tok",
+	"_hsenc",
+	"_hsmi",
+	"ref_src",
+}
+
+// CanonicalRules select the differences ignored when comparing urls. Only
+// http and https urls are canonicalized.
+type CanonicalRules struct {
+	// http and https urls are the same bookmark
+	IgnoreScheme bool `toml:"ignore-scheme" mapstructure:"ignore-scheme"`
+
+	// Compare host names case insensitively
+	LowerHost bool `toml:"lower-host" mapstructure:"lower-host"`
+
+	// example.com and www.example.com are the same site
+	StripWWW bool `toml:"strip-www" mapstructure:"strip-www"`
+
+	// /a/ and /a are the same path
+	StripTrailingSlash bool `toml:"strip-trailing-slash" mapstructure:"strip-trailing-slash"`
+
+	// Ignore #fragments
+	StripFragment bool `toml:"strip-fragment" mapstructure:"strip-fragment"`
+
+	// Query parameters ignored, a trailing * matches any suffix
+	TrackingParams []string `toml:"tracking-params" mapstructure:"tracking-params"`
+}
+
+func DefaultCanonicalRules() CanonicalRules {
+	return CanonicalRules{
+		IgnoreScheme:       true,
+		LowerHost:          true,
+		StripWWW:           true,
+		StripTrailingSlash: true,
+		StripFragment:      true,
+		TrackingParams:     slices.Clone(DefaultTrackingParams),
+	}
+}
+
+// CanonicalURL returns the key identifying duplicates of rawURL with the
+// configured rules. It is stored in the url_key column.
+//
+//	http://www.Example.com/a/?utm_source=x#top -> example.com/a
+func CanonicalURL(rawURL string) string {
+	return Config.CanonicalURL.Key(rawURL)
+}
+
+// Key returns the canonical form of rawURL. Other urls are returned as is.
+// Query parameters are sorted since their order does not matter.
+func (r CanonicalRules) Key(rawURL string) string {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return rawURL
+	}
+
+	host := u.Host
+	if r.LowerHost {
+		host = strings.ToLower(host)
+	}
+	if port := u.Port(); (u.Scheme == "http" && port == "80") ||
+		(u.Scheme == "https" && port == "443") {
+		host = strings.TrimSuffix(host, ":"+port)
+	}
+	if r.StripWWW && len(host) > 4 && strings.EqualFold(host[:4], "www.") {
+		host = host[4:]
+	}
+
+	path := u.EscapedPath()
+	if r.StripTrailingSlash {
+		path = strings.TrimRight(path, "/")
+	} else if path == "" {
+		path = "/"
+	}
+
+	var key strings.Builder
+	if !r.IgnoreScheme {
+		key.WriteString(u.Scheme + "://")
+	}
+	if u.User != nil {
+		key.WriteString(u.User.String() + "@")
+	}
+	key.WriteString(host)
+	key.WriteString(path)
+
+	if query := r.cleanQuery(u.RawQuery); query != "" {
+		key.WriteString("?" + query)
+	}
+	if !r.StripFragment && u.Fragment != "" {
+		key.WriteString("#" + u.EscapedFragment())
+	}
+
+	return key.String()
+}
+
+// removes the tracking parameters and sorts the query
+func (r CanonicalRules) cleanQuery(rawQuery string) string {
+	if rawQuery == "" {
+		return ""
+	}
+
+	params := []string{}
+	for param := range strings.SplitSeq(rawQuery, "&") {
+		if param == "" {
+			continue
+		}
+		name, _, _ := strings.Cut(param, "=")
+		if unescaped, err := url.QueryUnescape(name); err == nil {
+			name = unescaped
+		}
+		if !r.isTracking(strings.ToLower(name)) {
+			params = append(params, param)
+		}
+	}
+
+	slices.Sort(params)
+	return strings.Join(params, "&")
+}
+
+func (r CanonicalRules) isTracking(name string) bool {
+	for _, pattern := range r.TrackingParams {
+		pattern = strings.ToLower(pattern)
+		if prefix, ok := strings.CutSuffix(pattern, "*"); ok {
+			if strings.HasPrefix(name, prefix) {
+				return true
+			}
+		} else if name == pattern {
+			return true
+		}
+	}
+	return false
+}
+
+// RefreshURLKeys recomputes the url_key of all bookmarks with the current
+// rules and returns the number of updated bookmarks.
+func (db *DB) RefreshURLKeys() (int, error) {
+	tx, err := db.Handle.Beginx()
+	if err != nil {
+		return 0, DBError{DBName: db.Name, Err: err}
+	}
+	defer tx.Rollback()
+
+	n, err := refreshURLKeys(tx)
+	if err != nil {
+		return 0, DBError{DBName: db.Name, Err: err}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return 0, DBError{DBName: db.Name, Err: err}
+	}
+	return n, nil
+}
+
+func refreshURLKeys(tx *sqlx.Tx) (int, error) {
+	var rows []struct {
+		ID     uint64
+		URL    string `db:"URL"`
+		URLKey string `db:"url_key"`
+	}
+	if err := tx.Select(&rows, "SELECT id, URL, url_key FROM gskbookmarks"); err != nil {
+		return 0, err
+	}
+
+	updated := 0
+	for _, row := range rows {
+		key := CanonicalURL(row.URL)
+		if key == row.URLKey {
+			continue
+		}
+		if _, err := tx.Exec("UPDATE gskbookmarks SET url_key = ? WHERE id = ?", key, row.ID); err != nil {
+			return updated, err
+		}
+		updated++
+	}
+
+	return updated, nil
+}
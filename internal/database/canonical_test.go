@@ -0,0 +1,50 @@
+package database
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCanonicalURL(t *testing.T) {
+	rules := DefaultCanonicalRules()
+
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{"http://x.com/a", "x.com/a"},
+		{"https://x.com/a/", "x.com/a"},
+		{"https://x.com/a?utm_source=feed&utm_medium=rss", "x.com/a"},
+		{"https://X.com/a#top", "x.com/a"},
+		{"https://www.x.com/a", "x.com/a"},
+		{"https://x.com:443/a", "x.com/a"},
+		{"https://x.com", "x.com"},
+		{"https://x.com/", "x.com"},
+		{"https://x.com/a?b=2&a=1&fbclid=xyz", "x.com/a?a=1&b=2"},
+		{"https://x.com/A", "x.com/A"},
+		{"https://x.com:8080/a", "x.com:8080/a"},
+		{"https://www/a", "www/a"},
+		{"ftp://X.com/a/", "ftp://X.com/a/"},
+		{"place:sort=8", "place:sort=8"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			require.Equal(t, tt.want, rules.Key(tt.url))
+		})
+	}
+
+	t.Run("rules disabled", func(t *testing.T) {
+		rules := CanonicalRules{}
+		require.Equal(t, "https://www.X.com/a/?utm_source=x#top",
+			rules.Key("https://www.X.com/a/?utm_source=x#top"))
+		require.Equal(t, "http://x.com/", rules.Key("http://x.com"))
+	})
+
+	t.Run("custom tracking parameters", func(t *testing.T) {
+		rules := CanonicalRules{TrackingParams: []string{"ref", "pk_*"}}
+		require.Equal(t, "https://x.com/a?id=1",
+			rules.Key("https://x.com/a?ref=hn&pk_campaign=x&id=1"))
+	})
+}
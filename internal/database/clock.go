@@ -55,6 +55,15 @@ func (c *LamportClock) LocalTick() uint64 {
 	return c.Value
 }
 
+// nextLocalVersion returns the version of a change made by this node outside
+// of a sync, zero when the clock is not initialized
+func nextLocalVersion() uint64 {
+	if Clock == nil {
+		return 0
+	}
+	return Clock.LocalTick()
+}
+
 // GetDBClock returns lamport clock for this node's db (version column)
 func (db *DB) GetDBClock(ctx context.Context) (*LamportClock, error) {
 	var clock uint64
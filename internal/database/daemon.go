@@ -0,0 +1,102 @@
+//
+// Copyright (c) 2023-2025 Chakib Ben Ziane <contact@blob42.xyz> and [`GoSuki` contributors]
+// (https://github.com/blob42/gosuki/graphs/contributors).
+//
+// All rights reserved.
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+//
+// This file is part of GoSuki.
+//
+// GoSuki is free software: you can redistribute it and/or modify it under the terms of
+// the GNU Affero General Public License as published by the Free Software Foundation,
+// either version 3 of the License, or (at your option) any later version.
+//
+// GoSuki is distributed in the hope that it will be useful, but WITHOUT ANY WARRANTY;
+// without even the implied warranty of MERCHANTABILITY or FITNESS FOR A PARTICULAR
+// PURPOSE.  See the GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License along with
+// gosuki.  If not, see <http://www.gnu.org/licenses/>.
+
+package database
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	psutil "github.com/shirou/gopsutil/v4/process"
+
+	"github.com/blob42/gosuki/pkg/config"
+)
+
+// suffix of the file next to the database storing the pid of the daemon
+const pidSuffix = ".pid"
+
+// ErrDaemonRunning is returned by commands writing the database file while
+// the daemon runs. The daemon keeps the bookmarks in its caches and would
+// overwrite the changes on its next backup to disk.
+var ErrDaemonRunning = errors.New("the gosuki daemon is running")
+
+func pidPath() string {
+	return config.DBPath + pidSuffix
+}
+
+// DaemonPid returns the pid of the running daemon using the database, or 0
+// if no daemon is running. Pid files left by a daemon that was killed are
+// ignored.
+func DaemonPid() (int, error) {
+	data, err := os.ReadFile(pidPath())
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	} else if err != nil {
+		return 0, err
+	}
+
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil {
+		return 0, fmt.Errorf("invalid pid file %s: %w", pidPath(), err)
+	}
+
+	alive, err := psutil.PidExists(int32(pid))
+	if err != nil || !alive {
+		return 0, err
+	}
+	return pid, nil
+}
+
+// ClaimDaemon records the pid of the current process as the daemon using the
+// database. The returned func removes the pid file.
+func ClaimDaemon() (func(), error) {
+	if pid, err := DaemonPid(); err == nil && pid != 0 {
+		log.Warn("another daemon seems to use the database", "pid", pid, "pidfile", pidPath())
+	}
+
+	err := os.WriteFile(pidPath(), []byte(strconv.Itoa(os.Getpid())+"\n"), 0o644)
+	if err != nil {
+		return nil, err
+	}
+
+	return func() {
+		if err := os.Remove(pidPath()); err != nil && !errors.Is(err, os.ErrNotExist) {
+			log.Error("removing pid file", "err", err)
+		}
+	}, nil
+}
+
+// CheckDaemonStopped returns [ErrDaemonRunning] if a daemon is using the
+// database.
+func CheckDaemonStopped() error {
+	pid, err := DaemonPid()
+	if err != nil {
+		return err
+	}
+	if pid != 0 {
+		return fmt.Errorf("%w with pid %d, stop it first or remove %s if it is not gosuki",
+			ErrDaemonRunning, pid, pidPath())
+	}
+	return nil
+}
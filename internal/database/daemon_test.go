@@ -0,0 +1,35 @@
+package database
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/blob42/gosuki/pkg/config"
+)
+
+func TestDaemonPid(t *testing.T) {
+	orig := config.DBPath
+	config.DBPath = filepath.Join(t.TempDir(), "gosuki.db")
+	defer func() { config.DBPath = orig }()
+
+	require.NoError(t, CheckDaemonStopped())
+
+	release, err := ClaimDaemon()
+	require.NoError(t, err)
+
+	pid, err := DaemonPid()
+	require.NoError(t, err)
+	require.Equal(t, os.Getpid(), pid)
+	require.ErrorIs(t, CheckDaemonStopped(), ErrDaemonRunning)
+
+	release()
+	require.NoError(t, CheckDaemonStopped())
+
+	t.Run("pid file of a dead daemon", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(pidPath(), []byte("2147483647\n"), 0o644))
+		require.NoError(t, CheckDaemonStopped())
+	})
+}
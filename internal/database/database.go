@@ -111,6 +111,8 @@ func (e DBError) Error() string {
 
 var (
 	ErrVfsLocked = errors.New("vfs locked")
+
+	ErrBookmarkNotFound = errors.New("bookmark not found")
 )
 
 type Opener interface {
@@ -151,6 +153,11 @@ type DB struct {
 	Type       DBType
 	mu         *sync.RWMutex
 
+	// The title, tags and description of the bookmarks synced from this db
+	// replace the ones of the destination instead of being merged with them.
+	// The L1 cache always replaces the L2 cache, it holds all the changes.
+	Authoritative bool
+
 	filePath string
 
 	SQLXOpener
@@ -375,6 +382,16 @@ func SQLxxHash(in string) string {
 	return fmt.Sprintf("%d", xxhash.ChecksumString64(in))
 }
 
+// SQLURLSite reports whether the host of rawURL is domain or a subdomain of it
+func SQLURLSite(rawURL, domain string) bool {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+	host := strings.ToLower(u.Hostname())
+	return host == domain || strings.HasSuffix(host, "."+domain)
+}
+
 // Calculates xxhash sum for a bookmark
 func xhsum(url, metadata, tags, desc string) string {
 	input := fmt.Sprintf(
@@ -406,6 +423,10 @@ func RegisterSqliteHooks() {
 					return err
 				}
 
+				if err := conn.RegisterFunc("url_site", SQLURLSite, true); err != nil {
+					return err
+				}
+
 				// register function that will update internal clock
 				if err := conn.RegisterFunc("tick_clock", sqlTickClock, true); err != nil {
 					return err
@@ -436,6 +457,9 @@ func RegisterSqliteHooks() {
 type dbConfig struct {
 	SyncInterval time.Duration `toml:"sync-interval" mapstructure:"sync-interval"`
 	Path         string        `toml:"path" mapstructure:"path"`
+
+	// Rules used to detect duplicate bookmarks
+	CanonicalURL CanonicalRules `toml:"canonical-url" mapstructure:"canonical-url"`
 }
 
 func init() {
@@ -451,6 +475,7 @@ func init() {
 	Config = &dbConfig{
 		SyncInterval: time.Second * 4,
 		Path:         dbPath,
+		CanonicalURL: DefaultCanonicalRules(),
 	}
 
 	config.RegisterConfigurator("database", config.AsConfigurator(Config))
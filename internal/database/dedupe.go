@@ -0,0 +1,215 @@
+//
+// Copyright (c) 2023-2025 Chakib Ben Ziane <contact@blob42.xyz> and [`GoSuki` contributors]
+// (https://github.com/blob42/gosuki/graphs/contributors).
+//
+// All rights reserved.
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+//
+// This file is part of GoSuki.
+//
+// GoSuki is free software: you can redistribute it and/or modify it under the terms of
+// the GNU Affero General Public License as published by the Free Software Foundation,
+// either version 3 of the License, or (at your option) any later version.
+//
+// GoSuki is distributed in the hope that it will be useful, but WITHOUT ANY WARRANTY;
+// without even the implied warranty of MERCHANTABILITY or FITNESS FOR A PARTICULAR
+// PURPOSE.  See the GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License along with
+// gosuki.  If not, see <http://www.gnu.org/licenses/>.
+
+package database
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"github.com/gofrs/uuid"
+)
+
+// FlagMerged marks a duplicate merged into another bookmark. Merged bookmarks
+// stay tombstoned when their source sends them again.
+const FlagMerged = 0b00000010
+
+// module recorded in the history of merged bookmarks
+const dedupeModule = "dedupe"
+
+// DuplicateGroup is a set of bookmarks sharing the same canonical url
+type DuplicateGroup struct {
+	Key string
+
+	// The first bookmark is the one kept by [DB.MergeDuplicates]
+	Bookmarks []*RawBookmark
+}
+
+// DuplicateGroups returns the groups of bookmarks with the same url_key.
+// Call [DB.RefreshURLKeys] first when the canonicalization rules changed.
+func (db *DB) DuplicateGroups(ctx context.Context) ([]*DuplicateGroup, error) {
+	raws := []*RawBookmark{}
+	err := db.Handle.SelectContext(ctx, &raws, `
+		SELECT * FROM gskbookmarks
+		WHERE `+WhereNotDeleted+` AND url_key IN (
+			SELECT url_key FROM gskbookmarks
+			WHERE `+WhereNotDeleted+` AND url_key != ''
+			GROUP BY url_key HAVING COUNT(*) > 1
+		)
+		ORDER BY url_key, id`,
+	)
+	if err != nil {
+		return nil, DBError{DBName: db.Name, Err: err}
+	}
+
+	groups := []*DuplicateGroup{}
+	for _, raw := range raws {
+		if len(groups) == 0 || groups[len(groups)-1].Key != raw.URLKey {
+			groups = append(groups, &DuplicateGroup{Key: raw.URLKey})
+		}
+		group := groups[len(groups)-1]
+		group.Bookmarks = append(group.Bookmarks, raw)
+	}
+
+	for _, group := range groups {
+		slices.SortStableFunc(group.Bookmarks, compareKeepers)
+	}
+
+	return groups, nil
+}
+
+// the kept bookmark is preferably https, has the shortest url (the least
+// tracking parameters) and is the oldest one
+func compareKeepers(a, b *RawBookmark) int {
+	aHTTPS, bHTTPS := strings.HasPrefix(a.URL, "https:"), strings.HasPrefix(b.URL, "https:")
+	switch {
+	case aHTTPS && !bHTTPS:
+		return -1
+	case bHTTPS && !aHTTPS:
+		return 1
+	case len(a.URL) != len(b.URL):
+		return len(a.URL) - len(b.URL)
+	case a.Created != b.Created:
+		if a.Created < b.Created {
+			return -1
+		}
+		return 1
+	}
+	return int(a.ID) - int(b.ID)
+}
+
+// Merged returns the kept bookmark as it is after merging: tags are the union
+// of all tags, the longest title and description are kept and the creation
+// time is the earliest one.
+func (g *DuplicateGroup) Merged() *RawBookmark {
+	merged := *g.Bookmarks[0]
+
+	tagSet := map[string]bool{}
+	tags := &Tags{delim: TagSep}
+	for _, raw := range g.Bookmarks {
+		for _, tag := range tagsFromString(raw.Tags, TagSep).Get() {
+			if !tagSet[tag] {
+				tagSet[tag] = true
+				tags.Add(tag)
+			}
+		}
+
+		if len(raw.Metadata) > len(merged.Metadata) {
+			merged.Metadata = raw.Metadata
+		}
+		if len(raw.Desc) > len(merged.Desc) {
+			merged.Desc = raw.Desc
+		}
+		if raw.Created > 0 && (merged.Created == 0 || raw.Created < merged.Created) {
+			merged.Created = raw.Created
+		}
+	}
+	merged.Tags = tags.Sort().StringWrap()
+
+	return &merged
+}
+
+// MergeDuplicates merges the group into its first bookmark, see
+// [DuplicateGroup.Merged]. The other bookmarks are tombstoned and flagged with
+// [FlagMerged]. Changes are recorded in the bookmark history.
+func (db *DB) MergeDuplicates(ctx context.Context, group *DuplicateGroup) error {
+	if len(group.Bookmarks) < 2 {
+		return nil
+	}
+
+	version := nextLocalVersion()
+
+	keep, merged := group.Bookmarks[0], group.Merged()
+
+	tx, err := db.Handle.BeginTxx(ctx, nil)
+	if err != nil {
+		return DBError{DBName: db.Name, Err: err}
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE gskbookmarks
+		SET metadata = ?, tags = ?, desc = ?, created = ?, xhsum = ?,
+			modified = strftime('%s'), version = ?, node_id = ?
+		WHERE id = ?`,
+		merged.Metadata, merged.Tags, merged.Desc, merged.Created,
+		xhsum(merged.URL, merged.Metadata, merged.Tags, merged.Desc),
+		version, UUID(uuid.Nil), keep.ID,
+	)
+	if err != nil {
+		return DBError{DBName: db.Name, Err: err}
+	}
+
+	err = recordRevision(tx, &Revision{
+		BookmarkID: keep.ID,
+		URL:        keep.URL,
+		Action:     HistoryUpdate,
+		OldTitle:   keep.Metadata,
+		NewTitle:   merged.Metadata,
+		OldTags:    keep.Tags,
+		NewTags:    merged.Tags,
+		OldDesc:    keep.Desc,
+		NewDesc:    merged.Desc,
+		Module:     dedupeModule,
+		Version:    version,
+	})
+	if err != nil {
+		return DBError{DBName: db.Name, Err: err}
+	}
+
+	for _, dup := range group.Bookmarks[1:] {
+		_, err = tx.ExecContext(ctx, `
+			UPDATE gskbookmarks
+			SET deleted = 1, deleted_at = strftime('%s'), modified = strftime('%s'),
+				flags = flags | ?, version = ?, node_id = ?
+			WHERE id = ?`,
+			FlagMerged, version, UUID(uuid.Nil), dup.ID,
+		)
+		if err != nil {
+			return DBError{DBName: db.Name, Err: err}
+		}
+
+		err = recordRevision(tx, &Revision{
+			BookmarkID: dup.ID,
+			URL:        dup.URL,
+			Action:     HistoryDelete,
+			OldTitle:   dup.Metadata,
+			NewTitle:   dup.Metadata,
+			OldTags:    dup.Tags,
+			NewTags:    dup.Tags,
+			OldDesc:    dup.Desc,
+			NewDesc:    dup.Desc,
+			Module:     dedupeModule,
+			Version:    version,
+		})
+		if err != nil {
+			return DBError{DBName: db.Name, Err: err}
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return DBError{DBName: db.Name, Err: err}
+	}
+
+	log.Debug("merged duplicates", "key", group.Key, "kept", keep.URL, "count", len(group.Bookmarks))
+	return nil
+}
@@ -0,0 +1,105 @@
+package database
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMergeDuplicates(t *testing.T) {
+	db, cleanup := newTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	for _, bk := range []*Bookmark{
+		{URL: "http://x.com/a", Title: "A", Tags: []string{"one"}, Created: 300, Module: "chrome"},
+		{URL: "https://x.com/a/", Title: "A page", Tags: []string{"two"}, Created: 200, Module: "firefox"},
+		{URL: "https://x.com/a?utm_source=feed", Title: "A", Desc: "the a page", Created: 100, Module: "qute"},
+		{URL: "https://X.com/a#top", Tags: []string{"one", "three"}, Module: "chrome"},
+		{URL: "https://x.com/b", Title: "B", Module: "chrome"},
+	} {
+		require.NoError(t, db.UpsertBookmark(bk))
+	}
+
+	groups, err := db.DuplicateGroups(ctx)
+	require.NoError(t, err)
+	require.Len(t, groups, 1)
+
+	group := groups[0]
+	require.Equal(t, "x.com/a", group.Key)
+	require.Len(t, group.Bookmarks, 4)
+	require.Equal(t, "https://x.com/a/", group.Bookmarks[0].URL)
+
+	merged := group.Merged()
+	require.Equal(t, "https://x.com/a/", merged.URL)
+	require.Equal(t, "A page", merged.Metadata)
+	require.Equal(t, "the a page", merged.Desc)
+	require.Equal(t, ",one,three,two,", merged.Tags)
+	require.Equal(t, uint64(100), merged.Created)
+
+	require.NoError(t, db.MergeDuplicates(ctx, group))
+
+	groups, err = db.DuplicateGroups(ctx)
+	require.NoError(t, err)
+	require.Empty(t, groups)
+
+	kept, err := db.BookmarkByURL(ctx, "https://x.com/a/")
+	require.NoError(t, err)
+	require.False(t, kept.Deleted)
+	require.Equal(t, merged.Tags, kept.Tags)
+	require.Equal(t, "the a page", kept.Desc)
+
+	dup, err := db.BookmarkByURL(ctx, "http://x.com/a")
+	require.NoError(t, err)
+	require.True(t, dup.Deleted)
+	require.NotZero(t, dup.Flags&FlagMerged)
+
+	t.Run("merged duplicates are not restored", func(t *testing.T) {
+		require.NoError(t, db.UpsertBookmark(&Bookmark{
+			URL:    "http://x.com/a",
+			Title:  "A",
+			Tags:   []string{"one", "new"},
+			Module: "chrome",
+		}))
+
+		dup, err := db.BookmarkByURL(ctx, "http://x.com/a")
+		require.NoError(t, err)
+		require.True(t, dup.Deleted)
+	})
+
+	t.Run("changes are recorded in the history", func(t *testing.T) {
+		var actions []string
+		err := db.Handle.Select(&actions,
+			"SELECT action FROM gskbookmarks_history WHERE module = ? ORDER BY id", dedupeModule)
+		require.NoError(t, err)
+		require.Equal(t, []string{HistoryUpdate, HistoryDelete, HistoryDelete, HistoryDelete}, actions)
+	})
+}
+
+func TestSyncSkipsMergedDuplicates(t *testing.T) {
+	if hooksQueue == nil {
+		startSchedulers()
+	}
+
+	buffer := getBuffer(t)
+	cache := getCache(t, "test_merged_cache")
+
+	bk := &Bookmark{URL: "https://www.example.com/", Title: "Example", Module: "default"}
+	require.NoError(t, buffer.UpsertBookmark(bk))
+	buffer.SyncTo(cache)
+
+	_, err := cache.Handle.Exec(
+		"UPDATE gskbookmarks SET deleted = 1, flags = flags | ? WHERE URL = ?", FlagMerged, bk.URL)
+	require.NoError(t, err)
+
+	// the browser still has the bookmark
+	bk.Tags = []string{"changed"}
+	require.NoError(t, buffer.UpsertBookmark(bk))
+	buffer.SyncTo(cache)
+
+	raw, err := cache.BookmarkByURL(context.Background(), bk.URL)
+	require.NoError(t, err)
+	require.True(t, raw.Deleted)
+	require.Equal(t, "example.com", raw.URLKey)
+}
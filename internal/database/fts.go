@@ -0,0 +1,222 @@
+//
+//  Copyright (c) 2025 Chakib Ben Ziane <contact@blob42.xyz>  and [`gosuki` contributors](https://github.com/blob42/gosuki/graphs/contributors).
+//  All rights reserved.
+//
+//  SPDX-License-Identifier: AGPL-3.0-or-later
+//
+//  This file is part of GoSuki.
+//
+//  GoSuki is free software: you can redistribute it and/or modify
+//  it under the terms of the GNU Affero General Public License as
+//  published by the Free Software Foundation, either version 3 of the
+//  License, or (at your option) any later version.
+//
+//  GoSuki is distributed in the hope that it will be useful,
+//  but WITHOUT ANY WARRANTY; without even the implied warranty of
+//  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU Affero General Public License for more details.
+//
+//  You should have received a copy of the GNU Affero General Public License
+//  along with gosuki.  If not, see <http://www.gnu.org/licenses/>.
+//
+
+package database
+
+import (
+	"context"
+	"strings"
+	"sync/atomic"
+	"unicode"
+)
+
+// Full text search over gskbookmarks using the SQLite FTS5 extension.
+//
+// FTS5 is only available when gosuki is built with the `sqlite_fts5` tag. The
+// index is an external content table kept in sync with gskbookmarks by
+// triggers. Without FTS5, full text predicates fall back to LIKE matching.
+
+const (
+	FTSTable = "gskbookmarks_fts"
+
+	QCreateFTS = `
+	CREATE VIRTUAL TABLE IF NOT EXISTS gskbookmarks_fts USING fts5(
+		URL,
+		metadata,
+		desc,
+		tags,
+		content='gskbookmarks',
+		content_rowid='id',
+		tokenize='unicode61 remove_diacritics 2'
+	);
+	` + qCreateFTSTriggers
+
+	qCreateFTSTriggers = `
+	CREATE TRIGGER IF NOT EXISTS gskbookmarks_fts_insert
+	AFTER INSERT ON gskbookmarks
+	BEGIN
+		INSERT INTO gskbookmarks_fts(rowid, URL, metadata, desc, tags)
+		VALUES (new.id, new.URL, new.metadata, new.desc, new.tags);
+	END;
+
+	CREATE TRIGGER IF NOT EXISTS gskbookmarks_fts_delete
+	AFTER DELETE ON gskbookmarks
+	BEGIN
+		INSERT INTO gskbookmarks_fts(gskbookmarks_fts, rowid, URL, metadata, desc, tags)
+		VALUES ('delete', old.id, old.URL, old.metadata, old.desc, old.tags);
+	END;
+
+	CREATE TRIGGER IF NOT EXISTS gskbookmarks_fts_update
+	AFTER UPDATE OF URL, metadata, desc, tags ON gskbookmarks
+	BEGIN
+		INSERT INTO gskbookmarks_fts(gskbookmarks_fts, rowid, URL, metadata, desc, tags)
+		VALUES ('delete', old.id, old.URL, old.metadata, old.desc, old.tags);
+		INSERT INTO gskbookmarks_fts(rowid, URL, metadata, desc, tags)
+		VALUES (new.id, new.URL, new.metadata, new.desc, new.tags);
+	END;
+	`
+
+	// writes fail in builds without FTS5 while the triggers exist
+	qDropFTSTriggers = `
+	DROP TRIGGER IF EXISTS gskbookmarks_fts_insert;
+	DROP TRIGGER IF EXISTS gskbookmarks_fts_delete;
+	DROP TRIGGER IF EXISTS gskbookmarks_fts_update;
+	`
+
+	QRebuildFTS = `INSERT INTO gskbookmarks_fts(gskbookmarks_fts) VALUES ('rebuild')`
+
+	// bm25 weights of the URL, title, desc and tags columns
+	qFTSRank = `bm25(gskbookmarks_fts, 2.0, 10.0, 1.0, 5.0)`
+)
+
+// set once a database with a full text index is opened
+var fullTextEnabled atomic.Bool
+
+// FullTextEnabled reports whether full text search is available
+func FullTextEnabled() bool {
+	return fullTextEnabled.Load()
+}
+
+// hasFTS5 reports whether the sqlite library was compiled with FTS5
+func (db *DB) hasFTS5(ctx context.Context) bool {
+	var enabled bool
+	err := db.Handle.QueryRowContext(ctx,
+		"SELECT sqlite_compileoption_used('ENABLE_FTS5')").Scan(&enabled)
+	if err != nil {
+		log.Debug("checking fts5 support", "db", db.Name, "err", err)
+	}
+	return enabled
+}
+
+// hasFTSTable reports whether the full text index exists in db
+func (db *DB) hasFTSTable(ctx context.Context) bool {
+	var exists bool
+	err := db.Handle.QueryRowContext(ctx,
+		"SELECT 1 FROM sqlite_master WHERE type='table' AND name=?",
+		FTSTable,
+	).Scan(&exists)
+	return err == nil && exists
+}
+
+// hasFTSTriggers reports whether the triggers keeping the full text index in
+// sync exist in db
+func (db *DB) hasFTSTriggers(ctx context.Context) bool {
+	var count int
+	err := db.Handle.QueryRowContext(ctx,
+		"SELECT count(*) FROM sqlite_master WHERE type='trigger' AND name GLOB ?",
+		FTSTable+"_*",
+	).Scan(&count)
+	return err == nil && count == 3
+}
+
+// dropFTSTriggers removes the triggers of the full text index. The index is
+// left as is and rebuilt once FTS5 is available again.
+func (db *DB) dropFTSTriggers(ctx context.Context) error {
+	if _, err := db.Handle.ExecContext(ctx, qDropFTSTriggers); err != nil {
+		return DBError{DBName: db.Name, Err: err}
+	}
+	return nil
+}
+
+// initFTS creates the full text index and its triggers if FTS5 is available,
+// otherwise the triggers left by a build with FTS5 are dropped. The index is
+// rebuilt from gskbookmarks when created, when its triggers were dropped or
+// when `rebuild` is set.
+func (db *DB) initFTS(ctx context.Context, rebuild bool) error {
+	if !db.hasFTS5(ctx) {
+		log.Debug("fts5 not available, full text search disabled", "db", db.Name)
+		return db.dropFTSTriggers(ctx)
+	}
+
+	exists := db.hasFTSTable(ctx) && db.hasFTSTriggers(ctx)
+
+	tx, err := db.Handle.BeginTx(ctx, nil)
+	if err != nil {
+		return DBError{DBName: db.Name, Err: err}
+	}
+
+	if _, err = tx.ExecContext(ctx, QCreateFTS); err != nil {
+		tx.Rollback()
+		return DBError{DBName: db.Name, Err: err}
+	}
+
+	if !exists || rebuild {
+		log.Debug("building full text index", "db", db.Name)
+		if _, err = tx.ExecContext(ctx, QRebuildFTS); err != nil {
+			tx.Rollback()
+			return DBError{DBName: db.Name, Err: err}
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return DBError{DBName: db.Name, Err: err}
+	}
+
+	fullTextEnabled.Store(true)
+	return nil
+}
+
+func ftsWords(text string) []string {
+	return strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+}
+
+// restricts an FTS5 query to the given columns
+func ftsColumns(query string, fields []Field) string {
+	if query == "" || len(fields) == 0 {
+		return query
+	}
+
+	cols := make([]string, 0, len(fields))
+	for _, f := range fields {
+		cols = append(cols, string(f))
+	}
+	return "{" + strings.Join(cols, " ") + "} : (" + query + ")"
+}
+
+// ftsQuery turns user input into an FTS5 query matching all the words as
+// prefixes. Words are quoted so FTS5 operators in the input are ignored.
+func ftsQuery(text string, fields ...Field) string {
+	words := ftsWords(text)
+	if len(words) == 0 {
+		return ""
+	}
+
+	terms := make([]string, 0, len(words))
+	for _, w := range words {
+		terms = append(terms, `"`+w+`"*`)
+	}
+
+	return ftsColumns(strings.Join(terms, " "), fields)
+}
+
+// ftsPhrase turns user input into an FTS5 phrase query matching the words in
+// sequence
+func ftsPhrase(text string, fields ...Field) string {
+	words := ftsWords(text)
+	if len(words) == 0 {
+		return ""
+	}
+
+	return ftsColumns(`"`+strings.Join(words, " ")+`"`, fields)
+}
@@ -0,0 +1,163 @@
+package database
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/blob42/gosuki/test/fixtures"
+)
+
+func TestFTSQuery(t *testing.T) {
+	tests := []struct {
+		name   string
+		text   string
+		fields []Field
+		want   string
+	}{
+		{"single word", "golang", nil, `"golang"*`},
+		{"words", "rust  lang", nil, `"rust"* "lang"*`},
+		{"punctuation is a separator", "go-lang.com", nil, `"go"* "lang"* "com"*`},
+		{"fts operators are quoted", `NOT "x" OR y*`, nil, `"NOT"* "x"* "OR"* "y"*`},
+		{"only punctuation", `"*:(`, nil, ""},
+		{"column filter", "go", []Field{FieldURL, FieldTitle}, `{URL metadata} : ("go"*)`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, ftsQuery(tt.text, tt.fields...))
+		})
+	}
+}
+
+func newFTSTestDB(t *testing.T) *DB {
+	t.Helper()
+	testDB, cleanup := newTestDB(t)
+	t.Cleanup(cleanup)
+	if !testDB.hasFTS5(context.Background()) {
+		t.Skip("sqlite built without fts5, use -tags sqlite_fts5")
+	}
+	require.True(t, testDB.hasFTSTable(context.Background()))
+	require.True(t, FullTextEnabled())
+	return testDB
+}
+
+func TestFTSWithoutFTS5(t *testing.T) {
+	testDB, cleanup := newTestDB(t)
+	t.Cleanup(cleanup)
+	ctx := context.Background()
+	fts5 := testDB.hasFTS5(ctx)
+
+	if fts5 {
+		// opened by a build without fts5
+		require.NoError(t, testDB.dropFTSTriggers(ctx))
+	} else {
+		// indexed by a build with fts5, the triggers write to a table this
+		// build cannot open
+		_, err := testDB.Handle.Exec(qCreateFTSTriggers)
+		require.NoError(t, err)
+		seed := fixtures.TagVarietySet()[:1]
+		_, err = testDB.Handle.Exec(fixtures.BookmarkToInsertSQL(seed[0]))
+		require.ErrorContains(t, err, FTSTable)
+
+		require.NoError(t, testDB.initFTS(ctx, false))
+	}
+	require.False(t, testDB.hasFTSTriggers(ctx))
+
+	// writes succeed without the index
+	seedDB(t, testDB, fixtures.TagVarietySet())
+
+	if !fts5 {
+		return
+	}
+
+	// the index missed the writes and is rebuilt when fts5 is back
+	require.NoError(t, testDB.initFTS(ctx, false))
+	require.True(t, testDB.hasFTSTriggers(ctx))
+	result, err := NewBookmarkQuery(MatchFullText("lan")).Run(ctx, DefaultPagination())
+	require.NoError(t, err)
+	require.Equal(t, uint(2), result.Total)
+}
+
+func TestMatchFullText(t *testing.T) {
+	testDB := newFTSTestDB(t)
+	seedDB(t, testDB, fixtures.TagVarietySet())
+	ctx := context.Background()
+
+	t.Run("prefix match", func(t *testing.T) {
+		result, err := NewBookmarkQuery(MatchFullText("lan")).Run(ctx, DefaultPagination())
+		require.NoError(t, err)
+		require.Equal(t, uint(2), result.Total)
+	})
+
+	t.Run("all words must match", func(t *testing.T) {
+		result, err := NewBookmarkQuery(MatchFullText("rust lang")).Run(ctx, DefaultPagination())
+		require.NoError(t, err)
+		require.Len(t, result.Bookmarks, 1)
+		require.Equal(t, "https://rust-lang.com", result.Bookmarks[0].URL)
+	})
+
+	t.Run("column filter", func(t *testing.T) {
+		result, err := NewBookmarkQuery(MatchFullText("programming", FieldURL, FieldTitle)).
+			Run(ctx, DefaultPagination())
+		require.NoError(t, err)
+		require.Zero(t, result.Total)
+	})
+
+	t.Run("index follows updates", func(t *testing.T) {
+		_, err := testDB.Handle.Exec(
+			"UPDATE gskbookmarks SET desc = 'systems language' WHERE URL = 'https://go-lang.com'")
+		require.NoError(t, err)
+
+		result, err := NewBookmarkQuery(MatchFullText("systems")).Run(ctx, DefaultPagination())
+		require.NoError(t, err)
+		require.Len(t, result.Bookmarks, 1)
+		require.Equal(t, "https://go-lang.com", result.Bookmarks[0].URL)
+
+		_, err = testDB.Handle.Exec("DELETE FROM gskbookmarks WHERE URL = 'https://go-lang.com'")
+		require.NoError(t, err)
+
+		result, err = NewBookmarkQuery(MatchFullText("systems")).Run(ctx, DefaultPagination())
+		require.NoError(t, err)
+		require.Zero(t, result.Total)
+	})
+}
+
+func TestSortRelevance(t *testing.T) {
+	testDB := newFTSTestDB(t)
+	seedDB(t, testDB, fixtures.TagVarietySet())
+	ctx := context.Background()
+
+	q := NewBookmarkQuery(MatchFullText("linux"))
+
+	// GNU is only tagged linux
+	result, err := q.Run(ctx, &PaginationParams{Page: 1, Size: 10, SortBy: "modified"})
+	require.NoError(t, err)
+	require.Len(t, result.Bookmarks, 2)
+	require.Equal(t, "https://gnu.org", result.Bookmarks[0].URL)
+
+	result, err = q.Run(ctx, &PaginationParams{Page: 1, Size: 10, SortBy: SortRelevance})
+	require.NoError(t, err)
+	require.Len(t, result.Bookmarks, 2)
+	require.Equal(t, "https://linux.org", result.Bookmarks[0].URL)
+
+	result, err = q.Run(ctx, &PaginationParams{Page: 1, Size: 10, SortBy: SortRelevance, SortAsc: true})
+	require.NoError(t, err)
+	require.Equal(t, "https://gnu.org", result.Bookmarks[0].URL)
+
+	// combined with other predicates and pagination
+	q = NewBookmarkQuery(MatchFullText("linux"), MatchTag("os", false))
+	result, err = q.Run(ctx, &PaginationParams{Page: 2, Size: 1, SortBy: SortRelevance})
+	require.NoError(t, err)
+	require.Equal(t, uint(2), result.Total)
+	require.Len(t, result.Bookmarks, 1)
+	require.Equal(t, "https://gnu.org", result.Bookmarks[0].URL)
+}
+
+func TestSortRelevanceWithoutFullText(t *testing.T) {
+	q := NewBookmarkQuery(MatchText("go", false))
+	stmt, _, err := q.Build(&PaginationParams{Page: 1, Size: 10, SortBy: SortRelevance})
+	require.NoError(t, err)
+	require.Contains(t, stmt, "ORDER BY modified DESC")
+	require.NotContains(t, stmt, "gskbookmarks_fts")
+}
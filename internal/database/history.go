@@ -0,0 +1,274 @@
+//
+// Copyright (c) 2023-2025 Chakib Ben Ziane <contact@blob42.xyz> and [`GoSuki` contributors]
+// (https://github.com/blob42/gosuki/graphs/contributors).
+//
+// All rights reserved.
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+//
+// This file is part of GoSuki.
+//
+// GoSuki is free software: you can redistribute it and/or modify it under the terms of
+// the GNU Affero General Public License as published by the Free Software Foundation,
+// either version 3 of the License, or (at your option) any later version.
+//
+// GoSuki is distributed in the hope that it will be useful, but WITHOUT ANY WARRANTY;
+// without even the implied warranty of MERCHANTABILITY or FITNESS FOR A PARTICULAR
+// PURPOSE.  See the GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License along with
+// gosuki.  If not, see <http://www.gnu.org/licenses/>.
+
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Actions recorded in the bookmark history
+const (
+	HistoryInsert = "insert"
+	HistoryUpdate = "update"
+	HistoryDelete = "delete"
+
+	// a previous revision was restored by the user
+	HistoryRestore = "restore"
+)
+
+var ErrRevisionNotFound = errors.New("revision not found")
+
+// Revision is an entry of the bookmark history. It holds the title, tags and
+// description of a bookmark before and after a change.
+type Revision struct {
+	ID         uint64
+	BookmarkID uint64 `db:"bookmark_id"`
+	URL        string `db:"url"`
+	Action     string
+
+	OldTitle string `db:"old_metadata"`
+	NewTitle string `db:"new_metadata"`
+	OldTags  string `db:"old_tags"`
+	NewTags  string `db:"new_tags"`
+	OldDesc  string `db:"old_desc"`
+	NewDesc  string `db:"new_desc"`
+
+	// Module that made the change
+	Module string
+
+	// Lamport clock of the change, zero until the change is synced
+	Version uint64
+
+	ChangedAt uint64 `db:"changed_at"`
+}
+
+// OldTagList returns the tags before the change
+func (rev Revision) OldTagList() []string {
+	return tagsFromString(rev.OldTags, TagSep).Get()
+}
+
+// NewTagList returns the tags after the change
+func (rev Revision) NewTagList() []string {
+	return tagsFromString(rev.NewTags, TagSep).Get()
+}
+
+// changed reports whether the revision is worth recording. Updates that do
+// not touch the title, tags or description are skipped.
+func (rev Revision) changed() bool {
+	return rev.Action != HistoryUpdate ||
+		rev.OldTitle != rev.NewTitle ||
+		normalizeTags(rev.OldTags) != normalizeTags(rev.NewTags) ||
+		rev.OldDesc != rev.NewDesc
+}
+
+func normalizeTags(tags string) string {
+	return tagsFromString(tags, TagSep).Sort().StringWrap()
+}
+
+const (
+	qInsertRevision = `
+	INSERT INTO gskbookmarks_history(
+		bookmark_id,
+		url,
+		action,
+		old_metadata,
+		new_metadata,
+		old_tags,
+		new_tags,
+		old_desc,
+		new_desc,
+		module,
+		version
+	)
+	VALUES (
+		:bookmark_id,
+		:url,
+		:action,
+		:old_metadata,
+		:new_metadata,
+		:old_tags,
+		:new_tags,
+		:old_desc,
+		:new_desc,
+		:module,
+		:version
+	)`
+
+	// revisions recorded outside of a sync get the version of the sync that
+	// writes them to the L2 cache
+	qSetPendingVersion = `
+	UPDATE gskbookmarks_history SET version = ? WHERE url = ? AND version = 0`
+)
+
+func recordRevision(tx *sqlx.Tx, rev *Revision) error {
+	if !rev.changed() {
+		return nil
+	}
+	_, err := tx.NamedExec(qInsertRevision, rev)
+	return err
+}
+
+// the L2 cache holds revisions not yet written to disk
+func historyDB() *DB {
+	if L2Cache.DB != nil {
+		return L2Cache.DB
+	}
+	return DiskDB
+}
+
+// BookmarkHistory returns the revisions of the bookmark with the given id,
+// most recent first. Ids are the ones returned by the query functions.
+func BookmarkHistory(ctx context.Context, id uint64) ([]*Revision, error) {
+	src := historyDB()
+	revs := []*Revision{}
+	err := src.Handle.SelectContext(ctx, &revs,
+		"SELECT * FROM gskbookmarks_history WHERE bookmark_id = ? ORDER BY id DESC", id)
+	if err != nil {
+		return nil, DBError{DBName: src.Name, Err: err}
+	}
+	return revs, nil
+}
+
+// URLHistory returns the revisions of the bookmark matching url, most recent
+// first.
+func URLHistory(ctx context.Context, url string) ([]*Revision, error) {
+	src := historyDB()
+	revs := []*Revision{}
+	err := src.Handle.SelectContext(ctx, &revs,
+		"SELECT * FROM gskbookmarks_history WHERE url = ? ORDER BY id DESC", url)
+	if err != nil {
+		return nil, DBError{DBName: src.Name, Err: err}
+	}
+	return revs, nil
+}
+
+// RevisionByID returns the history entry with the given id
+func RevisionByID(ctx context.Context, id uint64) (*Revision, error) {
+	src := historyDB()
+	rev := &Revision{}
+	err := src.Handle.GetContext(ctx, rev,
+		"SELECT * FROM gskbookmarks_history WHERE id = ?", id)
+	if err == sql.ErrNoRows {
+		return nil, ErrRevisionNotFound
+	} else if err != nil {
+		return nil, DBError{DBName: src.Name, Err: err}
+	}
+	return rev, nil
+}
+
+// RestoreRevision sets the title, tags and description of a bookmark back to
+// the state recorded after the change rev. Tombstoned bookmarks are restored.
+//
+// The change is applied to the L1 and L2 caches and the L2 checksum is reset
+// so that the next cache sync writes it to disk with a new version.
+func RestoreRevision(ctx context.Context, rev *Revision) error {
+	cacheMu.Lock()
+	defer cacheMu.Unlock()
+
+	if Cache.DB == nil || L2Cache.DB == nil {
+		return errors.New("cache is not initialized")
+	}
+
+	res, err := Cache.Handle.ExecContext(ctx,
+		`UPDATE gskbookmarks
+		SET
+			metadata = ?,
+			tags = ?,
+			desc = ?,
+			deleted = 0,
+			deleted_at = 0,
+			flags = flags & ~?,
+			modified = strftime('%s'),
+			xhsum = ?
+		WHERE URL = ?`,
+		rev.NewTitle,
+		rev.NewTags,
+		rev.NewDesc,
+		FlagMerged,
+		xhsum(rev.URL, rev.NewTitle, rev.NewTags, rev.NewDesc),
+		rev.URL,
+	)
+	if err != nil {
+		return DBError{DBName: Cache.Name, Err: err}
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return ErrBookmarkNotFound
+	}
+
+	tx, err := L2Cache.Handle.BeginTxx(ctx, nil)
+	if err != nil {
+		return DBError{DBName: L2Cache.Name, Err: err}
+	}
+
+	current := RawBookmark{}
+	err = tx.GetContext(ctx, &current, "SELECT * FROM gskbookmarks WHERE URL = ?", rev.URL)
+	if err != nil {
+		tx.Rollback()
+		if err == sql.ErrNoRows {
+			return ErrBookmarkNotFound
+		}
+		return DBError{DBName: L2Cache.Name, Err: err}
+	}
+
+	_, err = tx.ExecContext(ctx,
+		`UPDATE gskbookmarks
+		SET metadata = ?, tags = ?, desc = ?, deleted = 0, deleted_at = 0,
+			flags = flags & ~?, xhsum = ''
+		WHERE URL = ?`,
+		rev.NewTitle,
+		rev.NewTags,
+		rev.NewDesc,
+		FlagMerged,
+		rev.URL,
+	)
+	if err != nil {
+		tx.Rollback()
+		return DBError{DBName: L2Cache.Name, Err: err}
+	}
+
+	err = recordRevision(tx, &Revision{
+		BookmarkID: current.ID,
+		URL:        rev.URL,
+		Action:     HistoryRestore,
+		OldTitle:   current.Metadata,
+		NewTitle:   rev.NewTitle,
+		OldTags:    current.Tags,
+		NewTags:    rev.NewTags,
+		OldDesc:    current.Desc,
+		NewDesc:    rev.NewDesc,
+		Module:     current.Module,
+	})
+	if err != nil {
+		tx.Rollback()
+		return DBError{DBName: L2Cache.Name, Err: err}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return DBError{DBName: L2Cache.Name, Err: err}
+	}
+
+	return nil
+}
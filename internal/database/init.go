@@ -50,6 +50,10 @@ func InitDiskConn(dbPath string) error {
 
 	_, err = DiskDB.Handle.Exec("PRAGMA wal_checkpoint(TRUNCATE)")
 
+	if DiskDB.hasFTS5(context.Background()) && DiskDB.hasFTSTable(context.Background()) {
+		fullTextEnabled.Store(true)
+	}
+
 	return err
 }
 
@@ -113,6 +117,11 @@ func Init(ctx context.Context, cmd *cli.Command) {
 	if err != nil {
 		log.Fatalf("getting local db clock: %s", err)
 	}
+
+	// tag aliases are applied to incoming bookmarks
+	if err = LoadTagAliases(ctx, L2Cache.DB); err != nil {
+		log.Fatalf("loading tag aliases: %s", err)
+	}
 }
 
 // Initialize the local database file
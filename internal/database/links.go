@@ -0,0 +1,135 @@
+//
+// Copyright (c) 2023-2025 Chakib Ben Ziane <contact@blob42.xyz> and [`GoSuki` contributors]
+// (https://github.com/blob42/gosuki/graphs/contributors).
+//
+// All rights reserved.
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+//
+// This file is part of GoSuki.
+//
+// GoSuki is free software: you can redistribute it and/or modify it under the terms of
+// the GNU Affero General Public License as published by the Free Software Foundation,
+// either version 3 of the License, or (at your option) any later version.
+//
+// GoSuki is distributed in the hope that it will be useful, but WITHOUT ANY WARRANTY;
+// without even the implied warranty of MERCHANTABILITY or FITNESS FOR A PARTICULAR
+// PURPOSE.  See the GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License along with
+// gosuki.  If not, see <http://www.gnu.org/licenses/>.
+
+package database
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+	"time"
+
+	"github.com/blob42/gosuki/hooks"
+)
+
+// Link status codes stored in link_status besides http status codes
+const (
+	// the host could not be resolved or refused the connection
+	StatusUnreachable = -1
+
+	// the check timed out or failed for another reason
+	StatusFailed = -2
+)
+
+// IsDeadStatus reports whether a link with the given status is gone for good
+func IsDeadStatus(status int) bool {
+	return status == StatusUnreachable || status == 404 || status == 410
+}
+
+// Link states accepted by [MatchLinkStatus]
+var linkStates = map[string]string{
+	"ok":        "link_status BETWEEN 200 AND 399",
+	"dead":      "link_status IN (-1, 404, 410)",
+	"redirect":  "link_final_url != '' AND link_final_url != URL",
+	"error":     "link_status != 0 AND link_status NOT BETWEEN 200 AND 399 AND link_status NOT IN (-1, 404, 410)",
+	"unchecked": "link_status = 0",
+}
+
+// ValidLinkStatus reports whether state is a link state or an http status code
+func ValidLinkStatus(state string) bool {
+	if _, ok := linkStates[state]; ok {
+		return true
+	}
+	code, err := strconv.Atoi(state)
+	return err == nil && code >= 100 && code <= 599
+}
+
+// MatchLinkStatus matches bookmarks by the result of their last link check.
+// state is one of ok, dead, redirect, error, unchecked or an http status code.
+func MatchLinkStatus(state string) Predicate {
+	if clause, ok := linkStates[state]; ok {
+		return Predicate{clause: clause}
+	}
+	if code, err := strconv.Atoi(state); err == nil {
+		return Predicate{clause: "link_status = ?", args: []any{code}}
+	}
+	return Predicate{}
+}
+
+// LinksToCheck returns up to limit http(s) urls last checked at or before
+// `before`, least recently checked first.
+func (db *DB) LinksToCheck(ctx context.Context, before time.Time, limit int) ([]string, error) {
+	var urls []string
+	err := db.Handle.SelectContext(ctx, &urls, `
+		SELECT URL FROM gskbookmarks
+		WHERE `+WhereNotDeleted+`
+		AND (URL LIKE 'http://%' OR URL LIKE 'https://%')
+		AND link_checked_at <= ?
+		ORDER BY link_checked_at, id
+		LIMIT ?`,
+		before.Unix(), limit,
+	)
+	if err != nil {
+		return nil, DBError{DBName: db.Name, Err: err}
+	}
+	return urls, nil
+}
+
+// SetLinkStatus records the result of a link check. It returns true and fires
+// the update hooks when a link that was alive or unchecked is found dead.
+func (db *DB) SetLinkStatus(ctx context.Context, url string, status int, finalURL string) (bool, error) {
+	tx, err := db.Handle.BeginTxx(ctx, nil)
+	if err != nil {
+		return false, DBError{DBName: db.Name, Err: err}
+	}
+	defer tx.Rollback()
+
+	raw := &RawBookmark{}
+	err = tx.GetContext(ctx, raw, "SELECT * FROM gskbookmarks WHERE URL = ?", url)
+	if err == sql.ErrNoRows {
+		return false, ErrBookmarkNotFound
+	} else if err != nil {
+		return false, DBError{DBName: db.Name, Err: err}
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE gskbookmarks
+		SET link_status = ?, link_final_url = ?, link_checked_at = ?
+		WHERE URL = ?`,
+		status, finalURL, time.Now().Unix(), url,
+	)
+	if err != nil {
+		return false, DBError{DBName: db.Name, Err: err}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return false, DBError{DBName: db.Name, Err: err}
+	}
+
+	died := IsDeadStatus(status) && !IsDeadStatus(raw.LinkStatus)
+	if died {
+		book := raw.AsBookmark()
+		book.LinkStatus = status
+		queueHook(hooks.HookJob{Book: book, Kind: hooks.GlobalUpdateHook})
+	}
+
+	return died, nil
+}
@@ -0,0 +1,64 @@
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/blob42/gosuki/test/fixtures"
+)
+
+func TestLinkStatus(t *testing.T) {
+	db, cleanup := newTestDB(t)
+	defer cleanup()
+	defer seedDB(t, db, fixtures.DefaultSeedSet())()
+
+	ctx := context.Background()
+
+	urls, err := db.LinksToCheck(ctx, time.Now(), 100)
+	require.NoError(t, err)
+	require.Len(t, urls, 5)
+
+	died, err := db.SetLinkStatus(ctx, "https://alpha.com", 404, "")
+	require.NoError(t, err)
+	require.True(t, died)
+
+	// already dead
+	died, err = db.SetLinkStatus(ctx, "https://alpha.com", StatusUnreachable, "")
+	require.NoError(t, err)
+	require.False(t, died)
+
+	died, err = db.SetLinkStatus(ctx, "https://beta.com", 200, "https://www.beta.com/")
+	require.NoError(t, err)
+	require.False(t, died)
+
+	_, err = db.SetLinkStatus(ctx, "https://not-bookmarked.com", 200, "")
+	require.ErrorIs(t, err, ErrBookmarkNotFound)
+
+	urls, err = db.LinksToCheck(ctx, time.Now().Add(-time.Hour), 100)
+	require.NoError(t, err)
+	require.Len(t, urls, 3)
+	require.NotContains(t, urls, "https://alpha.com")
+
+	count := func(state string) int {
+		t.Helper()
+		where := MatchLinkStatus(state)
+		var n int
+		err := db.Handle.Get(&n, "SELECT COUNT(*) FROM gskbookmarks WHERE "+where.clause, where.args...)
+		require.NoError(t, err)
+		return n
+	}
+
+	require.Equal(t, 1, count("dead"))
+	require.Equal(t, 1, count("ok"))
+	require.Equal(t, 1, count("redirect"))
+	require.Equal(t, 1, count("200"))
+	require.Equal(t, 0, count("error"))
+	require.Equal(t, 3, count("unchecked"))
+
+	require.True(t, ValidLinkStatus("dead"))
+	require.True(t, ValidLinkStatus("503"))
+	require.False(t, ValidLinkStatus("gone"))
+}
@@ -0,0 +1,45 @@
+//
+//  Copyright (c) 2025 Chakib Ben Ziane <contact@blob42.xyz>  and [`gosuki` contributors](https://github.com/blob42/gosuki/graphs/contributors).
+//  All rights reserved.
+//
+//  SPDX-License-Identifier: AGPL-3.0-or-later
+//
+//  This file is part of GoSuki.
+//
+//  GoSuki is free software: you can redistribute it and/or modify
+//  it under the terms of the GNU Affero General Public License as
+//  published by the Free Software Foundation, either version 3 of the
+//  License, or (at your option) any later version.
+//
+//  GoSuki is distributed in the hope that it will be useful,
+//  but WITHOUT ANY WARRANTY; without even the implied warranty of
+//  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU Affero General Public License for more details.
+//
+//  You should have received a copy of the GNU Affero General Public License
+//  along with gosuki.  If not, see <http://www.gnu.org/licenses/>.
+//
+
+package database
+
+// Performs the database schema migration from version 12 to version 13.
+// This migration adds the keyword column to bookmark_sources. The column
+// already exists when the table was created by [DB.migrateToVersion12].
+func (db *DB) migrateToVersion13() error {
+	log.Debug("DB schema: migrating to v13")
+
+	var exists bool
+	err := db.Handle.Get(&exists,
+		"SELECT COUNT(*) > 0 FROM pragma_table_info('bookmark_sources') WHERE name = 'keyword'")
+	if err != nil {
+		return DBError{DBName: db.Name, Err: err}
+	}
+	if exists {
+		return nil
+	}
+
+	if _, err = db.Handle.Exec("ALTER TABLE bookmark_sources ADD COLUMN keyword TEXT DEFAULT ''"); err != nil {
+		return DBError{DBName: db.Name, Err: err}
+	}
+	return nil
+}
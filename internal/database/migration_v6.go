@@ -0,0 +1,39 @@
+//
+//  Copyright (c) 2025 Chakib Ben Ziane <contact@blob42.xyz>  and [`gosuki` contributors](https://github.com/blob42/gosuki/graphs/contributors).
+//  All rights reserved.
+//
+//  SPDX-License-Identifier: AGPL-3.0-or-later
+//
+//  This file is part of GoSuki.
+//
+//  GoSuki is free software: you can redistribute it and/or modify
+//  it under the terms of the GNU Affero General Public License as
+//  published by the Free Software Foundation, either version 3 of the
+//  License, or (at your option) any later version.
+//
+//  GoSuki is distributed in the hope that it will be useful,
+//  but WITHOUT ANY WARRANTY; without even the implied warranty of
+//  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU Affero General Public License for more details.
+//
+//  You should have received a copy of the GNU Affero General Public License
+//  along with gosuki.  If not, see <http://www.gnu.org/licenses/>.
+//
+
+package database
+
+import "context"
+
+// Performs the database schema migration from version 5 to version 6.
+// This migration adds the full text search index:
+// 1. Creating the `gskbookmarks_fts` FTS5 table and its sync triggers
+// 2. Indexing all existing bookmarks
+//
+// The index is only created when sqlite is built with FTS5 support, the schema
+// is at version 6 either way. The index is created on a later startup if
+// support is added afterwards. A build without FTS5 drops the triggers of an
+// existing index and the next build with FTS5 rebuilds it, see [DB.initFTS].
+func (db *DB) migrateToVersion6() error {
+	log.Debug("DB schema: migrating to v6")
+	return db.initFTS(context.Background(), true)
+}
@@ -0,0 +1,36 @@
+//
+//  Copyright (c) 2025 Chakib Ben Ziane <contact@blob42.xyz>  and [`gosuki` contributors](https://github.com/blob42/gosuki/graphs/contributors).
+//  All rights reserved.
+//
+//  SPDX-License-Identifier: AGPL-3.0-or-later
+//
+//  This file is part of GoSuki.
+//
+//  GoSuki is free software: you can redistribute it and/or modify
+//  it under the terms of the GNU Affero General Public License as
+//  published by the Free Software Foundation, either version 3 of the
+//  License, or (at your option) any later version.
+//
+//  GoSuki is distributed in the hope that it will be useful,
+//  but WITHOUT ANY WARRANTY; without even the implied warranty of
+//  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU Affero General Public License for more details.
+//
+//  You should have received a copy of the GNU Affero General Public License
+//  along with gosuki.  If not, see <http://www.gnu.org/licenses/>.
+//
+
+package database
+
+// Performs the database schema migration from version 7 to version 8.
+// This migration adds the bookmark history by creating the
+// `gskbookmarks_history` table and its indexes. Existing bookmarks start
+// without revisions.
+func (db *DB) migrateToVersion8() error {
+	log.Debug("DB schema: migrating to v8")
+	if _, err := db.Handle.Exec(QCreateHistory); err != nil {
+		return DBError{DBName: db.Name, Err: err}
+	}
+
+	return nil
+}
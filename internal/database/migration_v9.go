@@ -0,0 +1,53 @@
+//
+//  Copyright (c) 2025 Chakib Ben Ziane <contact@blob42.xyz>  and [`gosuki` contributors](https://github.com/blob42/gosuki/graphs/contributors).
+//  All rights reserved.
+//
+//  SPDX-License-Identifier: AGPL-3.0-or-later
+//
+//  This file is part of GoSuki.
+//
+//  GoSuki is free software: you can redistribute it and/or modify
+//  it under the terms of the GNU Affero General Public License as
+//  published by the Free Software Foundation, either version 3 of the
+//  License, or (at your option) any later version.
+//
+//  GoSuki is distributed in the hope that it will be useful,
+//  but WITHOUT ANY WARRANTY; without even the implied warranty of
+//  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU Affero General Public License for more details.
+//
+//  You should have received a copy of the GNU Affero General Public License
+//  along with gosuki.  If not, see <http://www.gnu.org/licenses/>.
+//
+
+package database
+
+// Performs the database schema migration from version 8 to version 9.
+// This migration stores the result of link checks:
+// 1. Adding a `link_status` http status code column to gskbookmarks
+// 2. Adding a `link_final_url` column with the url reached after redirects
+// 3. Adding a `link_checked_at` unix timestamp column to gskbookmarks
+func (db *DB) migrateToVersion9() error {
+	log.Debug("DB schema: migrating to v9")
+	tx, err := db.Handle.Begin()
+	if err != nil {
+		return DBError{DBName: db.Name, Err: err}
+	}
+
+	for _, column := range []string{
+		"link_status INTEGER DEFAULT 0",
+		"link_final_url TEXT DEFAULT ''",
+		"link_checked_at INTEGER DEFAULT 0",
+	} {
+		if _, err = tx.Exec("ALTER TABLE gskbookmarks ADD COLUMN " + column); err != nil {
+			tx.Rollback()
+			return DBError{DBName: db.Name, Err: err}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return DBError{DBName: db.Name, Err: err}
+	}
+
+	return nil
+}
@@ -0,0 +1,172 @@
+//
+//  Copyright (c) 2025 Chakib Ben Ziane <contact@blob42.xyz>  and [`gosuki` contributors](https://github.com/blob42/gosuki/graphs/contributors).
+//  All rights reserved.
+//
+//  SPDX-License-Identifier: AGPL-3.0-or-later
+//
+//  This file is part of GoSuki.
+//
+//  GoSuki is free software: you can redistribute it and/or modify
+//  it under the terms of the GNU Affero General Public License as
+//  published by the Free Software Foundation, either version 3 of the
+//  License, or (at your option) any later version.
+//
+//  GoSuki is distributed in the hope that it will be useful,
+//  but WITHOUT ANY WARRANTY; without even the implied warranty of
+//  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU Affero General Public License for more details.
+//
+//  You should have received a copy of the GNU Affero General Public License
+//  along with gosuki.  If not, see <http://www.gnu.org/licenses/>.
+//
+
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/gofrs/uuid"
+)
+
+// PeerClock returns the last clock of peer `node` this node synchronized
+// with, or 0 if the peer is unknown.
+func (db *DB) PeerClock(ctx context.Context, node UUID) (uint64, error) {
+	var clock uint64
+	err := db.Handle.QueryRowContext(
+		ctx,
+		"SELECT version FROM sync_nodes WHERE node_id = ?",
+		node,
+	).Scan(&clock)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	} else if err != nil {
+		return 0, DBError{DBName: db.Name, Err: err}
+	}
+
+	return clock, nil
+}
+
+// SetPeerClock records the clock of peer `node` after a synchronization
+func (db *DB) SetPeerClock(ctx context.Context, node UUID, clock uint64) error {
+	_, err := db.Handle.ExecContext(ctx, `
+		INSERT INTO sync_nodes(node_id, version) VALUES (?, ?)
+		ON CONFLICT(node_id) DO UPDATE SET version = excluded.version
+		`,
+		node,
+		clock,
+	)
+	if err != nil {
+		return DBError{DBName: db.Name, Err: err}
+	}
+	return nil
+}
+
+// ChangesSince calls fn for every bookmark changed after `clock`, ordered by
+// version. Changes made by node `exclude` are skipped as the peer already
+// knows them. Bookmarks without node id were changed locally and are reported
+// with `local` as their node id.
+func (db *DB) ChangesSince(
+	ctx context.Context,
+	clock uint64,
+	exclude UUID,
+	local UUID,
+	fn func(*RawBookmark) error,
+) error {
+	rows, err := db.Handle.QueryxContext(ctx, `
+		SELECT * FROM gskbookmarks
+		WHERE version > ? AND hex(node_id) IS NOT hex(?)
+		ORDER BY version`,
+		clock,
+		exclude,
+	)
+	if err != nil {
+		return DBError{DBName: db.Name, Err: err}
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		raw := &RawBookmark{}
+		if err = rows.StructScan(raw); err != nil {
+			return DBError{DBName: db.Name, Err: err}
+		}
+
+		if raw.NodeID == UUID(uuid.Nil) {
+			raw.NodeID = local
+		}
+
+		if err = fn(raw); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+// InsertRawBookmarks inserts bookmarks as is, keeping their version and node
+// id. It is used to fill a buffer with changes received from a peer.
+func (db *DB) InsertRawBookmarks(ctx context.Context, raws ...*RawBookmark) error {
+	tx, err := db.Handle.BeginTxx(ctx, nil)
+	if err != nil {
+		return DBError{DBName: db.Name, Err: err}
+	}
+
+	stmt, err := tx.PreparexContext(ctx, `
+		INSERT INTO gskbookmarks(
+			URL, metadata, tags, desc, modified, flags, module, xhsum,
+			version, node_id, deleted, deleted_at, created, url_key
+		)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(URL) DO UPDATE SET
+			metadata = excluded.metadata,
+			tags = excluded.tags,
+			desc = excluded.desc,
+			modified = excluded.modified,
+			flags = excluded.flags,
+			module = excluded.module,
+			xhsum = excluded.xhsum,
+			version = excluded.version,
+			node_id = excluded.node_id,
+			deleted = excluded.deleted,
+			deleted_at = excluded.deleted_at,
+			created = CASE
+				WHEN excluded.created > 0 AND excluded.created < gskbookmarks.created
+				THEN excluded.created ELSE gskbookmarks.created END
+		WHERE excluded.version > gskbookmarks.version`,
+	)
+	if err != nil {
+		tx.Rollback()
+		return DBError{DBName: db.Name, Err: err}
+	}
+	defer stmt.Close()
+
+	for _, raw := range raws {
+		_, err = stmt.ExecContext(ctx,
+			raw.URL,
+			raw.Metadata,
+			raw.Tags,
+			raw.Desc,
+			raw.Modified,
+			raw.Flags,
+			raw.Module,
+			raw.XHSum,
+			raw.Version,
+			raw.NodeID,
+			raw.Deleted,
+			raw.DeletedAt,
+			raw.Created,
+			CanonicalURL(raw.URL),
+		)
+		if err != nil {
+			tx.Rollback()
+			return DBError{DBName: db.Name, Err: err}
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return DBError{DBName: db.Name, Err: err}
+	}
+
+	return nil
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
@@ -74,6 +75,8 @@ func TestBuildOrderBy_ValidFields(t *testing.T) {
 		{"title asc", "title", true, " ORDER BY metadata ASC"},
 		{"url desc", "url", false, " ORDER BY url DESC"},
 		{"url asc", "url", true, " ORDER BY url ASC"},
+		{"created desc", "created", false, " ORDER BY created DESC"},
+		{"frecency desc", "frecency", false, " ORDER BY " + qFrecency + " DESC, modified DESC"},
 	}
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
@@ -121,6 +124,30 @@ func TestListBookmarks_SortModifiedAsc(t *testing.T) {
 	}
 }
 
+func TestListBookmarks_SortCreatedAsc(t *testing.T) {
+	db, cleanup := newTestDB(t)
+	defer cleanup()
+
+	// creation order is the reverse of the modification order
+	seeds := fixtures.DefaultSeedSet()
+	for i := range seeds {
+		seeds[i].Created = int64(100 * (len(seeds) - i))
+	}
+	defer seedDB(t, db, seeds)()
+
+	result, err := ListBookmarks(context.Background(), &PaginationParams{
+		Page: 1, Size: -1, SortBy: "created", SortAsc: true,
+	})
+	require.NoError(t, err)
+	require.Equal(t, 5, len(result.Bookmarks))
+
+	expected := []string{"Epsilon", "Delta", "Gamma", "Beta", "Alpha"}
+	for i, bm := range result.Bookmarks {
+		require.Equal(t, expected[i], bm.Title, "index %d", i)
+		require.Equal(t, uint64(100*(i+1)), bm.Created, "index %d", i)
+	}
+}
+
 func TestListBookmarks_SortTitleAsc(t *testing.T) {
 	db, cleanup := newTestDB(t)
 	defer cleanup()
@@ -210,109 +237,116 @@ func TestDefaultPagination(t *testing.T) {
 	require.False(t, p.SortAsc)
 }
 
-// --- buildWhereClause (pure function) ---
+// --- BookmarkQuery builder (pure functions) ---
 
-func TestBuildWhereClause_NoTagNoFuzzy(t *testing.T) {
-	w := buildWhereClause("", false)
-	require.Contains(t, w, "URL like")
-	require.Contains(t, w, "metadata like")
-	require.Contains(t, w, "LOWER(tags) like")
+func TestMatchText_DefaultFields(t *testing.T) {
+	p := MatchText("test", false)
+	require.Contains(t, p.String(), "URL LIKE ?")
+	require.Contains(t, p.String(), "metadata LIKE ?")
+	require.Contains(t, p.String(), "LOWER(tags) LIKE ?")
+	require.Equal(t, []any{"%test%", "%test%", "%test%"}, p.args)
 }
 
-func TestBuildWhereClause_TagNoFuzzy(t *testing.T) {
-	w := buildWhereClause("linux", false)
-	require.Contains(t, w, "URL LIKE")
-	require.Contains(t, w, "metadata LIKE")
-	require.Contains(t, w, "LOWER(tags) LIKE")
+func TestMatchText_Fields(t *testing.T) {
+	p := MatchText("test", false, FieldURL, FieldTitle)
+	require.NotContains(t, p.String(), "tags")
+	require.Len(t, p.args, 2)
 }
 
-func TestBuildWhereClause_NoTagFuzzy(t *testing.T) {
-	w := buildWhereClause("", true)
-	require.Contains(t, w, "fuzzy(")
+func TestMatchText_Fuzzy(t *testing.T) {
+	p := MatchText("test", true)
+	require.Contains(t, p.String(), "fuzzy(?, URL)")
+	require.Equal(t, []any{"test", "test", "test"}, p.args)
 }
 
-func TestBuildWhereClause_TagFuzzy(t *testing.T) {
-	w := buildWhereClause("linux", true)
-	require.Contains(t, w, "fuzzy(")
-	require.Contains(t, w, "LOWER(tags) LIKE")
+func TestMatchText_Empty(t *testing.T) {
+	require.True(t, MatchText("  ", false).IsZero())
 }
 
-// --- buildCountQuery (pure function) ---
-
-func TestBuildCountQuery_NoTag(t *testing.T) {
-	q := buildCountQuery("", false, "test", "test")
-	require.Contains(t, q, "SELECT COUNT(*)")
-	require.Contains(t, q, "gskbookmarks")
-	require.Contains(t, q, "LIMIT 1")
+func TestMatchText_EscapesWildcards(t *testing.T) {
+	p := MatchText(`100%_\`, false, FieldTitle)
+	require.Equal(t, []any{`%100\%\_\\%`}, p.args)
 }
 
-func TestBuildCountQuery_Tag(t *testing.T) {
-	q := buildCountQuery("linux", false, "test", "linux")
-	require.Contains(t, q, "SELECT COUNT(*)")
-	require.Contains(t, q, "LOWER(tags) LIKE")
-}
+func TestMatchTags(t *testing.T) {
+	p := MatchTags(TagAnd, false, "Linux", "os")
+	require.Equal(t, "("+qMatchTag+") AND ("+qMatchTag+")", p.String())
+	require.Equal(t, []any{"Linux", "Linux/%", "Linux", "os", "os/%", "os"}, p.args)
 
-func TestBuildCountQuery_Fuzzy(t *testing.T) {
-	q := buildCountQuery("", true, "test", "test")
-	require.Contains(t, q, "fuzzy(")
-}
+	p = MatchTags(TagOr, false, "linux", "os")
+	require.Contains(t, p.String(), ") OR (")
 
-// --- buildWhereClauseForManyTags (pure function) ---
+	p = MatchTags(TagAnd, true, "go")
+	require.Equal(t, "fuzzy(?, tags)", p.String())
 
-func TestBuildWhereClauseForManyTags_NoQuery(t *testing.T) {
-	w := buildWhereClauseForManyTags("", []string{"linux", "os"}, TagAnd, false)
-	require.Contains(t, w, "LOWER(tags) like '%linux%'")
-	require.Contains(t, w, "LOWER(tags) like '%os%'")
-	require.Contains(t, w, "AND")
+	require.True(t, MatchTags(TagAnd, false, "", " ").IsZero())
 }
 
-func TestBuildWhereClauseForManyTags_WithQuery(t *testing.T) {
-	w := buildWhereClauseForManyTags("lang", []string{"programming"}, TagAnd, false)
-	require.Contains(t, w, "URL like '%lang%'")
-	require.Contains(t, w, "LOWER(tags) like '%programming%'")
-}
+func TestPredicateComposition(t *testing.T) {
+	p := And(
+		MatchModule("firefox"),
+		Not(MatchTag("os", false)),
+		Or(MatchText("a", false, FieldURL), Predicate{}),
+	)
+	require.Equal(t,
+		"("+qMatchModule+") AND (NOT ("+qMatchTag+")) AND (URL LIKE ? ESCAPE '\\')",
+		p.String())
+	require.Equal(t,
+		[]any{"firefox", "firefox", `firefox\_%`, "firefox", "os", "os/%", "os", "%a%"}, p.args)
 
-func TestBuildWhereClauseForManyTags_OrCondition(t *testing.T) {
-	w := buildWhereClauseForManyTags("", []string{"linux", "os"}, TagOr, false)
-	require.Contains(t, w, "OR")
-	require.Contains(t, w, "LOWER(tags) like '%linux%'")
-	require.Contains(t, w, "LOWER(tags) like '%os%'")
+	require.True(t, And().IsZero())
+	require.True(t, Not(Predicate{}).IsZero())
 }
 
-func TestBuildWhereClauseForManyTags_Fuzzy(t *testing.T) {
-	w := buildWhereClauseForManyTags("", []string{"go"}, TagAnd, true)
-	require.Contains(t, w, "fuzzy('go', tags)")
-}
+func TestModifiedBetween(t *testing.T) {
+	from := time.Unix(1000, 0)
+	to := time.Unix(2000, 0)
+
+	p := ModifiedBetween(from, to)
+	require.Equal(t, "(modified >= ?) AND (modified <= ?)", p.String())
+	require.Equal(t, []any{int64(1000), int64(2000)}, p.args)
 
-// --- buildSelectQuery (pure function) ---
+	p = ModifiedBetween(time.Time{}, to)
+	require.Equal(t, "modified <= ?", p.String())
 
-func TestBuildSelectQuery_NoTag(t *testing.T) {
-	q := buildSelectQuery("test", false, "", DefaultPagination())
-	require.Contains(t, q, "SELECT URL, metadata, tags, module")
-	require.Contains(t, q, "gskbookmarks")
-	require.Contains(t, q, "URL like '%test%'")
-	require.Contains(t, q, "LIMIT")
+	require.True(t, ModifiedBetween(time.Time{}, time.Time{}).IsZero())
 }
 
-func TestBuildSelectQuery_WithTag(t *testing.T) {
-	q := buildSelectQuery("test", false, "linux", DefaultPagination())
-	require.Contains(t, q, "URL LIKE '%test%'")
-	require.Contains(t, q, "LOWER(tags) LIKE '%linux%'")
+func TestCreatedBetween(t *testing.T) {
+	p := CreatedBetween(time.Unix(1000, 0), time.Time{})
+	require.Equal(t, "created >= ?", p.String())
+	require.Equal(t, []any{int64(1000)}, p.args)
+
+	require.True(t, CreatedBetween(time.Time{}, time.Time{}).IsZero())
 }
 
-func TestBuildSelectQuery_Fuzzy(t *testing.T) {
-	q := buildSelectQuery("test", true, "", DefaultPagination())
-	require.Contains(t, q, "fuzzy('test', URL)")
+func TestBookmarkQueryBuild(t *testing.T) {
+	q := NewBookmarkQuery(MatchText("test", false)).Where(MatchModule("chrome"))
+	stmt, args, err := q.Build(&PaginationParams{Page: 3, Size: 10, SortBy: "title"})
+	require.NoError(t, err)
+	require.Contains(t, stmt, "SELECT * FROM gskbookmarks WHERE")
+	require.Contains(t, stmt, WhereNotDeleted)
+	require.Contains(t, stmt, "ORDER BY metadata DESC LIMIT ? OFFSET ?")
+	require.NotContains(t, stmt, "test")
+	require.Equal(t,
+		[]any{"%test%", "%test%", "%test%", "chrome", "chrome", `chrome\_%`, "chrome", 10, 20}, args)
+
+	count, countArgs := q.BuildCount()
+	require.Contains(t, count, "SELECT COUNT(*)")
+	require.Equal(t, args[:7], countArgs)
+
+	_, _, err = q.Build(nil)
+	require.Error(t, err)
 }
 
-// --- QueryBookmarks (integration) ---
+// --- BookmarkQuery text search (integration) ---
 
 func TestQueryBookmarks_ByTitle(t *testing.T) {
 	db, cleanup := newTestDB(t)
 	defer cleanup()
 	defer seedDB(t, db, fixtures.DefaultSeedSet())()
 
-	result, err := QueryBookmarks(context.Background(), "Alpha", false, DefaultPagination())
+	result, err := NewBookmarkQuery(MatchText("Alpha", false)).Run(context.Background(), DefaultPagination())
 	require.NoError(t, err)
 	require.Equal(t, 1, len(result.Bookmarks))
 	require.Equal(t, "Alpha", result.Bookmarks[0].Title)
@@ -324,214 +358,200 @@ func TestQueryBookmarks_ByURL(t *testing.T) {
 	defer cleanup()
 	defer seedDB(t, db, fixtures.DefaultSeedSet())()
 
-	result, err := QueryBookmarks(context.Background(), "beta.com", false, DefaultPagination())
+	result, err := NewBookmarkQuery(MatchText("beta.com", false)).Run(context.Background(), DefaultPagination())
 	require.NoError(t, err)
 	require.Equal(t, 1, len(result.Bookmarks))
 	require.Equal(t, "Beta", result.Bookmarks[0].Title)
 }
 
-func TestQueryBookmarks_EmptyQuery(t *testing.T) {
-	db, cleanup := newTestDB(t)
-	defer cleanup()
-	defer seedDB(t, db, fixtures.DefaultSeedSet())()
-
-	_, err := QueryBookmarks(context.Background(), "", false, DefaultPagination())
-	require.Error(t, err)
-	require.Contains(t, err.Error(), "empty")
-}
-
 func TestQueryBookmarks_Fuzzy(t *testing.T) {
 	db, cleanup := newTestDB(t)
 	defer cleanup()
 	defer seedDB(t, db, fixtures.DefaultSeedSet())()
 
-	result, err := QueryBookmarks(context.Background(), "alph", true, DefaultPagination())
+	result, err := NewBookmarkQuery(MatchText("alph", true)).Run(context.Background(), DefaultPagination())
 	require.NoError(t, err)
 	require.GreaterOrEqual(t, len(result.Bookmarks), 1)
 }
 
-// --- QueryBookmarksByTag (integration) ---
-// Only error-path tests are included until the bug is fixed.
-
-func TestQueryBookmarksByTag_EmptyQuery(t *testing.T) {
-	db, cleanup := newTestDB(t)
-	defer cleanup()
-	defer seedDB(t, db, fixtures.DefaultSeedSet())()
-
-	_, err := QueryBookmarksByTag(context.Background(), "", "a", false, DefaultPagination())
-	require.Error(t, err)
-}
-
-func TestQueryBookmarksByTag_EmptyTag(t *testing.T) {
-	db, cleanup := newTestDB(t)
-	defer cleanup()
-	defer seedDB(t, db, fixtures.DefaultSeedSet())()
-
-	_, err := QueryBookmarksByTag(context.Background(), "test", "", false, DefaultPagination())
-	require.Error(t, err)
-}
-
-func TestQueryBookmarksByTag_NilPagination(t *testing.T) {
+func TestQueryBookmarks_NilPagination(t *testing.T) {
 	db, cleanup := newTestDB(t)
 	defer cleanup()
 	defer seedDB(t, db, fixtures.DefaultSeedSet())()
 
-	_, err := QueryBookmarksByTag(context.Background(), "test", "a", false, nil)
+	_, err := NewBookmarkQuery(MatchText("test", false)).Run(context.Background(), nil)
 	require.Error(t, err)
 }
 
-// --- QueryBookmarksByTags (integration) ---
-// clause contains % wildcards that get consumed by Sprintf. Functional tests
-// are skipped until the bug is fixed in queries.go.
-
-func TestQueryBookmarksByTags_EmptyTags(t *testing.T) {
+func TestQueryBookmarks_UserInputIsNotSQL(t *testing.T) {
 	db, cleanup := newTestDB(t)
 	defer cleanup()
 	defer seedDB(t, db, fixtures.DefaultSeedSet())()
 
-	_, err := QueryBookmarksByTags(context.Background(), "test", []string{}, TagAnd, false, DefaultPagination())
-	require.Error(t, err)
-}
+	_, err := db.Handle.Exec(
+		"INSERT INTO gskbookmarks(URL, metadata, tags) VALUES (?, ?, ?)",
+		"https://quote.com", "it's 100% quoted", ",quote,")
+	require.NoError(t, err)
 
-func TestQueryBookmarksByTags_NilPagination(t *testing.T) {
-	db, cleanup := newTestDB(t)
-	defer cleanup()
-	defer seedDB(t, db, fixtures.DefaultSeedSet())()
+	ctx := context.Background()
+	result, err := NewBookmarkQuery(MatchText("it's", false)).Run(ctx, DefaultPagination())
+	require.NoError(t, err)
+	require.Equal(t, 1, len(result.Bookmarks))
 
-	_, err := QueryBookmarksByTags(context.Background(), "test", []string{"a"}, TagAnd, false, nil)
-	require.Error(t, err)
-}
+	// wildcards are matched literally
+	result, err = NewBookmarkQuery(MatchText("100%", false)).Run(ctx, DefaultPagination())
+	require.NoError(t, err)
+	require.Equal(t, 1, len(result.Bookmarks))
 
-// --- BookmarksByTag (integration) ---
-// contains LIKE wildcards (%%) that get consumed by fmt.Sprintf.
-// Only error-path tests are included until the bug is fixed.
+	result, err = NewBookmarkQuery(MatchText("%", false, FieldURL)).Run(ctx, DefaultPagination())
+	require.NoError(t, err)
+	require.Empty(t, result.Bookmarks)
 
-func TestBookmarksByTag_EmptyTag(t *testing.T) {
-	db, cleanup := newTestDB(t)
-	defer cleanup()
-	defer seedDB(t, db, fixtures.DefaultSeedSet())()
+	injection := "x' OR 1=1; DROP TABLE gskbookmarks; --"
+	result, err = NewBookmarkQuery(MatchText(injection, false), MatchTag(injection, false)).
+		Run(ctx, DefaultPagination())
+	require.NoError(t, err)
+	require.Empty(t, result.Bookmarks)
 
-	_, err := BookmarksByTag(context.Background(), "", DefaultPagination())
-	require.Error(t, err)
+	total, err := db.TotalBookmarks(ctx)
+	require.NoError(t, err)
+	require.Equal(t, uint(6), total)
 }
 
-// --- BookmarksByTags (integration) ---
-// and crashes on nil pagination. Only error-path tests are included.
-
-func TestBookmarksByTags_EmptyTags(t *testing.T) {
-	db, cleanup := newTestDB(t)
-	defer cleanup()
-	defer seedDB(t, db, fixtures.DefaultSeedSet())()
-
-	_, err := BookmarksByTags(context.Background(), []string{}, TagAnd, DefaultPagination())
-	require.Error(t, err)
-}
+// --- BookmarkQuery text and tags (integration) ---
 
 func TestQueryBookmarksByTag_Match(t *testing.T) {
-	//
 	db, cleanup := newTestDB(t)
 	defer cleanup()
 	defer seedDB(t, db, fixtures.TagVarietySet())()
 
-	result, err := QueryBookmarksByTag(context.Background(), "lang", "programming", false, DefaultPagination())
+	result, err := NewBookmarkQuery(
+		MatchText("lang", false, FieldURL, FieldTitle),
+		MatchTag("programming", false),
+	).Run(context.Background(), DefaultPagination())
 	require.NoError(t, err)
 	require.Equal(t, 2, len(result.Bookmarks))
 	require.Equal(t, uint(2), result.Total)
 }
+
 func TestQueryBookmarksByTag_NoMatch(t *testing.T) {
-	//
 	db, cleanup := newTestDB(t)
 	defer cleanup()
 	defer seedDB(t, db, fixtures.DefaultSeedSet())()
 
-	result, err := QueryBookmarksByTag(context.Background(), "nonexistent", "a", false, DefaultPagination())
+	result, err := NewBookmarkQuery(
+		MatchText("nonexistent", false, FieldURL, FieldTitle),
+		MatchTag("a", false),
+	).Run(context.Background(), DefaultPagination())
 	require.NoError(t, err)
 	require.Equal(t, 0, len(result.Bookmarks))
 	require.Equal(t, uint(0), result.Total)
 }
+
 func TestQueryBookmarksByTags_And(t *testing.T) {
-	//
 	db, cleanup := newTestDB(t)
 	defer cleanup()
 	defer seedDB(t, db, fixtures.TagVarietySet())()
 
 	// Linux (linux, os) and GNU (linux, gnu, os) both have "linux" AND "os"
-	result, err := QueryBookmarksByTags(context.Background(), "", []string{"linux", "os"}, TagAnd, false, DefaultPagination())
+	result, err := NewBookmarkQuery(MatchTags(TagAnd, false, "linux", "os")).
+		Run(context.Background(), DefaultPagination())
 	require.NoError(t, err)
 	require.Equal(t, 2, len(result.Bookmarks))
 }
+
 func TestQueryBookmarksByTags_Or(t *testing.T) {
-	//
 	db, cleanup := newTestDB(t)
 	defer cleanup()
 	defer seedDB(t, db, fixtures.TagVarietySet())()
 
 	// Linux and GNU both have "linux" or "os"
-	result, err := QueryBookmarksByTags(context.Background(), "", []string{"linux", "os"}, TagOr, false, DefaultPagination())
+	result, err := NewBookmarkQuery(MatchTags(TagOr, false, "linux", "os")).
+		Run(context.Background(), DefaultPagination())
 	require.NoError(t, err)
 	require.Equal(t, 2, len(result.Bookmarks))
 }
+
 func TestQueryBookmarksByTags_WithQuery(t *testing.T) {
-	//
 	db, cleanup := newTestDB(t)
 	defer cleanup()
 	defer seedDB(t, db, fixtures.TagVarietySet())()
 
-	result, err := QueryBookmarksByTags(context.Background(), "Lang", []string{"programming"}, TagAnd, false, DefaultPagination())
+	result, err := NewBookmarkQuery(
+		MatchText("Lang", false, FieldURL, FieldTitle),
+		MatchTags(TagAnd, false, "programming"),
+	).Run(context.Background(), DefaultPagination())
 	require.NoError(t, err)
 	// "Lang" matches Go Lang and Rust Lang (both have programming tag)
 	require.Equal(t, 2, len(result.Bookmarks))
 }
+
+// --- BookmarkQuery tags only (integration) ---
+
 func TestBookmarksByTag_Match(t *testing.T) {
-	//
 	db, cleanup := newTestDB(t)
 	defer cleanup()
 	defer seedDB(t, db, fixtures.TagVarietySet())()
 
-	result, err := BookmarksByTag(context.Background(), "programming", DefaultPagination())
+	result, err := NewBookmarkQuery(MatchTag("programming", false)).
+		Run(context.Background(), DefaultPagination())
 	require.NoError(t, err)
 	require.Equal(t, 3, len(result.Bookmarks))
 	require.Equal(t, uint(3), result.Total)
 }
+
 func TestBookmarksByTag_NoMatch(t *testing.T) {
-	//
 	db, cleanup := newTestDB(t)
 	defer cleanup()
 	defer seedDB(t, db, fixtures.DefaultSeedSet())()
 
-	result, err := BookmarksByTag(context.Background(), "nonexistent", DefaultPagination())
+	result, err := NewBookmarkQuery(MatchTag("nonexistent", false)).
+		Run(context.Background(), DefaultPagination())
 	require.NoError(t, err)
 	require.Equal(t, 0, len(result.Bookmarks))
 }
+
 func TestBookmarksByTags_And(t *testing.T) {
-	//
 	db, cleanup := newTestDB(t)
 	defer cleanup()
 	defer seedDB(t, db, fixtures.TagVarietySet())()
 
 	// GNU has both "linux" and "gnu" tags
-	result, err := BookmarksByTags(context.Background(), []string{"linux", "gnu"}, TagAnd, DefaultPagination())
+	result, err := NewBookmarkQuery(MatchTags(TagAnd, false, "linux", "gnu")).
+		Run(context.Background(), DefaultPagination())
 	require.NoError(t, err)
 	require.Equal(t, 1, len(result.Bookmarks))
 	require.Equal(t, "GNU", result.Bookmarks[0].Title)
 }
-func TestBookmarksByTags_Or(t *testing.T) {
-	//
+
+// --- BookmarkQuery module and dates (integration) ---
+
+func TestBookmarkQuery_ModuleAndDates(t *testing.T) {
 	db, cleanup := newTestDB(t)
 	defer cleanup()
-	defer seedDB(t, db, fixtures.TagVarietySet())()
+	seed := fixtures.TagVarietySet()
+	seed[0].Module = "firefox"
+	defer seedDB(t, db, seed)()
 
-	// Linux (linux, os) and GNU (linux, gnu, os) match "linux" OR "os"
-	result, err := BookmarksByTags(context.Background(), []string{"linux", "os"}, TagOr, DefaultPagination())
+	ctx := context.Background()
+	result, err := NewBookmarkQuery(MatchModule("firefox")).Run(ctx, DefaultPagination())
 	require.NoError(t, err)
-	require.Equal(t, 2, len(result.Bookmarks))
-}
-func TestBookmarksByTags_NilPagination(t *testing.T) {
-	//
-	db, cleanup := newTestDB(t)
-	defer cleanup()
-	defer seedDB(t, db, fixtures.DefaultSeedSet())()
+	require.Equal(t, 1, len(result.Bookmarks))
+	require.Equal(t, "Go Lang", result.Bookmarks[0].Title)
 
-	_, err := BookmarksByTags(context.Background(), []string{"a"}, TagAnd, nil)
-	require.Error(t, err)
+	// Rust (2000), Python (3000) and Linux (4000)
+	result, err = NewBookmarkQuery(
+		ModifiedBetween(time.Unix(2000, 0), time.Unix(4000, 0)),
+	).Run(ctx, &PaginationParams{Page: 1, Size: -1, SortBy: "modified", SortAsc: true})
+	require.NoError(t, err)
+	require.Equal(t, uint(3), result.Total)
+	require.Equal(t, "Rust Lang", result.Bookmarks[0].Title)
+	require.Equal(t, "Linux", result.Bookmarks[2].Title)
+
+	result, err = NewBookmarkQuery(
+		MatchModule("test"),
+		Not(MatchTag("programming", false)),
+	).Run(ctx, DefaultPagination())
+	require.NoError(t, err)
+	require.Equal(t, 2, len(result.Bookmarks))
 }
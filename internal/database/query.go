@@ -0,0 +1,382 @@
+// Copyright (c) 2024-2025-2025-2025-2025 Chakib Ben Ziane <contact@blob42.xyz>  and [`gosuki` contributors](https://github.com/blob42/gosuki/graphs/contributors).
+// All rights reserved.
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+//
+// This file is part of GoSuki.
+//
+// GoSuki is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// GoSuki is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with gosuki.  If not, see <http://www.gnu.org/licenses/>.
+
+package database
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+)
+
+// Field is a gskbookmarks column searched by a text predicate
+type Field string
+
+const (
+	FieldURL   Field = "URL"
+	FieldTitle Field = "metadata"
+	FieldTags  Field = "tags"
+	FieldDesc  Field = "desc"
+)
+
+// Fields searched by [MatchText] when none is given
+var DefaultTextFields = []Field{FieldURL, FieldTitle, FieldTags}
+
+type TagCond int
+
+const (
+	TagAnd = iota
+	TagOr
+)
+
+// Predicate is a condition on the gskbookmarks table. User input is never part
+// of the clause, it is passed as bound arguments.
+type Predicate struct {
+	clause string
+	args   []any
+
+	// full text query used to rank results by relevance
+	match string
+}
+
+// IsZero reports whether the predicate matches everything
+func (p Predicate) IsZero() bool {
+	return p.clause == ""
+}
+
+func (p Predicate) String() string {
+	return p.clause
+}
+
+// escapes the LIKE wildcards so the text is matched literally
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+func likeArg(s string) string {
+	return "%" + likeEscaper.Replace(s) + "%"
+}
+
+// MatchText matches bookmarks containing text in any of the given fields
+// (URL, title and tags by default). A fuzzy search is used if fuzzy is set.
+func MatchText(text string, fuzzy bool, fields ...Field) Predicate {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return Predicate{}
+	}
+	if len(fields) == 0 {
+		fields = DefaultTextFields
+	}
+
+	preds := make([]Predicate, 0, len(fields))
+	for _, field := range fields {
+		if fuzzy {
+			preds = append(preds, Predicate{
+				clause: "fuzzy(?, " + string(field) + ")",
+				args:   []any{text},
+			})
+		} else if field == FieldTags {
+			preds = append(preds, Predicate{
+				clause: `LOWER(tags) LIKE ? ESCAPE '\'`,
+				args:   []any{likeArg(strings.ToLower(text))},
+			})
+		} else {
+			preds = append(preds, Predicate{
+				clause: string(field) + ` LIKE ? ESCAPE '\'`,
+				args:   []any{likeArg(text)},
+			})
+		}
+	}
+
+	return Or(preds...)
+}
+
+// MatchFullText matches bookmarks containing all the words of text as prefixes
+// in any of the given fields, using the full text index. Results can be ranked
+// with the "relevance" sort. Falls back to [MatchText] when full text search
+// is not available.
+func MatchFullText(text string, fields ...Field) Predicate {
+	if !FullTextEnabled() {
+		return MatchText(text, false, fields...)
+	}
+
+	match := ftsQuery(text, fields...)
+	if match == "" {
+		return MatchText(text, false, fields...)
+	}
+
+	return matchFTS(match)
+}
+
+// MatchPhrase matches bookmarks containing the words of text in sequence in any
+// of the given fields. Falls back to [MatchText] when full text search is not
+// available.
+func MatchPhrase(text string, fields ...Field) Predicate {
+	if !FullTextEnabled() {
+		return MatchText(text, false, fields...)
+	}
+
+	match := ftsPhrase(text, fields...)
+	if match == "" {
+		return MatchText(text, false, fields...)
+	}
+
+	return matchFTS(match)
+}
+
+func matchFTS(match string) Predicate {
+	return Predicate{
+		clause: "id IN (SELECT rowid FROM gskbookmarks_fts WHERE gskbookmarks_fts MATCH ?)",
+		args:   []any{match},
+		match:  match,
+	}
+}
+
+// MatchSite matches bookmarks whose host is domain or one of its subdomains
+func MatchSite(domain string) Predicate {
+	domain = strings.ToLower(strings.TrimSpace(domain))
+	if domain == "" {
+		return Predicate{}
+	}
+	return Predicate{clause: "url_site(URL, ?)", args: []any{domain}}
+}
+
+// MatchTag matches bookmarks tagged with `tag` or one of its aliases, ignoring
+// case. A fuzzy search on the tags is used if fuzzy is set.
+func MatchTag(tag string, fuzzy bool) Predicate {
+	tag = strings.TrimSpace(tag)
+	if tag == "" {
+		return Predicate{}
+	}
+
+	if fuzzy {
+		return Predicate{clause: "fuzzy(?, tags)", args: []any{tag}}
+	}
+	return matchTagName(tag)
+}
+
+// MatchTags matches bookmarks having all (TagAnd) or any (TagOr) of the tags
+func MatchTags(cond TagCond, fuzzy bool, tags ...string) Predicate {
+	preds := make([]Predicate, 0, len(tags))
+	for _, tag := range tags {
+		preds = append(preds, MatchTag(tag, fuzzy))
+	}
+
+	if cond == TagOr {
+		return Or(preds...)
+	}
+	return And(preds...)
+}
+
+// MatchModule matches bookmarks added by the given module or found in one of
+// its browser profiles, see [Source]. A browser module name also matches its
+// flavours and profiles: chrome matches chrome_Default and chrome_brave_Work.
+func MatchModule(module string) Predicate {
+	module = strings.TrimSpace(module)
+	if module == "" {
+		return Predicate{}
+	}
+	return matchModuleName(module)
+}
+
+// MatchKeyword matches bookmarks opened by keyword from the address bar of one
+// of their browser profiles, ignoring case.
+func MatchKeyword(keyword string) Predicate {
+	keyword = strings.TrimSpace(keyword)
+	if keyword == "" {
+		return Predicate{}
+	}
+	return matchKeywordName(keyword)
+}
+
+// ModifiedBetween matches bookmarks modified in the [from, to] interval. A zero
+// time leaves the interval open on that side.
+func ModifiedBetween(from, to time.Time) Predicate {
+	preds := make([]Predicate, 0, 2)
+	if !from.IsZero() {
+		preds = append(preds, Predicate{clause: "modified >= ?", args: []any{from.Unix()}})
+	}
+	if !to.IsZero() {
+		preds = append(preds, Predicate{clause: "modified <= ?", args: []any{to.Unix()}})
+	}
+	return And(preds...)
+}
+
+// CreatedBetween matches bookmarks created in the [from, to] interval. A zero
+// time leaves the interval open on that side.
+func CreatedBetween(from, to time.Time) Predicate {
+	preds := make([]Predicate, 0, 2)
+	if !from.IsZero() {
+		preds = append(preds, Predicate{clause: "created >= ?", args: []any{from.Unix()}})
+	}
+	if !to.IsZero() {
+		preds = append(preds, Predicate{clause: "created <= ?", args: []any{to.Unix()}})
+	}
+	return And(preds...)
+}
+
+func join(op string, preds []Predicate) Predicate {
+	nonZero := make([]Predicate, 0, len(preds))
+	for _, p := range preds {
+		if !p.IsZero() {
+			nonZero = append(nonZero, p)
+		}
+	}
+
+	switch len(nonZero) {
+	case 0:
+		return Predicate{}
+	case 1:
+		return nonZero[0]
+	}
+
+	clauses := make([]string, 0, len(nonZero))
+	args := make([]any, 0, len(nonZero))
+	matches := []string{}
+	for _, p := range nonZero {
+		clauses = append(clauses, "("+p.clause+")")
+		args = append(args, p.args...)
+		if p.match != "" {
+			matches = append(matches, "("+p.match+")")
+		}
+	}
+
+	return Predicate{
+		clause: strings.Join(clauses, " "+op+" "),
+		args:   args,
+		match:  strings.Join(matches, " OR "),
+	}
+}
+
+// And matches bookmarks matching all the predicates
+func And(preds ...Predicate) Predicate {
+	return join("AND", preds)
+}
+
+// Or matches bookmarks matching any of the predicates
+func Or(preds ...Predicate) Predicate {
+	return join("OR", preds)
+}
+
+// Not negates a predicate
+func Not(p Predicate) Predicate {
+	if p.IsZero() {
+		return p
+	}
+	return Predicate{clause: "NOT (" + p.clause + ")", args: p.args}
+}
+
+// BookmarkQuery is a search on the bookmarks of the disk database composed of
+// predicates. Tombstoned bookmarks are always excluded.
+//
+//	q := NewBookmarkQuery(MatchText("golang", false), MatchTags(TagOr, false, "go", "dev"))
+//	result, err := q.Run(ctx, DefaultPagination())
+type BookmarkQuery struct {
+	preds []Predicate
+}
+
+func NewBookmarkQuery(preds ...Predicate) *BookmarkQuery {
+	return &BookmarkQuery{preds: preds}
+}
+
+// Where adds predicates that must all match
+func (q *BookmarkQuery) Where(preds ...Predicate) *BookmarkQuery {
+	q.preds = append(q.preds, preds...)
+	return q
+}
+
+func (q *BookmarkQuery) where() Predicate {
+	return And(append(q.preds, Predicate{clause: WhereNotDeleted})...)
+}
+
+// Build returns the paginated select statement and its arguments.
+//
+// With the "relevance" sort, results are ranked with bm25 against the full
+// text predicates of the query. Queries without full text predicates are
+// sorted by modification time instead.
+func (q *BookmarkQuery) Build(pagination *PaginationParams) (string, []any, error) {
+	if pagination == nil {
+		return "", nil, errors.New("nil: *PaginationParams")
+	}
+
+	where := q.where()
+	stmt := "SELECT * FROM gskbookmarks WHERE " + where.clause +
+		buildOrderBy(pagination)
+	args := where.args
+
+	if pagination.SortBy == SortRelevance {
+		stmt, args = q.buildRanked(where, pagination)
+	}
+
+	stmt += " LIMIT ? OFFSET ?"
+	args = append(args, pagination.Size, (pagination.Page-1)*pagination.Size)
+
+	return stmt, args, nil
+}
+
+func (q *BookmarkQuery) buildRanked(where Predicate, pagination *PaginationParams) (string, []any) {
+	if where.match == "" {
+		return "SELECT * FROM gskbookmarks WHERE " + where.clause +
+				buildOrderBy(&PaginationParams{SortBy: "modified", SortAsc: pagination.SortAsc}),
+			where.args
+	}
+
+	// lower bm25 scores are better matches
+	dir := "ASC"
+	if pagination.SortAsc {
+		dir = "DESC"
+	}
+
+	stmt := "SELECT gskbookmarks.* FROM gskbookmarks LEFT JOIN (" +
+		"SELECT rowid AS fts_id, " + qFTSRank + " AS fts_rank " +
+		"FROM gskbookmarks_fts WHERE gskbookmarks_fts MATCH ?" +
+		") ON fts_id = id WHERE " + where.clause +
+		" ORDER BY fts_rank IS NULL, fts_rank " + dir + ", modified DESC"
+	args := append([]any{where.match}, where.args...)
+
+	return stmt, args
+}
+
+// BuildCount returns the statement counting all matching bookmarks
+func (q *BookmarkQuery) BuildCount() (string, []any) {
+	where := q.where()
+	return "SELECT COUNT(*) FROM gskbookmarks WHERE " + where.clause, where.args
+}
+
+// Run executes the query on the disk database
+func (q *BookmarkQuery) Run(ctx context.Context, pagination *PaginationParams) (*QueryResult, error) {
+	stmt, args, err := q.Build(pagination)
+	if err != nil {
+		return nil, err
+	}
+	log.Trace("query", "stmt", stmt, "args", args)
+
+	rawBooks := RawBookmarks{}
+	if err = DiskDB.Handle.SelectContext(ctx, &rawBooks, stmt, args...); err != nil {
+		return nil, err
+	}
+
+	var total uint
+	countStmt, countArgs := q.BuildCount()
+	if err = DiskDB.Handle.GetContext(ctx, &total, countStmt, countArgs...); err != nil {
+		return nil, err
+	}
+
+	return &QueryResult{rawBooks.AsBookmarks(), total}, nil
+}
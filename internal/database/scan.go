@@ -98,13 +98,17 @@ type RawBookmarks []*RawBookmark
 
 func (raw RawBookmark) AsBookmark() *gosuki.Bookmark {
 	return &gosuki.Bookmark{
+		ID:       raw.ID,
 		URL:      raw.URL,
 		Title:    raw.Metadata,
 		Tags:     tagsFromString(raw.Tags, TagSep).Get(),
 		Desc:     raw.Desc,
 		Module:   raw.Module,
 		Modified: raw.Modified,
+		Created:  raw.Created,
 		Xhsum:    raw.XHSum,
+
+		LinkStatus: raw.LinkStatus,
 	}
 }
 
@@ -129,7 +133,10 @@ type RawBookmark struct {
 	// Last modified
 	Modified uint64
 
-	// kept for buku compat, not used for now
+	// First saved, from the source of the bookmark when known
+	Created uint64
+
+	// kept for buku compat, see [FlagMerged]
 	Flags int
 
 	Module string
@@ -142,4 +149,16 @@ type RawBookmark struct {
 
 	// Node that made the change
 	NodeID UUID `db:"node_id"`
+
+	// Tombstone flag and unix time of deletion
+	Deleted   bool
+	DeletedAt uint64 `db:"deleted_at"`
+
+	// Result of the last link check, see [DB.SetLinkStatus]
+	LinkStatus    int    `db:"link_status"`
+	LinkFinalURL  string `db:"link_final_url"`
+	LinkCheckedAt uint64 `db:"link_checked_at"`
+
+	// Canonical form of the URL, see [CanonicalURL]
+	URLKey string `db:"url_key"`
 }
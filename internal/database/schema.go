@@ -40,9 +40,40 @@ import (
   - Version 4: Added performance indexes:
 	  - Created idx_gskbookmarks_version_node_id composite index
 	    on gskbookmarks(version, node_id) for P2P sync change detection
+  - Version 5: Added bookmark tombstones:
+	  - Added deleted column to gskbookmarks table
+	  - Added deleted_at column to gskbookmarks table
+  - Version 6: Added full text search:
+	  - Created gskbookmarks_fts FTS5 table (when sqlite has FTS5 support)
+	  - Created triggers keeping gskbookmarks_fts in sync with gskbookmarks
+  - Version 7: Added bookmark creation time:
+	  - Added created column to gskbookmarks table, backfilled from modified
+	  - Created gskbookmarks_created trigger defaulting created to modified
+  - Version 8: Added bookmark history:
+	  - Created gskbookmarks_history table recording the revisions of bookmarks
+  - Version 9: Added link checking:
+	  - Added link_status, link_final_url and link_checked_at columns to
+	    gskbookmarks table
+  - Version 10: Added url canonicalization:
+	  - Added url_key column to gskbookmarks table, see [CanonicalURL]
+	  - Created idx_gskbookmarks_url_key index on gskbookmarks(url_key)
+  - Version 11: Added normalized tags:
+	  - Created tags, bookmark_tags and tag_aliases tables
+	  - Created triggers indexing the tags column of gskbookmarks
+  - Version 12: Added bookmark provenance:
+	  - Created bookmark_sources table recording the browser profiles and
+	    folders holding each url
+  - Version 13: Added browser keywords:
+	  - Added keyword column to bookmark_sources table
+  - Version 14: Added browser history:
+	  - Created url_visits table recording the visits of bookmarked urls in
+	    each browser profile
+  - Version 15: Added browser sessions:
+	  - Created session_snapshots and session_tabs tables holding the
+	    windows and tabs open in the browsers
 */
 
-const CurrentSchemaVersion = 4
+const CurrentSchemaVersion = 15
 
 const (
 
@@ -52,6 +83,14 @@ const (
 	// flags: designed to be extended in future using bitwise masks
 	// Masks:
 	//     0b00000001: set title immutable ((do not change title when updating the bookmarks from the web ))
+	//     0b00000010: merged into another bookmark with the same url_key, see [FlagMerged]
+	// deleted: tombstone flag set when the bookmark was removed from its source
+	// deleted_at: time of deletion as unix timestamp
+	// created: time the bookmark was first saved, from the source when known
+	// link_status: http status code of the last link check, see [StatusUnreachable]
+	// link_final_url: url reached after following redirects
+	// link_checked_at: time of the last link check as unix timestamp
+	// url_key: canonical form of the URL used to find duplicates
 	QCreateSchema = `
     CREATE TABLE IF NOT EXISTS gskbookmarks (
 		id INTEGER PRIMARY KEY,
@@ -64,7 +103,14 @@ const (
 		module TEXT DEFAULT '' ,
 		xhsum TEXT DEFAULT '',
 		version INTEGER DEFAULT 0,
-		node_id BLOB
+		node_id BLOB,
+		deleted INTEGER DEFAULT 0,
+		deleted_at INTEGER DEFAULT 0,
+		created INTEGER DEFAULT (strftime('%s')),
+		link_status INTEGER DEFAULT 0,
+		link_final_url TEXT DEFAULT '',
+		link_checked_at INTEGER DEFAULT 0,
+		url_key TEXT DEFAULT ''
 	);
 
 	CREATE TABLE IF NOT EXISTS sync_nodes (
@@ -111,6 +157,49 @@ const (
 	END
 	`
 
+	// Rows inserted without a creation time (ex. buku) use the modified time
+	QCreateCreatedTrigger = `
+	CREATE TRIGGER IF NOT EXISTS gskbookmarks_created
+	AFTER INSERT ON gskbookmarks
+	WHEN new.created IS NULL OR new.created = 0
+	BEGIN
+		UPDATE gskbookmarks SET created = new.modified WHERE id = new.id;
+	END
+	`
+
+	QCreateURLKeyIndex = `
+	CREATE INDEX IF NOT EXISTS idx_gskbookmarks_url_key
+	ON gskbookmarks(url_key)
+	`
+
+	// action: insert, update, delete or restore
+	// old_*, new_*: bookmark fields before and after the change
+	// version: lamport clock of the change, 0 until the change is synced
+	// changed_at: time of the change as unix timestamp
+	QCreateHistory = `
+	CREATE TABLE IF NOT EXISTS gskbookmarks_history (
+		id INTEGER PRIMARY KEY,
+		bookmark_id INTEGER NOT NULL,
+		url TEXT NOT NULL,
+		action TEXT NOT NULL,
+		old_metadata TEXT DEFAULT '',
+		new_metadata TEXT DEFAULT '',
+		old_tags TEXT DEFAULT '',
+		new_tags TEXT DEFAULT '',
+		old_desc TEXT DEFAULT '',
+		new_desc TEXT DEFAULT '',
+		module TEXT DEFAULT '',
+		version INTEGER DEFAULT 0,
+		changed_at INTEGER DEFAULT (strftime('%s'))
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_gskbookmarks_history_bookmark_id
+	ON gskbookmarks_history(bookmark_id);
+
+	CREATE INDEX IF NOT EXISTS idx_gskbookmarks_history_url
+	ON gskbookmarks_history(url)
+	`
+
 	QCreateSchemaVersion = `
 		CREATE TABLE IF NOT EXISTS schema_version (
 			version INTEGER PRIMARY KEY
@@ -233,6 +322,61 @@ func checkDBVersion(db *DB) error {
 					return err
 				}
 				version = 4
+			case 4:
+				if err = db.migrateToVersion5(); err != nil {
+					return err
+				}
+				version = 5
+			case 5:
+				if err = db.migrateToVersion6(); err != nil {
+					return err
+				}
+				version = 6
+			case 6:
+				if err = db.migrateToVersion7(); err != nil {
+					return err
+				}
+				version = 7
+			case 7:
+				if err = db.migrateToVersion8(); err != nil {
+					return err
+				}
+				version = 8
+			case 8:
+				if err = db.migrateToVersion9(); err != nil {
+					return err
+				}
+				version = 9
+			case 9:
+				if err = db.migrateToVersion10(); err != nil {
+					return err
+				}
+				version = 10
+			case 10:
+				if err = db.migrateToVersion11(); err != nil {
+					return err
+				}
+				version = 11
+			case 11:
+				if err = db.migrateToVersion12(); err != nil {
+					return err
+				}
+				version = 12
+			case 12:
+				if err = db.migrateToVersion13(); err != nil {
+					return err
+				}
+				version = 13
+			case 13:
+				if err = db.migrateToVersion14(); err != nil {
+					return err
+				}
+				version = 14
+			case 14:
+				if err = db.migrateToVersion15(); err != nil {
+					return err
+				}
+				version = 15
 			}
 		}
 	}
@@ -243,6 +387,11 @@ func checkDBVersion(db *DB) error {
 		return DBError{DBName: db.Name, Err: err}
 	}
 
+	// the index is missing if FTS5 support was added after the migration
+	if err = db.initFTS(context.Background(), false); err != nil {
+		return fmt.Errorf("full text index: %w", err)
+	}
+
 	log.Debug("schema", "version", version)
 
 	return err
@@ -276,10 +425,50 @@ func (db *DB) InitSchema(ctx context.Context) error {
 		return DBError{DBName: db.Name, Err: err}
 	}
 
+	if _, err = tx.ExecContext(ctx, QCreateCreatedTrigger); err != nil {
+		tx.Rollback()
+		return DBError{DBName: db.Name, Err: err}
+	}
+
+	if _, err = tx.ExecContext(ctx, QCreateHistory); err != nil {
+		tx.Rollback()
+		return DBError{DBName: db.Name, Err: err}
+	}
+
+	if _, err = tx.ExecContext(ctx, QCreateURLKeyIndex); err != nil {
+		tx.Rollback()
+		return DBError{DBName: db.Name, Err: err}
+	}
+
+	if _, err = tx.ExecContext(ctx, QCreateTags); err != nil {
+		tx.Rollback()
+		return DBError{DBName: db.Name, Err: err}
+	}
+
+	if _, err = tx.ExecContext(ctx, QCreateSources); err != nil {
+		tx.Rollback()
+		return DBError{DBName: db.Name, Err: err}
+	}
+
+	if _, err = tx.ExecContext(ctx, QCreateVisits); err != nil {
+		tx.Rollback()
+		return DBError{DBName: db.Name, Err: err}
+	}
+
+	if _, err = tx.ExecContext(ctx, QCreateSessions); err != nil {
+		tx.Rollback()
+		return DBError{DBName: db.Name, Err: err}
+	}
+
 	if err = tx.Commit(); err != nil {
 		return DBError{DBName: db.Name, Err: err}
 	}
 
+	// caches are backed up to disk and must carry the index
+	if err = db.initFTS(ctx, false); err != nil {
+		return err
+	}
+
 	err = checkDBVersion(db)
 	if err != nil {
 		return fmt.Errorf("checking schema version: %w", err)
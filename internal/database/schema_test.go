@@ -86,6 +86,10 @@ func TestSchemaUpgrade(t *testing.T) {
 	)`)
 	require.NoError(t, err, "failed to create old bookmarks table")
 
+	_, err = db.Handle.Exec(
+		"INSERT INTO bookmarks (URL, metadata, tags, modified) VALUES ('https://old.com', 'Old', ',a,b,', 1234)")
+	require.NoError(t, err, "failed to insert old bookmark")
+
 	db.Close()
 
 	//IMP: the db name must be "gosuki_db"
@@ -102,7 +106,9 @@ func TestSchemaUpgrade(t *testing.T) {
 	require.Equal(t, CurrentSchemaVersion, version, "schema version upgrade failed")
 
 	// Verify that the new tables exist after upgrade
-	tables := []string{"gskbookmarks", "bookmarks"}
+	tables := []string{"gskbookmarks", "bookmarks", "gskbookmarks_history",
+		"tags", "bookmark_tags", "tag_aliases", "bookmark_sources", "url_visits",
+		"session_snapshots", "session_tabs"}
 	for _, table := range tables {
 		var count int
 		err = db.Handle.QueryRow("SELECT COUNT(*) FROM " + table).Scan(&count)
@@ -110,6 +116,37 @@ func TestSchemaUpgrade(t *testing.T) {
 		require.GreaterOrEqual(t, count, 0, "table %s should exist after upgrade", table)
 	}
 
+	// v7 backfills the creation time from the modification time
+	var created int64
+	err = db.Handle.QueryRow(
+		"SELECT created FROM gskbookmarks WHERE URL = 'https://old.com'").Scan(&created)
+	require.NoError(t, err, "failed to query created column")
+	require.Equal(t, int64(1234), created)
+
+	// v10 backfills the canonical url
+	var urlKey string
+	err = db.Handle.QueryRow(
+		"SELECT url_key FROM gskbookmarks WHERE URL = 'https://old.com'").Scan(&urlKey)
+	require.NoError(t, err, "failed to query url_key column")
+	require.Equal(t, "old.com", urlKey)
+
+	// v11 indexes the tags of existing bookmarks
+	var tagged int
+	err = db.Handle.QueryRow("SELECT COUNT(*) FROM bookmark_tags").Scan(&tagged)
+	require.NoError(t, err, "failed to query bookmark_tags")
+	require.Equal(t, 2, tagged)
+
+	// v13 adds the keyword column to bookmark_sources tables created by v12
+	_, err = db.Handle.Exec(`DROP TABLE bookmark_sources;
+		CREATE TABLE bookmark_sources (url TEXT NOT NULL, module TEXT NOT NULL,
+			flavour TEXT DEFAULT '', profile TEXT DEFAULT '', folder TEXT DEFAULT '',
+			first_seen INTEGER, last_seen INTEGER, PRIMARY KEY (url, module)) WITHOUT ROWID;
+		UPDATE schema_version SET version = 12`)
+	require.NoError(t, err, "failed to restore v12 bookmark_sources")
+	require.NoError(t, checkDBVersion(db), "failed to upgrade from v12")
+	_, err = db.Handle.Exec("SELECT keyword FROM bookmark_sources")
+	require.NoError(t, err, "failed to query keyword column")
+
 	db.Close()
 	os.Remove(dbPath)
 }
@@ -0,0 +1,276 @@
+//
+// Copyright (c) 2023-2025 Chakib Ben Ziane <contact@blob42.xyz> and [`GoSuki` contributors]
+// (https://github.com/blob42/gosuki/graphs/contributors).
+//
+// All rights reserved.
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+//
+// This file is part of GoSuki.
+//
+// GoSuki is free software: you can redistribute it and/or modify it under the terms of
+// the GNU Affero General Public License as published by the Free Software Foundation,
+// either version 3 of the License, or (at your option) any later version.
+//
+// GoSuki is distributed in the hope that it will be useful, but WITHOUT ANY WARRANTY;
+// without even the implied warranty of MERCHANTABILITY or FITNESS FOR A PARTICULAR
+// PURPOSE.  See the GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License along with
+// gosuki.  If not, see <http://www.gnu.org/licenses/>.
+
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Snapshots of the windows and tabs open in the browsers.
+//
+// source: browser profile the session was read from, ex: firefox_default
+// taken_at: unix time of the snapshot
+// checksum: hash of the open urls, unchanged sessions are not saved again
+var QCreateSessions = `
+	CREATE TABLE IF NOT EXISTS session_snapshots (
+		id INTEGER PRIMARY KEY,
+		source TEXT NOT NULL,
+		taken_at INTEGER NOT NULL DEFAULT (strftime('%s')),
+		checksum TEXT NOT NULL
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_session_snapshots_source
+		ON session_snapshots(source, taken_at);
+
+	CREATE TABLE IF NOT EXISTS session_tabs (
+		snapshot_id INTEGER NOT NULL,
+		window INTEGER NOT NULL,
+		window_name TEXT DEFAULT '',
+		position INTEGER NOT NULL,
+		url TEXT NOT NULL,
+		title TEXT DEFAULT '',
+		PRIMARY KEY (snapshot_id, window, position)
+	) WITHOUT ROWID;
+	`
+
+// Session is a snapshot of the windows open in a browser profile
+type Session struct {
+	ID       int64  `db:"id"`
+	Source   string `db:"source"`
+	TakenAt  uint64 `db:"taken_at"`
+	Checksum string `db:"checksum"`
+
+	// number of tabs, only set when listing sessions
+	Tabs int `db:"tabs"`
+
+	Windows []*SessionWindow `db:"-"`
+}
+
+type SessionWindow struct {
+	// name given to the window by the user, if any
+	Name string
+	Tabs []*SessionTab
+}
+
+// SessionTab is the page shown by a tab
+type SessionTab struct {
+	URL   string `db:"url"`
+	Title string `db:"title"`
+}
+
+// TabCount returns the number of tabs open in all windows
+func (s *Session) TabCount() int {
+	count := 0
+	for _, w := range s.Windows {
+		count += len(w.Tabs)
+	}
+	return count
+}
+
+// WindowName returns the name of the nth window, starting at 0. Unnamed
+// windows are numbered: window-1, window-2...
+func (s *Session) WindowName(n int) string {
+	if name := s.Windows[n].Name; name != "" {
+		return name
+	}
+	return fmt.Sprintf("window-%d", n+1)
+}
+
+// checksum of the urls open in each window. Titles are left out as pages
+// update them all the time (unread counters...).
+func (s *Session) checksum() string {
+	var b strings.Builder
+	for _, w := range s.Windows {
+		b.WriteString(w.Name)
+		for _, tab := range w.Tabs {
+			b.WriteString("\n" + tab.URL)
+		}
+		b.WriteString("\n\n")
+	}
+	return SQLxxHash(b.String())
+}
+
+// SaveSession stores a snapshot of s unless the same urls were open in the
+// last snapshot of its source. It reports whether a snapshot was saved.
+func (db *DB) SaveSession(ctx context.Context, s *Session) (bool, error) {
+	s.Checksum = s.checksum()
+
+	tx, err := db.Handle.BeginTxx(ctx, nil)
+	if err != nil {
+		return false, DBError{DBName: db.Name, Err: err}
+	}
+	defer tx.Rollback()
+
+	var last string
+	err = tx.GetContext(ctx, &last, `
+		SELECT checksum FROM session_snapshots WHERE source = ?
+		ORDER BY taken_at DESC, id DESC LIMIT 1`, s.Source)
+	if err != nil && err != sql.ErrNoRows {
+		return false, DBError{DBName: db.Name, Err: err}
+	}
+	if last == s.Checksum {
+		return false, nil
+	}
+
+	res, err := tx.ExecContext(ctx, `
+		INSERT INTO session_snapshots(source, taken_at, checksum)
+		VALUES (?, CASE WHEN ? > 0 THEN ? ELSE strftime('%s') END, ?)`,
+		s.Source, s.TakenAt, s.TakenAt, s.Checksum)
+	if err != nil {
+		return false, DBError{DBName: db.Name, Err: err}
+	}
+	if s.ID, err = res.LastInsertId(); err != nil {
+		return false, DBError{DBName: db.Name, Err: err}
+	}
+
+	stmt, err := tx.PreparexContext(ctx, `
+		INSERT INTO session_tabs(snapshot_id, window, window_name, position, url, title)
+		VALUES (?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		return false, DBError{DBName: db.Name, Err: err}
+	}
+	defer cleanup(stmt.Close)
+
+	for i, w := range s.Windows {
+		for pos, tab := range w.Tabs {
+			_, err = stmt.ExecContext(ctx, s.ID, i, w.Name, pos, tab.URL, tab.Title)
+			if err != nil {
+				return false, DBError{DBName: db.Name, Err: err}
+			}
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return false, DBError{DBName: db.Name, Err: err}
+	}
+	log.Debug("saved session", "source", s.Source, "id", s.ID, "tabs", s.TabCount())
+
+	return true, nil
+}
+
+// ErrSessionNotFound is returned for unknown session snapshots
+var ErrSessionNotFound = errors.New("session not found")
+
+// Sessions lists the session snapshots, newest first. Only the snapshots of
+// source are listed unless it is empty. Windows are not loaded, see
+// [DB.SessionByID].
+func (db *DB) Sessions(ctx context.Context, source string) ([]*Session, error) {
+	sessions := []*Session{}
+	err := db.Handle.SelectContext(ctx, &sessions, `
+		SELECT s.*, (SELECT count(*) FROM session_tabs WHERE snapshot_id = s.id) AS tabs
+		FROM session_snapshots s
+		WHERE ? = '' OR s.source = ?
+		ORDER BY s.taken_at DESC, s.id DESC`, source, source)
+	if err != nil {
+		return nil, DBError{DBName: db.Name, Err: err}
+	}
+	return sessions, nil
+}
+
+// SessionByID returns the session snapshot id with its windows
+func (db *DB) SessionByID(ctx context.Context, id int64) (*Session, error) {
+	s := &Session{}
+	err := db.Handle.GetContext(ctx, s,
+		"SELECT *, 0 AS tabs FROM session_snapshots WHERE id = ?", id)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("%w: %d", ErrSessionNotFound, id)
+	} else if err != nil {
+		return nil, DBError{DBName: db.Name, Err: err}
+	}
+
+	rows, err := db.Handle.QueryxContext(ctx, `
+		SELECT window, window_name, url, title FROM session_tabs
+		WHERE snapshot_id = ? ORDER BY window, position`, id)
+	if err != nil {
+		return nil, DBError{DBName: db.Name, Err: err}
+	}
+	defer rows.Close()
+
+	last := -1
+	for rows.Next() {
+		var window int
+		var name string
+		tab := &SessionTab{}
+		if err = rows.Scan(&window, &name, &tab.URL, &tab.Title); err != nil {
+			return nil, DBError{DBName: db.Name, Err: err}
+		}
+		if window != last {
+			s.Windows = append(s.Windows, &SessionWindow{Name: name})
+			last = window
+		}
+		w := s.Windows[len(s.Windows)-1]
+		w.Tabs = append(w.Tabs, tab)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, DBError{DBName: db.Name, Err: err}
+	}
+	s.Tabs = s.TabCount()
+
+	return s, nil
+}
+
+// SessionDiff lists the tabs opened and closed between two snapshots
+type SessionDiff struct {
+	Opened []*SessionTab
+	Closed []*SessionTab
+}
+
+// DiffSessions compares the urls open in the snapshots from and to. Tabs
+// moved to another window or position are not reported.
+func DiffSessions(from, to *Session) *SessionDiff {
+	count := func(s *Session) map[string]int {
+		urls := map[string]int{}
+		for _, w := range s.Windows {
+			for _, tab := range w.Tabs {
+				urls[tab.URL]++
+			}
+		}
+		return urls
+	}
+	before, after := count(from), count(to)
+
+	diff := &SessionDiff{}
+	for _, w := range to.Windows {
+		for _, tab := range w.Tabs {
+			if before[tab.URL] > 0 {
+				before[tab.URL]--
+				continue
+			}
+			diff.Opened = append(diff.Opened, tab)
+		}
+	}
+	for _, w := range from.Windows {
+		for _, tab := range w.Tabs {
+			if after[tab.URL] > 0 {
+				after[tab.URL]--
+				continue
+			}
+			diff.Closed = append(diff.Closed, tab)
+		}
+	}
+
+	return diff
+}
@@ -0,0 +1,71 @@
+package database
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSessions(t *testing.T) {
+	ctx := context.Background()
+	db, cleanup := newTestDB(t)
+	defer cleanup()
+
+	first := &Session{Source: "firefox_default", TakenAt: 1000, Windows: []*SessionWindow{
+		{Tabs: []*SessionTab{{URL: "https://a.com", Title: "A"}, {URL: "https://b.com"}}},
+		{Name: "research", Tabs: []*SessionTab{{URL: "https://c.com"}}},
+	}}
+	saved, err := db.SaveSession(ctx, first)
+	require.NoError(t, err)
+	require.True(t, saved)
+
+	// only titles changed
+	same := &Session{Source: "firefox_default", TakenAt: 1100, Windows: []*SessionWindow{
+		{Tabs: []*SessionTab{{URL: "https://a.com", Title: "(1) A"}, {URL: "https://b.com"}}},
+		{Name: "research", Tabs: []*SessionTab{{URL: "https://c.com"}}},
+	}}
+	saved, err = db.SaveSession(ctx, same)
+	require.NoError(t, err)
+	require.False(t, saved, "unchanged sessions are not saved")
+
+	second := &Session{Source: "firefox_default", TakenAt: 1200, Windows: []*SessionWindow{
+		{Tabs: []*SessionTab{{URL: "https://a.com"}, {URL: "https://d.com"}}},
+		{Name: "research", Tabs: []*SessionTab{{URL: "https://c.com"}}},
+	}}
+	saved, err = db.SaveSession(ctx, second)
+	require.NoError(t, err)
+	require.True(t, saved)
+
+	_, err = db.SaveSession(ctx, &Session{Source: "qutebrowser", Windows: []*SessionWindow{
+		{Tabs: []*SessionTab{{URL: "https://e.com"}}},
+	}})
+	require.NoError(t, err)
+
+	sessions, err := db.Sessions(ctx, "firefox_default")
+	require.NoError(t, err)
+	require.Len(t, sessions, 2)
+	require.Equal(t, second.ID, sessions[0].ID, "newest first")
+	require.Equal(t, 3, sessions[0].Tabs)
+
+	sessions, err = db.Sessions(ctx, "")
+	require.NoError(t, err)
+	require.Len(t, sessions, 3)
+
+	loaded, err := db.SessionByID(ctx, first.ID)
+	require.NoError(t, err)
+	require.Equal(t, uint64(1000), loaded.TakenAt)
+	require.Len(t, loaded.Windows, 2)
+	require.Equal(t, "research", loaded.Windows[1].Name)
+	require.Equal(t, "A", loaded.Windows[0].Tabs[0].Title)
+	require.Equal(t, "https://b.com", loaded.Windows[0].Tabs[1].URL)
+
+	_, err = db.SessionByID(ctx, 999)
+	require.ErrorIs(t, err, ErrSessionNotFound)
+
+	diff := DiffSessions(loaded, second)
+	require.Len(t, diff.Opened, 1)
+	require.Equal(t, "https://d.com", diff.Opened[0].URL)
+	require.Len(t, diff.Closed, 1)
+	require.Equal(t, "https://b.com", diff.Closed[0].URL)
+}
@@ -0,0 +1,338 @@
+//
+// Copyright (c) 2023-2025 Chakib Ben Ziane <contact@blob42.xyz> and [`GoSuki` contributors]
+// (https://github.com/blob42/gosuki/graphs/contributors).
+//
+// All rights reserved.
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+//
+// This file is part of GoSuki.
+//
+// GoSuki is free software: you can redistribute it and/or modify it under the terms of
+// the GNU Affero General Public License as published by the Free Software Foundation,
+// either version 3 of the License, or (at your option) any later version.
+//
+// GoSuki is distributed in the hope that it will be useful, but WITHOUT ANY WARRANTY;
+// without even the implied warranty of MERCHANTABILITY or FITNESS FOR A PARTICULAR
+// PURPOSE.  See the GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License along with
+// gosuki.  If not, see <http://www.gnu.org/licenses/>.
+
+package database
+
+import (
+	"context"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/blob42/gosuki"
+)
+
+// module: module instance holding the url, ex: firefox_default
+// folder: folder path of the bookmark in the browser
+// keyword: comma separated address bar keywords of the url in the browser
+// first_seen, last_seen: unix timestamps of the first and last run of the
+// module that found the url
+var QCreateSources = `
+	CREATE TABLE IF NOT EXISTS bookmark_sources (
+		url TEXT NOT NULL,
+		module TEXT NOT NULL,
+		flavour TEXT DEFAULT '',
+		profile TEXT DEFAULT '',
+		folder TEXT DEFAULT '',
+		keyword TEXT DEFAULT '',
+		first_seen INTEGER DEFAULT (strftime('%s')),
+		last_seen INTEGER DEFAULT (strftime('%s')),
+		PRIMARY KEY (url, module)
+	) WITHOUT ROWID;
+
+	CREATE INDEX IF NOT EXISTS idx_bookmark_sources_module
+	ON bookmark_sources(module);
+	`
+
+// Source is a row of bookmark_sources
+type Source struct {
+	URL       string `db:"url"`
+	Module    string
+	Flavour   string
+	Profile   string
+	Folder    string
+	Keyword   string
+	FirstSeen uint64 `db:"first_seen"`
+	LastSeen  uint64 `db:"last_seen"`
+}
+
+func (s *Source) AsSource() gosuki.Source {
+	return gosuki.Source{
+		Module:    s.Module,
+		Flavour:   s.Flavour,
+		Profile:   s.Profile,
+		Folder:    s.Folder,
+		Keyword:   s.Keyword,
+		FirstSeen: s.FirstSeen,
+		LastSeen:  s.LastSeen,
+	}
+}
+
+const qUpsertSource = `
+	INSERT INTO bookmark_sources(url, module, flavour, profile, folder, keyword)
+	VALUES (?, ?, ?, ?, ?, ?)
+	ON CONFLICT(url, module) DO UPDATE SET
+		flavour = excluded.flavour,
+		profile = excluded.profile,
+		folder = excluded.folder,
+		keyword = excluded.keyword,
+		last_seen = strftime('%s')`
+
+// RecordSources records that url was found in the given sources. The last seen
+// time of already known sources is updated.
+func (db *DB) RecordSources(url string, sources ...gosuki.Source) error {
+	if len(sources) == 0 {
+		return nil
+	}
+
+	tx, err := db.Handle.Beginx()
+	if err != nil {
+		return DBError{DBName: db.Name, Err: err}
+	}
+	defer tx.Rollback()
+
+	for _, src := range sources {
+		if src.Module == "" {
+			continue
+		}
+		_, err = tx.Exec(qUpsertSource, url, src.Module, src.Flavour, src.Profile, src.Folder, src.Keyword)
+		if err != nil {
+			return DBError{DBName: db.Name, Err: err}
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return DBError{DBName: db.Name, Err: err}
+	}
+	return nil
+}
+
+// RemoveSources forgets the sources of the given urls. It is called on the
+// buffer of a module with the urls removed from the browser.
+func (db *DB) RemoveSources(urls ...string) error {
+	if len(urls) == 0 {
+		return nil
+	}
+
+	query, args, err := sqlx.In("DELETE FROM bookmark_sources WHERE url IN (?)", urls)
+	if err != nil {
+		return err
+	}
+	if _, err = db.Handle.Exec(query, args...); err != nil {
+		return DBError{DBName: db.Name, Err: err}
+	}
+	return nil
+}
+
+// SourceModules returns the modules holding any of the given urls
+func (db *DB) SourceModules(urls ...string) ([]string, error) {
+	if len(urls) == 0 {
+		return nil, nil
+	}
+
+	query, args, err := sqlx.In(
+		"SELECT DISTINCT module FROM bookmark_sources WHERE url IN (?)", urls)
+	if err != nil {
+		return nil, err
+	}
+
+	modules := []string{}
+	if err = db.Handle.Select(&modules, query, args...); err != nil {
+		return nil, DBError{DBName: db.Name, Err: err}
+	}
+	return modules, nil
+}
+
+// RemoveModuleSources forgets the given urls for the given modules only
+func (db *DB) RemoveModuleSources(modules []string, urls ...string) error {
+	if len(modules) == 0 || len(urls) == 0 {
+		return nil
+	}
+
+	query, args, err := sqlx.In(
+		"DELETE FROM bookmark_sources WHERE module IN (?) AND url IN (?)", modules, urls)
+	if err != nil {
+		return err
+	}
+	if _, err = db.Handle.Exec(query, args...); err != nil {
+		return DBError{DBName: db.Name, Err: err}
+	}
+	return nil
+}
+
+// ModuleURLs returns the urls of the bookmarks not deleted that module holds
+func (db *DB) ModuleURLs(module string) ([]string, error) {
+	urls := []string{}
+	err := db.Handle.Select(&urls,
+		`SELECT DISTINCT s.url FROM bookmark_sources s
+		JOIN gskbookmarks b ON b.URL = s.url
+		WHERE s.module = ? AND b.deleted = 0`, module)
+	if err != nil {
+		return nil, DBError{DBName: db.Name, Err: err}
+	}
+	return urls, nil
+}
+
+// HeldURLs returns the urls, among the given ones, that have a source
+func (db *DB) HeldURLs(urls ...string) ([]string, error) {
+	if len(urls) == 0 {
+		return nil, nil
+	}
+
+	query, args, err := sqlx.In(
+		"SELECT DISTINCT url FROM bookmark_sources WHERE url IN (?)", urls)
+	if err != nil {
+		return nil, err
+	}
+
+	held := []string{}
+	if err = db.Handle.Select(&held, query, args...); err != nil {
+		return nil, DBError{DBName: db.Name, Err: err}
+	}
+	return held, nil
+}
+
+// URLSources returns the browser profiles holding url, most recently seen
+// first
+func (db *DB) URLSources(ctx context.Context, url string) ([]*Source, error) {
+	sources := []*Source{}
+	err := db.Handle.SelectContext(ctx, &sources,
+		"SELECT * FROM bookmark_sources WHERE url = ? ORDER BY last_seen DESC, module", url)
+	if err != nil {
+		return nil, DBError{DBName: db.Name, Err: err}
+	}
+	return sources, nil
+}
+
+// LoadSources fills the Sources field of the given bookmarks
+func (db *DB) LoadSources(ctx context.Context, bookmarks []*gosuki.Bookmark) error {
+	if len(bookmarks) == 0 {
+		return nil
+	}
+
+	byURL := make(map[string]*gosuki.Bookmark, len(bookmarks))
+	urls := make([]string, 0, len(bookmarks))
+	for _, bk := range bookmarks {
+		bk.Sources = nil
+		byURL[bk.URL] = bk
+		urls = append(urls, bk.URL)
+	}
+
+	query, args, err := sqlx.In(
+		"SELECT * FROM bookmark_sources WHERE url IN (?) ORDER BY last_seen DESC, module", urls)
+	if err != nil {
+		return err
+	}
+
+	sources := []*Source{}
+	if err = db.Handle.SelectContext(ctx, &sources, query, args...); err != nil {
+		return DBError{DBName: db.Name, Err: err}
+	}
+
+	for _, src := range sources {
+		if bk, ok := byURL[src.URL]; ok {
+			bk.Sources = append(bk.Sources, src.AsSource())
+		}
+	}
+	return nil
+}
+
+// syncSources replaces the sources of the modules found in src with the ones
+// in src, keeping the earliest first seen time. Modules are only known by the
+// buffer they run in, so src holds all the current sources of its modules.
+// The L1 cache holds the sources of all modules, it replaces all of them.
+func syncSources(src, dst *DB) error {
+	sources := []*Source{}
+	if err := src.Handle.Select(&sources, "SELECT * FROM bookmark_sources"); err != nil {
+		return DBError{DBName: src.Name, Err: err}
+	}
+
+	byModule := map[string][]*Source{}
+	if src.Name == CacheName {
+		modules := []string{}
+		if err := dst.Handle.Select(&modules, "SELECT DISTINCT module FROM bookmark_sources"); err != nil {
+			return DBError{DBName: dst.Name, Err: err}
+		}
+		for _, module := range modules {
+			byModule[module] = nil
+		}
+	}
+	for _, source := range sources {
+		byModule[source.Module] = append(byModule[source.Module], source)
+	}
+	if len(byModule) == 0 {
+		return nil
+	}
+
+	tx, err := dst.Handle.Beginx()
+	if err != nil {
+		return DBError{DBName: dst.Name, Err: err}
+	}
+	defer tx.Rollback()
+
+	for module, sources := range byModule {
+		firstSeen := map[string]uint64{}
+		rows, err := tx.Queryx(
+			"SELECT url, first_seen FROM bookmark_sources WHERE module = ?", module)
+		if err != nil {
+			return DBError{DBName: dst.Name, Err: err}
+		}
+		for rows.Next() {
+			var url string
+			var seen uint64
+			if err = rows.Scan(&url, &seen); err != nil {
+				rows.Close()
+				return DBError{DBName: dst.Name, Err: err}
+			}
+			firstSeen[url] = seen
+		}
+		rows.Close()
+
+		if _, err = tx.Exec("DELETE FROM bookmark_sources WHERE module = ?", module); err != nil {
+			return DBError{DBName: dst.Name, Err: err}
+		}
+
+		for _, source := range sources {
+			if seen, ok := firstSeen[source.URL]; ok && seen < source.FirstSeen {
+				source.FirstSeen = seen
+			}
+			_, err = tx.NamedExec(`
+				INSERT INTO bookmark_sources(url, module, flavour, profile, folder, keyword, first_seen, last_seen)
+				VALUES (:url, :module, :flavour, :profile, :folder, :keyword, :first_seen, :last_seen)`,
+				source,
+			)
+			if err != nil {
+				return DBError{DBName: dst.Name, Err: err}
+			}
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return DBError{DBName: dst.Name, Err: err}
+	}
+	return nil
+}
+
+// matches bookmarks created by module or found in one of its instances
+const qMatchModule = "module = ? OR URL IN (SELECT url FROM bookmark_sources " +
+	`WHERE module = ? OR module LIKE ? ESCAPE '\' OR flavour = ?)`
+
+func matchModuleName(module string) Predicate {
+	instances := likeEscaper.Replace(module) + "\\_%"
+	return Predicate{clause: qMatchModule, args: []any{module, module, instances, module}}
+}
+
+// matches bookmarks having keyword in the keyword list of one of their sources
+const qMatchKeyword = "URL IN (SELECT url FROM bookmark_sources " +
+	`WHERE ',' || keyword || ',' LIKE ? ESCAPE '\')`
+
+func matchKeywordName(keyword string) Predicate {
+	return Predicate{clause: qMatchKeyword, args: []any{"%," + likeEscaper.Replace(keyword) + ",%"}}
+}
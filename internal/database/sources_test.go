@@ -0,0 +1,178 @@
+package database
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/blob42/gosuki"
+)
+
+func newSourceBuffer(t *testing.T, module, flavour, profile, folder string, urls ...string) *DB {
+	t.Helper()
+	buffer := getBuffer(t)
+	for _, url := range urls {
+		require.NoError(t, buffer.UpsertBookmark(&Bookmark{
+			URL:    url,
+			Module: module,
+			Sources: []gosuki.Source{{
+				Module: module, Flavour: flavour, Profile: profile, Folder: folder,
+			}},
+		}))
+	}
+	return buffer
+}
+
+func TestBookmarkSources(t *testing.T) {
+	ctx := context.Background()
+
+	cache, err := NewDB("test_sources", "", DBTypeCacheDSN).Init()
+	require.NoError(t, err)
+	require.NoError(t, cache.InitSchema(ctx))
+	defer cache.Close()
+
+	firefox := newSourceBuffer(t, "firefox_default", "firefox", "default", "Work/Docs",
+		"https://a.com", "https://b.com")
+	chrome := newSourceBuffer(t, "chrome_brave_Work", "brave", "Work", "",
+		"https://a.com")
+	firefox.SyncTo(cache)
+	chrome.SyncTo(cache)
+
+	sources, err := cache.URLSources(ctx, "https://a.com")
+	require.NoError(t, err)
+	require.Len(t, sources, 2)
+	modules := []string{sources[0].Module, sources[1].Module}
+	require.ElementsMatch(t, []string{"firefox_default", "chrome_brave_Work"}, modules)
+	for _, src := range sources {
+		require.NotZero(t, src.FirstSeen)
+		if src.Module == "firefox_default" {
+			require.Equal(t, "Work/Docs", src.Folder)
+			require.Equal(t, "default", src.Profile)
+		}
+	}
+
+	t.Run("sources are replaced by the module buffer", func(t *testing.T) {
+		_, err := cache.Handle.Exec(
+			"UPDATE bookmark_sources SET first_seen = 100 WHERE module = 'firefox_default'")
+		require.NoError(t, err)
+
+		require.NoError(t, firefox.RemoveSources("https://b.com"))
+		require.NoError(t, firefox.RecordSources("https://a.com",
+			gosuki.Source{Module: "firefox_default", Folder: "Personal"}))
+		firefox.SyncTo(cache)
+
+		sources, err := cache.URLSources(ctx, "https://b.com")
+		require.NoError(t, err)
+		require.Empty(t, sources)
+
+		sources, err = cache.URLSources(ctx, "https://a.com")
+		require.NoError(t, err)
+		require.Len(t, sources, 2)
+		for _, src := range sources {
+			if src.Module == "firefox_default" {
+				require.Equal(t, "Personal", src.Folder)
+				require.Equal(t, uint64(100), src.FirstSeen, "first seen time is kept")
+			}
+		}
+	})
+
+	t.Run("load sources", func(t *testing.T) {
+		bookmarks := []*gosuki.Bookmark{{URL: "https://a.com"}, {URL: "https://b.com"}}
+		require.NoError(t, cache.LoadSources(ctx, bookmarks))
+		require.Len(t, bookmarks[0].Sources, 2)
+		require.Empty(t, bookmarks[1].Sources)
+	})
+
+	t.Run("the L1 cache replaces the sources of all modules", func(t *testing.T) {
+		l1, err := NewDB(CacheName, "", DBTypeInMemoryDSN).Init()
+		require.NoError(t, err)
+		require.NoError(t, l1.InitSchema(ctx))
+		defer l1.Close()
+
+		firefox.SyncTo(l1)
+		l1.SyncTo(cache)
+		require.NoError(t, l1.RemoveModuleSources([]string{"firefox_default"}, "https://a.com"))
+		held, err := l1.HeldURLs("https://a.com", "https://b.com")
+		require.NoError(t, err)
+		require.Empty(t, held)
+
+		l1.SyncTo(cache)
+		sources, err := cache.URLSources(ctx, "https://a.com")
+		require.NoError(t, err)
+		require.Empty(t, sources)
+	})
+}
+
+func TestMatchModuleSources(t *testing.T) {
+	db, cleanup := newTestDB(t)
+	defer cleanup()
+
+	for _, bk := range []*Bookmark{
+		{URL: "https://a.com", Module: "firefox_default", Sources: []gosuki.Source{
+			{Module: "firefox_default", Flavour: "firefox"},
+			{Module: "chrome_brave_Work", Flavour: "brave"},
+		}},
+		{URL: "https://b.com", Module: "qutebrowser", Sources: []gosuki.Source{
+			{Module: "qutebrowser"},
+		}},
+		{URL: "https://c.com", Module: "chromium"},
+	} {
+		require.NoError(t, db.UpsertBookmark(bk))
+	}
+
+	for module, want := range map[string][]string{
+		"chrome":            {"https://a.com"},
+		"brave":             {"https://a.com"},
+		"chrome_brave_Work": {"https://a.com"},
+		"qutebrowser":       {"https://b.com"},
+		"chromium":          {"https://c.com"},
+		"firefox_def":       {},
+	} {
+		result, err := NewBookmarkQuery(MatchModule(module)).Run(context.Background(), DefaultPagination())
+		require.NoError(t, err)
+		urls := []string{}
+		for _, bk := range result.Bookmarks {
+			urls = append(urls, bk.URL)
+		}
+		require.ElementsMatch(t, want, urls, "module:%s", module)
+	}
+}
+
+func TestMatchKeyword(t *testing.T) {
+	db, cleanup := newTestDB(t)
+	defer cleanup()
+
+	for _, bk := range []*Bookmark{
+		{URL: "https://go.dev", Module: "firefox_default", Sources: []gosuki.Source{
+			{Module: "firefox_default", Keyword: "go,golang"},
+		}},
+		{URL: "https://github.com", Module: "firefox_work", Sources: []gosuki.Source{
+			{Module: "firefox_work", Keyword: "gh"},
+		}},
+		{URL: "https://gitlab.com", Module: "chrome_Default"},
+	} {
+		require.NoError(t, db.UpsertBookmark(bk))
+	}
+
+	sources, err := db.URLSources(context.Background(), "https://github.com")
+	require.NoError(t, err)
+	require.Len(t, sources, 1)
+	require.Equal(t, "gh", sources[0].Keyword)
+
+	for keyword, want := range map[string][]string{
+		"golang": {"https://go.dev"},
+		"Go":     {"https://go.dev"},
+		"gh":     {"https://github.com"},
+		"g":      {},
+		"go,gol": {},
+	} {
+		result, err := NewBookmarkQuery(MatchKeyword(keyword)).Run(context.Background(), DefaultPagination())
+		require.NoError(t, err)
+		urls := []string{}
+		for _, bk := range result.Bookmarks {
+			urls = append(urls, bk.URL)
+		}
+		require.ElementsMatch(t, want, urls, "keyword:%s", keyword)
+	}
+}
@@ -56,12 +56,14 @@ package database
 
 
 import (
+	"database/sql"
 	"fmt"
 	"strconv"
 	"sync"
 	"sync/atomic"
 	"time"
 
+	"github.com/gofrs/uuid"
 	"github.com/jmoiron/sqlx"
 	sqlite3 "github.com/mattn/go-sqlite3"
 
@@ -116,6 +118,9 @@ It performs the following steps:
  5. Commits transactions for both insert and update phases
  6. If dst is a memcache, schedules a disk backup after completion
 
+Changes written to the L2 cache are recorded in the bookmark history with the
+values before and after the change. See [Revision].
+
 The synchronization uses SQLite transactions for consistency and handles
 duplicate URL constraints by comparing hash values. Tags are merged and
 normalized during updates. Lamport clock is used to maintain versioning
@@ -127,7 +132,9 @@ Parameters:
 
 Behavior:
 - When syncing to L2 cache, increments the version field on successful inserts
-- Merges tags from both source and destination when updating existing entries
+- Merges tags from both source and destination when updating existing
+entries. The tags, title and description of an authoritative source, like the
+L1 cache, replace the ones of the destination instead.
 - Normalizes merged tags by sorting them alphabetically
 - Only updates entries when there are actual changes in metadata, tags, or
 description
@@ -138,12 +145,14 @@ func (src *DB) SyncToClock(dst *DB, remoteClock uint64) {
 	var err error
 	var sqlite3Err sqlite3.Error
 	var isSqlErr bool
-	var existingUrls = make(map[uint64]*RawBookmark)
+	var existingUrls = make(map[string]existingBookmark)
 
 	log.Debugf("syncing <%s> to <%s>", src.Name, dst.Name)
 	cacheMu.Lock()
 	defer cacheMu.Unlock()
 
+	replace := src.Authoritative || src.Name == CacheName
+
 	getSourceTable, err := src.Handle.Preparex(`SELECT * FROM gskbookmarks`)
 	defer func() {
 		err = getSourceTable.Close()
@@ -166,10 +175,13 @@ func (src *DB) SyncToClock(dst *DB, remoteClock uint64) {
 			module,
 			xhsum,
 			version,
-			node_id
-			
+			node_id,
+			deleted,
+			deleted_at,
+			created,
+			url_key
 		)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
 	)
 	if err != nil {
 		log.Error("prepare stmt", "err", err)
@@ -193,17 +205,23 @@ func (src *DB) SyncToClock(dst *DB, remoteClock uint64) {
 			module,
 			xhsum,
 			version,
-			node_id
+			node_id,
+			deleted,
+			deleted_at,
+			created
 		) = (
-			CASE WHEN ? != '' THEN ? ELSE metadata END,
+			CASE WHEN ? OR ? != '' THEN ? ELSE metadata END,
 			?,
-			CASE WHEN ? != '' THEN ? ELSE desc END,
+			CASE WHEN ? OR ? != '' THEN ? ELSE desc END,
 			strftime('%s'),
 			?,
 			?,
 			?,
 			?,
-			?
+			?,
+			?,
+			?,
+			CASE WHEN ? > 0 AND ? < created THEN ? ELSE created END
 		)
 		WHERE url=? 
 		`,
@@ -231,8 +249,8 @@ func (src *DB) SyncToClock(dst *DB, remoteClock uint64) {
 		return
 	}
 
-	getDstTagsStmt, err := dst.Handle.Preparex(
-		`SELECT tags FROM gskbookmarks WHERE url=? LIMIT 1`,
+	getDstStmt, err := dst.Handle.Preparex(
+		`SELECT id, metadata, tags, desc, deleted, deleted_at, flags FROM gskbookmarks WHERE url=? LIMIT 1`,
 	)
 
 	// Start syncing all entries from source table
@@ -247,7 +265,8 @@ func (src *DB) SyncToClock(dst *DB, remoteClock uint64) {
 		}
 
 		// Try to insert to row in dst table
-		_, err = dstTx.Stmtx(tryInsertDstRow).Exec(
+		var res sql.Result
+		res, err = dstTx.Stmtx(tryInsertDstRow).Exec(
 			scan.URL,
 			scan.Metadata,
 			scan.Tags,
@@ -262,6 +281,10 @@ func (src *DB) SyncToClock(dst *DB, remoteClock uint64) {
 			),
 			remoteClock,
 			scan.NodeID,
+			scan.Deleted,
+			scan.DeletedAt,
+			scan.Created,
+			CanonicalURL(scan.URL),
 		)
 
 		isSqlErr = false
@@ -279,29 +302,52 @@ func (src *DB) SyncToClock(dst *DB, remoteClock uint64) {
 
 			// check original hash of bookmark
 			var oldBkHash xxhashsum
-			err = dstTx.QueryRowx("SELECT xhsum FROM gskbookmarks WHERE url = ?", scan.URL).Scan(&oldBkHash)
+			var oldCreated uint64
+			err = dstTx.QueryRowx("SELECT xhsum, created FROM gskbookmarks WHERE url = ?", scan.URL).
+				Scan(&oldBkHash, &oldCreated)
 			if err != nil {
 				log.Error("select xhsum from", "src", L2Cache.Name, "url", scan.URL, "err", err)
 				continue
 
 			}
 
-			existingUrls[uint64(oldBkHash)] = &scan
+			existingUrls[scan.URL] = existingBookmark{uint64(oldBkHash), oldCreated, &scan}
 
 			// insertion success on l2 cache, update clock
 		} else if err == nil && dst.Name == L2CacheName {
 			log.Trace("inserted", "url", scan.URL, "tags", scan.Tags)
-			hooksQueue <- hooks.HookJob{
-				Book: scan.AsBookmark(),
-				Kind: hooks.GlobalInsertHook,
+			if !scan.Deleted {
+				queueHook(hooks.HookJob{
+					Book: scan.AsBookmark(),
+					Kind: hooks.GlobalInsertHook,
+				})
 			}
 
+			version := Clock.Tick(remoteClock)
 			_, err = dstTx.Exec("UPDATE gskbookmarks SET version = ? WHERE URL = ?",
-				Clock.Tick(remoteClock), scan.URL)
+				version, scan.URL)
 			if err != nil {
 				log.Error("insert:clock-inc", "err", err)
 				dstTx.Rollback()
 			}
+
+			id, _ := res.LastInsertId()
+			rev := &Revision{
+				BookmarkID: uint64(id),
+				URL:        scan.URL,
+				Action:     HistoryInsert,
+				NewTitle:   scan.Metadata,
+				NewTags:    scan.Tags,
+				NewDesc:    scan.Desc,
+				Module:     scan.Module,
+				Version:    version,
+			}
+			if scan.Deleted {
+				rev.Action = HistoryDelete
+			}
+			if err = recordRevision(dstTx, rev); err != nil {
+				log.Error("insert:history", "url", scan.URL, "err", err)
+			}
 		}
 	}
 
@@ -316,14 +362,39 @@ func (src *DB) SyncToClock(dst *DB, remoteClock uint64) {
 	}
 
 	// Loop performing the update for each existing bookmark
-	for hash, scan := range existingUrls {
-		var tags string
+	for _, existing := range existingUrls {
+		hash, scan := existing.hash, existing.scan
+		var id uint64
+		var title, tags, desc string
+		var dstDeleted bool
+		var dstDeletedAt uint64
+		var dstFlags int
 		//log.Debugf("updating existing %s", scan.Url)
 
-		if err = dstTx.Stmtx(getDstTagsStmt).Get(&tags, scan.URL); err != nil {
+		err = dstTx.Stmtx(getDstStmt).QueryRowx(scan.URL).
+			Scan(&id, &title, &tags, &desc, &dstDeleted, &dstDeletedAt, &dstFlags)
+		if err != nil {
 			log.Error("get tags query", "err", err)
 		}
 
+		// a module still holding a bookmark deleted elsewhere does not
+		// restore it, only a source that found the url again does
+		if dstDeleted && !scan.Deleted && src.Name != CacheName {
+			readded, err := src.isReadded(scan.URL, dstDeletedAt)
+			if err != nil {
+				log.Error("checking sources", "url", scan.URL, "err", err)
+			} else if !readded {
+				scan.Deleted, scan.DeletedAt = true, dstDeletedAt
+			}
+		}
+
+		// duplicates merged into another bookmark are not restored by
+		// sources still holding them
+		if dstFlags&FlagMerged != 0 && scan.Flags&FlagMerged == 0 {
+			log.Trace("merged duplicate, skipping", "url", scan.URL)
+			continue
+		}
+
 		srcTags := tagsFromString(scan.Tags, TagSep).Sort()
 		dstTags := tagsFromString(tags, TagSep).Sort()
 
@@ -331,8 +402,10 @@ func (src *DB) SyncToClock(dst *DB, remoteClock uint64) {
 		for _, v := range srcTags.tags {
 			tagMap[v] = true
 		}
-		for _, v := range dstTags.tags {
-			tagMap[v] = true
+		if !replace {
+			for _, v := range dstTags.tags {
+				tagMap[v] = true
+			}
 		}
 
 		newTags := &Tags{delim: TagSep} //merged tags
@@ -342,10 +415,27 @@ func (src *DB) SyncToClock(dst *DB, remoteClock uint64) {
 		newTagsStr := newTags.Sort().StringWrap()
 		newHash := xhsum(scan.URL, scan.Metadata, newTagsStr, scan.Desc)
 
-		if strconv.FormatUint(hash, 10) == newHash {
+		// tombstones are propagated even when the content did not change
+		if strconv.FormatUint(hash, 10) == newHash && dstDeleted == scan.Deleted {
+
+			// an earlier creation time reported by the source is not a
+			// change of the bookmark: no new version and same modified time
+			if scan.Created > 0 && scan.Created < existing.created {
+				_, err = dstTx.Exec("UPDATE gskbookmarks SET created = ? WHERE url = ?",
+					scan.Created, scan.URL)
+				if err != nil {
+					log.Error("update created", "url", scan.URL, "err", err)
+				}
+			}
 			continue
 		}
 
+		// merging tags of both sides is a change made by this node
+		nodeID := scan.NodeID
+		if newTagsStr != srcTags.StringWrap() {
+			nodeID = UUID(uuid.Nil)
+		}
+
 		clock := remoteClock
 
 		if dst.Name == L2CacheName {
@@ -353,25 +443,39 @@ func (src *DB) SyncToClock(dst *DB, remoteClock uint64) {
 		}
 
 		_, err = dstTx.Stmtx(updateDstRow).Exec(
+			replace,
 			scan.Metadata,
 			scan.Metadata,
 			newTagsStr,
+			replace,
 			scan.Desc,
 			scan.Desc,
-			0, //flags
+			scan.Flags,
 			scan.Module,
 			newHash,
 			clock,
-			scan.NodeID,
+			nodeID,
+			scan.Deleted,
+			scan.DeletedAt,
+			scan.Created,
+			scan.Created,
+			scan.Created,
 			scan.URL,
 		)
 
+		if err == nil && dst.Name == L2CacheName {
+			err = recordSyncedUpdate(dstTx, id, title, tags, desc, dstDeleted,
+				scan, newTagsStr, clock)
+		}
+
 		if err != nil {
 			log.Errorf("%s: %s", err, scan.URL)
+		} else if scan.Deleted {
+			log.Trace("tombstoned", "url", scan.URL)
 			// update success
 		} else {
 			log.Trace("updated", "url", scan.URL, "tags", newTagsStr)
-			hooksQueue <- hooks.HookJob{
+			queueHook(hooks.HookJob{
 				Book: &gosuki.Bookmark{
 					URL:    scan.URL,
 					Title:  scan.Metadata,
@@ -380,7 +484,7 @@ func (src *DB) SyncToClock(dst *DB, remoteClock uint64) {
 					Module: scan.Module,
 				},
 				Kind: hooks.GlobalUpdateHook,
-			}
+			})
 		}
 
 		log.Debugf("synced %s to %s", scan.URL, dst.Name)
@@ -392,12 +496,93 @@ func (src *DB) SyncToClock(dst *DB, remoteClock uint64) {
 		log.Error("sync:commit", "err", err)
 	}
 
+	if err = syncSources(src, dst); err != nil {
+		log.Error("sync:sources", "err", err)
+	}
+
 	// If we are syncing to memcache, schedule a write to disk
 	if dst.Name == CacheName {
 		ScheduleBackupToDisk()
 	}
 }
 
+// isReadded reports whether url, tombstoned at deletedAt, was found again by
+// one of the sources of the buffer src. Buffers holding no source for url, such
+// as the api and p2p buffers, always restore it.
+func (src *DB) isReadded(url string, deletedAt uint64) (bool, error) {
+	var firstSeen []uint64
+	err := src.Handle.Select(&firstSeen,
+		"SELECT first_seen FROM bookmark_sources WHERE url = ?", url)
+	if err != nil {
+		return false, DBError{DBName: src.Name, Err: err}
+	}
+	if len(firstSeen) == 0 {
+		return true, nil
+	}
+
+	for _, seen := range firstSeen {
+		if seen > deletedAt {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// recordSyncedUpdate records the update of an existing bookmark in the
+// history and assigns the version of the update to the revisions recorded
+// since the last sync.
+func recordSyncedUpdate(
+	tx *sqlx.Tx,
+	id uint64,
+	title, tags, desc string,
+	deleted bool,
+	scan *RawBookmark,
+	newTags string,
+	version uint64,
+) error {
+	rev := &Revision{
+		BookmarkID: id,
+		URL:        scan.URL,
+		Action:     HistoryUpdate,
+		OldTitle:   title,
+		NewTitle:   title,
+		OldTags:    tags,
+		NewTags:    newTags,
+		OldDesc:    desc,
+		NewDesc:    desc,
+		Module:     scan.Module,
+		Version:    version,
+	}
+
+	// empty fields do not overwrite the existing ones
+	if scan.Metadata != "" {
+		rev.NewTitle = scan.Metadata
+	}
+	if scan.Desc != "" {
+		rev.NewDesc = scan.Desc
+	}
+
+	switch {
+	case scan.Deleted && !deleted:
+		rev.Action = HistoryDelete
+	case !scan.Deleted && deleted:
+		rev.Action = HistoryInsert
+	}
+
+	if _, err := tx.Exec(qSetPendingVersion, version, scan.URL); err != nil {
+		return err
+	}
+
+	return recordRevision(tx, rev)
+}
+
+// bookmark already present in the destination of a sync with its original hash
+type existingBookmark struct {
+	hash    uint64
+	created uint64
+	scan    *RawBookmark
+}
+
 var (
 	syncQueue  chan any
 	hooksQueue chan hooks.HookJob
@@ -465,6 +650,16 @@ func ScheduleBackupToDisk() {
 	}()
 }
 
+// queueHook sends a job to the hooks scheduler. Jobs are dropped when the
+// schedulers are not running (ie. the daemon is not started).
+func queueHook(job hooks.HookJob) {
+	if hooksQueue == nil {
+		log.Trace("hooks scheduler not running, dropping", "kind", job.Kind)
+		return
+	}
+	hooksQueue <- job
+}
+
 func startSchedulers() {
 	syncQueue = make(chan any)
 	hooksQueue = make(chan hooks.HookJob, 100)
@@ -644,5 +839,11 @@ func (src *DB) SyncToCache() error {
 		log.Debugf("syncing <%s> to cache", src.Name)
 		src.SyncTo(Cache.DB)
 	}
+
+	// tombstones reached the cache. Keeping them in the buffer would delete
+	// the bookmark again after another module restores it.
+	if _, err = src.Handle.Exec("DELETE FROM gskbookmarks WHERE deleted = 1"); err != nil {
+		return DBError{DBName: src.Name, Err: err}
+	}
 	return nil
 }
@@ -316,6 +316,8 @@ func TestSyncToDisk(t *testing.T) {
 	}
 
 	for i, bm := range bookmarks {
+		// the canonical url is computed when inserting
+		bm.URLKey = CanonicalURL(bm.URL)
 		require.Equal(t, bm, dstBookmarks[i])
 	}
 }
@@ -0,0 +1,423 @@
+//
+// Copyright (c) 2023-2025 Chakib Ben Ziane <contact@blob42.xyz> and [`GoSuki` contributors]
+// (https://github.com/blob42/gosuki/graphs/contributors).
+//
+// All rights reserved.
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+//
+// This file is part of GoSuki.
+//
+// GoSuki is free software: you can redistribute it and/or modify it under the terms of
+// the GNU Affero General Public License as published by the Free Software Foundation,
+// either version 3 of the License, or (at your option) any later version.
+//
+// GoSuki is distributed in the hope that it will be useful, but WITHOUT ANY WARRANTY;
+// without even the implied warranty of MERCHANTABILITY or FITNESS FOR A PARTICULAR
+// PURPOSE.  See the GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License along with
+// gosuki.  If not, see <http://www.gnu.org/licenses/>.
+
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/gofrs/uuid"
+	"github.com/jmoiron/sqlx"
+
+	"github.com/blob42/gosuki"
+)
+
+// Normalized tag storage.
+//
+// The `tags` column of gskbookmarks stays the source of truth and keeps the
+// buku format (`,tag1,tag2,`). The `tags` and `bookmark_tags` tables are an
+// index of it maintained by triggers, used to match exact tags and count
+// them. Tag names are case insensitive.
+//
+// Aliases map a tag to another one. They are applied to the tags of incoming
+// bookmarks and when searching by tag. An alias to the empty tag drops it.
+
+// returns the tags of a `,tag1,tag2,` column as a json_each table. The string
+// is turned into a json array, values that are not valid json are ignored.
+func tagValues(col string) string {
+	array := fmt.Sprintf(
+		`'["' || replace(replace(replace(trim(%s, ','), '\', '\\'), '"', '\"'), ',', '","') || '"]'`,
+		col,
+	)
+	return fmt.Sprintf("json_each(CASE WHEN json_valid(%[1]s) THEN %[1]s ELSE '[]' END)", array)
+}
+
+var (
+	QCreateTags = fmt.Sprintf(`
+	CREATE TABLE IF NOT EXISTS tags (
+		id INTEGER PRIMARY KEY,
+		name TEXT NOT NULL UNIQUE COLLATE NOCASE
+	);
+
+	CREATE TABLE IF NOT EXISTS bookmark_tags (
+		bookmark_id INTEGER NOT NULL,
+		tag_id INTEGER NOT NULL,
+		PRIMARY KEY (bookmark_id, tag_id)
+	) WITHOUT ROWID;
+
+	CREATE INDEX IF NOT EXISTS idx_bookmark_tags_tag_id
+	ON bookmark_tags(tag_id);
+
+	CREATE TABLE IF NOT EXISTS tag_aliases (
+		alias TEXT PRIMARY KEY COLLATE NOCASE,
+		tag TEXT NOT NULL
+	);
+
+	CREATE TRIGGER IF NOT EXISTS gskbookmarks_tags_insert
+	AFTER INSERT ON gskbookmarks
+	BEGIN
+		INSERT OR IGNORE INTO tags(name)
+		SELECT value FROM %[1]s WHERE trim(value) != '';
+		INSERT OR IGNORE INTO bookmark_tags(bookmark_id, tag_id)
+		SELECT new.id, tags.id FROM %[1]s JOIN tags ON tags.name = value;
+	END;
+
+	CREATE TRIGGER IF NOT EXISTS gskbookmarks_tags_update
+	AFTER UPDATE OF tags ON gskbookmarks
+	BEGIN
+		DELETE FROM bookmark_tags WHERE bookmark_id = old.id;
+		INSERT OR IGNORE INTO tags(name)
+		SELECT value FROM %[1]s WHERE trim(value) != '';
+		INSERT OR IGNORE INTO bookmark_tags(bookmark_id, tag_id)
+		SELECT new.id, tags.id FROM %[1]s JOIN tags ON tags.name = value;
+	END;
+
+	CREATE TRIGGER IF NOT EXISTS gskbookmarks_tags_delete
+	AFTER DELETE ON gskbookmarks
+	BEGIN
+		DELETE FROM bookmark_tags WHERE bookmark_id = old.id;
+	END;
+	`, tagValues("new.tags"))
+
+	// fills the tag index from existing bookmarks
+	QIndexTags = fmt.Sprintf(`
+	INSERT OR IGNORE INTO tags(name)
+	SELECT value FROM gskbookmarks, %[1]s WHERE trim(value) != '';
+
+	INSERT OR IGNORE INTO bookmark_tags(bookmark_id, tag_id)
+	SELECT gskbookmarks.id, tags.id FROM gskbookmarks, %[1]s
+	JOIN tags ON tags.name = value;
+	`, tagValues("gskbookmarks.tags"))
+)
+
+// module recorded in the history of bookmarks changed by tag operations
+const tagsModule = "tags"
+
+// TagCount is a tag with the number of bookmarks using it
+type TagCount struct {
+	Name  string
+	Count uint
+
+	// Aliases of this tag
+	Aliases []string
+}
+
+// TagCounts returns the tags of non deleted bookmarks, most used first
+func (db *DB) TagCounts(ctx context.Context) ([]*TagCount, error) {
+	counts := []*TagCount{}
+	err := db.Handle.SelectContext(ctx, &counts, `
+		SELECT tags.name AS name, COUNT(*) AS count
+		FROM tags
+		JOIN bookmark_tags ON bookmark_tags.tag_id = tags.id
+		JOIN gskbookmarks ON gskbookmarks.id = bookmark_tags.bookmark_id
+		WHERE `+WhereNotDeleted+`
+		GROUP BY tags.id
+		ORDER BY count DESC, name`,
+	)
+	if err != nil {
+		return nil, DBError{DBName: db.Name, Err: err}
+	}
+
+	aliases, err := db.TagAliases(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	byName := make(map[string]*TagCount, len(counts))
+	for _, tc := range counts {
+		byName[strings.ToLower(tc.Name)] = tc
+	}
+	for alias, tag := range aliases {
+		if tc, ok := byName[strings.ToLower(tag)]; ok {
+			tc.Aliases = append(tc.Aliases, alias)
+		}
+	}
+	for _, tc := range counts {
+		slices.Sort(tc.Aliases)
+	}
+
+	return counts, nil
+}
+
+// TagAliases returns the aliases mapped to their tag
+func (db *DB) TagAliases(ctx context.Context) (map[string]string, error) {
+	var rows []struct {
+		Alias string
+		Tag   string
+	}
+	if err := db.Handle.SelectContext(ctx, &rows, "SELECT alias, tag FROM tag_aliases"); err != nil {
+		return nil, DBError{DBName: db.Name, Err: err}
+	}
+
+	aliases := make(map[string]string, len(rows))
+	for _, row := range rows {
+		aliases[row.Alias] = row.Tag
+	}
+	return aliases, nil
+}
+
+var ErrInvalidAlias = errors.New("invalid tag alias")
+
+// SetTagAlias maps alias to tag for incoming bookmarks and tag searches.
+// Existing bookmarks are not changed, use [DB.MergeTags] for that. An empty
+// tag drops the alias from incoming bookmarks.
+func (db *DB) SetTagAlias(ctx context.Context, alias, tag string) error {
+	alias, tag = strings.TrimSpace(alias), strings.TrimSpace(tag)
+	if alias == "" || strings.EqualFold(alias, tag) || strings.Contains(alias+tag, TagSep) {
+		return ErrInvalidAlias
+	}
+
+	tx, err := db.Handle.BeginTxx(ctx, nil)
+	if err != nil {
+		return DBError{DBName: db.Name, Err: err}
+	}
+	defer tx.Rollback()
+
+	if err = setTagAlias(ctx, tx, alias, tag); err != nil {
+		return DBError{DBName: db.Name, Err: err}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return DBError{DBName: db.Name, Err: err}
+	}
+	return db.loadAliases(ctx)
+}
+
+func setTagAlias(ctx context.Context, tx *sqlx.Tx, alias, tag string) error {
+	// aliases pointing to the alias follow it
+	_, err := tx.ExecContext(ctx,
+		"UPDATE tag_aliases SET tag = ? WHERE tag = ? COLLATE NOCASE", tag, alias)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO tag_aliases(alias, tag) VALUES (?, ?)
+		ON CONFLICT(alias) DO UPDATE SET tag = excluded.tag`,
+		alias, tag,
+	)
+	if err != nil {
+		return err
+	}
+
+	// a tag cannot be an alias of itself
+	_, err = tx.ExecContext(ctx, "DELETE FROM tag_aliases WHERE alias = tag")
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// RemoveTagAlias deletes an alias
+func (db *DB) RemoveTagAlias(ctx context.Context, alias string) error {
+	res, err := db.Handle.ExecContext(ctx, "DELETE FROM tag_aliases WHERE alias = ?", alias)
+	if err != nil {
+		return DBError{DBName: db.Name, Err: err}
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return ErrInvalidAlias
+	}
+	return db.loadAliases(ctx)
+}
+
+// MergeTags replaces the `from` tags with `into` in all bookmarks and makes
+// them aliases of `into`, so they are also replaced in incoming bookmarks. It
+// is used to rename a tag. Returns the number of changed bookmarks.
+func (db *DB) MergeTags(ctx context.Context, into string, from ...string) (int, error) {
+	into = strings.TrimSpace(into)
+	if into == "" || strings.Contains(into, TagSep) {
+		return 0, ErrInvalidAlias
+	}
+	return db.replaceTags(ctx, into, from)
+}
+
+// DeleteTag removes tag from all bookmarks and drops it from incoming
+// bookmarks. Returns the number of changed bookmarks.
+func (db *DB) DeleteTag(ctx context.Context, tag string) (int, error) {
+	return db.replaceTags(ctx, "", []string{tag})
+}
+
+// replaces the `from` tags with `into`, or removes them if into is empty
+func (db *DB) replaceTags(ctx context.Context, into string, from []string) (int, error) {
+	replaced := map[string]bool{}
+	for _, tag := range from {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag != "" && tag != strings.ToLower(into) {
+			replaced[tag] = true
+		}
+	}
+	if len(replaced) == 0 {
+		return 0, nil
+	}
+
+	tx, err := db.Handle.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, DBError{DBName: db.Name, Err: err}
+	}
+	defer tx.Rollback()
+
+	names := make([]any, 0, len(replaced))
+	for tag := range replaced {
+		names = append(names, tag)
+	}
+
+	raws := []*RawBookmark{}
+	err = tx.SelectContext(ctx, &raws, `
+		SELECT * FROM gskbookmarks WHERE id IN (
+			SELECT bookmark_id FROM bookmark_tags
+			JOIN tags ON tags.id = bookmark_tags.tag_id
+			WHERE tags.name IN (?`+strings.Repeat(", ?", len(names)-1)+`)
+		)`,
+		names...,
+	)
+	if err != nil {
+		return 0, DBError{DBName: db.Name, Err: err}
+	}
+
+	version := nextLocalVersion()
+	for _, raw := range raws {
+		tags := &Tags{delim: TagSep}
+		for _, tag := range tagsFromString(raw.Tags, TagSep).Get() {
+			if replaced[strings.ToLower(tag)] {
+				tag = into
+			}
+			if tag != "" && !slices.ContainsFunc(tags.Get(), func(t string) bool {
+				return strings.EqualFold(t, tag)
+			}) {
+				tags.Add(tag)
+			}
+		}
+		newTags := tags.Sort().StringWrap()
+
+		_, err = tx.ExecContext(ctx, `
+			UPDATE gskbookmarks
+			SET tags = ?, xhsum = ?, modified = strftime('%s'), version = ?, node_id = ?
+			WHERE id = ?`,
+			newTags, xhsum(raw.URL, raw.Metadata, newTags, raw.Desc),
+			version, UUID(uuid.Nil), raw.ID,
+		)
+		if err != nil {
+			return 0, DBError{DBName: db.Name, Err: err}
+		}
+
+		err = recordRevision(tx, &Revision{
+			BookmarkID: raw.ID,
+			URL:        raw.URL,
+			Action:     HistoryUpdate,
+			OldTitle:   raw.Metadata,
+			NewTitle:   raw.Metadata,
+			OldTags:    raw.Tags,
+			NewTags:    newTags,
+			OldDesc:    raw.Desc,
+			NewDesc:    raw.Desc,
+			Module:     tagsModule,
+			Version:    version,
+		})
+		if err != nil {
+			return 0, DBError{DBName: db.Name, Err: err}
+		}
+	}
+
+	// tag names keep the case they were first seen with
+	if into != "" {
+		_, err = tx.ExecContext(ctx, "UPDATE tags SET name = ? WHERE name = ?", into, into)
+		if err != nil {
+			return 0, DBError{DBName: db.Name, Err: err}
+		}
+	}
+
+	for tag := range replaced {
+		if err = setTagAlias(ctx, tx, tag, into); err != nil {
+			return 0, DBError{DBName: db.Name, Err: err}
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return 0, DBError{DBName: db.Name, Err: err}
+	}
+
+	return len(raws), db.loadAliases(ctx)
+}
+
+// aliases applied to incoming bookmarks, keyed by lower case alias
+var tagAliases = struct {
+	sync.RWMutex
+	m map[string]string
+}{m: map[string]string{}}
+
+// LoadTagAliases loads the aliases applied to incoming bookmarks from db
+func LoadTagAliases(ctx context.Context, db *DB) error {
+	return db.loadAliases(ctx)
+}
+
+func (db *DB) loadAliases(ctx context.Context) error {
+	aliases, err := db.TagAliases(ctx)
+	if err != nil {
+		return err
+	}
+
+	m := make(map[string]string, len(aliases))
+	for alias, tag := range aliases {
+		m[strings.ToLower(alias)] = tag
+	}
+
+	tagAliases.Lock()
+	tagAliases.m = m
+	tagAliases.Unlock()
+	return nil
+}
+
+// applyTagAliases replaces aliases with their tag and removes dropped tags
+func applyTagAliases(tags []string) []string {
+	tagAliases.RLock()
+	defer tagAliases.RUnlock()
+
+	if len(tagAliases.m) == 0 {
+		return tags
+	}
+
+	result := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		if target, ok := tagAliases.m[strings.ToLower(strings.TrimSpace(tag))]; ok {
+			tag = target
+		}
+		if tag != "" && !slices.Contains(result, tag) {
+			result = append(result, tag)
+		}
+	}
+	return result
+}
+
+// matches bookmarks tagged with tag, one of its sub folders or one of its
+// aliases
+const qMatchTag = "id IN (SELECT bookmark_id FROM bookmark_tags JOIN tags ON tags.id = tag_id " +
+	`WHERE tags.name = ? OR tags.name LIKE ? ESCAPE '\' ` +
+	"OR tags.name IN (SELECT alias FROM tag_aliases WHERE tag = ? COLLATE NOCASE))"
+
+func matchTagName(tag string) Predicate {
+	subFolders := likeEscaper.Replace(strings.TrimSuffix(tag, gosuki.FolderSep)) + gosuki.FolderSep + "%"
+	return Predicate{clause: qMatchTag, args: []any{tag, subFolders, tag}}
+}
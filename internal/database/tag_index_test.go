@@ -0,0 +1,178 @@
+package database
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func tagURLs(t *testing.T, tag string) []string {
+	t.Helper()
+	result, err := NewBookmarkQuery(MatchTag(tag, false)).
+		Run(context.Background(), DefaultPagination())
+	require.NoError(t, err)
+
+	urls := []string{}
+	for _, bk := range result.Bookmarks {
+		urls = append(urls, bk.URL)
+	}
+	return urls
+}
+
+func TestTagIndex(t *testing.T) {
+	db, cleanup := newTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	for _, bk := range []*Bookmark{
+		{URL: "https://go.dev", Tags: []string{"go", "Lang"}, Module: "chrome"},
+		{URL: "https://golang.org", Tags: []string{"golang"}, Module: "chrome"},
+		{URL: "https://djangoproject.com", Tags: []string{"django", "lang"}, Module: "firefox"},
+	} {
+		require.NoError(t, db.UpsertBookmark(bk))
+	}
+
+	t.Run("tags match whole names ignoring case", func(t *testing.T) {
+		require.Equal(t, []string{"https://go.dev"}, tagURLs(t, "go"))
+		require.Equal(t, []string{"https://go.dev"}, tagURLs(t, "GO"))
+		require.ElementsMatch(t,
+			[]string{"https://go.dev", "https://djangoproject.com"}, tagURLs(t, "lang"))
+	})
+
+	t.Run("folder tags match their sub folders", func(t *testing.T) {
+		for _, bk := range []*Bookmark{
+			{URL: "https://work.com/docs", Tags: []string{"Work/ProjectA/Docs"}, Module: "chrome"},
+			{URL: "https://home.com/docs", Tags: []string{"Personal/Docs"}, Module: "chrome"},
+			{URL: "https://workshop.com", Tags: []string{"Workshop"}, Module: "chrome"},
+		} {
+			require.NoError(t, db.UpsertBookmark(bk))
+		}
+
+		require.Equal(t, []string{"https://work.com/docs"}, tagURLs(t, "work"))
+		require.Equal(t, []string{"https://work.com/docs"}, tagURLs(t, "Work/ProjectA"))
+		require.Equal(t, []string{"https://home.com/docs"}, tagURLs(t, "Personal/Docs"))
+		require.Empty(t, tagURLs(t, "Docs"))
+
+		_, err := db.Handle.Exec(
+			"DELETE FROM gskbookmarks WHERE URL IN ('https://work.com/docs', 'https://home.com/docs', 'https://workshop.com')")
+		require.NoError(t, err)
+	})
+
+	t.Run("tag index follows updates and deletes", func(t *testing.T) {
+		require.NoError(t, db.UpsertBookmark(&Bookmark{
+			URL: "https://golang.org", Tags: []string{"lang"}, Module: "chrome",
+		}))
+		require.Len(t, tagURLs(t, "lang"), 3)
+
+		_, err := db.Handle.Exec(
+			"UPDATE gskbookmarks SET deleted = 1 WHERE URL = 'https://djangoproject.com'")
+		require.NoError(t, err)
+
+		counts, err := db.TagCounts(ctx)
+		require.NoError(t, err)
+		require.Equal(t, "Lang", counts[0].Name, "first seen case is kept")
+		require.Equal(t, uint(2), counts[0].Count)
+		for _, tc := range counts {
+			require.NotEqual(t, "django", tc.Name)
+		}
+
+		_, err = db.Handle.Exec("DELETE FROM gskbookmarks WHERE URL = 'https://djangoproject.com'")
+		require.NoError(t, err)
+		var n int
+		require.NoError(t, db.Handle.Get(&n, "SELECT COUNT(*) FROM bookmark_tags"))
+		require.Equal(t, 4, n)
+	})
+}
+
+func TestMergeTags(t *testing.T) {
+	db, cleanup := newTestDB(t)
+	defer cleanup()
+	defer LoadTagAliases(context.Background(), db)
+
+	ctx := context.Background()
+	for _, bk := range []*Bookmark{
+		{URL: "https://a.com", Tags: []string{"js", "web"}, Module: "chrome"},
+		{URL: "https://b.com", Tags: []string{"JavaScript"}, Module: "chrome"},
+		{URL: "https://c.com", Tags: []string{"ecmascript", "js"}, Module: "chrome"},
+	} {
+		require.NoError(t, db.UpsertBookmark(bk))
+	}
+
+	n, err := db.MergeTags(ctx, "javascript", "js", "ecmascript")
+	require.NoError(t, err)
+	require.Equal(t, 2, n)
+
+	a, err := db.BookmarkByURL(ctx, "https://a.com")
+	require.NoError(t, err)
+	require.Equal(t, ",javascript,web,", a.Tags)
+	c, err := db.BookmarkByURL(ctx, "https://c.com")
+	require.NoError(t, err)
+	require.Equal(t, ",javascript,", c.Tags)
+
+	revs := []*Revision{}
+	require.NoError(t, db.Handle.Select(&revs,
+		"SELECT * FROM gskbookmarks_history WHERE url = 'https://a.com' ORDER BY id DESC"))
+	require.Equal(t, tagsModule, revs[0].Module)
+	require.Equal(t, ",js,web,", revs[0].OldTags)
+
+	counts, err := db.TagCounts(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "javascript", counts[0].Name)
+	require.Equal(t, uint(3), counts[0].Count)
+	require.Equal(t, []string{"ecmascript", "js"}, counts[0].Aliases)
+
+	// browsers syncing the old tag get the new one
+	require.NoError(t, db.UpsertBookmark(&Bookmark{
+		URL: "https://d.com", Tags: []string{"JS", "node"}, Module: "firefox",
+	}))
+	d, err := db.BookmarkByURL(ctx, "https://d.com")
+	require.NoError(t, err)
+	require.Equal(t, ",javascript,node,", d.Tags)
+
+	t.Run("delete", func(t *testing.T) {
+		n, err := db.DeleteTag(ctx, "web")
+		require.NoError(t, err)
+		require.Equal(t, 1, n)
+
+		require.NoError(t, db.UpsertBookmark(&Bookmark{
+			URL: "https://e.com", Tags: []string{"web", "css"}, Module: "firefox",
+		}))
+		e, err := db.BookmarkByURL(ctx, "https://e.com")
+		require.NoError(t, err)
+		require.Equal(t, ",css,", e.Tags)
+	})
+}
+
+func TestTagAliases(t *testing.T) {
+	db, cleanup := newTestDB(t)
+	defer cleanup()
+	defer LoadTagAliases(context.Background(), db)
+
+	ctx := context.Background()
+	require.NoError(t, db.UpsertBookmark(&Bookmark{
+		URL: "https://a.com", Tags: []string{"k8s"}, Module: "chrome",
+	}))
+
+	require.ErrorIs(t, db.SetTagAlias(ctx, "k8s", "K8S"), ErrInvalidAlias)
+	require.ErrorIs(t, db.SetTagAlias(ctx, "a,b", "c"), ErrInvalidAlias)
+	require.NoError(t, db.SetTagAlias(ctx, "k8s", "kubernetes"))
+
+	// existing bookmarks are found through the alias
+	require.Equal(t, []string{"https://a.com"}, tagURLs(t, "kubernetes"))
+
+	require.NoError(t, db.UpsertBookmark(&Bookmark{
+		URL: "https://b.com", Tags: []string{"k8s"}, Module: "chrome",
+	}))
+	b, err := db.BookmarkByURL(ctx, "https://b.com")
+	require.NoError(t, err)
+	require.Equal(t, ",kubernetes,", b.Tags)
+
+	aliases, err := db.TagAliases(ctx)
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"k8s": "kubernetes"}, aliases)
+
+	require.NoError(t, db.RemoveTagAlias(ctx, "k8s"))
+	require.ErrorIs(t, db.RemoveTagAlias(ctx, "k8s"), ErrInvalidAlias)
+	require.Equal(t, []string{"https://b.com"}, tagURLs(t, "kubernetes"))
+}
@@ -0,0 +1,129 @@
+//
+// Copyright (c) 2023-2025 Chakib Ben Ziane <contact@blob42.xyz> and [`GoSuki` contributors]
+// (https://github.com/blob42/gosuki/graphs/contributors).
+//
+// All rights reserved.
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+//
+// This file is part of GoSuki.
+//
+// GoSuki is free software: you can redistribute it and/or modify it under the terms of
+// the GNU Affero General Public License as published by the Free Software Foundation,
+// either version 3 of the License, or (at your option) any later version.
+//
+// GoSuki is distributed in the hope that it will be useful, but WITHOUT ANY WARRANTY;
+// without even the implied warranty of MERCHANTABILITY or FITNESS FOR A PARTICULAR
+// PURPOSE.  See the GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License along with
+// gosuki.  If not, see <http://www.gnu.org/licenses/>.
+
+package database
+
+import (
+	"context"
+	"fmt"
+)
+
+// source: browser profile the visits were read from, ex: firefox_default
+// visit_count: number of visits of the url in the profile
+// last_visit: unix timestamp of the last visit
+var QCreateVisits = `
+	CREATE TABLE IF NOT EXISTS url_visits (
+		url TEXT NOT NULL,
+		source TEXT NOT NULL,
+		visit_count INTEGER DEFAULT 0,
+		last_visit INTEGER DEFAULT 0,
+		PRIMARY KEY (url, source)
+	) WITHOUT ROWID;
+	`
+
+// Visit is the browsing history of a url in a browser profile
+type Visit struct {
+	URL       string `db:"url"`
+	Count     uint64 `db:"visit_count"`
+	LastVisit uint64 `db:"last_visit"` // unix seconds
+}
+
+// SortFrecency ranks bookmarks by how often and how recently they were
+// visited, see [DB.SetVisits]
+const SortFrecency = "frecency"
+
+// Score of a bookmark for the "frecency" sort: the visits of the url in all the
+// browser profiles, weighted by the age of the last visit.
+const qFrecency = `(SELECT ifnull(sum(visit_count), 0) * CASE
+		WHEN max(last_visit) >= strftime('%s') - 4 * 86400 THEN 100
+		WHEN max(last_visit) >= strftime('%s') - 14 * 86400 THEN 70
+		WHEN max(last_visit) >= strftime('%s') - 31 * 86400 THEN 50
+		WHEN max(last_visit) >= strftime('%s') - 90 * 86400 THEN 30
+		ELSE 10 END
+	FROM url_visits WHERE url_visits.url = gskbookmarks.URL)`
+
+// SetVisits replaces the visits read from the history of source. Only the
+// visits of bookmarked urls are kept. It returns the number of visits stored.
+func (db *DB) SetVisits(ctx context.Context, source string, visits []*Visit) (int, error) {
+	tx, err := db.Handle.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, DBError{DBName: db.Name, Err: err}
+	}
+	defer tx.Rollback()
+
+	var urls []string
+	err = tx.SelectContext(ctx, &urls, "SELECT URL FROM gskbookmarks WHERE "+WhereNotDeleted)
+	if err != nil {
+		return 0, DBError{DBName: db.Name, Err: err}
+	}
+	bookmarked := make(map[string]bool, len(urls))
+	for _, url := range urls {
+		bookmarked[url] = true
+	}
+
+	if _, err = tx.ExecContext(ctx, "DELETE FROM url_visits WHERE source = ?", source); err != nil {
+		return 0, DBError{DBName: db.Name, Err: err}
+	}
+
+	stmt, err := tx.PreparexContext(ctx, `
+		INSERT INTO url_visits(url, source, visit_count, last_visit)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT(url, source) DO UPDATE SET
+			visit_count = visit_count + excluded.visit_count,
+			last_visit = max(last_visit, excluded.last_visit)`,
+	)
+	if err != nil {
+		return 0, DBError{DBName: db.Name, Err: err}
+	}
+	defer cleanup(stmt.Close)
+
+	stored := 0
+	for _, v := range visits {
+		if !bookmarked[v.URL] || v.Count == 0 {
+			continue
+		}
+		if _, err = stmt.ExecContext(ctx, v.URL, source, v.Count, v.LastVisit); err != nil {
+			return 0, DBError{DBName: db.Name, Err: err}
+		}
+		stored++
+	}
+
+	if err = tx.Commit(); err != nil {
+		return 0, DBError{DBName: db.Name, Err: err}
+	}
+	log.Debug("stored visits", "source", source, "count", stored, "db", db.Name)
+
+	return stored, nil
+}
+
+// URLVisits returns the total visits of url in all browser profiles and the
+// time of the last one
+func (db *DB) URLVisits(ctx context.Context, url string) (*Visit, error) {
+	visit := &Visit{URL: url}
+	err := db.Handle.QueryRowxContext(ctx, `
+		SELECT ifnull(sum(visit_count), 0), ifnull(max(last_visit), 0)
+		FROM url_visits WHERE url = ?`, url,
+	).Scan(&visit.Count, &visit.LastVisit)
+	if err != nil {
+		return nil, DBError{DBName: db.Name, Err: fmt.Errorf("visits of %s: %w", url, err)}
+	}
+	return visit, nil
+}
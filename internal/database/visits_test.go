@@ -0,0 +1,67 @@
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestVisits(t *testing.T) {
+	ctx := context.Background()
+	db, cleanup := newTestDB(t)
+	defer cleanup()
+
+	for _, url := range []string{"https://a.com", "https://b.com", "https://c.com", "https://d.com"} {
+		require.NoError(t, db.UpsertBookmark(&Bookmark{URL: url, Module: "test"}))
+	}
+
+	now := uint64(time.Now().Unix())
+	lastMonth := now - 60*86400
+
+	stored, err := db.SetVisits(ctx, "firefox_default", []*Visit{
+		{URL: "https://a.com", Count: 3, LastVisit: now},
+		{URL: "https://b.com", Count: 10, LastVisit: lastMonth},
+		{URL: "https://not-bookmarked.com", Count: 100, LastVisit: now},
+	})
+	require.NoError(t, err)
+	require.Equal(t, 2, stored, "only bookmarked urls are stored")
+
+	_, err = db.SetVisits(ctx, "chrome_Default", []*Visit{
+		{URL: "https://c.com", Count: 2, LastVisit: now},
+		{URL: "https://a.com", Count: 2, LastVisit: lastMonth},
+	})
+	require.NoError(t, err)
+
+	visit, err := db.URLVisits(ctx, "https://a.com")
+	require.NoError(t, err)
+	require.Equal(t, uint64(5), visit.Count, "visits of all the profiles are summed")
+	require.Equal(t, now, visit.LastVisit)
+
+	frecent := func() []string {
+		result, err := NewBookmarkQuery().Run(ctx, &PaginationParams{Page: 1, Size: -1, SortBy: SortFrecency})
+		require.NoError(t, err)
+		urls := []string{}
+		for _, bk := range result.Bookmarks {
+			urls = append(urls, bk.URL)
+		}
+		return urls
+	}
+
+	// a: 5 * 100, b: 10 * 30, c: 2 * 100, d: never visited
+	require.Equal(t, []string{"https://a.com", "https://b.com", "https://c.com", "https://d.com"}, frecent())
+
+	t.Run("visits of a source are replaced", func(t *testing.T) {
+		_, err := db.SetVisits(ctx, "firefox_default", []*Visit{
+			{URL: "https://d.com", Count: 50, LastVisit: now},
+		})
+		require.NoError(t, err)
+
+		visit, err := db.URLVisits(ctx, "https://a.com")
+		require.NoError(t, err)
+		require.Equal(t, uint64(2), visit.Count)
+
+		require.Equal(t, []string{"https://d.com", "https://c.com", "https://a.com", "https://b.com"}, frecent())
+	})
+}
@@ -1,4 +1,7 @@
-// Script to generate a json file containg 1000 random bookmark.
+// Script to generate a Chrome Bookmarks json file containing random bookmarks.
+//
+//	go run ./internal/scripts/gen-chrome-bookmarks.go -amt 50000 -o Bookmarks
+//
 // The json file format is as following:
 //
 //	{
@@ -87,15 +90,37 @@ import (
 
 const QuantKnob = 5000
 
+// fields are in the order written by Chrome
 type Bookmark struct {
+	Children     []Bookmark        `json:"children,omitempty"`
 	DateAdded    string            `json:"date_added"`
+	DateModified string            `json:"date_modified,omitempty"`
+	GUID         string            `json:"guid"`
 	ID           string            `json:"id"`
 	MetaInfo     map[string]string `json:"meta_info,omitempty"`
 	Name         string            `json:"name"`
 	Type         string            `json:"type"`
 	URL          string            `json:"url,omitempty"`
-	Children     []Bookmark        `json:"children,omitempty"`
-	DateModified string            `json:"date_modified,omitempty"`
+}
+
+var lastID = 2
+
+func nextID() string {
+	lastID++
+	return fmt.Sprintf("%d", lastID)
+}
+
+func randomGUID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+// microseconds since Jan 1 1601
+func chromeTime() string {
+	return fmt.Sprintf("%d", time.Now().UnixMicro()+11644473600000000)
 }
 
 func randomString(n int) string {
@@ -107,32 +132,32 @@ func randomString(n int) string {
 	return string(b)
 }
 
-func randomBookmark() Bookmark {
+// randomBookmark returns a url or a folder using up to *urls urls
+func randomBookmark(urls *int) Bookmark {
 	bookmark := Bookmark{
-		DateAdded: fmt.Sprintf("%d", time.Now().UnixNano()),
-		ID:        fmt.Sprintf("%d", rand.Intn(10000)),
+		DateAdded: chromeTime(),
+		GUID:      randomGUID(),
+		ID:        nextID(),
 		Name:      randomString(10),
 	}
 
 	if rand.Float64() < 0.5 {
 		bookmark.Type = "url"
 		bookmark.URL = "https://" + randomString(10) + ".com"
+		*urls--
 	} else {
 		bookmark.Type = "folder"
+		bookmark.DateModified = chromeTime()
 		var children []Bookmark
-		for i := 0; i < rand.Intn(5); i++ {
-			children = append(children, randomBookmark())
+		for i := 0; i < rand.Intn(5) && *urls > 0; i++ {
+			children = append(children, randomBookmark(urls))
 		}
 		bookmark.Children = children
 	}
 
-	if rand.Float64() < 0.5 {
-		bookmark.DateModified = fmt.Sprintf("%d", time.Now().UnixNano())
-	}
-
 	if rand.Float64() < 0.5 {
 		bookmark.MetaInfo = map[string]string{
-			"last_visited_desktop": fmt.Sprintf("%d", time.Now().UnixNano()),
+			"last_visited_desktop": chromeTime(),
 		}
 	}
 
@@ -143,12 +168,13 @@ func main() {
 
 	var bookmarks []Bookmark
 
-	bkAmount := flag.Int("amt", QuantKnob, "approximate amount of urls to generate")
+	bkAmount := flag.Int("amt", QuantKnob, "amount of urls to generate")
+	output := flag.String("o", "", "output file, defaults to stdout")
 
 	flag.Parse()
 
-	for i := 0; i < *bkAmount; i++ {
-		bookmarks = append(bookmarks, randomBookmark())
+	for urls := *bkAmount; urls > 0; {
+		bookmarks = append(bookmarks, randomBookmark(&urls))
 	}
 
 	data := map[string]interface{}{
@@ -156,14 +182,16 @@ func main() {
 		"roots": map[string]interface{}{
 			"bookmark_bar": Bookmark{
 				DateAdded:    "13152359615589278",
+				GUID:         "0bc5d13f-2cba-5d74-951f-3f233fe6c908",
 				ID:           "1",
 				Name:         "Bookmarks bar",
 				Type:         "folder",
 				Children:     bookmarks,
-				DateModified: fmt.Sprintf("%d", time.Now().UnixNano()),
+				DateModified: chromeTime(),
 			},
-			"other_bookmarks": Bookmark{
+			"other": Bookmark{
 				DateAdded:    "13152359615589283",
+				GUID:         "82b081ec-3dd3-529c-8475-ab6c344590dd",
 				ID:           "2",
 				Name:         "Other bookmarks",
 				Type:         "folder",
@@ -173,7 +201,21 @@ func main() {
 		"version": 1,
 	}
 
-	enc := json.NewEncoder(os.Stdout)
+	out := os.Stdout
+	if *output != "" {
+		f, err := os.Create(*output)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		defer f.Close()
+		out = f
+	}
+
+	enc := json.NewEncoder(out)
 	enc.SetIndent("", " ")
-	enc.Encode(data)
+	if err := enc.Encode(data); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
 }
@@ -0,0 +1,188 @@
+// Copyright (c) 2024-2025-2025-2025-2025 Chakib Ben Ziane <contact@blob42.xyz>  and [`gosuki` contributors](https://github.com/blob42/gosuki/graphs/contributors).
+// All rights reserved.
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+//
+// This file is part of GoSuki.
+//
+// GoSuki is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// GoSuki is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with gosuki.  If not, see <http://www.gnu.org/licenses/>.
+
+// Package search implements the query language used to search bookmarks from
+// suki, the API and the web UI.
+//
+//	golang tag:web -tag:work            words, tags and negation
+//	title:"rust book" OR url:rust-lang  field qualifiers and quoted phrases
+//	(go OR rust) site:github.com        grouping, AND is implicit
+//	modified:>2025-01-01                date predicates
+//	created:2024-01-01..2024-06-30      on the modification or creation time
+//	status:dead                         result of the last link check
+//	golang :OR web,programming          legacy tag list syntax
+//
+// A query is parsed into an AST with [Parse] and compiled into database
+// predicates with [Compile].
+package search
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Field qualifies the bookmark attribute a term applies to
+type Field string
+
+const (
+	FieldAny      Field = ""
+	FieldTitle    Field = "title"
+	FieldURL      Field = "url"
+	FieldDesc     Field = "desc"
+	FieldTag      Field = "tag"
+	FieldModule   Field = "module"
+	FieldKeyword  Field = "keyword"
+	FieldSite     Field = "site"
+	FieldModified Field = "modified"
+	FieldCreated  Field = "created"
+	FieldStatus   Field = "status"
+)
+
+// accepted `field:` qualifiers
+var fields = map[string]Field{
+	"title":    FieldTitle,
+	"url":      FieldURL,
+	"desc":     FieldDesc,
+	"tag":      FieldTag,
+	"tags":     FieldTag,
+	"module":   FieldModule,
+	"keyword":  FieldKeyword,
+	"site":     FieldSite,
+	"modified": FieldModified,
+	"created":  FieldCreated,
+	"status":   FieldStatus,
+}
+
+// date fields take a date or a comparison as value
+var dateFields = map[Field]bool{
+	FieldModified: true,
+	FieldCreated:  true,
+}
+
+// Node is an element of the query AST
+type Node interface {
+	// Pos is the character position of the node in the query, starting at 1
+	Pos() int
+	String() string
+}
+
+type Op int
+
+const (
+	OpAnd Op = iota
+	OpOr
+)
+
+func (op Op) String() string {
+	if op == OpOr {
+		return "OR"
+	}
+	return "AND"
+}
+
+// Binary combines two nodes with AND or OR
+type Binary struct {
+	Op          Op
+	Left, Right Node
+	pos         int
+}
+
+func (b *Binary) Pos() int { return b.pos }
+
+func (b *Binary) String() string {
+	return fmt.Sprintf("(%s %s %s)", b.Op, b.Left, b.Right)
+}
+
+// Not negates a node
+type Not struct {
+	X   Node
+	pos int
+}
+
+func (n *Not) Pos() int { return n.pos }
+
+func (n *Not) String() string {
+	return fmt.Sprintf("(NOT %s)", n.X)
+}
+
+// Term matches a word or a quoted phrase in the given field
+type Term struct {
+	Field  Field
+	Value  string
+	Phrase bool
+	pos    int
+}
+
+func (t *Term) Pos() int { return t.pos }
+
+func (t *Term) String() string {
+	var s strings.Builder
+	if t.Field != FieldAny {
+		s.WriteString(string(t.Field) + ":")
+	}
+	if t.Phrase {
+		s.WriteString(fmt.Sprintf("%q", t.Value))
+	} else {
+		s.WriteString(t.Value)
+	}
+	return s.String()
+}
+
+// DateRange matches dates in the [From, To] interval. A zero time leaves the
+// interval open on that side.
+type DateRange struct {
+	Field    Field
+	From, To time.Time
+	pos      int
+}
+
+func (d *DateRange) Pos() int { return d.pos }
+
+func (d *DateRange) String() string {
+	const layout = "2006-01-02T15:04:05"
+	from, to := "*", "*"
+	if !d.From.IsZero() {
+		from = d.From.Format(layout)
+	}
+	if !d.To.IsZero() {
+		to = d.To.Format(layout)
+	}
+	return fmt.Sprintf("%s:[%s,%s]", d.Field, from, to)
+}
+
+// ParseError is a syntax error in a query
+type ParseError struct {
+	Query string
+	Pos   int // character position of the error, starting at 1
+	Msg   string
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("invalid query: %s at position %d", e.Msg, e.Pos)
+}
+
+// Caret returns the query with a marker under the position of the error
+//
+//	title:"go
+//	      ^
+func (e *ParseError) Caret() string {
+	return e.Query + "\n" + strings.Repeat(" ", max(e.Pos-1, 0)) + "^"
+}
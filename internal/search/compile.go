@@ -0,0 +1,127 @@
+// Copyright (c) 2024-2025-2025-2025-2025 Chakib Ben Ziane <contact@blob42.xyz>  and [`gosuki` contributors](https://github.com/blob42/gosuki/graphs/contributors).
+// All rights reserved.
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+//
+// This file is part of GoSuki.
+//
+// GoSuki is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// GoSuki is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with gosuki.  If not, see <http://www.gnu.org/licenses/>.
+
+package search
+
+import (
+	"fmt"
+
+	db "github.com/blob42/gosuki/internal/database"
+)
+
+// Options change how a query is compiled
+type Options struct {
+	// Fuzzy matches words and tags with a fuzzy search instead of full text
+	Fuzzy bool
+}
+
+// Compile converts a query AST to a database predicate. A nil node matches all
+// bookmarks.
+func Compile(node Node, opts Options) db.Predicate {
+	switch n := node.(type) {
+	case nil:
+		return db.Predicate{}
+
+	case *Binary:
+		left, right := Compile(n.Left, opts), Compile(n.Right, opts)
+		if n.Op == OpOr {
+			return db.Or(left, right)
+		}
+		return db.And(left, right)
+
+	case *Not:
+		return db.Not(Compile(n.X, opts))
+
+	case *DateRange:
+		if n.Field == FieldCreated {
+			return db.CreatedBetween(n.From, n.To)
+		}
+		return db.ModifiedBetween(n.From, n.To)
+
+	case *Term:
+		return compileTerm(n, opts)
+	}
+
+	panic(fmt.Sprintf("search: unknown node %T", node))
+}
+
+func compileTerm(t *Term, opts Options) db.Predicate {
+	var dbFields []db.Field
+
+	switch t.Field {
+	case FieldTag:
+		return db.MatchTag(t.Value, opts.Fuzzy)
+	case FieldModule:
+		return db.MatchModule(t.Value)
+	case FieldKeyword:
+		return db.MatchKeyword(t.Value)
+	case FieldSite:
+		return db.MatchSite(t.Value)
+	case FieldStatus:
+		return db.MatchLinkStatus(t.Value)
+	case FieldTitle:
+		dbFields = []db.Field{db.FieldTitle}
+	case FieldURL:
+		dbFields = []db.Field{db.FieldURL}
+	case FieldDesc:
+		dbFields = []db.Field{db.FieldDesc}
+	}
+
+	switch {
+	case opts.Fuzzy:
+		return db.MatchText(t.Value, true, dbFields...)
+	case t.Phrase:
+		return db.MatchPhrase(t.Value, dbFields...)
+	default:
+		return db.MatchFullText(t.Value, dbFields...)
+	}
+}
+
+// NewQuery parses query and returns the matching bookmark query
+func NewQuery(query string, opts Options) (*db.BookmarkQuery, error) {
+	node, err := Parse(query)
+	if err != nil {
+		return nil, err
+	}
+	return db.NewBookmarkQuery(Compile(node, opts)), nil
+}
+
+// Words returns the text searched by the query, excluding negated terms. It is
+// used to highlight matches.
+func Words(node Node) []string {
+	var words []string
+
+	var walk func(Node)
+	walk = func(n Node) {
+		switch n := n.(type) {
+		case *Binary:
+			walk(n.Left)
+			walk(n.Right)
+		case *Term:
+			switch n.Field {
+			case FieldAny, FieldTitle, FieldURL, FieldDesc:
+				words = append(words, n.Value)
+			}
+		}
+	}
+	walk(node)
+
+	return words
+}
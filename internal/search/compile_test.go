@@ -0,0 +1,81 @@
+package search
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/blob42/gosuki"
+	db "github.com/blob42/gosuki/internal/database"
+	"github.com/blob42/gosuki/test"
+	"github.com/blob42/gosuki/test/fixtures"
+)
+
+func TestCompile(t *testing.T) {
+	h := test.NewHarnessWithSeed(t, fixtures.TagVarietySet())
+	defer h.Cleanup()
+	h.SeedBookmarks([]fixtures.SeedBookmark{
+		{URL: "https://pkg.go.dev/net", Title: "net package", Desc: "Go networking", Modified: 1735776000, Tags: []string{"go"}, Module: "chrome"},
+		{URL: "https://blog.rust-lang.org/", Title: "Rust Blog", Modified: 1738454400, Created: 1600000000, Tags: []string{"rust", "news"}, Module: "firefox"},
+	})
+	_, err := h.DB.SetLinkStatus(context.Background(), "https://pkg.go.dev/net", 404, "")
+	require.NoError(t, err)
+	require.NoError(t, h.DB.RecordSources("https://pkg.go.dev/net",
+		gosuki.Source{Module: "chrome_Default", Keyword: "gopkg"}))
+
+	orig := db.DiskDB
+	db.DiskDB = h.DB
+	defer func() { db.DiskDB = orig }()
+
+	tests := []struct {
+		query string
+		opts  Options
+		want  []string
+	}{
+		{"lang", Options{}, []string{"https://go-lang.com", "https://rust-lang.com", "https://blog.rust-lang.org/"}},
+		{"rust lang", Options{}, []string{"https://rust-lang.com", "https://blog.rust-lang.org/"}},
+		{"go OR python", Options{}, []string{"https://go-lang.com", "https://python.org", "https://pkg.go.dev/net"}},
+		{`"rust blog"`, Options{}, []string{"https://blog.rust-lang.org/"}},
+		{"title:rust", Options{}, []string{"https://rust-lang.com", "https://blog.rust-lang.org/"}},
+		{"url:go", Options{}, []string{"https://go-lang.com", "https://pkg.go.dev/net"}},
+		{"desc:networking", Options{}, []string{"https://pkg.go.dev/net"}},
+		{"tag:os", Options{}, []string{"https://linux.org", "https://gnu.org"}},
+		{"tag:programming -tag:rust", Options{}, []string{"https://go-lang.com", "https://python.org"}},
+		{"tag:linux,gnu", Options{}, []string{"https://gnu.org"}},
+		{":OR python,gnu", Options{}, []string{"https://python.org", "https://gnu.org"}},
+		{"module:firefox", Options{}, []string{"https://blog.rust-lang.org/"}},
+		{"keyword:gopkg", Options{}, []string{"https://pkg.go.dev/net"}},
+		{"site:rust-lang.org", Options{}, []string{"https://blog.rust-lang.org/"}},
+		{"site:go.dev", Options{}, []string{"https://pkg.go.dev/net"}},
+		{"site:lang.org", Options{}, nil},
+		{"modified:>=2025-01-01", Options{}, []string{"https://pkg.go.dev/net", "https://blog.rust-lang.org/"}},
+		{"modified:<2025-01-01 tag:os", Options{}, []string{"https://linux.org", "https://gnu.org"}},
+		{"created:2020-01-01..2020-12-31", Options{}, []string{"https://blog.rust-lang.org/"}},
+		{"status:dead", Options{}, []string{"https://pkg.go.dev/net"}},
+		{"status:unchecked tag:os", Options{}, []string{"https://linux.org", "https://gnu.org"}},
+		{"(tag:rust OR tag:python) -module:firefox", Options{}, []string{"https://rust-lang.com", "https://python.org"}},
+		{"rst", Options{Fuzzy: true}, []string{"https://rust-lang.com", "https://blog.rust-lang.org/"}},
+		{"", Options{}, []string{
+			"https://go-lang.com", "https://rust-lang.com", "https://python.org", "https://linux.org",
+			"https://gnu.org", "https://pkg.go.dev/net", "https://blog.rust-lang.org/",
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			q, err := NewQuery(tt.query, tt.opts)
+			require.NoError(t, err)
+
+			result, err := q.Run(context.Background(), &db.PaginationParams{Page: 1, Size: -1})
+			require.NoError(t, err)
+
+			urls := []string{}
+			for _, bk := range result.Bookmarks {
+				urls = append(urls, bk.URL)
+			}
+			require.ElementsMatch(t, tt.want, urls)
+			require.Equal(t, uint(len(tt.want)), result.Total)
+		})
+	}
+}
@@ -0,0 +1,238 @@
+// Copyright (c) 2024-2025-2025-2025-2025 Chakib Ben Ziane <contact@blob42.xyz>  and [`gosuki` contributors](https://github.com/blob42/gosuki/graphs/contributors).
+// All rights reserved.
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+//
+// This file is part of GoSuki.
+//
+// GoSuki is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// GoSuki is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with gosuki.  If not, see <http://www.gnu.org/licenses/>.
+
+package search
+
+import (
+	"strings"
+	"unicode"
+)
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokLParen
+	tokRParen
+	tokAnd
+	tokOr
+	tokNot
+	tokTerm
+	tokTagList // legacy `:tag1,tag2` and `:OR tag1,tag2`
+)
+
+type token struct {
+	kind tokenKind
+	pos  int // starting at 1
+
+	// tokTerm, tokTagList
+	field    string // raw qualifier before `:`
+	value    string
+	valuePos int
+	phrase   bool
+
+	// tokTagList
+	or bool
+}
+
+type lexer struct {
+	query string
+	input []rune
+	i     int
+}
+
+func (l *lexer) errorf(pos int, msg string) *ParseError {
+	return &ParseError{Query: l.query, Pos: pos, Msg: msg}
+}
+
+func (l *lexer) peek() rune {
+	if l.i >= len(l.input) {
+		return 0
+	}
+	return l.input[l.i]
+}
+
+func (l *lexer) skipSpaces() {
+	for l.i < len(l.input) && unicode.IsSpace(l.input[l.i]) {
+		l.i++
+	}
+}
+
+func isDelim(r rune) bool {
+	return unicode.IsSpace(r) || r == '(' || r == ')' || r == '"'
+}
+
+// reads until a delimiter
+func (l *lexer) readWord() string {
+	start := l.i
+	for l.i < len(l.input) && !isDelim(l.input[l.i]) {
+		l.i++
+	}
+	return string(l.input[start:l.i])
+}
+
+// reads a double quoted string, `\"` escapes a quote
+func (l *lexer) readQuoted() (string, error) {
+	open := l.i + 1
+	l.i++ // opening quote
+
+	var s strings.Builder
+	for l.i < len(l.input) {
+		r := l.input[l.i]
+		switch {
+		case r == '\\' && l.i+1 < len(l.input) && l.input[l.i+1] == '"':
+			s.WriteRune('"')
+			l.i += 2
+		case r == '"':
+			l.i++
+			return s.String(), nil
+		default:
+			s.WriteRune(r)
+			l.i++
+		}
+	}
+
+	return "", l.errorf(open, "unterminated quoted string")
+}
+
+func (l *lexer) tokens() ([]token, error) {
+	var toks []token
+
+	for {
+		l.skipSpaces()
+		pos := l.i + 1
+
+		if l.i >= len(l.input) {
+			return append(toks, token{kind: tokEOF, pos: pos}), nil
+		}
+
+		switch r := l.peek(); {
+		case r == '(':
+			l.i++
+			toks = append(toks, token{kind: tokLParen, pos: pos})
+
+		case r == ')':
+			l.i++
+			toks = append(toks, token{kind: tokRParen, pos: pos})
+
+		case r == '"':
+			value, err := l.readQuoted()
+			if err != nil {
+				return nil, err
+			}
+			toks = append(toks, token{
+				kind:     tokTerm,
+				pos:      pos,
+				value:    value,
+				valuePos: pos,
+				phrase:   true,
+			})
+
+		case r == '-' && l.i+1 < len(l.input) &&
+			!unicode.IsSpace(l.input[l.i+1]) && l.input[l.i+1] != ')':
+			l.i++
+			toks = append(toks, token{kind: tokNot, pos: pos})
+
+		case r == ':':
+			tok, err := l.lexTagList(pos)
+			if err != nil {
+				return nil, err
+			}
+			if tok.value != "" {
+				toks = append(toks, tok)
+			}
+
+		default:
+			tok, err := l.lexWord(pos)
+			if err != nil {
+				return nil, err
+			}
+			toks = append(toks, tok)
+		}
+	}
+}
+
+// lexes a word, an operator or a `field:value` term
+func (l *lexer) lexWord(pos int) (token, error) {
+	word := l.readWord()
+
+	switch word {
+	case "AND":
+		return token{kind: tokAnd, pos: pos}, nil
+	case "OR":
+		return token{kind: tokOr, pos: pos}, nil
+	case "NOT":
+		return token{kind: tokNot, pos: pos}, nil
+	}
+
+	tok := token{kind: tokTerm, pos: pos, value: word, valuePos: pos}
+
+	// words with an unknown qualifier, like urls, are searched as is
+	name, value, found := strings.Cut(word, ":")
+	if _, known := fields[strings.ToLower(name)]; !found || !known {
+		return tok, nil
+	}
+
+	tok.field = strings.ToLower(name)
+	tok.value = value
+	tok.valuePos = pos + len([]rune(name)) + 1
+
+	if value == "" {
+		if l.peek() != '"' {
+			return tok, l.errorf(tok.valuePos, "missing value for "+tok.field+":")
+		}
+		quoted, err := l.readQuoted()
+		if err != nil {
+			return tok, err
+		}
+		tok.value = quoted
+		tok.phrase = true
+	}
+
+	return tok, nil
+}
+
+// lexes the legacy tag list syntax `:tag1,tag2` and `:OR tag1,tag2`
+func (l *lexer) lexTagList(pos int) (token, error) {
+	l.i++ // colon
+	tok := token{kind: tokTagList, pos: pos, valuePos: l.i + 1}
+	tok.value = l.readWord()
+
+	if tok.value == "OR" && unicode.IsSpace(l.peek()) {
+		tok.or = true
+		l.skipSpaces()
+		tok.valuePos = l.i + 1
+		tok.value = l.readWord()
+	}
+
+	// `:web, programming` continues the list after a comma
+	for strings.HasSuffix(tok.value, ",") {
+		save := l.i
+		l.skipSpaces()
+		next := l.readWord()
+		if next == "" || next == "AND" || next == "OR" || next == "NOT" {
+			l.i = save
+			break
+		}
+		tok.value += next
+	}
+
+	return tok, nil
+}
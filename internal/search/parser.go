@@ -0,0 +1,306 @@
+// Copyright (c) 2024-2025-2025-2025-2025 Chakib Ben Ziane <contact@blob42.xyz>  and [`gosuki` contributors](https://github.com/blob42/gosuki/graphs/contributors).
+// All rights reserved.
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+//
+// This file is part of GoSuki.
+//
+// GoSuki is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// GoSuki is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with gosuki.  If not, see <http://www.gnu.org/licenses/>.
+
+package search
+
+import (
+	"strings"
+	"time"
+
+	db "github.com/blob42/gosuki/internal/database"
+)
+
+// Grammar:
+//
+//	query   = or
+//	or      = and { "OR" and }
+//	and     = unary { [ "AND" ] unary }
+//	unary   = ( "-" | "NOT" ) unary | primary
+//	primary = "(" or ")" | term | ":" tags | ":OR" tags
+//	term    = [ field ":" ] ( word | phrase )
+//
+// Terms are AND-ed when no operator is given. Operators are case sensitive.
+
+const dateLayout = "2006-01-02"
+
+type parser struct {
+	lex  *lexer
+	toks []token
+	i    int
+}
+
+// Parse parses a search query. An empty query returns a nil node.
+func Parse(query string) (Node, error) {
+	lex := &lexer{query: query, input: []rune(query)}
+	toks, err := lex.tokens()
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{lex: lex, toks: toks}
+	if p.peek().kind == tokEOF {
+		return nil, nil
+	}
+
+	node, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+
+	if tok := p.peek(); tok.kind != tokEOF {
+		return nil, p.unexpected(tok)
+	}
+
+	return node, nil
+}
+
+func (p *parser) peek() token {
+	return p.toks[p.i]
+}
+
+func (p *parser) next() token {
+	tok := p.toks[p.i]
+	if tok.kind != tokEOF {
+		p.i++
+	}
+	return tok
+}
+
+func (p *parser) unexpected(tok token) *ParseError {
+	switch tok.kind {
+	case tokEOF:
+		return p.lex.errorf(tok.pos, "unexpected end of query")
+	case tokRParen:
+		return p.lex.errorf(tok.pos, "unexpected )")
+	case tokAnd:
+		return p.lex.errorf(tok.pos, "unexpected AND")
+	case tokOr:
+		return p.lex.errorf(tok.pos, "unexpected OR")
+	}
+	return p.lex.errorf(tok.pos, "unexpected term")
+}
+
+func startsOperand(kind tokenKind) bool {
+	switch kind {
+	case tokLParen, tokNot, tokTerm, tokTagList:
+		return true
+	}
+	return false
+}
+
+func (p *parser) parseOr() (Node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.peek().kind == tokOr {
+		op := p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &Binary{Op: OpOr, Left: left, Right: right, pos: op.pos}
+	}
+
+	return left, nil
+}
+
+func (p *parser) parseAnd() (Node, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		tok := p.peek()
+		if tok.kind == tokAnd {
+			p.next()
+		} else if !startsOperand(tok.kind) {
+			return left, nil
+		}
+
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = &Binary{Op: OpAnd, Left: left, Right: right, pos: tok.pos}
+	}
+}
+
+func (p *parser) parseUnary() (Node, error) {
+	if tok := p.peek(); tok.kind == tokNot {
+		p.next()
+		x, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &Not{X: x, pos: tok.pos}, nil
+	}
+
+	return p.parsePrimary()
+}
+
+func (p *parser) parsePrimary() (Node, error) {
+	tok := p.next()
+
+	switch tok.kind {
+	case tokLParen:
+		if p.peek().kind == tokRParen {
+			return nil, p.lex.errorf(tok.pos, "empty parentheses")
+		}
+		node, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek().kind != tokRParen {
+			return nil, p.lex.errorf(tok.pos, "missing closing parenthesis")
+		}
+		p.next()
+		return node, nil
+
+	case tokTerm:
+		return p.parseTerm(tok)
+
+	case tokTagList:
+		op := OpAnd
+		if tok.or {
+			op = OpOr
+		}
+		return p.tagList(tok, op)
+	}
+
+	return nil, p.unexpected(tok)
+}
+
+func (p *parser) parseTerm(tok token) (Node, error) {
+	field := fields[tok.field]
+
+	if dateFields[field] {
+		return p.parseDate(tok, field)
+	}
+
+	if field == FieldStatus {
+		value := strings.ToLower(tok.value)
+		if !db.ValidLinkStatus(value) {
+			return nil, p.lex.errorf(tok.valuePos, "unknown link status "+`"`+tok.value+`"`+
+				", expected ok, dead, redirect, error, unchecked or an http code")
+		}
+		return &Term{Field: field, Value: value, pos: tok.pos}, nil
+	}
+
+	if field == FieldTag && !tok.phrase && strings.Contains(tok.value, ",") {
+		return p.tagList(tok, OpAnd)
+	}
+
+	return &Term{
+		Field:  field,
+		Value:  tok.value,
+		Phrase: tok.phrase,
+		pos:    tok.pos,
+	}, nil
+}
+
+// builds the tags of a comma separated list joined with op
+func (p *parser) tagList(tok token, op Op) (Node, error) {
+	var node Node
+	for tag := range strings.SplitSeq(tok.value, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "" {
+			continue
+		}
+		term := &Term{Field: FieldTag, Value: tag, pos: tok.pos}
+		if node == nil {
+			node = term
+		} else {
+			node = &Binary{Op: op, Left: node, Right: term, pos: tok.pos}
+		}
+	}
+
+	if node == nil {
+		return nil, p.lex.errorf(tok.valuePos, "missing tag")
+	}
+	return node, nil
+}
+
+// parses `field:DATE`, `field:>DATE`, `field:>=DATE`, `field:<DATE`,
+// `field:<=DATE` and `field:DATE..DATE`. Dates are in the local time zone.
+func (p *parser) parseDate(tok token, field Field) (Node, error) {
+	value := tok.value
+	pos := tok.valuePos
+
+	parse := func(s string, at int) (time.Time, error) {
+		t, err := time.ParseInLocation(dateLayout, s, time.Local)
+		if err != nil {
+			return t, p.lex.errorf(at, "invalid date "+`"`+s+`"`+", expected YYYY-MM-DD")
+		}
+		return t, nil
+	}
+
+	node := &DateRange{Field: field, pos: tok.pos}
+
+	if from, to, ok := strings.Cut(value, ".."); ok {
+		start, err := parse(from, pos)
+		if err != nil {
+			return nil, err
+		}
+		end, err := parse(to, pos+len([]rune(from))+2)
+		if err != nil {
+			return nil, err
+		}
+		if end.Before(start) {
+			return nil, p.lex.errorf(pos, "empty date range")
+		}
+		node.From, node.To = start, endOfDay(end)
+		return node, nil
+	}
+
+	op := ""
+	for _, prefix := range []string{">=", "<=", ">", "<", "="} {
+		if strings.HasPrefix(value, prefix) {
+			op = prefix
+			break
+		}
+	}
+
+	day, err := parse(value[len(op):], pos+len(op))
+	if err != nil {
+		return nil, err
+	}
+
+	switch op {
+	case ">":
+		node.From = day.AddDate(0, 0, 1)
+	case ">=":
+		node.From = day
+	case "<":
+		node.To = day.Add(-time.Second)
+	case "<=":
+		node.To = endOfDay(day)
+	default:
+		node.From, node.To = day, endOfDay(day)
+	}
+
+	return node, nil
+}
+
+func endOfDay(day time.Time) time.Time {
+	return day.AddDate(0, 0, 1).Add(-time.Second)
+}
@@ -0,0 +1,143 @@
+package search
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  string
+	}{
+		{"empty", "", "<nil>"},
+		{"spaces", "   ", "<nil>"},
+		{"word", "golang", "golang"},
+		{"implicit and", "golang web", "(AND golang web)"},
+		{"explicit and", "golang AND web", "(AND golang web)"},
+		{"or", "go OR rust", "(OR go rust)"},
+		{"and binds tighter than or", "a b OR c", "(OR (AND a b) c)"},
+		{"parentheses", "a (b OR c)", "(AND a (OR b c))"},
+		{"nested parentheses", "((a OR b) c)", "(AND (OR a b) c)"},
+		{"lowercase operators are words", "this or that", "(AND (AND this or) that)"},
+		{"phrase", `"rust book"`, `"rust book"`},
+		{"escaped quote", `"say \"hi\""`, `"say \"hi\""`},
+		{"field", "title:go", "title:go"},
+		{"field is case insensitive", "Title:go", "title:go"},
+		{"field phrase", `title:"rust book"`, `title:"rust book"`},
+		{"all fields", "title:a url:b desc:c tag:d module:e site:f.org",
+			"(AND (AND (AND (AND (AND title:a url:b) desc:c) tag:d) module:e) site:f.org)"},
+		{"tags alias", "tags:web", "tag:web"},
+		{"tag list", "tag:web,dev", "(AND tag:web tag:dev)"},
+		{"negation", "-tag:work", "(NOT tag:work)"},
+		{"not keyword", "NOT work", "(NOT work)"},
+		{"negated group", "go -(video OR talk)", "(AND go (NOT (OR video talk)))"},
+		{"negated phrase", `-"hello world"`, `(NOT "hello world")`},
+		{"dash in word", "go-lang", "go-lang"},
+		{"lone dash", "a - b", "(AND (AND a -) b)"},
+		{"unknown field is a word", "golang:awesome", "golang:awesome"},
+		{"url", "https://go.dev/doc", "https://go.dev/doc"},
+
+		// legacy tag list
+		{"legacy tags", "golang :web,programming", "(AND golang (AND tag:web tag:programming))"},
+		{"legacy tags with spaces", "golang :web, programming, go",
+			"(AND golang (AND (AND tag:web tag:programming) tag:go))"},
+		{"legacy or tags", "golang :OR web,programming", "(AND golang (OR tag:web tag:programming))"},
+		{"legacy or tags with spaces", "golang :OR  web, programming",
+			"(AND golang (OR tag:web tag:programming))"},
+		{"legacy tags only", ":web,programming", "(AND tag:web tag:programming)"},
+		{"legacy empty tag list", "golang :", "golang"},
+		{"only colon", ":", "<nil>"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node, err := Parse(tt.query)
+			require.NoError(t, err)
+			if node == nil {
+				require.Equal(t, tt.want, "<nil>")
+				return
+			}
+			require.Equal(t, tt.want, node.String())
+		})
+	}
+}
+
+func TestParseDate(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"modified:2025-01-02", "modified:[2025-01-02T00:00:00,2025-01-02T23:59:59]"},
+		{"modified:=2025-01-02", "modified:[2025-01-02T00:00:00,2025-01-02T23:59:59]"},
+		{"modified:>2025-01-02", "modified:[2025-01-03T00:00:00,*]"},
+		{"modified:>=2025-01-02", "modified:[2025-01-02T00:00:00,*]"},
+		{"modified:<2025-01-02", "modified:[*,2025-01-01T23:59:59]"},
+		{"modified:<=2025-01-02", "modified:[*,2025-01-02T23:59:59]"},
+		{"modified:2025-01-02..2025-02-01", "modified:[2025-01-02T00:00:00,2025-02-01T23:59:59]"},
+		{"created:<2025-01-02", "created:[*,2025-01-01T23:59:59]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			node, err := Parse(tt.query)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, node.String())
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		pos   int
+		msg   string
+	}{
+		{"unterminated quote", `go "rust book`, 4, "unterminated quoted string"},
+		{"unterminated field phrase", `title:"rust`, 7, "unterminated quoted string"},
+		{"unclosed parenthesis", "a (b OR c", 3, "missing closing parenthesis"},
+		{"unexpected parenthesis", "a b)", 4, "unexpected )"},
+		{"empty parentheses", "a ()", 3, "empty parentheses"},
+		{"dangling or", "a OR", 5, "unexpected end of query"},
+		{"leading or", "OR a", 1, "unexpected OR"},
+		{"double operator", "a AND OR b", 7, "unexpected OR"},
+		{"dangling not", "a NOT", 6, "unexpected end of query"},
+		{"missing value", "title: go", 7, "missing value for title:"},
+		{"invalid date", "go modified:>2025-13-01", 14, `invalid date "2025-13-01", expected YYYY-MM-DD`},
+		{"invalid range end", "modified:2025-01-01..soon", 22, `invalid date "soon", expected YYYY-MM-DD`},
+		{"empty range", "modified:2025-02-01..2025-01-01", 10, "empty date range"},
+		{"unknown link status", "status:gone", 8, `unknown link status "gone", expected ok, dead, redirect, error, unchecked or an http code`},
+		{"empty tag list", "tag:,", 5, "missing tag"},
+		{"unicode position", `été "x`, 5, "unterminated quoted string"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(tt.query)
+			require.Error(t, err)
+
+			parseErr, ok := err.(*ParseError)
+			require.True(t, ok, "want *ParseError, got %T", err)
+			require.Equal(t, tt.msg, parseErr.Msg)
+			require.Equal(t, tt.pos, parseErr.Pos)
+			require.Equal(t, tt.query, parseErr.Query)
+		})
+	}
+}
+
+func TestParseErrorCaret(t *testing.T) {
+	_, err := Parse(`title:"go`)
+	require.Error(t, err)
+	require.Equal(t, "invalid query: unterminated quoted string at position 7", err.Error())
+	require.Equal(t, "title:\"go\n      ^", err.(*ParseError).Caret())
+}
+
+func TestWords(t *testing.T) {
+	node, err := Parse(`go title:"rust book" -video tag:web (url:gnu OR desc:kernel)`)
+	require.NoError(t, err)
+	require.Equal(t, []string{"go", "rust book", "gnu", "kernel"}, Words(node))
+	require.Empty(t, Words(nil))
+}
@@ -76,6 +76,16 @@ func NewWebUIServer(tuiMode bool) *WebUIServer {
 
 	apiRoute := chi.NewRouter()
 	apiRoute.Get("/bookmarks", api.GetAPIBookmarks)
+	apiRoute.Get("/bookmarks/{id}/history", api.GetAPIBookmarkHistory)
+	apiRoute.Get("/tags", api.GetAPITags)
+	apiRoute.Group(func(r chi.Router) {
+		r.Use(api.WriteGuard)
+		r.Post("/bookmarks", api.PostAPIBookmark)
+		r.Post("/bookmarks/tags", api.PostAPIBookmarksTags)
+		r.Patch("/bookmarks/{id}", api.PatchAPIBookmark)
+		r.Delete("/bookmarks/{id}", api.DeleteAPIBookmark)
+		r.Post("/bookmarks/{id}/history/{rev}/restore", api.PostAPIRestoreRevision)
+	})
 
 	router.Mount("/api", apiRoute)
 
@@ -41,6 +41,7 @@ func CopyFileToDst(src string, dst string) error {
 	if err != nil {
 		return err
 	}
+	defer srcFile.Close()
 
 	dstFile, err := os.OpenFile(dst, os.O_RDWR|os.O_CREATE, 0644)
 	if err != nil {
@@ -49,15 +50,17 @@ func CopyFileToDst(src string, dst string) error {
 
 	_, err = io.Copy(dstFile, srcFile)
 	if err != nil {
+		dstFile.Close()
 		return err
 	}
 
 	err = dstFile.Sync()
 	if err != nil {
+		dstFile.Close()
 		return err
 	}
 
-	return nil
+	return dstFile.Close()
 
 }
 
@@ -134,3 +137,30 @@ func init() {
 		log.Fatal(err)
 	}
 }
+
+// WriteFileAtomic replaces the file at path with data. The data is written to
+// a temporary file in the same directory which is then renamed over path, the
+// file mode of path is kept.
+func WriteFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err = tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+
+	if info, err := os.Stat(path); err == nil {
+		if err = os.Chmod(tmp.Name(), info.Mode()); err != nil {
+			return err
+		}
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
@@ -34,6 +34,7 @@ import (
 
 	"github.com/blob42/gosuki/internal/api"
 	db "github.com/blob42/gosuki/internal/database"
+	"github.com/blob42/gosuki/internal/search"
 	"github.com/go-chi/chi/v5"
 
 	"github.com/kr/pretty"
@@ -124,28 +125,35 @@ func NamedView(name string) http.HandlerFunc {
 }
 
 func highlightQuery(r *http.Request, marks []*UIBookmark) error {
-	if query := r.URL.Query().Get("query"); query != "" {
-		//compile regex from query
-		regex, err := regexp.Compile(`(?i)` + query)
-		if err != nil {
-			return errors.New("invalid regex pattern")
-		}
+	query := strings.TrimPrefix(r.URL.Query().Get("query"), "~")
+	if query == "" {
+		return nil
+	}
 
-		// highlight match
-		for _, bk := range marks {
-			bk.Title = regex.ReplaceAllString(bk.Title, fmt.Sprintf(
-				"<em>%s</em>",
-				query,
-			))
-			bk.DisplayURL = regex.ReplaceAllString(bk.URL, fmt.Sprintf(
-				"<em>%s</em>",
-				query,
-			))
-			bk.Desc = regex.ReplaceAllString(bk.Desc, fmt.Sprintf(
-				"<em>%s</em>",
-				query,
-			))
-		}
+	node, err := search.Parse(query)
+	if err != nil {
+		return err
+	}
+
+	words := search.Words(node)
+	if len(words) == 0 {
+		return nil
+	}
+	for i, w := range words {
+		words[i] = regexp.QuoteMeta(w)
+	}
+
+	//compile regex from query words
+	regex, err := regexp.Compile(`(?i)` + strings.Join(words, "|"))
+	if err != nil {
+		return errors.New("invalid regex pattern")
+	}
+
+	// highlight match
+	for _, bk := range marks {
+		bk.Title = regex.ReplaceAllString(bk.Title, "<em>${0}</em>")
+		bk.DisplayURL = regex.ReplaceAllString(bk.URL, "<em>${0}</em>")
+		bk.Desc = regex.ReplaceAllString(bk.Desc, "<em>${0}</em>")
 	}
 	return nil
 }
@@ -171,7 +179,7 @@ func ListBookmarks(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, fmt.Sprintf(
 			"fetching bookmarks: %s",
 			err,
-		), http.StatusInternalServerError)
+		), api.ErrorStatus(err))
 		return
 	}
 
@@ -209,7 +217,7 @@ func IndexView(w http.ResponseWriter, r *http.Request) {
 	bookmarks, total, err = api.GetBookmarks(r)
 
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
+		w.WriteHeader(api.ErrorStatus(err))
 		fmt.Fprintf(w, "getting bookmarks: %s", err)
 		return
 	}
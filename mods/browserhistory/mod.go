@@ -0,0 +1,146 @@
+//
+//  Copyright (c) 2025 Chakib Ben Ziane <contact@blob42.xyz>  and [`gosuki` contributors](https://github.com/blob42/gosuki/graphs/contributors).
+//  All rights reserved.
+//
+//  SPDX-License-Identifier: AGPL-3.0-or-later
+//
+//  This file is part of GoSuki.
+//
+//  GoSuki is free software: you can redistribute it and/or modify
+//  it under the terms of the GNU Affero General Public License as
+//  published by the Free Software Foundation, either version 3 of the
+//  License, or (at your option) any later version.
+//
+//  GoSuki is distributed in the hope that it will be useful,
+//  but WITHOUT ANY WARRANTY; without even the implied warranty of
+//  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU Affero General Public License for more details.
+//
+//  You should have received a copy of the GNU Affero General Public License
+//  along with gosuki.  If not, see <http://www.gnu.org/licenses/>.
+
+// Package browserhistory reads the browsing history of the browser profiles
+// and records how often and when bookmarked urls were visited. Bookmarks can
+// then be sorted by frecency.
+package browserhistory
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/blob42/gosuki"
+	db "github.com/blob42/gosuki/internal/database"
+	"github.com/blob42/gosuki/pkg/config"
+	"github.com/blob42/gosuki/pkg/logging"
+	"github.com/blob42/gosuki/pkg/modules"
+	"github.com/blob42/gosuki/pkg/watch"
+)
+
+const (
+	ModID = "browser-history"
+
+	DefaultInterval = 30 * time.Minute
+)
+
+var (
+	Config *HistoryConfig
+	log    = logging.GetLogger(ModID)
+)
+
+type HistoryConfig struct {
+	// Reading the browser history is opt-in
+	Enabled bool `toml:"enabled" mapstructure:"enabled"`
+
+	// Time between two reads of the browser histories
+	Interval time.Duration `toml:"interval" mapstructure:"interval"`
+}
+
+func NewHistoryConfig() *HistoryConfig {
+	return &HistoryConfig{
+		Interval: DefaultInterval,
+	}
+}
+
+// HistoryModule reads the history of all the browser modules implementing
+// [modules.HistoryReader] at each interval. It does not produce bookmarks,
+// visits are written to the L2 cache.
+type HistoryModule struct {
+	ctx context.Context
+}
+
+func (hm HistoryModule) ModInfo() modules.ModInfo {
+	return modules.ModInfo{
+		ID: modules.ModID(ModID),
+		New: func() modules.Module {
+			return &HistoryModule{}
+		},
+	}
+}
+
+func (hm *HistoryModule) Init(ctx *modules.Context) error {
+	if !Config.Enabled {
+		return &modules.ErrModDisabled{
+			Err:    errors.New("browser history disabled"),
+			Reason: "set browser-history.enabled to rank bookmarks by visits",
+		}
+	}
+
+	hm.ctx = ctx.Context
+	return nil
+}
+
+func (hm *HistoryModule) Fetch() ([]*gosuki.Bookmark, error) {
+	stored, err := ReadVisits(hm.ctx, db.L2Cache.DB, modules.GetBrowserModules())
+	if err != nil {
+		return nil, err
+	}
+
+	log.Info("read browser history", "visited bookmarks", stored)
+	db.ScheduleBackupToDisk()
+
+	return nil, nil
+}
+
+// Interval at which the browser histories are read
+func (hm HistoryModule) Interval() time.Duration {
+	return Config.Interval
+}
+
+// ReadVisits reads the history of the browsers and stores the visits of the
+// urls bookmarked in target. It returns the number of visits stored.
+func ReadVisits(ctx context.Context, target *db.DB, browsers []modules.BrowserModule) (int, error) {
+	stored := 0
+	for _, browser := range browsers {
+		if _, ok := browser.ModInfo().New().(modules.HistoryReader); !ok {
+			continue
+		}
+
+		histories, err := modules.ReadHistories(ctx, browser)
+		if err != nil {
+			log.Error("reading history", "browser", browser.ModInfo().ID, "err", err)
+			continue
+		}
+
+		for source, visits := range histories {
+			n, err := target.SetVisits(ctx, source, visits)
+			if err != nil {
+				return stored, err
+			}
+			log.Debug("visits", "source", source, "read", len(visits), "stored", n)
+			stored += n
+		}
+	}
+
+	return stored, ctx.Err()
+}
+
+func init() {
+	Config = NewHistoryConfig()
+	config.RegisterConfigurator(ModID, config.AsConfigurator(Config))
+	modules.RegisterModule(&HistoryModule{})
+}
+
+// interface guards
+var _ watch.Poller = (*HistoryModule)(nil)
+var _ modules.Initializer = (*HistoryModule)(nil)
@@ -1,6 +1,10 @@
 package mods
 
 import (
+	_ "github.com/blob42/gosuki/mods/browserhistory"
 	_ "github.com/blob42/gosuki/mods/github"
 	_ "github.com/blob42/gosuki/mods/importer"
+	_ "github.com/blob42/gosuki/mods/linkcheck"
+	_ "github.com/blob42/gosuki/mods/p2psync"
+	_ "github.com/blob42/gosuki/mods/sessions"
 )
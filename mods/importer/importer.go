@@ -81,7 +81,7 @@ func (im *BookmarksImporter) Init(ctx *modules.Context) error {
 			EventNames: []string{"*"},
 			ResetWatch: false,
 		})
-		watcher, err := watch.NewWatcher("html_bookmarks", watches...)
+		watcher, err := watch.NewWatcher(ImporterID, watches...)
 		if err != nil {
 			return fmt.Errorf("setup watcher: %w", err)
 		}
@@ -207,10 +207,6 @@ func loadBookmarksFromHTML(filePath string) ([]*gosuki.Bookmark, error) {
 	urlsSeen := make(map[string]bool)
 
 	doc.Find("dt>a").Each(func(_ int, a *goquery.Selection) {
-		dt := a.Parent()
-		dl := dt.Parent()
-		h3 := dl.Parent().Find("h3").First()
-
 		title := strings.TrimSpace(a.Text())
 		url, exists := a.Attr("href")
 		if !exists {
@@ -225,9 +221,7 @@ func loadBookmarksFromHTML(filePath string) ([]*gosuki.Bookmark, error) {
 
 		// Extract tags
 		tags := make([]string, 0)
-		category := strings.TrimSpace(h3.Text())
-
-		if category != "" {
+		if category := folderPath(a); category != "" {
 			tags = append(tags, category)
 		}
 
@@ -245,6 +239,20 @@ func loadBookmarksFromHTML(filePath string) ([]*gosuki.Bookmark, error) {
 	return bookmarks, nil
 }
 
+// folderPath returns the path of the <h3> folders containing the bookmark link
+// as a hierarchical tag
+func folderPath(a *goquery.Selection) string {
+	var folders []string
+	a.ParentsFiltered("dl").Each(func(_ int, dl *goquery.Selection) {
+		h3 := dl.PrevAllFiltered("h3").First()
+		if name := strings.TrimSpace(h3.Text()); name != "" {
+			folders = append(folders, name)
+		}
+	})
+	slices.Reverse(folders)
+	return strings.Join(folders, gosuki.FolderSep)
+}
+
 type BookmarksImporterConfig struct {
 	Paths []string `toml:"paths" mapstructure:"paths"`
 }
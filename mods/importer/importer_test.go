@@ -100,6 +100,63 @@ func TestImportMultipleBookmarks(t *testing.T) {
 	}
 }
 
+func TestImportNestedFolders(t *testing.T) {
+	htmlContent := `
+    <!DOCTYPE NETSCAPE-Bookmark-file-1>
+    <HTML>
+    <HEAD><TITLE>Bookmarks</TITLE></HEAD>
+    <BODY>
+    <DL><p>
+    <DT><H3>Work</H3>
+    <DL><p>
+        <DT><H3>ProjectA</H3>
+        <DL><p>
+            <DT><H3>Docs</H3>
+            <DL><p>
+                <DT><A HREF="https://work.com/docs">Work docs</A>
+            </DL><p>
+            <DT><A HREF="https://work.com/a">Project A</A>
+        </DL><p>
+    </DL><p>
+    <DT><H3>Personal</H3>
+    <DL><p>
+        <DT><H3>Docs</H3>
+        <DL><p>
+            <DT><A HREF="https://home.com/docs">Home docs</A>
+        </DL><p>
+    </DL><p>
+    <DT><A HREF="https://top.com">Top</A>
+    </DL><p>
+    </BODY></HTML>
+    `
+
+	tmpFile, err := createTestFile(t, htmlContent)
+	if err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	defer os.Remove(tmpFile)
+
+	bookmarks, err := loadBookmarksFromHTML(tmpFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string][]string{
+		"https://work.com/docs": {"Work/ProjectA/Docs"},
+		"https://work.com/a":    {"Work/ProjectA"},
+		"https://home.com/docs": {"Personal/Docs"},
+		"https://top.com":       {},
+	}
+	if len(bookmarks) != len(want) {
+		t.Fatalf("Expected %d bookmarks, got %d", len(want), len(bookmarks))
+	}
+	for _, bm := range bookmarks {
+		if diff := cmp.Diff(want[bm.URL], bm.Tags); diff != "" {
+			t.Errorf("tags of %s mismatch (-want +got):\n%s", bm.URL, diff)
+		}
+	}
+}
+
 func TestImportDuplicateURLs(t *testing.T) {
 	htmlContent := `
     <!DOCTYPE NETSCAPE-Bookmark-file-1>
@@ -0,0 +1,253 @@
+//
+//  Copyright (c) 2025 Chakib Ben Ziane <contact@blob42.xyz>  and [`gosuki` contributors](https://github.com/blob42/gosuki/graphs/contributors).
+//  All rights reserved.
+//
+//  SPDX-License-Identifier: AGPL-3.0-or-later
+//
+//  This file is part of GoSuki.
+//
+//  GoSuki is free software: you can redistribute it and/or modify
+//  it under the terms of the GNU Affero General Public License as
+//  published by the Free Software Foundation, either version 3 of the
+//  License, or (at your option) any later version.
+//
+//  GoSuki is distributed in the hope that it will be useful,
+//  but WITHOUT ANY WARRANTY; without even the implied warranty of
+//  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU Affero General Public License for more details.
+//
+//  You should have received a copy of the GNU Affero General Public License
+//  along with gosuki.  If not, see <http://www.gnu.org/licenses/>.
+
+package linkcheck
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"syscall"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	db "github.com/blob42/gosuki/internal/database"
+)
+
+// Result of a link check
+type Result struct {
+	URL string
+
+	// http status code, or [db.StatusUnreachable] and [db.StatusFailed]
+	Status int
+
+	// url after following redirects, empty if not redirected
+	FinalURL string
+
+	Err error
+}
+
+// Dead reports whether the link is gone
+func (r Result) Dead() bool {
+	return db.IsDeadStatus(r.Status)
+}
+
+// Checker checks links with a limit on parallel requests and on the request
+// rate to each host.
+type Checker struct {
+	client      *http.Client
+	concurrency int
+	hostDelay   time.Duration
+	userAgent   string
+
+	mu       sync.Mutex
+	limiters map[string]*rate.Limiter
+}
+
+func NewChecker(conf *LinkCheckConfig) *Checker {
+	concurrency := conf.Concurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	return &Checker{
+		client:      &http.Client{Timeout: conf.Timeout},
+		concurrency: concurrency,
+		hostDelay:   conf.HostDelay,
+		userAgent:   conf.UserAgent,
+		limiters:    map[string]*rate.Limiter{},
+	}
+}
+
+func (c *Checker) limiter(host string) *rate.Limiter {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	l, ok := c.limiters[host]
+	if !ok {
+		limit := rate.Inf
+		if c.hostDelay > 0 {
+			limit = rate.Every(c.hostDelay)
+		}
+		l = rate.NewLimiter(limit, 1)
+		c.limiters[host] = l
+	}
+	return l
+}
+
+// Check requests link with HEAD, falling back to GET for servers that do not
+// handle HEAD requests properly.
+func (c *Checker) Check(ctx context.Context, link string) Result {
+	u, err := url.Parse(link)
+	if err != nil {
+		return Result{URL: link, Status: db.StatusFailed, Err: err}
+	}
+
+	res := c.request(ctx, http.MethodHead, link, u.Host)
+	if res.Err != nil || res.Status >= 400 {
+		if ctx.Err() != nil {
+			return res
+		}
+		res = c.request(ctx, http.MethodGet, link, u.Host)
+	}
+	return res
+}
+
+func (c *Checker) request(ctx context.Context, method, link, host string) Result {
+	res := Result{URL: link}
+
+	if err := c.limiter(host).Wait(ctx); err != nil {
+		res.Status, res.Err = db.StatusFailed, err
+		return res
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, link, nil)
+	if err != nil {
+		res.Status, res.Err = db.StatusFailed, err
+		return res
+	}
+	if c.userAgent != "" {
+		req.Header.Set("User-Agent", c.userAgent)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		res.Status, res.Err = errorStatus(err), err
+		return res
+	}
+	// the body is not needed
+	resp.Body.Close()
+
+	res.Status = resp.StatusCode
+	if final := resp.Request.URL.String(); final != link {
+		res.FinalURL = final
+	}
+	return res
+}
+
+// classifies request errors, only hosts that are known to be gone count as
+// dead. Timeouts and other errors can be transient.
+func errorStatus(err error) int {
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+		return db.StatusUnreachable
+	}
+	if errors.Is(err, syscall.ECONNREFUSED) {
+		return db.StatusUnreachable
+	}
+	return db.StatusFailed
+}
+
+// CheckAll checks links in parallel and sends the results to the returned
+// channel, which is closed when done. Checks interrupted by ctx are dropped.
+func (c *Checker) CheckAll(ctx context.Context, links []string) <-chan Result {
+	jobs := make(chan string)
+	results := make(chan Result)
+
+	var wg sync.WaitGroup
+	for range c.concurrency {
+		wg.Go(func() {
+			for link := range jobs {
+				res := c.Check(ctx, link)
+				if ctx.Err() != nil {
+					continue
+				}
+				results <- res
+			}
+		})
+	}
+
+	go func() {
+		defer close(jobs)
+		for _, link := range links {
+			select {
+			case jobs <- link:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	return results
+}
+
+// Report summarizes a batch of link checks
+type Report struct {
+	Checked int
+
+	// dead links, including the ones already known dead
+	Dead []Result
+
+	// number of links found dead since the previous check
+	Died int
+
+	// checks that failed without a definitive answer (timeouts, tls errors...)
+	Failed []Result
+}
+
+// CheckLinks checks up to limit links of target not checked for olderThan and
+// records the results. A negative limit checks all links.
+func CheckLinks(
+	ctx context.Context,
+	target *db.DB,
+	checker *Checker,
+	olderThan time.Duration,
+	limit int,
+) (*Report, error) {
+	links, err := target.LinksToCheck(ctx, time.Now().Add(-olderThan), limit)
+	if err != nil {
+		return nil, err
+	}
+	log.Debug("checking links", "count", len(links))
+
+	report := &Report{}
+	for res := range checker.CheckAll(ctx, links) {
+		log.Trace("checked", "url", res.URL, "status", res.Status, "final", res.FinalURL, "err", res.Err)
+
+		died, err := target.SetLinkStatus(ctx, res.URL, res.Status, res.FinalURL)
+		if err != nil {
+			log.Error("saving link status", "url", res.URL, "err", err)
+			continue
+		}
+
+		report.Checked++
+		switch {
+		case res.Dead():
+			report.Dead = append(report.Dead, res)
+			if died {
+				report.Died++
+			}
+		case res.Status == db.StatusFailed:
+			report.Failed = append(report.Failed, res)
+		}
+	}
+
+	return report, ctx.Err()
+}
@@ -0,0 +1,143 @@
+package linkcheck
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	db "github.com/blob42/gosuki/internal/database"
+	"github.com/blob42/gosuki/test"
+	"github.com/blob42/gosuki/test/fixtures"
+)
+
+func newTestServer(t *testing.T) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/ok", func(w http.ResponseWriter, r *http.Request) {})
+	mux.HandleFunc("/gone", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusGone)
+	})
+	mux.HandleFunc("/no-head", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodHead {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	})
+	mux.HandleFunc("/moved", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/ok", http.StatusMovedPermanently)
+	})
+	mux.HandleFunc("/slow", func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(500 * time.Millisecond)
+	})
+
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// address of a closed port
+func refusedURL(t *testing.T) string {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := l.Addr().String()
+	l.Close()
+	return "http://" + addr + "/"
+}
+
+func testConfig() *LinkCheckConfig {
+	conf := NewLinkCheckConfig()
+	conf.HostDelay = 0
+	conf.Timeout = 200 * time.Millisecond
+	return conf
+}
+
+func TestCheck(t *testing.T) {
+	srv := newTestServer(t)
+	checker := NewChecker(testConfig())
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		url      string
+		status   int
+		finalURL string
+		dead     bool
+	}{
+		{"ok", srv.URL + "/ok", 200, "", false},
+		{"not found", srv.URL + "/missing", 404, "", true},
+		{"gone", srv.URL + "/gone", 410, "", true},
+		{"HEAD not allowed", srv.URL + "/no-head", 200, "", false},
+		{"redirect", srv.URL + "/moved", 200, srv.URL + "/ok", false},
+		{"timeout", srv.URL + "/slow", db.StatusFailed, "", false},
+		{"connection refused", refusedURL(t), db.StatusUnreachable, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := checker.Check(ctx, tt.url)
+			require.Equal(t, tt.url, res.URL)
+			require.Equal(t, tt.status, res.Status, res.Err)
+			require.Equal(t, tt.finalURL, res.FinalURL)
+			require.Equal(t, tt.dead, res.Dead())
+		})
+	}
+}
+
+func TestHostDelay(t *testing.T) {
+	srv := newTestServer(t)
+	conf := testConfig()
+	conf.HostDelay = 100 * time.Millisecond
+	checker := NewChecker(conf)
+
+	links := []string{srv.URL + "/ok", srv.URL + "/ok", srv.URL + "/ok"}
+	start := time.Now()
+	count := 0
+	for range checker.CheckAll(context.Background(), links) {
+		count++
+	}
+
+	require.Equal(t, 3, count)
+	require.GreaterOrEqual(t, time.Since(start), 200*time.Millisecond)
+}
+
+func TestCheckLinks(t *testing.T) {
+	srv := newTestServer(t)
+	h := test.NewHarnessWithSeed(t, []fixtures.SeedBookmark{
+		{URL: srv.URL + "/ok", Title: "ok"},
+		{URL: srv.URL + "/gone", Title: "gone"},
+		{URL: srv.URL + "/moved", Title: "moved"},
+		{URL: "ftp://example.com/file", Title: "not http"},
+	})
+	defer h.Cleanup()
+
+	ctx := context.Background()
+	checker := NewChecker(testConfig())
+
+	report, err := CheckLinks(ctx, h.DB, checker, DefaultRecheckAfter, -1)
+	require.NoError(t, err)
+	require.Equal(t, 3, report.Checked)
+	require.Len(t, report.Dead, 1)
+	require.Equal(t, 1, report.Died)
+	require.Empty(t, report.Failed)
+
+	raw, err := h.DB.BookmarkByURL(ctx, srv.URL+"/moved")
+	require.NoError(t, err)
+	require.Equal(t, 200, raw.LinkStatus)
+	require.Equal(t, srv.URL+"/ok", raw.LinkFinalURL)
+	require.NotZero(t, raw.LinkCheckedAt)
+
+	// recently checked links are skipped
+	report, err = CheckLinks(ctx, h.DB, checker, DefaultRecheckAfter, -1)
+	require.NoError(t, err)
+	require.Zero(t, report.Checked)
+
+	// known dead links are reported but did not die again
+	report, err = CheckLinks(ctx, h.DB, checker, 0, -1)
+	require.NoError(t, err)
+	require.Equal(t, 3, report.Checked)
+	require.Len(t, report.Dead, 1)
+	require.Zero(t, report.Died)
+}
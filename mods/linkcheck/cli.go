@@ -0,0 +1,103 @@
+//
+//  Copyright (c) 2025 Chakib Ben Ziane <contact@blob42.xyz>  and [`gosuki` contributors](https://github.com/blob42/gosuki/graphs/contributors).
+//  All rights reserved.
+//
+//  SPDX-License-Identifier: AGPL-3.0-or-later
+//
+//  This file is part of GoSuki.
+//
+//  GoSuki is free software: you can redistribute it and/or modify
+//  it under the terms of the GNU Affero General Public License as
+//  published by the Free Software Foundation, either version 3 of the
+//  License, or (at your option) any later version.
+//
+//  GoSuki is distributed in the hope that it will be useful,
+//  but WITHOUT ANY WARRANTY; without even the implied warranty of
+//  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU Affero General Public License for more details.
+//
+//  You should have received a copy of the GNU Affero General Public License
+//  along with gosuki.  If not, see <http://www.gnu.org/licenses/>.
+
+package linkcheck
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/blob42/gosuki/cmd"
+	db "github.com/blob42/gosuki/internal/database"
+)
+
+var linksCheckCmd = &cli.Command{
+	Name:  "check",
+	Usage: "check bookmarked links now and report dead ones",
+	Description: `Checks the links not checked since linkcheck.recheck-after and records
+their status. Dead links can then be listed with: suki status:dead
+
+Checking is refused while the gosuki daemon is running, it checks links itself.`,
+	Flags: []cli.Flag{
+		&cli.BoolFlag{
+			Name:    "all",
+			Aliases: []string{"a"},
+			Usage:   "check all links, including recently checked ones",
+		},
+		&cli.IntFlag{
+			Name:    "limit",
+			Aliases: []string{"n"},
+			Usage:   "maximum number of links to check, 0 for no limit",
+		},
+	},
+	Action: checkLinksAction,
+}
+
+var LinksCmds = &cli.Command{
+	Name:  "links",
+	Usage: "dead link checker commands",
+	Commands: []*cli.Command{
+		linksCheckCmd,
+	},
+}
+
+func checkLinksAction(ctx context.Context, c *cli.Command) error {
+	olderThan := Config.RecheckAfter
+	if c.Bool("all") {
+		olderThan = 0
+	}
+
+	limit := c.Int("limit")
+	if limit <= 0 {
+		limit = -1
+	}
+
+	db.Init(ctx, c)
+	defer db.DiskDB.Close()
+
+	// the daemon checks links itself and would overwrite the results with its
+	// cache
+	if err := db.CheckDaemonStopped(); err != nil {
+		return err
+	}
+
+	report, err := CheckLinks(ctx, db.DiskDB, NewChecker(Config), olderThan, limit)
+	if err != nil {
+		return err
+	}
+
+	for _, res := range report.Dead {
+		fmt.Printf("%-4d %s\n", res.Status, res.URL)
+	}
+	for _, res := range report.Failed {
+		fmt.Printf("%-4s %s: %s\n", "err", res.URL, res.Err)
+	}
+
+	fmt.Printf("checked %d links: %d dead (%d new), %d failed\n",
+		report.Checked, len(report.Dead), report.Died, len(report.Failed))
+	return nil
+}
+
+func init() {
+	cmd.RegisterModCommand(ModID, LinksCmds)
+}
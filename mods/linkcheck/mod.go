@@ -0,0 +1,151 @@
+//
+//  Copyright (c) 2025 Chakib Ben Ziane <contact@blob42.xyz>  and [`gosuki` contributors](https://github.com/blob42/gosuki/graphs/contributors).
+//  All rights reserved.
+//
+//  SPDX-License-Identifier: AGPL-3.0-or-later
+//
+//  This file is part of GoSuki.
+//
+//  GoSuki is free software: you can redistribute it and/or modify
+//  it under the terms of the GNU Affero General Public License as
+//  published by the Free Software Foundation, either version 3 of the
+//  License, or (at your option) any later version.
+//
+//  GoSuki is distributed in the hope that it will be useful,
+//  but WITHOUT ANY WARRANTY; without even the implied warranty of
+//  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU Affero General Public License for more details.
+//
+//  You should have received a copy of the GNU Affero General Public License
+//  along with gosuki.  If not, see <http://www.gnu.org/licenses/>.
+
+// Package linkcheck periodically checks that bookmarked urls are still alive.
+// The http status, final redirect url and time of the last check are stored
+// with each bookmark and can be searched with the `status:` query qualifier.
+// The update hooks are fired when a link dies.
+package linkcheck
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/blob42/gosuki"
+	db "github.com/blob42/gosuki/internal/database"
+	"github.com/blob42/gosuki/pkg/config"
+	"github.com/blob42/gosuki/pkg/logging"
+	"github.com/blob42/gosuki/pkg/modules"
+	"github.com/blob42/gosuki/pkg/watch"
+)
+
+const (
+	ModID = "linkcheck"
+
+	DefaultInterval     = time.Hour
+	DefaultRecheckAfter = 7 * 24 * time.Hour
+	DefaultBatchSize    = 500
+	DefaultConcurrency  = 8
+	DefaultHostDelay    = 2 * time.Second
+	DefaultTimeout      = 15 * time.Second
+	DefaultUserAgent    = "Mozilla/5.0 (compatible; gosuki-linkcheck)"
+)
+
+var (
+	Config *LinkCheckConfig
+	log    = logging.GetLogger(ModID)
+)
+
+type LinkCheckConfig struct {
+	// Link checking is opt-in since it sends requests to every bookmarked site
+	Enabled bool `toml:"enabled" mapstructure:"enabled"`
+
+	// Time between two batches of checks
+	Interval time.Duration `toml:"interval" mapstructure:"interval"`
+
+	// Links are checked again after this delay
+	RecheckAfter time.Duration `toml:"recheck-after" mapstructure:"recheck-after"`
+
+	// Maximum number of links checked per batch
+	BatchSize int `toml:"batch-size" mapstructure:"batch-size"`
+
+	// Number of links checked in parallel
+	Concurrency int `toml:"concurrency" mapstructure:"concurrency"`
+
+	// Minimum delay between two requests to the same host
+	HostDelay time.Duration `toml:"host-delay" mapstructure:"host-delay"`
+
+	// Timeout of a single check, redirects included
+	Timeout time.Duration `toml:"timeout" mapstructure:"timeout"`
+
+	UserAgent string `toml:"user-agent" mapstructure:"user-agent"`
+}
+
+func NewLinkCheckConfig() *LinkCheckConfig {
+	return &LinkCheckConfig{
+		Interval:     DefaultInterval,
+		RecheckAfter: DefaultRecheckAfter,
+		BatchSize:    DefaultBatchSize,
+		Concurrency:  DefaultConcurrency,
+		HostDelay:    DefaultHostDelay,
+		Timeout:      DefaultTimeout,
+		UserAgent:    DefaultUserAgent,
+	}
+}
+
+// LinkChecker is the module checking a batch of links at each interval. It
+// does not produce bookmarks, results are written to the L2 cache.
+type LinkChecker struct {
+	ctx     context.Context
+	checker *Checker
+}
+
+func (lc LinkChecker) ModInfo() modules.ModInfo {
+	return modules.ModInfo{
+		ID: modules.ModID(ModID),
+		New: func() modules.Module {
+			return &LinkChecker{}
+		},
+	}
+}
+
+func (lc *LinkChecker) Init(ctx *modules.Context) error {
+	if !Config.Enabled {
+		return &modules.ErrModDisabled{
+			Err:    errors.New("link checking disabled"),
+			Reason: "set linkcheck.enabled to check bookmarked links",
+		}
+	}
+
+	lc.ctx = ctx.Context
+	lc.checker = NewChecker(Config)
+	return nil
+}
+
+func (lc *LinkChecker) Fetch() ([]*gosuki.Bookmark, error) {
+	report, err := CheckLinks(lc.ctx, db.L2Cache.DB, lc.checker, Config.RecheckAfter, Config.BatchSize)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Info("checked links", "checked", report.Checked, "dead", len(report.Dead), "died", report.Died)
+	if report.Checked > 0 {
+		db.ScheduleBackupToDisk()
+	}
+
+	return nil, nil
+}
+
+// Interval at which a batch of links is checked
+func (lc LinkChecker) Interval() time.Duration {
+	return Config.Interval
+}
+
+func init() {
+	Config = NewLinkCheckConfig()
+	config.RegisterConfigurator(ModID, config.AsConfigurator(Config))
+	modules.RegisterModule(&LinkChecker{})
+}
+
+// interface guards
+var _ watch.Poller = (*LinkChecker)(nil)
+var _ modules.Initializer = (*LinkChecker)(nil)
@@ -0,0 +1,270 @@
+//
+//  Copyright (c) 2025 Chakib Ben Ziane <contact@blob42.xyz>  and [`gosuki` contributors](https://github.com/blob42/gosuki/graphs/contributors).
+//  All rights reserved.
+//
+//  SPDX-License-Identifier: AGPL-3.0-or-later
+//
+//  This file is part of GoSuki.
+//
+//  GoSuki is free software: you can redistribute it and/or modify
+//  it under the terms of the GNU Affero General Public License as
+//  published by the Free Software Foundation, either version 3 of the
+//  License, or (at your option) any later version.
+//
+//  GoSuki is distributed in the hope that it will be useful,
+//  but WITHOUT ANY WARRANTY; without even the implied warranty of
+//  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU Affero General Public License for more details.
+//
+//  You should have received a copy of the GNU Affero General Public License
+//  along with gosuki.  If not, see <http://www.gnu.org/licenses/>.
+//
+
+// Package p2psync synchronizes bookmarks between gosuki nodes. Each node
+// serves its changes over http and pulls the changes of its peers, using the
+// lamport clock versions of the database to only exchange new changes.
+package p2psync
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gofrs/uuid"
+
+	db "github.com/blob42/gosuki/internal/database"
+	"github.com/blob42/gosuki/pkg/config"
+	"github.com/blob42/gosuki/pkg/logging"
+	"github.com/blob42/gosuki/pkg/modules"
+)
+
+const (
+	ModID = "p2p-sync"
+
+	DefaultSyncInterval = time.Minute
+
+	// suffix of the file next to the database storing the node id
+	nodeIDSuffix = ".node_id"
+)
+
+var (
+	Config *SyncConfig
+	log    = logging.GetLogger(ModID)
+)
+
+type SyncConfig struct {
+	// Address to serve changes to peers, ex: 127.0.0.1:4242
+	Listen string `toml:"listen" mapstructure:"listen"`
+
+	// Base urls of peers to pull changes from, ex: http://laptop:4242
+	Peers []string `toml:"peers" mapstructure:"peers"`
+
+	// Shared secret between peers, required to listen on a non loopback
+	// address
+	Secret string `toml:"secret" mapstructure:"secret"`
+
+	SyncInterval time.Duration `toml:"sync-interval" mapstructure:"sync-interval"`
+}
+
+func NewSyncConfig() *SyncConfig {
+	return &SyncConfig{
+		Peers:        []string{},
+		SyncInterval: DefaultSyncInterval,
+	}
+}
+
+// P2PSync is the sync module. It listens to module messages to push
+// notifications to peers after each local change.
+type P2PSync struct {
+	node *Node
+
+	// last known name of synced peers
+	peers map[uuid.UUID]string
+}
+
+func (ps P2PSync) ModInfo() modules.ModInfo {
+	return modules.ModInfo{
+		ID: modules.ModID(ModID),
+		New: func() modules.Module {
+			return &P2PSync{}
+		},
+	}
+}
+
+func (ps *P2PSync) Init(ctx *modules.Context) error {
+	if Config.Listen == "" && len(Config.Peers) == 0 {
+		return &modules.ErrModDisabled{
+			Err:    errors.New("no listen address or peers"),
+			Reason: "configure p2p-sync.listen or p2p-sync.peers",
+		}
+	}
+
+	if err := checkListen(Config.Listen, Config.Secret); err != nil {
+		return err
+	}
+
+	id, err := LoadNodeID(config.DBPath + nodeIDSuffix)
+	if err != nil {
+		return err
+	}
+
+	ps.node = NewNode(id, db.L2Cache.DB, db.Cache.DB)
+	ps.node.Secret = Config.Secret
+	if hostname, err := os.Hostname(); err == nil {
+		ps.node.Name = hostname
+	}
+	ps.peers = map[uuid.UUID]string{}
+
+	log.Info("node initialized", "id", id, "peers", len(Config.Peers))
+	return nil
+}
+
+// checkListen refuses to serve changes without a secret on an address
+// reachable from other hosts.
+func checkListen(addr, secret string) error {
+	if addr == "" || secret != "" || isLoopback(addr) {
+		return nil
+	}
+
+	return &modules.ErrModDisabled{
+		Err:    fmt.Errorf("no secret to serve changes on %s", addr),
+		Reason: "set p2p-sync.secret or listen on a loopback address",
+	}
+}
+
+func isLoopback(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+func (ps *P2PSync) MsgListen(ctx context.Context, queue <-chan modules.ModMsg) {
+	if Config.Listen != "" {
+		go ps.serve(ctx)
+	}
+
+	ticker := time.NewTicker(Config.SyncInterval)
+	defer ticker.Stop()
+
+	ps.pullPeers(ctx)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			ps.pullPeers(ctx)
+		case <-ps.node.Notify:
+			ps.pullPeers(ctx)
+		case msg := <-queue:
+			if msg.Type == modules.MsgTriggerSync {
+				ps.notifyPeers(ctx)
+			}
+		}
+	}
+}
+
+func (ps *P2PSync) serve(ctx context.Context) {
+	server := &http.Server{
+		Addr:        Config.Listen,
+		Handler:     ps.node.Handler(),
+		ReadTimeout: 30 * time.Second,
+		IdleTimeout: 120 * time.Second,
+		BaseContext: func(net.Listener) context.Context { return ctx },
+	}
+
+	go func() {
+		<-ctx.Done()
+		server.Close()
+	}()
+
+	log.Info("serving changes", "addr", Config.Listen)
+	if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		log.Error("serving changes", "addr", Config.Listen, "err", err)
+	}
+}
+
+func (ps *P2PSync) pullPeers(ctx context.Context) {
+	synced := false
+	for _, peer := range Config.Peers {
+		info, err := ps.node.Pull(ctx, peer)
+		if err != nil {
+			log.Warn("pulling changes", "peer", peer, "err", err)
+			continue
+		}
+		ps.peers[info.ID] = info.Name
+		synced = true
+	}
+
+	if !synced {
+		return
+	}
+
+	peers := make(map[uuid.UUID]string, len(ps.peers))
+	for id, name := range ps.peers {
+		peers[id] = name
+	}
+
+	go func() {
+		modules.ModMsgBus <- modules.ModMsg{
+			Type:    modules.MsgSyncPeers,
+			To:      "tui",
+			Payload: peers,
+		}
+	}()
+}
+
+func (ps *P2PSync) notifyPeers(ctx context.Context) {
+	for _, peer := range Config.Peers {
+		if err := ps.node.NotifyPeer(ctx, peer); err != nil {
+			log.Debug("notifying peer", "peer", peer, "err", err)
+		}
+	}
+}
+
+// LoadNodeID reads the node id stored at path or creates a new one
+func LoadNodeID(path string) (uuid.UUID, error) {
+	data, err := os.ReadFile(path)
+	if err == nil {
+		return uuid.FromString(strings.TrimSpace(string(data)))
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return uuid.Nil, err
+	}
+
+	id, err := uuid.NewV4()
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	if err = os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return uuid.Nil, err
+	}
+
+	if err = os.WriteFile(path, []byte(id.String()+"\n"), 0600); err != nil {
+		return uuid.Nil, fmt.Errorf("saving node id: %w", err)
+	}
+
+	return id, nil
+}
+
+func init() {
+	Config = NewSyncConfig()
+	config.RegisterConfigurator(ModID, config.AsConfigurator(Config))
+	modules.RegisterModule(&P2PSync{})
+}
+
+// interface guards
+var _ modules.MsgListener = (*P2PSync)(nil)
+var _ modules.Initializer = (*P2PSync)(nil)
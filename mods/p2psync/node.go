@@ -0,0 +1,456 @@
+//
+//  Copyright (c) 2025 Chakib Ben Ziane <contact@blob42.xyz>  and [`gosuki` contributors](https://github.com/blob42/gosuki/graphs/contributors).
+//  All rights reserved.
+//
+//  SPDX-License-Identifier: AGPL-3.0-or-later
+//
+//  This file is part of GoSuki.
+//
+//  GoSuki is free software: you can redistribute it and/or modify
+//  it under the terms of the GNU Affero General Public License as
+//  published by the Free Software Foundation, either version 3 of the
+//  License, or (at your option) any later version.
+//
+//  GoSuki is distributed in the hope that it will be useful,
+//  but WITHOUT ANY WARRANTY; without even the implied warranty of
+//  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU Affero General Public License for more details.
+//
+//  You should have received a copy of the GNU Affero General Public License
+//  along with gosuki.  If not, see <http://www.gnu.org/licenses/>.
+//
+
+package p2psync
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofrs/uuid"
+
+	db "github.com/blob42/gosuki/internal/database"
+)
+
+const (
+	NodePath    = "/p2p/node"
+	ChangesPath = "/p2p/changes"
+	NotifyPath  = "/p2p/notify"
+
+	// number of changes applied at once when pulling from a peer
+	batchSize = 500
+)
+
+// NodeInfo describes a node to its peers
+type NodeInfo struct {
+	ID    uuid.UUID `json:"id"`
+	Name  string    `json:"name"`
+	Clock uint64    `json:"clock"`
+}
+
+// Change is a bookmark change as streamed to peers
+type Change struct {
+	URL       string    `json:"url"`
+	Title     string    `json:"metadata"`
+	Tags      string    `json:"tags"`
+	Desc      string    `json:"desc"`
+	Modified  uint64    `json:"modified"`
+	Created   uint64    `json:"created"`
+	Flags     int       `json:"flags"`
+	Module    string    `json:"module"`
+	Version   uint64    `json:"version"`
+	NodeID    uuid.UUID `json:"node_id"`
+	Deleted   bool      `json:"deleted"`
+	DeletedAt uint64    `json:"deleted_at"`
+}
+
+func changeFromRaw(raw *db.RawBookmark) Change {
+	return Change{
+		URL:       raw.URL,
+		Title:     raw.Metadata,
+		Tags:      raw.Tags,
+		Desc:      raw.Desc,
+		Modified:  raw.Modified,
+		Created:   raw.Created,
+		Flags:     raw.Flags,
+		Module:    raw.Module,
+		Version:   raw.Version,
+		NodeID:    uuid.UUID(raw.NodeID),
+		Deleted:   raw.Deleted,
+		DeletedAt: raw.DeletedAt,
+	}
+}
+
+func (c Change) raw() *db.RawBookmark {
+	return &db.RawBookmark{
+		URL:       c.URL,
+		Metadata:  c.Title,
+		Tags:      c.Tags,
+		Desc:      c.Desc,
+		Modified:  c.Modified,
+		Created:   c.Created,
+		Flags:     c.Flags,
+		Module:    c.Module,
+		Version:   c.Version,
+		NodeID:    db.UUID(c.NodeID),
+		Deleted:   c.Deleted,
+		DeletedAt: c.DeletedAt,
+	}
+}
+
+// Node exchanges bookmark changes with its peers. Changes are pulled: a node
+// asks a peer for all rows with a version greater than the last clock it
+// received from this peer. The peer clocks are stored in the sync_nodes table.
+type Node struct {
+	ID   uuid.UUID
+	Name string
+
+	// Shared secret required from peers, if set
+	Secret string
+
+	// Database served to peers, also holds the peer clocks
+	Store *db.DB
+
+	// Database where changes pulled from peers are applied
+	Target *db.DB
+
+	// Receives a value when a peer notifies about new changes
+	Notify chan struct{}
+
+	client *http.Client
+}
+
+func NewNode(id uuid.UUID, store, target *db.DB) *Node {
+	return &Node{
+		ID:     id,
+		Name:   id.String(),
+		Store:  store,
+		Target: target,
+		Notify: make(chan struct{}, 1),
+		client: &http.Client{Timeout: 5 * time.Minute},
+	}
+}
+
+// Info returns the current node info
+func (n *Node) Info(ctx context.Context) (*NodeInfo, error) {
+	clock, err := n.Store.GetDBClock(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &NodeInfo{
+		ID:    n.ID,
+		Name:  n.Name,
+		Clock: clock.Value,
+	}, nil
+}
+
+func (n *Node) authorized(r *http.Request) bool {
+	if n.Secret == "" {
+		return true
+	}
+	got := []byte(r.Header.Get("Authorization"))
+	return subtle.ConstantTimeCompare(got, []byte("Bearer "+n.Secret)) == 1
+}
+
+func (n *Node) authorize(r *http.Request) {
+	if n.Secret != "" {
+		r.Header.Set("Authorization", "Bearer "+n.Secret)
+	}
+}
+
+// Handler returns the http handler serving this node to its peers
+func (n *Node) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET "+NodePath, n.serveInfo)
+	mux.HandleFunc("GET "+ChangesPath, n.serveChanges)
+	mux.HandleFunc("POST "+NotifyPath, n.serveNotify)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !n.authorized(r) {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		mux.ServeHTTP(w, r)
+	})
+}
+
+func (n *Node) serveInfo(w http.ResponseWriter, r *http.Request) {
+	info, err := n.Info(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err = json.NewEncoder(w).Encode(info); err != nil {
+		log.Error("encoding node info", "err", err)
+	}
+}
+
+// serveChanges streams, as newline delimited json, the changes with a version
+// greater than the `since` parameter. Changes originating from the requesting
+// `node` are skipped.
+func (n *Node) serveChanges(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	since, err := strconv.ParseUint(query.Get("since"), 10, 64)
+	if err != nil {
+		http.Error(w, "invalid since parameter", http.StatusBadRequest)
+		return
+	}
+
+	peer, err := uuid.FromString(query.Get("node"))
+	if err != nil {
+		http.Error(w, "invalid node parameter", http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	enc := json.NewEncoder(w)
+	flusher, canFlush := w.(http.Flusher)
+
+	count := 0
+	err = n.Store.ChangesSince(r.Context(), since, db.UUID(peer), db.UUID(n.ID),
+		func(raw *db.RawBookmark) error {
+			if err := enc.Encode(changeFromRaw(raw)); err != nil {
+				return err
+			}
+			count++
+			if canFlush && count%batchSize == 0 {
+				flusher.Flush()
+			}
+			return nil
+		})
+	if err != nil {
+		// headers are already sent, the peer detects the truncated stream
+		log.Error("streaming changes", "peer", peer, "err", err)
+		return
+	}
+
+	log.Debug("sent changes", "peer", peer, "since", since, "count", count)
+}
+
+func (n *Node) serveNotify(w http.ResponseWriter, r *http.Request) {
+	select {
+	case n.Notify <- struct{}{}:
+	default:
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func (n *Node) get(ctx context.Context, peer string, path string, query url.Values) (*http.Response, error) {
+	target := strings.TrimSuffix(peer, "/") + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return nil, err
+	}
+	n.authorize(req)
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("%s: %s", target, resp.Status)
+	}
+
+	return resp, nil
+}
+
+// PeerInfo fetches the node info of a peer
+func (n *Node) PeerInfo(ctx context.Context, peer string) (*NodeInfo, error) {
+	resp, err := n.get(ctx, peer, NodePath, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	info := &NodeInfo{}
+	if err = json.NewDecoder(resp.Body).Decode(info); err != nil {
+		return nil, fmt.Errorf("decoding node info: %w", err)
+	}
+
+	if info.ID == uuid.Nil {
+		return nil, errors.New("peer did not send its node id")
+	}
+
+	return info, nil
+}
+
+// Pull fetches the changes made on `peer` since the last sync and applies
+// them to the target database. The peer clock is recorded in the store once
+// the applied changes are synced to it.
+func (n *Node) Pull(ctx context.Context, peer string) (*NodeInfo, error) {
+	info, err := n.PeerInfo(ctx, peer)
+	if err != nil {
+		return nil, err
+	}
+
+	if info.ID == n.ID {
+		return nil, fmt.Errorf("peer %s has the same node id as this node", peer)
+	}
+
+	since, err := n.Store.PeerClock(ctx, db.UUID(info.ID))
+	if err != nil {
+		return nil, err
+	}
+
+	// pulled changes are compared with the store, it must hold the local
+	// changes not synced yet
+	if n.Target != n.Store {
+		n.Target.SyncTo(n.Store)
+	}
+
+	resp, err := n.get(ctx, peer, ChangesPath, url.Values{
+		"since": {strconv.FormatUint(since, 10)},
+		"node":  {n.ID.String()},
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	clock := max(since, info.Clock)
+	batch := make([]*db.RawBookmark, 0, batchSize)
+	total := 0
+
+	dec := json.NewDecoder(bufio.NewReader(resp.Body))
+	for dec.More() {
+		change := Change{}
+		if err = dec.Decode(&change); err != nil {
+			return nil, fmt.Errorf("decoding change: %w", err)
+		}
+		clock = max(clock, change.Version)
+		batch = append(batch, change.raw())
+
+		if len(batch) == batchSize {
+			if err = n.apply(ctx, batch, clock); err != nil {
+				return nil, err
+			}
+			total += len(batch)
+			batch = batch[:0]
+		}
+	}
+
+	if len(batch) > 0 {
+		if err = n.apply(ctx, batch, clock); err != nil {
+			return nil, err
+		}
+		total += len(batch)
+	}
+
+	// the changes must reach the store before the peer clock, a clock saved
+	// to disk without its changes would skip them forever
+	if total > 0 && n.Target != n.Store {
+		n.Target.SyncToClock(n.Store, clock)
+	}
+
+	if err = n.Store.SetPeerClock(ctx, db.UUID(info.ID), clock); err != nil {
+		return nil, err
+	}
+
+	if db.Clock != nil {
+		db.Clock.Tick(clock)
+	}
+
+	log.Debug("pulled changes", "peer", info.Name, "count", total, "clock", clock)
+	info.Clock = clock
+	return info, nil
+}
+
+// apply writes pulled changes to a buffer then merges them in the target
+// database. Conflicts are resolved per bookmark, last writer wins: a change
+// is applied if its version is greater than the version of the bookmark in the
+// store, ties are won by the greatest node id. Winning changes replace the
+// title, tags and description of the bookmark.
+func (n *Node) apply(ctx context.Context, raws []*db.RawBookmark, clock uint64) error {
+	buffer, err := db.NewBuffer(ModID)
+	if err != nil {
+		return err
+	}
+	defer buffer.Close()
+	buffer.Authoritative = true
+
+	winners := make([]*db.RawBookmark, 0, len(raws))
+	for _, raw := range raws {
+		wins, err := n.wins(ctx, raw)
+		if err != nil {
+			return err
+		}
+		if wins {
+			winners = append(winners, raw)
+		} else {
+			log.Trace("older change, skipping", "url", raw.URL, "version", raw.Version)
+		}
+	}
+
+	if len(winners) == 0 {
+		return nil
+	}
+
+	if err = buffer.InsertRawBookmarks(ctx, winners...); err != nil {
+		return err
+	}
+
+	buffer.SyncToClock(n.Target, clock)
+	return nil
+}
+
+// wins reports whether the pulled change raw is newer than the bookmark in the
+// store
+func (n *Node) wins(ctx context.Context, raw *db.RawBookmark) (bool, error) {
+	local, err := n.Store.BookmarkByURL(ctx, raw.URL)
+	if errors.Is(err, db.ErrBookmarkNotFound) {
+		return true, nil
+	} else if err != nil {
+		return false, err
+	}
+
+	if raw.Version != local.Version {
+		return raw.Version > local.Version, nil
+	}
+
+	// rows changed by this node have no node id
+	localNode := local.NodeID
+	if uuid.UUID(localNode) == uuid.Nil {
+		localNode = db.UUID(n.ID)
+	}
+	return bytes.Compare(raw.NodeID[:], localNode[:]) > 0, nil
+}
+
+// NotifyPeer tells `peer` that this node has new changes to pull
+func (n *Node) NotifyPeer(ctx context.Context, peer string) error {
+	target := strings.TrimSuffix(peer, "/") + NotifyPath
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, nil)
+	if err != nil {
+		return err
+	}
+	n.authorize(req)
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusAccepted {
+		return fmt.Errorf("%s: %s", target, resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,309 @@
+package p2psync
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/blob42/gosuki"
+	db "github.com/blob42/gosuki/internal/database"
+)
+
+func TestMain(m *testing.M) {
+	db.Clock = &db.LamportClock{}
+	db.RegisterSqliteHooks()
+	os.Exit(m.Run())
+}
+
+type testDaemon struct {
+	*Node
+	db     *db.DB
+	server *httptest.Server
+}
+
+// starts a node on localhost with its own database file, used as both the
+// store and the target
+func newTestDaemon(t *testing.T, name string) *testDaemon {
+	t.Helper()
+	dbPath := filepath.Join(t.TempDir(), name+".db")
+
+	memDB, err := db.NewDB(name+"_mem", "", db.DBTypeInMemoryDSN).Init()
+	require.NoError(t, err)
+	require.NoError(t, memDB.InitSchema(context.Background()))
+	require.NoError(t, memDB.BackupToDisk(dbPath))
+	memDB.Close()
+
+	store, err := db.NewDB(name, dbPath, db.DBTypeFileDSN).Init()
+	require.NoError(t, err)
+
+	id, err := LoadNodeID(dbPath + nodeIDSuffix)
+	require.NoError(t, err)
+
+	node := NewNode(id, store, store)
+	node.Name = name
+	server := httptest.NewServer(node.Handler())
+
+	t.Cleanup(func() {
+		server.Close()
+		store.Close()
+	})
+
+	return &testDaemon{node, store, server}
+}
+
+// starts a node serving its database file and applying pulled changes to a
+// separate cache, like the L2 and L1 caches of the daemon
+func newCachedTestDaemon(t *testing.T, name string) *testDaemon {
+	t.Helper()
+	d := newTestDaemon(t, name)
+
+	cache, err := db.NewDB(name+"_l1", "", db.DBTypeCacheDSN).Init()
+	require.NoError(t, err)
+	require.NoError(t, cache.InitSchema(context.Background()))
+	t.Cleanup(func() { cache.Close() })
+
+	// the L1 cache replaces the bookmarks of the L2 cache
+	cache.Authoritative = true
+
+	d.Target = cache
+	return d
+}
+
+// adds local changes to the daemon's database at the given clock
+func (d *testDaemon) add(t *testing.T, clock uint64, bookmarks ...*gosuki.Bookmark) {
+	t.Helper()
+	buffer, err := db.NewBuffer("test")
+	require.NoError(t, err)
+	defer buffer.Close()
+
+	for _, bk := range bookmarks {
+		require.NoError(t, buffer.UpsertBookmark(bk))
+	}
+	if d.Target != d.db {
+		buffer.SyncToClock(d.Target, clock)
+	}
+	buffer.SyncToClock(d.db, clock)
+}
+
+// replaces the tags of a bookmark on the daemon at the given clock, like the
+// tags API
+func (d *testDaemon) setTags(t *testing.T, clock uint64, url string, tags string) {
+	t.Helper()
+	raw := d.bookmark(t, url)
+	raw.Tags = tags
+	raw.NodeID = db.UUID(uuid.Nil)
+
+	buffer, err := db.NewBuffer("test")
+	require.NoError(t, err)
+	defer buffer.Close()
+	buffer.Authoritative = true
+
+	require.NoError(t, buffer.InsertRawBookmarks(context.Background(), raw))
+	if d.Target != d.db {
+		buffer.SyncToClock(d.Target, clock)
+	}
+	buffer.SyncToClock(d.db, clock)
+}
+
+func (d *testDaemon) bookmark(t *testing.T, url string) *db.RawBookmark {
+	t.Helper()
+	raw, err := d.db.BookmarkByURL(context.Background(), url)
+	require.NoError(t, err)
+	return raw
+}
+
+func (d *testDaemon) count(t *testing.T) int {
+	t.Helper()
+	var count int
+	require.NoError(t, d.db.Handle.Get(&count, "SELECT count(*) FROM gskbookmarks"))
+	return count
+}
+
+func TestLoadNodeID(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "gosuki.db"+nodeIDSuffix)
+
+	id, err := LoadNodeID(path)
+	require.NoError(t, err)
+	require.NotEqual(t, uuid.Nil, id)
+
+	again, err := LoadNodeID(path)
+	require.NoError(t, err)
+	require.Equal(t, id, again)
+}
+
+func TestConvergence(t *testing.T) {
+	ctx := context.Background()
+	alpha := newCachedTestDaemon(t, "alpha")
+	beta := newCachedTestDaemon(t, "beta")
+
+	alpha.add(t, 1,
+		&gosuki.Bookmark{URL: "https://a.com", Title: "A", Tags: []string{"a"}, Module: "test"},
+		&gosuki.Bookmark{URL: "https://shared.com", Title: "Shared", Tags: []string{"alpha"}, Module: "test"},
+	)
+	beta.add(t, 1,
+		&gosuki.Bookmark{URL: "https://b.com", Title: "B", Tags: []string{"b"}, Module: "test"},
+		&gosuki.Bookmark{URL: "https://shared.com", Title: "Shared", Tags: []string{"beta"}, Module: "test"},
+	)
+
+	info, err := alpha.Pull(ctx, beta.server.URL)
+	require.NoError(t, err)
+	require.Equal(t, beta.ID, info.ID)
+	require.Equal(t, "beta", info.Name)
+
+	_, err = beta.Pull(ctx, alpha.server.URL)
+	require.NoError(t, err)
+
+	require.Equal(t, 3, alpha.count(t))
+	require.Equal(t, 3, beta.count(t))
+
+	// concurrent changes of the same version are won by the greatest node id
+	shared := ",alpha,"
+	if bytes.Compare(beta.ID[:], alpha.ID[:]) > 0 {
+		shared = ",beta,"
+	}
+	require.Equal(t, shared, alpha.bookmark(t, "https://shared.com").Tags)
+	require.Equal(t, shared, beta.bookmark(t, "https://shared.com").Tags)
+
+	// pulled changes keep the node that made them
+	require.Equal(t, db.UUID(alpha.ID), beta.bookmark(t, "https://a.com").NodeID)
+
+	t.Run("peer clocks are recorded", func(t *testing.T) {
+		clock, err := alpha.db.PeerClock(ctx, db.UUID(beta.ID))
+		require.NoError(t, err)
+		require.NotZero(t, clock)
+
+		// nothing new to pull
+		info, err := alpha.Pull(ctx, beta.server.URL)
+		require.NoError(t, err)
+		require.Equal(t, clock, info.Clock)
+	})
+
+	t.Run("tombstones propagate", func(t *testing.T) {
+		require.NoError(t, alpha.db.MarkDeleted("https://a.com"))
+		_, err := alpha.db.Handle.Exec(
+			"UPDATE gskbookmarks SET version = 100 WHERE URL = ?", "https://a.com")
+		require.NoError(t, err)
+
+		_, err = beta.Pull(ctx, alpha.server.URL)
+		require.NoError(t, err)
+		require.True(t, beta.bookmark(t, "https://a.com").Deleted)
+	})
+}
+
+func TestConvergenceLastWriterWins(t *testing.T) {
+	ctx := context.Background()
+	alpha := newCachedTestDaemon(t, "alpha")
+	beta := newCachedTestDaemon(t, "beta")
+
+	alpha.add(t, 1, &gosuki.Bookmark{
+		URL: "https://a.com", Title: "A", Tags: []string{"foo", "bar"}, Module: "test",
+	})
+	_, err := beta.Pull(ctx, alpha.server.URL)
+	require.NoError(t, err)
+	require.Equal(t, ",bar,foo,", beta.bookmark(t, "https://a.com").Tags)
+
+	t.Run("removed tags", func(t *testing.T) {
+		alpha.setTags(t, 10, "https://a.com", ",foo,")
+
+		_, err := beta.Pull(ctx, alpha.server.URL)
+		require.NoError(t, err)
+		require.Equal(t, ",foo,", beta.bookmark(t, "https://a.com").Tags)
+
+		_, err = alpha.Pull(ctx, beta.server.URL)
+		require.NoError(t, err)
+		require.Equal(t, ",foo,", alpha.bookmark(t, "https://a.com").Tags)
+	})
+
+	t.Run("older changes are ignored", func(t *testing.T) {
+		beta.setTags(t, 30, "https://a.com", ",beta,")
+		alpha.setTags(t, 20, "https://a.com", ",alpha,")
+
+		_, err := beta.Pull(ctx, alpha.server.URL)
+		require.NoError(t, err)
+		require.Equal(t, ",beta,", beta.bookmark(t, "https://a.com").Tags)
+
+		_, err = alpha.Pull(ctx, beta.server.URL)
+		require.NoError(t, err)
+		require.Equal(t, ",beta,", alpha.bookmark(t, "https://a.com").Tags)
+	})
+}
+
+func TestPullSyncsChangesToStore(t *testing.T) {
+	ctx := context.Background()
+	alpha := newTestDaemon(t, "alpha")
+	beta := newCachedTestDaemon(t, "beta")
+
+	alpha.add(t, 1,
+		&gosuki.Bookmark{URL: "https://a.com", Title: "A", Tags: []string{"a"}, Module: "test"},
+		&gosuki.Bookmark{URL: "https://b.com", Title: "B", Tags: []string{"b"}, Module: "test"},
+	)
+
+	_, err := beta.Pull(ctx, alpha.server.URL)
+	require.NoError(t, err)
+
+	// the store written to disk holds the peer clock with its changes
+	clock, err := beta.db.PeerClock(ctx, db.UUID(alpha.ID))
+	require.NoError(t, err)
+	require.NotZero(t, clock)
+	require.Equal(t, 2, beta.count(t))
+	require.Equal(t, ",a,", beta.bookmark(t, "https://a.com").Tags)
+}
+
+func TestNodeSecret(t *testing.T) {
+	alpha := newTestDaemon(t, "alpha")
+	beta := newTestDaemon(t, "beta")
+	beta.Secret = "s3cret"
+
+	_, err := alpha.Pull(context.Background(), beta.server.URL)
+	require.ErrorContains(t, err, "401")
+
+	alpha.Secret = "s3cret"
+	_, err = alpha.Pull(context.Background(), beta.server.URL)
+	require.NoError(t, err)
+}
+
+func TestCheckListen(t *testing.T) {
+	for addr, ok := range map[string]bool{
+		"":               true,
+		"127.0.0.1:4242": true,
+		"[::1]:4242":     true,
+		"localhost:4242": true,
+		":4242":          false,
+		"0.0.0.0:4242":   false,
+		"10.0.0.2:4242":  false,
+		"laptop:4242":    false,
+	} {
+		err := checkListen(addr, "")
+		if ok {
+			require.NoError(t, err, addr)
+		} else {
+			require.Error(t, err, addr)
+		}
+		require.NoError(t, checkListen(addr, "s3cret"), addr)
+	}
+}
+
+func TestNotify(t *testing.T) {
+	alpha := newTestDaemon(t, "alpha")
+	beta := newTestDaemon(t, "beta")
+
+	require.NoError(t, alpha.NotifyPeer(context.Background(), beta.server.URL))
+
+	select {
+	case <-beta.Notify:
+	default:
+		t.Fatal("peer was not notified")
+	}
+
+	resp, err := http.Get(beta.server.URL + ChangesPath + "?since=abc")
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
@@ -0,0 +1,259 @@
+//
+//  Copyright (c) 2025 Chakib Ben Ziane <contact@blob42.xyz>  and [`gosuki` contributors](https://github.com/blob42/gosuki/graphs/contributors).
+//  All rights reserved.
+//
+//  SPDX-License-Identifier: AGPL-3.0-or-later
+//
+//  This file is part of GoSuki.
+//
+//  GoSuki is free software: you can redistribute it and/or modify
+//  it under the terms of the GNU Affero General Public License as
+//  published by the Free Software Foundation, either version 3 of the
+//  License, or (at your option) any later version.
+//
+//  GoSuki is distributed in the hope that it will be useful,
+//  but WITHOUT ANY WARRANTY; without even the implied warranty of
+//  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU Affero General Public License for more details.
+//
+//  You should have received a copy of the GNU Affero General Public License
+//  along with gosuki.  If not, see <http://www.gnu.org/licenses/>.
+
+// Package sessions snapshots the windows and tabs open in the browsers. Each
+// snapshot is kept in the database and the open tabs are saved as bookmarks,
+// one collection per window.
+package sessions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+
+	"github.com/blob42/gosuki"
+	db "github.com/blob42/gosuki/internal/database"
+	"github.com/blob42/gosuki/pkg/config"
+	"github.com/blob42/gosuki/pkg/logging"
+	"github.com/blob42/gosuki/pkg/modules"
+	"github.com/blob42/gosuki/pkg/watch"
+)
+
+const (
+	ModID = "sessions"
+
+	// root of the tags given to the open tabs
+	TagPrefix = "tabs"
+)
+
+var (
+	Config *SessionsConfig
+	log    = logging.GetLogger(ModID)
+	model  *sessionsModel
+)
+
+type SessionsConfig struct {
+	// Capturing the browser sessions is opt-in
+	Enabled bool `toml:"enabled" mapstructure:"enabled"`
+}
+
+type sessionsModel struct {
+	// sessions of the browser profiles by source
+	sessions map[string]modules.ProfileSession
+	watcher  *watch.WatchDescriptor
+
+	// loads are triggered by concurrent watch events
+	mu sync.Mutex
+}
+
+// SessionsModule watches the session files of the browser modules
+// implementing [modules.SessionReader].
+type SessionsModule struct{}
+
+func (sm SessionsModule) ModInfo() modules.ModInfo {
+	return modules.ModInfo{
+		ID: modules.ModID(ModID),
+		New: func() modules.Module {
+			return &SessionsModule{}
+		},
+	}
+}
+
+func (sm *SessionsModule) Init(ctx *modules.Context) error {
+	if !Config.Enabled {
+		return &modules.ErrModDisabled{
+			Err:    errors.New("sessions disabled"),
+			Reason: "set sessions.enabled to capture the open tabs",
+		}
+	}
+
+	watches := []*watch.Watch{}
+	for _, browser := range modules.GetBrowserModules() {
+		for source, ps := range modules.ProfileSessions(browser) {
+			w, err := sessionWatch(ps)
+			if err != nil {
+				log.Debug("no session", "source", source, "err", err)
+				continue
+			}
+			model.sessions[source] = ps
+			watches = append(watches, w)
+		}
+	}
+	if len(watches) == 0 {
+		return errors.New("no browser session found")
+	}
+
+	watcher, err := watch.NewWatcher(ModID, watches...)
+	if err != nil {
+		return fmt.Errorf("setup watcher: %w", err)
+	}
+	model.watcher = watcher
+
+	return nil
+}
+
+// sessionWatch watches the session file of a profile, or all the files of its
+// session directory
+func sessionWatch(ps modules.ProfileSession) (*watch.Watch, error) {
+	path, err := ps.Path()
+	if err != nil {
+		return nil, err
+	}
+
+	w := &watch.Watch{
+		Path:       path,
+		EventTypes: []fsnotify.Op{fsnotify.Write, fsnotify.Create},
+		EventNames: []string{"*"},
+	}
+
+	// session files are usually replaced on write
+	if info, err := os.Stat(path); err != nil || !info.IsDir() {
+		w.Path = filepath.Dir(path)
+		w.EventNames = []string{path}
+	}
+	if _, err = os.Stat(w.Path); err != nil {
+		return nil, err
+	}
+
+	return w, nil
+}
+
+// PreLoad takes a first snapshot of the sessions
+func (sm *SessionsModule) PreLoad() ([]*gosuki.Bookmark, error) {
+	return sm.Load()
+}
+
+// Load implements watch.WatchLoader. Sessions are snapshotted when their open
+// urls changed since the last snapshot, their tabs are returned as bookmarks.
+func (sm *SessionsModule) Load() ([]*gosuki.Bookmark, error) {
+	model.mu.Lock()
+	defer model.mu.Unlock()
+
+	sources := slices.Sorted(maps.Keys(model.sessions))
+	result := []*gosuki.Bookmark{}
+	saved := false
+
+	for _, source := range sources {
+		session, err := model.sessions[source].Read(context.Background())
+		if err != nil {
+			log.Warn("reading session", "source", source, "err", err)
+			continue
+		}
+		session.Source = source
+		dropInternalTabs(session)
+
+		ok, err := db.L2Cache.SaveSession(context.Background(), session)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+
+		log.Info("session snapshot", "source", source, "windows", len(session.Windows),
+			"tabs", session.TabCount())
+		saved = true
+		result = append(result, TabBookmarks(session)...)
+	}
+
+	if saved {
+		db.ScheduleBackupToDisk()
+	}
+
+	return result, nil
+}
+
+// dropInternalTabs removes the browser pages (new tab, settings...) and the
+// windows left empty
+func dropInternalTabs(s *db.Session) {
+	for _, w := range s.Windows {
+		w.Tabs = slices.DeleteFunc(w.Tabs, func(tab *db.SessionTab) bool {
+			u, err := url.Parse(tab.URL)
+			if err != nil {
+				return true
+			}
+			switch u.Scheme {
+			case "http", "https", "ftp", "file":
+				return false
+			}
+			return true
+		})
+	}
+	s.Windows = slices.DeleteFunc(s.Windows, func(w *db.SessionWindow) bool {
+		return len(w.Tabs) == 0
+	})
+}
+
+// TabBookmarks returns the tabs of a session as bookmarks. Each window is a
+// collection tagged tabs/<source>/<window>, the tabs are also tagged with
+// the day of the snapshot: tabs/2025-01-31.
+func TabBookmarks(s *db.Session) []*gosuki.Bookmark {
+	taken := time.Now()
+	if s.TakenAt > 0 {
+		taken = time.Unix(int64(s.TakenAt), 0)
+	}
+	day := TagPrefix + gosuki.FolderSep + taken.Format(time.DateOnly)
+
+	result := []*gosuki.Bookmark{}
+	for n, w := range s.Windows {
+		collection := strings.Join([]string{TagPrefix, s.Source, s.WindowName(n)}, gosuki.FolderSep)
+		for _, tab := range w.Tabs {
+			result = append(result, &gosuki.Bookmark{
+				URL:     tab.URL,
+				Title:   tab.Title,
+				Tags:    []string{collection, day},
+				Module:  ModID,
+				Created: s.TakenAt,
+			})
+		}
+	}
+	return result
+}
+
+func (sm SessionsModule) Watch() *watch.WatchDescriptor {
+	return model.watcher
+}
+
+func (sm SessionsModule) Name() string {
+	return ModID
+}
+
+func init() {
+	model = &sessionsModel{sessions: map[string]modules.ProfileSession{}}
+	Config = &SessionsConfig{}
+	config.RegisterConfigurator(ModID, config.AsConfigurator(Config))
+	modules.RegisterModule(&SessionsModule{})
+}
+
+// interface guards
+var _ modules.Initializer = (*SessionsModule)(nil)
+var _ watch.WatchLoader = (*SessionsModule)(nil)
+var _ modules.DumbPreLoader = (*SessionsModule)(nil)
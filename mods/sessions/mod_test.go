@@ -0,0 +1,40 @@
+package sessions
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	db "github.com/blob42/gosuki/internal/database"
+)
+
+func TestTabBookmarks(t *testing.T) {
+	taken := time.Date(2025, 1, 31, 12, 0, 0, 0, time.Local)
+	session := &db.Session{
+		Source:  "firefox_default",
+		TakenAt: uint64(taken.Unix()),
+		Windows: []*db.SessionWindow{
+			{Tabs: []*db.SessionTab{
+				{URL: "https://go.dev/", Title: "Go"},
+				{URL: "about:newtab"},
+				{URL: "chrome://settings/"},
+			}},
+			{Tabs: []*db.SessionTab{{URL: "about:blank"}}},
+			{Name: "Research", Tabs: []*db.SessionTab{{URL: "file:///home/user/notes.html"}}},
+		},
+	}
+
+	dropInternalTabs(session)
+	require.Len(t, session.Windows, 2, "empty windows are dropped")
+
+	bookmarks := TabBookmarks(session)
+	require.Len(t, bookmarks, 2)
+
+	require.Equal(t, "https://go.dev/", bookmarks[0].URL)
+	require.Equal(t, "Go", bookmarks[0].Title)
+	require.Equal(t, ModID, bookmarks[0].Module)
+	require.Equal(t, []string{"tabs/firefox_default/window-1", "tabs/2025-01-31"}, bookmarks[0].Tags)
+
+	require.Equal(t, []string{"tabs/firefox_default/Research", "tabs/2025-01-31"}, bookmarks[1].Tags)
+}
@@ -36,6 +36,12 @@ const (
 	Mozilla BrowserFamily = iota
 	ChromeBased
 	Qutebrowser
+
+	// Browsers storing their bookmarks in an XBEL file
+	XBEL
+
+	Epiphany // GNOME Web
+	Falkon
 )
 
 type BrowserDef struct {
@@ -51,6 +57,9 @@ type BrowserDef struct {
 
 	// (linux only) path to flatpak package base dir
 	FlatpakDir string
+
+	// Bookmark file relative to the base dir, for browsers without profiles
+	BkFile string
 }
 
 func (b BrowserDef) Detect() bool {
@@ -86,3 +95,12 @@ func ChromeBrowser(flavour, base, snap, flat string) BrowserDef {
 		FlatpakDir: flat,
 	}
 }
+
+func XBELBrowser(flavour, base, bkFile string) BrowserDef {
+	return BrowserDef{
+		Flavour: flavour,
+		BaseDir: base,
+		Family:  XBEL,
+		BkFile:  bkFile,
+	}
+}
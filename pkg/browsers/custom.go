@@ -0,0 +1,163 @@
+//
+//  Copyright (c) 2025 Chakib Ben Ziane <contact@blob42.xyz>  and [`gosuki` contributors](https://github.com/blob42/gosuki/graphs/contributors).
+//  All rights reserved.
+//
+//  SPDX-License-Identifier: AGPL-3.0-or-later
+//
+//  This file is part of GoSuki.
+//
+//  GoSuki is free software: you can redistribute it and/or modify
+//  it under the terms of the GNU Affero General Public License as
+//  published by the Free Software Foundation, either version 3 of the
+//  License, or (at your option) any later version.
+//
+//  GoSuki is distributed in the hope that it will be useful,
+//  but WITHOUT ANY WARRANTY; without even the implied warranty of
+//  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU Affero General Public License for more details.
+//
+//  You should have received a copy of the GNU Affero General Public License
+//  along with gosuki.  If not, see <http://www.gnu.org/licenses/>.
+//
+
+package browsers
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+
+	"github.com/urfave/cli/v3"
+	"gopkg.in/yaml.v3"
+
+	"github.com/blob42/gosuki/pkg/config"
+)
+
+// Custom browser definitions are read at startup from the [browsers] section
+// of config.toml and from the yaml files of DefinitionsDir, next to the config
+// file. Both use the browsers.yaml format:
+//
+//	[browsers.chrome.thorium.linux]
+//	base_dir = "~/.config/thorium"
+const (
+	DefinitionsDir = "browsers.d"
+	ConfigName     = "browsers"
+)
+
+// definitions of the [browsers] config section
+type definitionsConfig struct {
+	defs []BrowserDef
+}
+
+func (c *definitionsConfig) Set(opt string, _ any) error {
+	return fmt.Errorf("%s: use the browsers.yaml format", opt)
+}
+
+func (c *definitionsConfig) Get(opt string) (any, error) {
+	return nil, fmt.Errorf("%s option not defined", opt)
+}
+
+func (c *definitionsConfig) Dump() map[string]any {
+	return map[string]any{}
+}
+
+// MapFrom validates the definitions of the config file, they are merged once
+// the config is ready.
+func (c *definitionsConfig) MapFrom(src any) error {
+	data, err := yaml.Marshal(src)
+	if err != nil {
+		return err
+	}
+
+	c.defs, err = LoadDefinitions(data)
+	return err
+}
+
+var configDefinitions = &definitionsConfig{}
+
+// LoadDefinitions parses browser definitions in the browsers.yaml format and
+// returns the ones of the current platform.
+func LoadDefinitions(data []byte) ([]BrowserDef, error) {
+	cfg, err := ParseDefinitions(data)
+	if err != nil {
+		return nil, err
+	}
+
+	return cfg.Definitions()[runtime.GOOS], nil
+}
+
+// LoadDefinitionsDir loads the .yaml and .yml files of dir in lexical order. A
+// missing directory is not an error.
+func LoadDefinitionsDir(dir string) ([]BrowserDef, error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	var result []BrowserDef
+	for _, entry := range entries {
+		ext := filepath.Ext(entry.Name())
+		if entry.IsDir() || (ext != ".yaml" && ext != ".yml") {
+			continue
+		}
+
+		path := filepath.Join(dir, entry.Name())
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+
+		defs, err := LoadDefinitions(data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		result = append(result, defs...)
+	}
+
+	return result, nil
+}
+
+// MergeBrowserDefs adds definitions to DefinedBrowsers. A definition replaces
+// the one with the same family and flavour, which allows changing the paths of
+// a builtin browser.
+func MergeBrowserDefs(defs ...BrowserDef) {
+	for _, def := range defs {
+		i := slices.IndexFunc(DefinedBrowsers, func(bd BrowserDef) bool {
+			return bd.Family == def.Family && bd.Flavour == def.Flavour
+		})
+		if i >= 0 {
+			log.Debug("replacing browser definition", "flavour", def.Flavour)
+			DefinedBrowsers[i] = def
+			continue
+		}
+
+		log.Debug("adding browser definition", "flavour", def.Flavour)
+		AddBrowserDef(def)
+	}
+}
+
+// loads the custom definitions, the config file takes precedence over the
+// definitions directory
+func loadCustomDefinitions(_ context.Context, _ *cli.Command) error {
+	if config.ConfigFileFlag != "" {
+		dir := filepath.Join(filepath.Dir(config.ConfigFileFlag), DefinitionsDir)
+		defs, err := LoadDefinitionsDir(dir)
+		if err != nil {
+			return err
+		}
+		MergeBrowserDefs(defs...)
+	}
+
+	MergeBrowserDefs(configDefinitions.defs...)
+	return nil
+}
+
+func init() {
+	config.RegisterConfigurator(ConfigName, configDefinitions)
+	config.RegisterConfReadyHooks(loadCustomDefinitions)
+}
@@ -0,0 +1,103 @@
+package browsers
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// the generated definitions must match browsers.yaml
+func TestBuiltinDefinitions(t *testing.T) {
+	data, err := os.ReadFile("browsers.yaml")
+	require.NoError(t, err)
+
+	defs, err := LoadDefinitions(data)
+	require.NoError(t, err)
+	assert.Equal(t, defs, DefinedBrowsers[:len(defs)])
+}
+
+func TestParseDefinitions(t *testing.T) {
+	cfg, err := ParseDefinitions([]byte(`
+chrome:
+  thorium:
+    linux:
+      base_dir: ~/.config/thorium
+    windows:
+      base_dir: "%LOCALAPPDATA%\\Thorium\\User Data"
+other:
+  xbel:
+    arora:
+      linux:
+        base_dir: ~/.local/share/arora
+        bookmarks: bookmarks.xbel
+`))
+	require.NoError(t, err)
+
+	defs := cfg.Definitions()
+	require.Len(t, defs["linux"], 2)
+	assert.Equal(t, ChromeBrowser("thorium", "~/.config/thorium", "", ""), defs["linux"][0])
+	assert.Equal(t, XBELBrowser("arora", "~/.local/share/arora", "bookmarks.xbel"), defs["linux"][1])
+	assert.Len(t, defs["windows"], 1)
+
+	for name, spec := range map[string]string{
+		"unknown field":    "chrome:\n  thorium:\n    linux:\n      base: /tmp\n",
+		"unknown family":   "other:\n  webkit:\n    thorium:\n      linux:\n        base_dir: /tmp\n",
+		"unknown platform": "chrome:\n  thorium:\n    linx:\n      base_dir: /tmp\n",
+		"missing base dir": "mozilla:\n  floorp:\n    linux:\n      snap: /tmp\n",
+		"invalid flavour":  "chrome:\n  my browser:\n    linux:\n      base_dir: /tmp\n",
+	} {
+		_, err := ParseDefinitions([]byte(spec))
+		assert.Error(t, err, name)
+	}
+
+	cfg, err = ParseDefinitions([]byte{})
+	require.NoError(t, err)
+	assert.Empty(t, cfg.Definitions())
+}
+
+func TestLoadDefinitionsDir(t *testing.T) {
+	dir := t.TempDir()
+	platform := runtime.GOOS
+
+	write := func(name, content string) {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644))
+	}
+	write("10-thorium.yaml", "chrome:\n  thorium:\n    "+platform+":\n      base_dir: /opt/thorium\n")
+	write("20-floorp.yml", "mozilla:\n  floorp:\n    "+platform+":\n      base_dir: /opt/floorp\n")
+	write("README", "not a definition")
+
+	defs, err := LoadDefinitionsDir(dir)
+	require.NoError(t, err)
+	require.Len(t, defs, 2)
+	assert.Equal(t, "thorium", defs[0].Flavour)
+	assert.Equal(t, Mozilla, defs[1].Family)
+
+	defs, err = LoadDefinitionsDir(filepath.Join(dir, "missing"))
+	require.NoError(t, err)
+	assert.Empty(t, defs)
+
+	write("30-bad.yaml", "chrome: [")
+	_, err = LoadDefinitionsDir(dir)
+	assert.ErrorContains(t, err, "30-bad.yaml")
+}
+
+func TestMergeBrowserDefs(t *testing.T) {
+	builtin := slices.Clone(DefinedBrowsers)
+	t.Cleanup(func() { DefinedBrowsers = builtin })
+
+	// replace the paths of a builtin browser
+	replaced := builtin[0]
+	replaced.BaseDir = "/opt/replaced"
+	replaced.FlatpakDir = ""
+
+	MergeBrowserDefs(replaced, ChromeBrowser("thorium", "/opt/thorium", "", ""))
+
+	assert.Len(t, DefinedBrowsers, len(builtin)+1)
+	assert.Equal(t, replaced, Defined(replaced.Family)[replaced.Flavour])
+	assert.Equal(t, "/opt/thorium", Defined(ChromeBased)["thorium"].BaseDir)
+}
@@ -10,6 +10,7 @@ var DefinedBrowsers = []BrowserDef{
 		"~/Library/Application Support/BraveSoftware/Brave-Browser",
 		"",
 		"",
+		"",
 	},
 	{
 		"chrome",
@@ -17,6 +18,7 @@ var DefinedBrowsers = []BrowserDef{
 		"~/Library/Application Support/Google/Chrome",
 		"",
 		"",
+		"",
 	},
 	{
 		"chromium",
@@ -24,6 +26,7 @@ var DefinedBrowsers = []BrowserDef{
 		"~/Library/Application Support/chromium",
 		"",
 		"",
+		"",
 	},
 	{
 		"basilisk",
@@ -31,6 +34,7 @@ var DefinedBrowsers = []BrowserDef{
 		"~/Library/Application Support/Basilisk",
 		"",
 		"",
+		"",
 	},
 	{
 		"firefox",
@@ -38,6 +42,7 @@ var DefinedBrowsers = []BrowserDef{
 		"~/Library/Application Support/Firefox",
 		"",
 		"",
+		"",
 	},
 	{
 		"librewolf",
@@ -45,6 +50,7 @@ var DefinedBrowsers = []BrowserDef{
 		"~/Library/Application Support/Librewolf",
 		"",
 		"",
+		"",
 	},
 	{
 		"palemoon",
@@ -52,6 +58,7 @@ var DefinedBrowsers = []BrowserDef{
 		"~/Library/Application Support/PaleMoon",
 		"",
 		"",
+		"",
 	},
 	{
 		"zen",
@@ -59,6 +66,7 @@ var DefinedBrowsers = []BrowserDef{
 		"~/Library/Application Support/zen",
 		"",
 		"",
+		"",
 	},
 }
 
@@ -10,6 +10,7 @@ var DefinedBrowsers = []BrowserDef{
 		"~/.config/google-chrome",
 		"",
 		"",
+		"",
 	},
 	{
 		"chromium",
@@ -17,6 +18,7 @@ var DefinedBrowsers = []BrowserDef{
 		"~/.config/chromium",
 		"",
 		"",
+		"",
 	},
 	{
 		"basilisk",
@@ -24,6 +26,7 @@ var DefinedBrowsers = []BrowserDef{
 		"~/.basilisk",
 		"",
 		"",
+		"",
 	},
 	{
 		"firefox",
@@ -31,6 +34,7 @@ var DefinedBrowsers = []BrowserDef{
 		"~/.mozilla/firefox",
 		"",
 		"",
+		"",
 	},
 	{
 		"librewolf",
@@ -38,6 +42,7 @@ var DefinedBrowsers = []BrowserDef{
 		"~/.librewolf",
 		"",
 		"",
+		"",
 	},
 	{
 		"palemoon",
@@ -45,6 +50,7 @@ var DefinedBrowsers = []BrowserDef{
 		"~/.palemoon",
 		"",
 		"",
+		"",
 	},
 	{
 		"qutebrowser",
@@ -52,6 +58,31 @@ var DefinedBrowsers = []BrowserDef{
 		"~/.config/qutebrowser",
 		"",
 		"",
+		"",
+	},
+	{
+		"konqueror",
+		3,
+		"~/.local/share/konqueror",
+		"",
+		"",
+		"bookmarks.xml",
+	},
+	{
+		"epiphany",
+		4,
+		"~/.local/share/epiphany",
+		"",
+		"",
+		"",
+	},
+	{
+		"falkon",
+		5,
+		"~/.config/falkon",
+		"",
+		"",
+		"",
 	},
 }
 
@@ -10,6 +10,7 @@ var DefinedBrowsers = []BrowserDef{
 		"~/.config/BraveSoftware/Brave-Browser",
 		"~/snap/brave/current/.config/BraveSoftware/Brave-Browser",
 		"~/.var/app/com.brave.Browser/config/BraveSoftware/Brave-Browser",
+		"",
 	},
 	{
 		"chrome",
@@ -17,6 +18,7 @@ var DefinedBrowsers = []BrowserDef{
 		"~/.config/google-chrome",
 		"",
 		"~/.var/app/com.google.Chrome/config/google-chrome",
+		"",
 	},
 	{
 		"chromium",
@@ -24,6 +26,7 @@ var DefinedBrowsers = []BrowserDef{
 		"~/.config/chromium",
 		"~/snap/chromium/common/chromium/",
 		"~/.var/app/org.chromium.Chromium/config/chromium",
+		"",
 	},
 	{
 		"basilisk",
@@ -31,6 +34,7 @@ var DefinedBrowsers = []BrowserDef{
 		"~/.basilisk",
 		"",
 		"",
+		"",
 	},
 	{
 		"firefox",
@@ -38,6 +42,7 @@ var DefinedBrowsers = []BrowserDef{
 		"~/.mozilla/firefox",
 		"~/snap/firefox/common/.mozilla/firefox",
 		"~/.var/app/org.mozilla.firefox/.mozilla/firefox",
+		"",
 	},
 	{
 		"icecat",
@@ -45,6 +50,7 @@ var DefinedBrowsers = []BrowserDef{
 		"~/.mozilla/icecat",
 		"",
 		"",
+		"",
 	},
 	{
 		"librewolf",
@@ -52,6 +58,7 @@ var DefinedBrowsers = []BrowserDef{
 		"~/.librewolf",
 		"",
 		"~/.var/app/io.gitlab.librewolf-community/.librewolf",
+		"",
 	},
 	{
 		"palemoon",
@@ -59,6 +66,7 @@ var DefinedBrowsers = []BrowserDef{
 		"~/.palemoon",
 		"",
 		"",
+		"",
 	},
 	{
 		"waterfox",
@@ -66,6 +74,7 @@ var DefinedBrowsers = []BrowserDef{
 		"~/.waterfox",
 		"",
 		"~/.var/app/net.waterfox.waterfox/.waterfox",
+		"",
 	},
 	{
 		"zen",
@@ -73,6 +82,7 @@ var DefinedBrowsers = []BrowserDef{
 		"~/.zen",
 		"",
 		"~/.var/app/app.zen_browser.zen/.zen",
+		"",
 	},
 	{
 		"qutebrowser",
@@ -80,6 +90,47 @@ var DefinedBrowsers = []BrowserDef{
 		"~/.config/qutebrowser",
 		"",
 		"",
+		"",
+	},
+	{
+		"konqueror",
+		3,
+		"~/.local/share/konqueror",
+		"",
+		"",
+		"bookmarks.xml",
+	},
+	{
+		"midori",
+		3,
+		"~/.config/midori",
+		"",
+		"~/.var/app/org.midori_browser.Midori/config/midori",
+		"bookmarks.xbel",
+	},
+	{
+		"otter",
+		3,
+		"~/.config/otter",
+		"",
+		"",
+		"bookmarks.xbel",
+	},
+	{
+		"epiphany",
+		4,
+		"~/.local/share/epiphany",
+		"",
+		"~/.var/app/org.gnome.Epiphany/data/epiphany",
+		"",
+	},
+	{
+		"falkon",
+		5,
+		"~/.config/falkon",
+		"",
+		"~/.var/app/org.kde.falkon/config/falkon",
+		"",
 	},
 }
 
@@ -10,6 +10,7 @@ var DefinedBrowsers = []BrowserDef{
 		"~/.config/google-chrome",
 		"",
 		"",
+		"",
 	},
 	{
 		"chromium",
@@ -17,6 +18,7 @@ var DefinedBrowsers = []BrowserDef{
 		"~/.config/chromium",
 		"",
 		"",
+		"",
 	},
 	{
 		"firefox",
@@ -24,6 +26,7 @@ var DefinedBrowsers = []BrowserDef{
 		"~/.mozilla/firefox",
 		"",
 		"",
+		"",
 	},
 	{
 		"librewolf",
@@ -31,6 +34,7 @@ var DefinedBrowsers = []BrowserDef{
 		"~/.librewolf",
 		"",
 		"",
+		"",
 	},
 	{
 		"qutebrowser",
@@ -38,6 +42,7 @@ var DefinedBrowsers = []BrowserDef{
 		"~/.config/qutebrowser",
 		"",
 		"",
+		"",
 	},
 }
 
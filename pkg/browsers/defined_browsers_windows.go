@@ -10,6 +10,7 @@ var DefinedBrowsers = []BrowserDef{
 		"%LOCALAPPDATA%\\BraveSoftware\\Brave-Browser\\User Data",
 		"",
 		"",
+		"",
 	},
 	{
 		"chrome",
@@ -17,6 +18,7 @@ var DefinedBrowsers = []BrowserDef{
 		"%LOCALAPPDATA%\\Google\\Chrome\\User Data",
 		"",
 		"",
+		"",
 	},
 	{
 		"chromium",
@@ -24,6 +26,7 @@ var DefinedBrowsers = []BrowserDef{
 		"%LOCALAPPDATA%\\Chromium\\User Data",
 		"",
 		"",
+		"",
 	},
 	{
 		"edge",
@@ -31,6 +34,7 @@ var DefinedBrowsers = []BrowserDef{
 		"%LOCALAPPDATA%\\Microsoft\\Edge\\User Data",
 		"",
 		"",
+		"",
 	},
 	{
 		"firefox",
@@ -38,6 +42,7 @@ var DefinedBrowsers = []BrowserDef{
 		"%APPDATA%\\Mozilla\\Firefox",
 		"",
 		"",
+		"",
 	},
 	{
 		"qutebrowser",
@@ -45,6 +50,23 @@ var DefinedBrowsers = []BrowserDef{
 		"%APPDATA%\\qutebrowser",
 		"",
 		"",
+		"",
+	},
+	{
+		"otter",
+		3,
+		"%APPDATA%\\otter",
+		"",
+		"",
+		"bookmarks.xbel",
+	},
+	{
+		"falkon",
+		5,
+		"%LOCALAPPDATA%\\falkon",
+		"",
+		"",
+		"",
 	},
 }
 
@@ -23,12 +23,23 @@
 package browsers
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"sort"
 	"strings"
 
 	"gopkg.in/yaml.v3"
 )
 
+// Platforms supported in browser definitions
+var SupportedPlatforms = []string{"linux", "darwin", "windows", "freebsd", "netbsd", "openbsd"}
+
+var reFlavour = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]*$`)
+
 func (f *BrowserFamily) UnmarshalYAML(value *yaml.Node) error {
 	switch value.Kind {
 	case yaml.ScalarNode:
@@ -39,6 +50,12 @@ func (f *BrowserFamily) UnmarshalYAML(value *yaml.Node) error {
 			*f = ChromeBased
 		case "qutebrowser":
 			*f = Qutebrowser
+		case "xbel":
+			*f = XBEL
+		case "epiphany":
+			*f = Epiphany
+		case "falkon":
+			*f = Falkon
 		default:
 			return fmt.Errorf("unknown family: %s", value.Value)
 		}
@@ -74,4 +91,126 @@ type PlatformConfig struct {
 	BaseDir string `yaml:"base_dir"`
 	Snap    string `yaml:"snap"`
 	Flatpak string `yaml:"flat"` // note: changed from flat to flatpak for clarity
+
+	// bookmark file in base_dir
+	Bookmarks string `yaml:"bookmarks"`
+}
+
+// ParseDefinitions parses and validates browser definitions in the format of
+// browsers.yaml. Unknown fields are rejected.
+func ParseDefinitions(data []byte) (*BrowserConfig, error) {
+	var cfg BrowserConfig
+
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&cfg); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("invalid browser definitions: %w", err)
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &cfg, nil
+}
+
+// Validate checks the flavour names, the platforms and that each platform has
+// a base directory.
+func (cfg BrowserConfig) Validate() error {
+	var errs []error
+
+	check := func(flv flavour, platforms Platforms) {
+		if !reFlavour.MatchString(string(flv)) {
+			errs = append(errs, fmt.Errorf("invalid flavour name %q", flv))
+		}
+		for p, pCfg := range platforms {
+			if !slices.Contains(SupportedPlatforms, string(p)) {
+				errs = append(errs, fmt.Errorf("%s: unknown platform %q", flv, p))
+			} else if pCfg.BaseDir == "" {
+				errs = append(errs, fmt.Errorf("%s: %s: missing base_dir", flv, p))
+			}
+		}
+	}
+
+	for flv, platforms := range cfg.Chrome {
+		check(flv, platforms)
+	}
+	for flv, platforms := range cfg.Mozilla {
+		check(flv, platforms)
+	}
+	for _, custom := range cfg.Other {
+		for flv, platforms := range custom {
+			check(flv, platforms)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Definitions returns the browser definitions of each platform. Definitions
+// are sorted by family and flavour.
+func (cfg BrowserConfig) Definitions() map[string][]BrowserDef {
+	result := make(map[string][]BrowserDef)
+
+	for _, flv := range sortedKeys(cfg.Chrome) {
+		platforms := cfg.Chrome[flv]
+		for _, p := range sortedKeys(platforms) {
+			pCfg := platforms[p]
+			result[string(p)] = append(result[string(p)], ChromeBrowser(
+				string(flv),
+				pCfg.BaseDir,
+				pCfg.Snap,
+				pCfg.Flatpak,
+			))
+		}
+	}
+
+	for _, flv := range sortedKeys(cfg.Mozilla) {
+		platforms := cfg.Mozilla[flv]
+		for _, p := range sortedKeys(platforms) {
+			pCfg := platforms[p]
+			result[string(p)] = append(result[string(p)], MozBrowser(
+				string(flv),
+				pCfg.BaseDir,
+				pCfg.Snap,
+				pCfg.Flatpak,
+			))
+		}
+	}
+
+	families := make([]BrowserFamily, 0, len(cfg.Other))
+	for family := range cfg.Other {
+		families = append(families, family)
+	}
+	slices.Sort(families)
+
+	for _, family := range families {
+		definitions := cfg.Other[family]
+		for _, flv := range sortedKeys(definitions) {
+			platforms := definitions[flv]
+			for _, p := range sortedKeys(platforms) {
+				pCfg := platforms[p]
+				result[string(p)] = append(result[string(p)], BrowserDef{
+					Flavour:    string(flv),
+					Family:     family,
+					BaseDir:    pCfg.BaseDir,
+					SnapDir:    pCfg.Snap,
+					FlatpakDir: pCfg.Flatpak,
+					BkFile:     pCfg.Bookmarks,
+				})
+			}
+		}
+	}
+
+	return result
+}
+
+// sortedKeys returns the keys of a string-keyed map in sorted order.
+func sortedKeys[K ~string, V any](m map[K]V) []K {
+	keys := make([]K, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+	return keys
 }
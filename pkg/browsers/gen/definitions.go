@@ -31,8 +31,6 @@ import (
 	"strconv"
 	"text/template"
 
-	"gopkg.in/yaml.v3"
-
 	"github.com/blob42/gosuki/pkg/browsers"
 )
 
@@ -49,6 +47,7 @@ var DefinedBrowsers = []BrowserDef{
 		"{{.BaseDir | gostring}}",
 		"{{.SnapDir | gostring}}",
 		"{{.FlatpakDir | gostring}}",
+		"{{.BkFile | gostring}}",
 	},{{ end }}
 }
 
@@ -80,69 +79,16 @@ func generateBrowserConfs(defFile string) (browserConfigs, error) {
 		return nil, err
 	}
 
-	var cfg browsers.BrowserConfig
-	err = yaml.Unmarshal(data, &cfg)
+	cfg, err := browsers.ParseDefinitions(data)
 	if err != nil {
-		return nil, fmt.Errorf("invalid config: %w", err)
-	}
-
-	// pretty.Println(cfg)
-
-	bCfgs := make(map[platform][]browsers.BrowserDef)
-
-	// Chrome browsers
-	for _, flavour := range sortedStringKeys(cfg.Chrome) {
-		platforms := cfg.Chrome[flavour]
-		for _, p := range sortedStringKeys(platforms) {
-			pCfg := platforms[p]
-			bCfgs[platform(p)] = append(bCfgs[platform(p)], browsers.ChromeBrowser(
-				string(flavour),
-				pCfg.BaseDir,
-				pCfg.Snap,
-				pCfg.Flatpak,
-			))
-		}
-	}
-
-	for _, flavour := range sortedStringKeys(cfg.Mozilla) {
-		platforms := cfg.Mozilla[flavour]
-		for _, p := range sortedStringKeys(platforms) {
-			pCfg := platforms[p]
-			if bCfgs[platform(p)] == nil {
-				bCfgs[platform(p)] = []browsers.BrowserDef{}
-			}
-			bCfgs[platform(p)] = append(bCfgs[platform(p)], browsers.MozBrowser(
-				string(flavour),
-				pCfg.BaseDir,
-				pCfg.Snap,
-				pCfg.Flatpak,
-			))
-		}
+		return nil, err
 	}
 
-	// Custom browser defs
-	for _, family := range sortedFamilyKeys(cfg.Other) {
-		definitions := cfg.Other[family]
-		for _, flavour := range sortedStringKeys(definitions) {
-			platforms := definitions[flavour]
-			for _, p := range sortedStringKeys(platforms) {
-				pCfg := platforms[p]
-				bDef := browsers.BrowserDef{
-					Flavour:    string(flavour),
-					Family:     family,
-					BaseDir:    pCfg.BaseDir,
-					SnapDir:    pCfg.Snap,
-					FlatpakDir: pCfg.Flatpak,
-				}
-				if bCfgs[platform(p)] == nil {
-					bCfgs[platform(p)] = []browsers.BrowserDef{}
-				}
-				bCfgs[platform(p)] = append(bCfgs[platform(p)], bDef)
-			}
-		}
+	bCfgs := make(browserConfigs)
+	for p, defs := range cfg.Definitions() {
+		bCfgs[platform(p)] = defs
 	}
 
-	// pretty.Println(bCfgs)
 	return bCfgs, nil
 }
 
@@ -156,16 +102,6 @@ func sortedStringKeys[K ~string, V any](m map[K]V) []K {
 	return keys
 }
 
-// sortedFamilyKeys returns BrowserFamily keys in ascending numeric order.
-func sortedFamilyKeys[V any](m map[browsers.BrowserFamily]V) []browsers.BrowserFamily {
-	keys := make([]browsers.BrowserFamily, 0, len(m))
-	for k := range m {
-		keys = append(keys, k)
-	}
-	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
-	return keys
-}
-
 // gostring escapes a string for use in a Go string literal (without surrounding quotes)
 func gostring(s string) string {
 	quoted := strconv.Quote(s)
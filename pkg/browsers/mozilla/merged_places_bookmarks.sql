@@ -23,6 +23,7 @@ SELECT
     ifnull(moz_places.id, -1) as plId,
     ifnull(moz_places.url, "") as plUrl,
     ifnull(moz_places.description, "") as plDescription,
+    ifnull((SELECT group_concat(keyword) FROM moz_keywords WHERE place_id = moz_places.id), "") as plKeyword,
 
 
     ifnull(moz_bookmarks.title, "") as bkTitle,
@@ -0,0 +1,135 @@
+//
+// Copyright (c) 2023-2025 Chakib Ben Ziane <contact@blob42.xyz> and [`GoSuki` contributors]
+// (https://github.com/blob42/gosuki/graphs/contributors).
+//
+// All rights reserved.
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+//
+// This file is part of GoSuki.
+//
+// GoSuki is free software: you can redistribute it and/or modify it under the terms of
+// the GNU Affero General Public License as published by the Free Software Foundation,
+// either version 3 of the License, or (at your option) any later version.
+//
+// GoSuki is distributed in the hope that it will be useful, but WITHOUT ANY WARRANTY;
+// without even the implied warranty of MERCHANTABILITY or FITNESS FOR A PARTICULAR
+// PURPOSE.  See the GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License along with
+// gosuki.  If not, see <http://www.gnu.org/licenses/>.
+
+package mozilla
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// mozLz4 files (sessionstore.jsonlz4, recovery.jsonlz4...) are a magic
+// header, the uint32 size of the decompressed data and a single lz4 block.
+var mozLz4Magic = []byte("mozLz40\x00")
+
+var ErrNotMozLz4 = errors.New("not a mozLz4 file")
+
+// ReadMozLz4 reads and decompresses the mozLz4 file at path
+func ReadMozLz4(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	out, err := DecodeMozLz4(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return out, nil
+}
+
+// DecodeMozLz4 decompresses mozLz4 data
+func DecodeMozLz4(data []byte) ([]byte, error) {
+	if len(data) < len(mozLz4Magic)+4 || !bytes.HasPrefix(data, mozLz4Magic) {
+		return nil, ErrNotMozLz4
+	}
+	size := binary.LittleEndian.Uint32(data[len(mozLz4Magic):])
+	return decodeLz4Block(data[len(mozLz4Magic)+4:], int(size))
+}
+
+var errLz4Corrupt = errors.New("corrupt lz4 block")
+
+// decodeLz4Block decompresses a raw lz4 block of the given decompressed size.
+// A block is a list of sequences: a token holding the literal and match
+// lengths, the literals, then the offset of the match in the output. The last
+// sequence only has literals.
+func decodeLz4Block(src []byte, size int) ([]byte, error) {
+	dst := make([]byte, 0, size)
+
+	// lengths of 15 continue on the next bytes until a byte is not 255
+	readLen := func(i int, n int) (int, int, error) {
+		if n != 15 {
+			return n, i, nil
+		}
+		for {
+			if i >= len(src) {
+				return 0, i, errLz4Corrupt
+			}
+			b := src[i]
+			i++
+			n += int(b)
+			if b != 255 {
+				return n, i, nil
+			}
+		}
+	}
+
+	i := 0
+	for i < len(src) {
+		token := src[i]
+		i++
+
+		litLen, next, err := readLen(i, int(token>>4))
+		if err != nil {
+			return nil, err
+		}
+		i = next
+		if i+litLen > len(src) {
+			return nil, errLz4Corrupt
+		}
+		dst = append(dst, src[i:i+litLen]...)
+		i += litLen
+
+		// last sequence
+		if i == len(src) {
+			break
+		}
+
+		if i+2 > len(src) {
+			return nil, errLz4Corrupt
+		}
+		offset := int(binary.LittleEndian.Uint16(src[i:]))
+		i += 2
+		if offset == 0 || offset > len(dst) {
+			return nil, errLz4Corrupt
+		}
+
+		matchLen, next, err := readLen(i, int(token&0x0f))
+		if err != nil {
+			return nil, err
+		}
+		i = next
+		matchLen += 4
+
+		// the match can overlap the bytes it produces
+		start := len(dst) - offset
+		for k := 0; k < matchLen; k++ {
+			dst = append(dst, dst[start+k])
+		}
+	}
+
+	if len(dst) != size {
+		return nil, fmt.Errorf("%w: decompressed %d bytes, expected %d", errLz4Corrupt, len(dst), size)
+	}
+	return dst, nil
+}
@@ -0,0 +1,42 @@
+package mozilla
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func mozLz4(block []byte, size int) []byte {
+	data := append([]byte{}, mozLz4Magic...)
+	data = binary.LittleEndian.AppendUint32(data, uint32(size))
+	return append(data, block...)
+}
+
+func TestDecodeMozLz4(t *testing.T) {
+	// 3 literals, a match of 9 bytes at offset 3 then a last literal
+	block := []byte{0x35, 'a', 'b', 'c', 0x03, 0x00, 0x10, '!'}
+	out, err := DecodeMozLz4(mozLz4(block, 13))
+	require.NoError(t, err)
+	assert.Equal(t, "abcabcabcabc!", string(out))
+
+	// literal length continued on the next bytes: 15 + 255 + 10
+	long := make([]byte, 280)
+	for i := range long {
+		long[i] = byte('a' + i%26)
+	}
+	block = append([]byte{0xf0, 255, 10}, long...)
+	out, err = DecodeMozLz4(mozLz4(block, len(long)))
+	require.NoError(t, err)
+	assert.Equal(t, long, out)
+
+	_, err = DecodeMozLz4([]byte("{}"))
+	assert.ErrorIs(t, err, ErrNotMozLz4)
+
+	_, err = DecodeMozLz4(mozLz4([]byte{0x35, 'a', 'b', 'c', 0x09, 0x00}, 13))
+	assert.ErrorIs(t, err, errLz4Corrupt, "offset out of the output")
+
+	_, err = DecodeMozLz4(mozLz4([]byte{0x10, '!'}, 5))
+	assert.ErrorIs(t, err, errLz4Corrupt, "size mismatch")
+}
@@ -90,16 +90,23 @@ type MozBookmark struct {
 	URL            string
 	PlDesc         string `db:"plDesc"`
 	BkLastModified Sqlid  `db:"lastModified"`
+
+	// earliest creation time of the bookmark entries in microseconds
+	DateAdded Sqlid `db:"dateAdded"`
+
+	// comma separated address bar keywords from moz_keywords
+	Keyword string
 }
 
 // Type is used for scanning from `merged-places-bookmarks.sql`
-// plId  plUrl plDescription bkId  bkTitle bkLastModified  isFolder  isTag  isBk  bkParent
+// plId  plUrl plDescription plKeyword bkId  bkTitle bkLastModified  isFolder  isTag  isBk  bkParent
 type MergedPlaceBookmark struct {
-	PlID    Sqlid  `db:"plId"`
-	PlURL   string `db:"plUrl"`
-	PlDesc  string `db:"plDescription"`
-	BkID    Sqlid  `db:"bkId"`
-	BkTitle string `db:"bkTitle"`
+	PlID      Sqlid  `db:"plId"`
+	PlURL     string `db:"plUrl"`
+	PlDesc    string `db:"plDescription"`
+	PlKeyword string `db:"plKeyword"`
+	BkID      Sqlid  `db:"bkId"`
+	BkTitle   string `db:"bkTitle"`
 
 	//firefox stores timestamps in milliseconds as integer
 	//sqlite3 strftime('%s', ...) returns seconds
@@ -43,5 +43,15 @@ const (
 	QFolders = `
     SELECT id, title, parent FROM moz_bookmarks 
     WHERE type = 2 AND parent NOT IN (4, 0) AND lastModified > :change_since
+    `
+
+	// urls of all bookmarks, entries under tag folders and in the gosuki
+	// write back folder are ignored
+	QBookmarkedURLs = `
+    SELECT DISTINCT moz_places.url FROM moz_bookmarks
+    JOIN moz_places ON moz_bookmarks.fk = moz_places.id
+    WHERE moz_bookmarks.type = 1
+    AND moz_bookmarks.parent NOT IN (SELECT id FROM moz_bookmarks WHERE parent = 4)
+    AND moz_bookmarks.parent NOT IN (` + QWriteBackFolderID + `)
     `
 )
@@ -99,6 +99,12 @@ func Test_loadQueries(t *testing.T) {
 				t.Error(err)
 			}
 
+			for _, bk := range bookmarks {
+				if bk.PlURL == "https://go.dev/" && bk.PlKeyword != "golang" {
+					t.Errorf("expected keyword golang for %s, got %q", bk.PlURL, bk.PlKeyword)
+				}
+			}
+
 			// pretty.Log(bookmarks)
 		})
 	})
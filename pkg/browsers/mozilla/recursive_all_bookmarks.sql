@@ -118,7 +118,10 @@ SELECT
  group_concat(folders) as folders,
  url,
  ifnull(plDesc, "") as plDesc,
- (SELECT max(moz_bookmarks.lastModified) FROM moz_bookmarks WHERE fk=placeId ) as lastModified
+ (SELECT max(moz_bookmarks.lastModified) FROM moz_bookmarks WHERE fk=placeId ) as lastModified,
+ ifnull((SELECT min(moz_bookmarks.dateAdded) FROM moz_bookmarks WHERE fk=placeId AND type = 1), 0) as dateAdded,
+ -- address bar keywords of the url
+ ifnull((SELECT group_concat(keyword) FROM moz_keywords WHERE place_id = placeId), "") as keyword
  FROM all_bookmarks
 GROUP BY placeId
 ORDER BY lastModified
@@ -41,8 +41,9 @@ WITH RECURSIVE
 					AND parent IN (SELECT id FROM moz_bookmarks WHERE fk ISNULL and parent NOT IN (4,0)) -- parent is a folder
 	),
 	
+	-- all tag folders, tag changes are found from their entries below
 	tags AS (
-		SELECT id, type, fk, title FROM moz_bookmarks WHERE type = 2 AND parent IN (4,0) AND lastModified > :change_since
+		SELECT id, type, fk, title FROM moz_bookmarks WHERE type = 2 AND parent IN (4,0)
 		),
 	
 	marks(id, type, fk, title, tags, parent, folder)
@@ -50,8 +51,11 @@ WITH RECURSIVE
 			SELECT id, type, fk, title, title as tags, parent, parent as folder FROM bk_in_folders -- bookmarks			
 
  			UNION
-			-- links between bookmarks and tags
-			SELECT id, type, fk, NULL, NULL, parent, parent FROM moz_bookmarks WHERE type = 1 AND fk IS NOT NULL AND lastModified > :change_since
+			-- links between bookmarks and tags, a tagged url has a new entry under the tag
+			-- folder while its bookmark is left untouched. Entries of renamed tags are kept too.
+			SELECT id, type, fk, NULL, NULL, parent, parent FROM moz_bookmarks WHERE type = 1 AND fk IS NOT NULL
+					AND (lastModified > :change_since
+						OR parent IN (SELECT id FROM moz_bookmarks WHERE type = 2 AND parent = 4 AND lastModified > :change_since))
 					
 			UNION
 			-- get all tags which are tags of bookmarks in folders (pre selected)
@@ -116,6 +120,9 @@ parentFolderId,
  folders,
  url,
  ifnull(plDesc, "") as plDesc,
- (SELECT max(moz_bookmarks.lastModified) FROM moz_bookmarks WHERE fk=placeId ) as lastModified
+ (SELECT max(moz_bookmarks.lastModified) FROM moz_bookmarks WHERE fk=placeId ) as lastModified,
+ ifnull((SELECT min(moz_bookmarks.dateAdded) FROM moz_bookmarks WHERE fk=placeId AND type = 1), 0) as dateAdded,
+ -- address bar keywords of the url
+ ifnull((SELECT group_concat(keyword) FROM moz_keywords WHERE place_id = placeId), "") as keyword
  FROM all_bookmarks
 ORDER BY lastModified
@@ -0,0 +1,160 @@
+//
+// Copyright (c) 2023-2025 Chakib Ben Ziane <contact@blob42.xyz> and [`GoSuki` contributors]
+// (https://github.com/blob42/gosuki/graphs/contributors).
+//
+// All rights reserved.
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+//
+// This file is part of GoSuki.
+//
+// GoSuki is free software: you can redistribute it and/or modify it under the terms of
+// the GNU Affero General Public License as published by the Free Software Foundation,
+// either version 3 of the License, or (at your option) any later version.
+//
+// GoSuki is distributed in the hope that it will be useful, but WITHOUT ANY WARRANTY;
+// without even the implied warranty of MERCHANTABILITY or FITNESS FOR A PARTICULAR
+// PURPOSE.  See the GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License along with
+// gosuki.  If not, see <http://www.gnu.org/licenses/>.
+
+package mozilla
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/blob42/gosuki/internal/utils"
+)
+
+// Firefox writes changes to the WAL and only moves them to places.sqlite when
+// the WAL is checkpointed. Between two checkpoints the main database file does
+// not change and a snapshot only needs a fresh copy of the WAL.
+
+const (
+	walSuffix = "-wal"
+	shmSuffix = "-shm"
+
+	// retries when places.sqlite is checkpointed while being copied
+	maxSnapshotTries = 3
+)
+
+// PlacesSnapshot is a copy of places.sqlite kept across reads of a profile.
+// Refresh copies the database again only after a WAL checkpoint changed it.
+type PlacesSnapshot struct {
+	src string
+	dir string
+
+	// state of the source database when it was last copied
+	size    int64
+	modTime time.Time
+}
+
+// NewPlacesSnapshot prepares a snapshot of the places.sqlite at placesPath.
+// Nothing is copied before the first call to Refresh.
+func NewPlacesSnapshot(placesPath string) (*PlacesSnapshot, error) {
+	dir, err := os.MkdirTemp(utils.TMPDIR, "places-")
+	if err != nil {
+		return nil, fmt.Errorf("creating snapshot dir: %w", err)
+	}
+
+	return &PlacesSnapshot{
+		src: placesPath,
+		dir: dir,
+	}, nil
+}
+
+// Path returns the path of the copied database
+func (s *PlacesSnapshot) Path() string {
+	return filepath.Join(s.dir, filepath.Base(s.src))
+}
+
+// Refresh brings the snapshot up to date with the source database. It returns
+// true if the whole database was copied and false if only the WAL was.
+// Connections to the snapshot must be closed before calling Refresh, even
+// when only the WAL is copied.
+func (s *PlacesSnapshot) Refresh() (bool, error) {
+	var full bool
+
+	for range maxSnapshotTries {
+		before, err := os.Stat(s.src)
+		if err != nil {
+			return full, err
+		}
+
+		if s.modTime.IsZero() || before.Size() != s.size || !before.ModTime().Equal(s.modTime) {
+			if err = copyFile(s.src, s.Path()); err != nil {
+				return full, err
+			}
+			full = true
+		}
+
+		if err = s.copyWAL(); err != nil {
+			return full, err
+		}
+
+		// a checkpoint during the copy leaves a WAL that does not match the
+		// copied database, start over
+		after, err := os.Stat(s.src)
+		if err != nil {
+			return full, err
+		}
+		s.size, s.modTime = before.Size(), before.ModTime()
+		if after.Size() == s.size && after.ModTime().Equal(s.modTime) {
+			return full, nil
+		}
+		log.Debug("places checkpointed during snapshot, retrying", "path", s.src)
+		s.modTime = time.Time{}
+	}
+
+	return full, fmt.Errorf("%s keeps changing, could not take a snapshot", s.src)
+}
+
+// copyWAL replaces the WAL of the snapshot. The shared memory index is
+// removed so that sqlite rebuilds it from the new WAL.
+func (s *PlacesSnapshot) copyWAL() error {
+	dst := s.Path()
+	if err := os.Remove(dst + shmSuffix); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	err := copyFile(s.src+walSuffix, dst+walSuffix)
+	if errors.Is(err, fs.ErrNotExist) {
+		err = os.Remove(dst + walSuffix)
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+	}
+	return err
+}
+
+// Clean removes the snapshot files
+func (s *PlacesSnapshot) Clean() error {
+	return os.RemoveAll(s.dir)
+}
+
+// copyFile copies src over dst, truncating dst
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+
+	if _, err = io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,153 @@
+package mozilla
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/blob42/gosuki/internal/database"
+	"github.com/blob42/gosuki/internal/utils"
+)
+
+// newTestPlaces copies the test places.sqlite to a temp dir and returns its
+// path with a connection to write to it like Firefox does
+func newTestPlaces(tb testing.TB) (string, *sqlx.DB) {
+	tb.Helper()
+	utils.TMPDIR = tb.TempDir()
+	src := filepath.Join(tb.TempDir(), PlacesFile)
+	require.NoError(tb, utils.CopyFileToDst("testdata/places.sqlite", src))
+
+	db, err := sqlx.Open("sqlite3", "file:"+src+"?_journal_mode=WAL")
+	require.NoError(tb, err)
+	tb.Cleanup(func() { db.Close() })
+
+	// keep the WAL around between writes
+	_, err = db.Exec("PRAGMA wal_autocheckpoint=0")
+	require.NoError(tb, err)
+
+	return src, db
+}
+
+func openSnapshot(tb testing.TB, snap *PlacesSnapshot) *database.DB {
+	tb.Helper()
+	db, err := database.NewDB("places_snapshot", snap.Path(), database.DBTypeFileDSN,
+		database.DsnOptions{"mode": "ro"}).Init()
+	require.NoError(tb, err)
+	return db
+}
+
+func TestPlacesSnapshot(t *testing.T) {
+	src, writer := newTestPlaces(t)
+
+	snap, err := NewPlacesSnapshot(src)
+	require.NoError(t, err)
+	defer snap.Clean()
+
+	title := func() string {
+		db := openSnapshot(t, snap)
+		defer db.Close()
+		var title string
+		require.NoError(t, db.Handle.Get(&title, "SELECT title FROM moz_bookmarks WHERE id = 3"))
+		return title
+	}
+
+	full, err := snap.Refresh()
+	require.NoError(t, err)
+	assert.True(t, full, "first refresh copies the database")
+
+	full, err = snap.Refresh()
+	require.NoError(t, err)
+	assert.False(t, full)
+
+	_, err = writer.Exec("UPDATE moz_bookmarks SET title = 'in wal' WHERE id = 3")
+	require.NoError(t, err)
+	full, err = snap.Refresh()
+	require.NoError(t, err)
+	assert.False(t, full, "changes in the WAL only copy the WAL")
+	assert.Equal(t, "in wal", title())
+
+	_, err = writer.Exec("UPDATE moz_bookmarks SET title = 'checkpointed' WHERE id = 3")
+	require.NoError(t, err)
+	_, err = writer.Exec("PRAGMA wal_checkpoint(TRUNCATE)")
+	require.NoError(t, err)
+	full, err = snap.Refresh()
+	require.NoError(t, err)
+	assert.True(t, full, "a checkpoint copies the database again")
+	assert.Equal(t, "checkpointed", title())
+}
+
+// Compares reading the bookmarks changed by a write to places.sqlite through
+// a full copy per read, a snapshot refreshed from the WAL and a live connection
+func BenchmarkPlacesRead(b *testing.B) {
+	read := func(b *testing.B, db *sqlx.DB) {
+		var urls []string
+		if err := db.Select(&urls, QBookmarkedURLs); err != nil {
+			b.Fatal(err)
+		}
+	}
+
+	write := func(b *testing.B, db *sqlx.DB, i int) {
+		b.StopTimer()
+		_, err := db.Exec("UPDATE moz_bookmarks SET title = ? WHERE id = 3", fmt.Sprint(i))
+		if err != nil {
+			b.Fatal(err)
+		}
+		b.StartTimer()
+	}
+
+	b.Run("copy", func(b *testing.B) {
+		src, writer := newTestPlaces(b)
+		for i := 0; b.Loop(); i++ {
+			write(b, writer, i)
+			dir := b.TempDir()
+			if err := utils.CopyFilesToTmpFolder(src+"*", dir); err != nil {
+				b.Fatal(err)
+			}
+			db, err := database.NewDB("places_copy", filepath.Join(dir, PlacesFile),
+				database.DBTypeFileDSN, database.DsnOptions{"_journal_mode": "WAL"}).Init()
+			if err != nil {
+				b.Fatal(err)
+			}
+			read(b, db.Handle)
+			db.Close()
+		}
+	})
+
+	b.Run("snapshot", func(b *testing.B) {
+		src, writer := newTestPlaces(b)
+		snap, err := NewPlacesSnapshot(src)
+		if err != nil {
+			b.Fatal(err)
+		}
+		defer snap.Clean()
+
+		for i := 0; b.Loop(); i++ {
+			write(b, writer, i)
+			if _, err := snap.Refresh(); err != nil {
+				b.Fatal(err)
+			}
+			db := openSnapshot(b, snap)
+			read(b, db.Handle)
+			db.Close()
+		}
+	})
+
+	b.Run("live", func(b *testing.B) {
+		src, writer := newTestPlaces(b)
+		db, err := database.NewDB("places_live", src, database.DBTypeFileDSN,
+			database.DsnOptions{"mode": "ro"}).Init()
+		if err != nil {
+			b.Fatal(err)
+		}
+		defer db.Close()
+
+		for i := 0; b.Loop(); i++ {
+			write(b, writer, i)
+			read(b, db.Handle)
+		}
+	})
+}
@@ -19,14 +19,18 @@
 // You should have received a copy of the GNU Affero General Public License along with
 // gosuki.  If not, see <http://www.gnu.org/licenses/>.
 
-// TODO: auto detect vfs lock then switch or not to watch&copy places
 package mozilla
 
 import (
+	"database/sql"
 	"errors"
 	"fmt"
+	"io/fs"
 	"path"
 
+	sqlite3 "github.com/mattn/go-sqlite3"
+
+	"github.com/blob42/gosuki/internal/database"
 	"github.com/blob42/gosuki/internal/utils"
 )
 
@@ -44,16 +48,143 @@ const (
 	//- https://dxr.mozilla.org/mozilla-central/source/storage/mozStorageService.cpp#377
 	//- Change on github: https://github.com/mozilla/gecko-dev/commit/a543f35d4be483b19446304f52e4781d7a4a0a2f
 	PrefMultiProcessAccess = "storage.multiProcessAccess.enabled"
+
+	// Preferences saved by Firefox, including the ones changed in about:config
+	SavedPrefsFile = "prefs.js"
 )
 
 var (
 	ErrMultiProcessAlreadyEnabled = errors.New("multiProcessAccess already enabled")
 )
 
-// TODO!:
-func CheckVFSLock(bkDir string) error {
-	log.Debugf("TODO: checking VFS for <%s>", bkDir)
-	return nil
+// ReadStrategy is how gosuki reads places.sqlite
+type ReadStrategy int
+
+const (
+	// Read a copy of places.sqlite and its WAL made in a tmp dir. Works even
+	// when Firefox holds an exclusive lock on the database.
+	ReadCopy ReadStrategy = iota
+
+	// Read places.sqlite in place. Requires the VFS lock to be disabled and
+	// the database to use the WAL journal so reads do not block Firefox.
+	ReadLive
+)
+
+func (s ReadStrategy) String() string {
+	if s == ReadLive {
+		return "live"
+	}
+	return "copy"
+}
+
+// VFSLockState describes the VFS lock of the places.sqlite of a profile
+type VFSLockState struct {
+	// multiProcessAccess pref found in user.js or prefs.js
+	MultiProcessAccess bool
+
+	// places.sqlite is exclusively locked by a running browser
+	Locked bool
+
+	// journal mode of places.sqlite, empty if it could not be read
+	JournalMode string
+
+	Strategy ReadStrategy
+}
+
+func (st VFSLockState) String() string {
+	journal := st.JournalMode
+	if journal == "" {
+		journal = "unknown"
+	}
+	return fmt.Sprintf("multiProcessAccess=%t locked=%t journal=%s strategy=%s",
+		st.MultiProcessAccess, st.Locked, journal, st.Strategy)
+}
+
+// MultiProcessAccess reports whether the VFS lock is disabled in the profile
+// at bkDir. Firefox applies user.js over prefs.js at startup so user.js is
+// looked up first.
+func MultiProcessAccess(bkDir string) (bool, error) {
+	for _, file := range []string{PrefsFile, SavedPrefsFile} {
+		enabled, err := GetPrefBool(path.Join(bkDir, file), PrefMultiProcessAccess)
+		if errors.Is(err, ErrPrefNotFound) || errors.Is(err, fs.ErrNotExist) {
+			continue
+		} else if err != nil {
+			return false, fmt.Errorf("%s: %w", file, err)
+		}
+		return enabled, nil
+	}
+
+	return false, nil
+}
+
+// CheckVFSLock detects the VFS lock state of places.sqlite in bkDir and
+// selects how it should be read. The database is read in place only when
+// the VFS lock is disabled, no exclusive lock is held and it is in WAL mode.
+func CheckVFSLock(bkDir string) (*VFSLockState, error) {
+	var err error
+	state := &VFSLockState{}
+
+	if state.MultiProcessAccess, err = MultiProcessAccess(bkDir); err != nil {
+		return nil, err
+	}
+
+	state.JournalMode, err = probePlaces(path.Join(bkDir, PlacesFile))
+	if errors.Is(err, database.ErrVfsLocked) {
+		state.Locked = true
+	} else if err != nil {
+		return nil, err
+	}
+
+	if state.MultiProcessAccess && !state.Locked && state.JournalMode == "wal" {
+		state.Strategy = ReadLive
+	}
+
+	log.Debug("checked VFS lock", "dir", bkDir, "state", state)
+	return state, nil
+}
+
+// probePlaces opens places.sqlite read only and returns its journal mode.
+// Returns [database.ErrVfsLocked] if a browser holds an exclusive lock.
+func probePlaces(placesPath string) (string, error) {
+	db := database.NewDB("places_probe",
+		placesPath,
+		database.DBTypeFileDSN,
+		database.DsnOptions{"mode": "ro", "_busy_timeout": "100"},
+	)
+
+	// plain driver, the gosuki sqlite hooks are only registered by commands
+	// using the gosuki database
+	db.EngineMode = "sqlite3"
+
+	db, err := db.Init()
+	if err != nil {
+		return "", err
+	}
+	defer db.Close()
+
+	var mode string
+	if err = db.Handle.Get(&mode, "PRAGMA journal_mode"); err != nil {
+		return "", lockErr(err)
+	}
+
+	// reading a row needs a shared lock, refused under an exclusive lock
+	var id int64
+	err = db.Handle.Get(&id, "SELECT id FROM moz_bookmarks LIMIT 1")
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return "", lockErr(err)
+	}
+
+	return mode, nil
+}
+
+// lockErr converts sqlite busy errors to [database.ErrVfsLocked]
+func lockErr(err error) error {
+	var sqlErr sqlite3.Error
+	if errors.As(err, &sqlErr) &&
+		(sqlErr.Code == sqlite3.ErrBusy || sqlErr.Code == sqlite3.ErrLocked) {
+		return database.ErrVfsLocked
+	}
+	return err
 }
 
 func UnlockPlaces(bkDir string) error {
@@ -0,0 +1,88 @@
+package mozilla
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/blob42/gosuki/internal/utils"
+)
+
+// newVFSProfile returns a profile dir holding a copy of the test places.sqlite
+// and the given user.js and prefs.js content
+func newVFSProfile(t *testing.T, userJS, prefsJS string) string {
+	t.Helper()
+	dir := t.TempDir()
+	require.NoError(t, utils.CopyFileToDst("testdata/places.sqlite", filepath.Join(dir, PlacesFile)))
+	if userJS != "" {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, PrefsFile), []byte(userJS), 0644))
+	}
+	if prefsJS != "" {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, SavedPrefsFile), []byte(prefsJS), 0644))
+	}
+	return dir
+}
+
+const (
+	prefEnabled  = `user_pref("storage.multiProcessAccess.enabled", true);` + "\n"
+	prefDisabled = `user_pref("storage.multiProcessAccess.enabled", false);` + "\n"
+)
+
+func TestCheckVFSLock(t *testing.T) {
+	t.Run("default prefs", func(t *testing.T) {
+		state, err := CheckVFSLock(newVFSProfile(t, "", ""))
+		require.NoError(t, err)
+		assert.False(t, state.MultiProcessAccess)
+		assert.False(t, state.Locked)
+		assert.Equal(t, "wal", state.JournalMode)
+		assert.Equal(t, ReadCopy, state.Strategy)
+	})
+
+	t.Run("multiProcessAccess in user.js", func(t *testing.T) {
+		state, err := CheckVFSLock(newVFSProfile(t, prefEnabled, ""))
+		require.NoError(t, err)
+		assert.True(t, state.MultiProcessAccess)
+		assert.Equal(t, ReadLive, state.Strategy)
+	})
+
+	t.Run("multiProcessAccess in prefs.js", func(t *testing.T) {
+		state, err := CheckVFSLock(newVFSProfile(t, "", prefEnabled))
+		require.NoError(t, err)
+		assert.Equal(t, ReadLive, state.Strategy)
+	})
+
+	t.Run("user.js overrides prefs.js", func(t *testing.T) {
+		state, err := CheckVFSLock(newVFSProfile(t, prefDisabled, prefEnabled))
+		require.NoError(t, err)
+		assert.False(t, state.MultiProcessAccess)
+		assert.Equal(t, ReadCopy, state.Strategy)
+	})
+
+	t.Run("exclusive lock", func(t *testing.T) {
+		dir := newVFSProfile(t, prefEnabled, "")
+
+		// hold the lock like Firefox does without multiProcessAccess
+		db, err := sqlx.Open("sqlite3", "file:"+filepath.Join(dir, PlacesFile))
+		require.NoError(t, err)
+		defer db.Close()
+		db.SetMaxOpenConns(1)
+		_, err = db.Exec("PRAGMA locking_mode=EXCLUSIVE")
+		require.NoError(t, err)
+		_, err = db.Exec("UPDATE moz_bookmarks SET title = title WHERE id = 1")
+		require.NoError(t, err)
+
+		state, err := CheckVFSLock(dir)
+		require.NoError(t, err)
+		assert.True(t, state.Locked)
+		assert.Equal(t, ReadCopy, state.Strategy)
+	})
+
+	t.Run("missing places", func(t *testing.T) {
+		_, err := CheckVFSLock(t.TempDir())
+		assert.Error(t, err)
+	})
+}
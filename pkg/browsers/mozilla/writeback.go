@@ -0,0 +1,366 @@
+//
+// Copyright (c) 2023-2025 Chakib Ben Ziane <contact@blob42.xyz> and [`GoSuki` contributors]
+// (https://github.com/blob42/gosuki/graphs/contributors).
+//
+// All rights reserved.
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+//
+// This file is part of GoSuki.
+//
+// GoSuki is free software: you can redistribute it and/or modify it under the terms of
+// the GNU Affero General Public License as published by the Free Software Foundation,
+// either version 3 of the License, or (at your option) any later version.
+//
+// GoSuki is distributed in the hope that it will be useful, but WITHOUT ANY WARRANTY;
+// without even the implied warranty of MERCHANTABILITY or FITNESS FOR A PARTICULAR
+// PURPOSE.  See the GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License along with
+// gosuki.  If not, see <http://www.gnu.org/licenses/>.
+
+package mozilla
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/bits"
+	"net/url"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/blob42/gosuki/internal/utils"
+)
+
+// Writing bookmarks to places.sqlite.
+//
+// Bookmarks are written to a folder under "Other Bookmarks" (`unfiled`)
+// identified by a fixed guid. Firefox maintains some columns with temporary
+// triggers that do not exist outside of the browser, the writer updates
+// `moz_places.foreign_count` itself and computes `url_hash` like Firefox.
+// Changes are recorded for Firefox Sync the way Firefox does: the change
+// counter of modified items and of their parents is bumped and removed items
+// known to the sync server get a tombstone in `moz_bookmarks_deleted`.
+
+const (
+	// guid of the folder written by gosuki, guids are 12 url safe base64 chars
+	WriteBackGUID = "gosukifolder"
+
+	// id of the "Other Bookmarks" root folder
+	UnfiledFolderID = 5
+
+	// Firefox only hashes the first 1500 chars of an url
+	maxCharsToHash = 1500
+
+	goldenRatioU32 = 0x9E3779B9
+
+	// moz_bookmarks.syncStatus of items not uploaded yet
+	syncStatusNew = 1
+
+	// moz_bookmarks.syncStatus of items known to the sync server
+	syncStatusNormal = 2
+)
+
+var (
+	ErrPlacesLocked = errors.New("places.sqlite is locked by a running browser")
+)
+
+// sql queries used by the writer
+const (
+	QWriteBackFolderID = `SELECT id FROM moz_bookmarks WHERE guid = '` + WriteBackGUID + `'`
+
+	qWriteBackEntries = `
+	SELECT moz_places.url, ifnull(moz_bookmarks.title, '') AS title FROM moz_bookmarks
+	JOIN moz_places ON moz_bookmarks.fk = moz_places.id
+	WHERE moz_bookmarks.parent = ?
+	ORDER BY moz_bookmarks.position
+	`
+
+	// urls bookmarked outside of the gosuki folder
+	qOtherBookmarkedURLs = `
+	SELECT DISTINCT moz_places.url FROM moz_bookmarks
+	JOIN moz_places ON moz_bookmarks.fk = moz_places.id
+	WHERE moz_bookmarks.type = 1
+	AND moz_bookmarks.parent NOT IN (` + QWriteBackFolderID + `)
+	`
+)
+
+// WriteBackEntry is a bookmark to write in the gosuki folder
+type WriteBackEntry struct {
+	URL   string `db:"url"`
+	Title string `db:"title"`
+}
+
+// CheckWritable returns [ErrPlacesLocked] if places.sqlite in bkDir is used by
+// a process. Disabling the VFS lock allows reading places.sqlite while the
+// browser runs but writing under it would race with the browser's own writes.
+func CheckWritable(bkDir string) error {
+	pusers, err := utils.FileProcessUsers(path.Join(bkDir, PlacesFile))
+	if err != nil {
+		return err
+	}
+	if len(pusers) > 0 {
+		return ErrPlacesLocked
+	}
+
+	return nil
+}
+
+// hashString is mozilla::HashString from mfbt/HashFunctions.h
+func hashString(s string) uint32 {
+	var h uint32
+	for i := 0; i < len(s); i++ {
+		h = goldenRatioU32 * (bits.RotateLeft32(h, 5) ^ uint32(s[i]))
+	}
+	return h
+}
+
+// URLHash computes the `moz_places.url_hash` of rawURL. The first 16 bits
+// hold the hash of the scheme and the last 32 bits the hash of the url.
+func URLHash(rawURL string) int64 {
+	head := rawURL[:min(len(rawURL), maxCharsToHash)]
+	hash := int64(hashString(head))
+
+	if i := strings.IndexByte(rawURL, ':'); i >= 0 {
+		prefix := int64(hashString(rawURL[:i]) & 0xFFFF)
+		hash += prefix << 32
+	}
+
+	return hash
+}
+
+// RevHost returns the `moz_places.rev_host` of rawURL: the reversed host
+// followed by a dot
+func RevHost(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+
+	host := []rune(strings.ToLower(u.Hostname()))
+	slices.Reverse(host)
+	return string(host) + "."
+}
+
+// NewGUID returns a random places guid
+func NewGUID() string {
+	buf := make([]byte, 9)
+	rand.Read(buf)
+	return base64.RawURLEncoding.EncodeToString(buf)
+}
+
+// WriteBackFolder replaces the content of the gosuki folder with entries.
+// Entries already bookmarked elsewhere are skipped. The folder is created if
+// needed and removed when empty. Returns false when the folder was up to date.
+func WriteBackFolder(db *sqlx.DB, title string, entries []WriteBackEntry, now time.Time) (bool, error) {
+	tx, err := db.Beginx()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	var folderID int64
+	err = tx.Get(&folderID, QWriteBackFolderID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return false, err
+	}
+
+	var others []string
+	if err = tx.Select(&others, qOtherBookmarkedURLs); err != nil {
+		return false, err
+	}
+
+	known := make(map[string]bool, len(others))
+	for _, u := range others {
+		known[u] = true
+	}
+
+	var want []WriteBackEntry
+	for _, e := range entries {
+		if known[e.URL] {
+			continue
+		}
+		known[e.URL] = true
+		want = append(want, e)
+	}
+
+	var have []WriteBackEntry
+	var folderTitle string
+	if folderID != 0 {
+		if err = tx.Select(&have, qWriteBackEntries, folderID); err != nil {
+			return false, err
+		}
+		if err = tx.Get(&folderTitle, `SELECT title FROM moz_bookmarks WHERE id = ?`, folderID); err != nil {
+			return false, err
+		}
+	}
+
+	if slices.Equal(have, want) && (folderID == 0 || folderTitle == title) {
+		return false, nil
+	}
+
+	// firefox timestamps are in microseconds
+	ts := now.UnixMicro()
+
+	if folderID != 0 {
+		if err = clearFolder(tx, folderID, ts); err != nil {
+			return false, err
+		}
+	}
+
+	switch {
+	case len(want) == 0 && folderID != 0:
+		if err = removeBookmark(tx, folderID, ts); err != nil {
+			return false, err
+		}
+		return true, tx.Commit()
+
+	case len(want) == 0:
+		return true, tx.Commit()
+
+	case folderID == 0:
+		res, err := tx.Exec(`
+		INSERT INTO moz_bookmarks (type, parent, position, title, dateAdded, lastModified, guid,
+			syncStatus, syncChangeCounter)
+		VALUES (2, ?, (SELECT ifnull(max(position) + 1, 0) FROM moz_bookmarks WHERE parent = ?),
+			?, ?, ?, ?, ?, 1)`,
+			UnfiledFolderID, UnfiledFolderID, title, ts, ts, WriteBackGUID, syncStatusNew)
+		if err != nil {
+			return false, fmt.Errorf("creating folder: %w", err)
+		}
+		if folderID, err = res.LastInsertId(); err != nil {
+			return false, err
+		}
+		if err = touchFolder(tx, UnfiledFolderID, ts); err != nil {
+			return false, err
+		}
+
+	default:
+		if err = touchFolder(tx, folderID, ts); err != nil {
+			return false, err
+		}
+		_, err = tx.Exec(`UPDATE moz_bookmarks SET title = ? WHERE id = ?`, title, folderID)
+		if err != nil {
+			return false, err
+		}
+	}
+
+	for pos, e := range want {
+		placeID, err := upsertPlace(tx, e)
+		if err != nil {
+			return false, fmt.Errorf("%s: %w", e.URL, err)
+		}
+
+		_, err = tx.Exec(`
+		INSERT INTO moz_bookmarks (type, fk, parent, position, title, dateAdded, lastModified, guid,
+			syncStatus, syncChangeCounter)
+		VALUES (1, ?, ?, ?, ?, ?, ?, ?, ?, 1)`,
+			placeID, folderID, pos, e.Title, ts, ts, NewGUID(), syncStatusNew)
+		if err != nil {
+			return false, fmt.Errorf("%s: %w", e.URL, err)
+		}
+
+		_, err = tx.Exec(`UPDATE moz_places SET foreign_count = foreign_count + 1 WHERE id = ?`, placeID)
+		if err != nil {
+			return false, err
+		}
+	}
+
+	return true, tx.Commit()
+}
+
+// bumps the sync change counter and the modification time of a folder whose
+// children changed
+func touchFolder(tx *sqlx.Tx, folderID int64, ts int64) error {
+	_, err := tx.Exec(`
+	UPDATE moz_bookmarks SET syncChangeCounter = syncChangeCounter + 1, lastModified = ?
+	WHERE id = ?`, ts, folderID)
+	return err
+}
+
+// records the removal of the bookmarks matching `where` for Firefox Sync. Like
+// Firefox, items never uploaded do not get a tombstone.
+func insertTombstones(tx *sqlx.Tx, ts int64, where string, args ...any) error {
+	args = append([]any{ts, syncStatusNormal}, args...)
+	_, err := tx.Exec(`
+	INSERT OR REPLACE INTO moz_bookmarks_deleted (guid, dateRemoved)
+	SELECT guid, ? FROM moz_bookmarks WHERE syncStatus = ? AND `+where, args...)
+	return err
+}
+
+// removes the bookmarks in folder and releases their places
+func clearFolder(tx *sqlx.Tx, folderID int64, ts int64) error {
+	if err := insertTombstones(tx, ts, "parent = ?", folderID); err != nil {
+		return err
+	}
+
+	_, err := tx.Exec(`
+	UPDATE moz_places SET foreign_count = foreign_count - 1
+	WHERE id IN (SELECT fk FROM moz_bookmarks WHERE parent = ? AND fk IS NOT NULL)`, folderID)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`DELETE FROM moz_bookmarks WHERE parent = ?`, folderID)
+	if err != nil {
+		return err
+	}
+
+	return touchFolder(tx, folderID, ts)
+}
+
+// removes a bookmark and shifts the position of the following siblings
+func removeBookmark(tx *sqlx.Tx, id int64, ts int64) error {
+	var parent int64
+	if err := tx.Get(&parent, `SELECT parent FROM moz_bookmarks WHERE id = ?`, id); err != nil {
+		return err
+	}
+
+	if err := insertTombstones(tx, ts, "id = ?", id); err != nil {
+		return err
+	}
+
+	_, err := tx.Exec(`
+	UPDATE moz_bookmarks SET position = position - 1
+	WHERE parent = ?
+	AND position > (SELECT position FROM moz_bookmarks WHERE id = ?)`, parent, id)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`DELETE FROM moz_bookmarks WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+
+	return touchFolder(tx, parent, ts)
+}
+
+// returns the id of the place of e, inserting it if needed
+func upsertPlace(tx *sqlx.Tx, e WriteBackEntry) (int64, error) {
+	var id int64
+	err := tx.Get(&id, `SELECT id FROM moz_places WHERE url_hash = ? AND url = ?`,
+		URLHash(e.URL), e.URL)
+	if err == nil {
+		return id, nil
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		return 0, err
+	}
+
+	res, err := tx.Exec(`
+	INSERT INTO moz_places (url, title, rev_host, hidden, frecency, guid, url_hash)
+	VALUES (?, ?, ?, 0, -1, ?, ?)`,
+		e.URL, e.Title, RevHost(e.URL), NewGUID(), URLHash(e.URL))
+	if err != nil {
+		return 0, err
+	}
+
+	return res.LastInsertId()
+}
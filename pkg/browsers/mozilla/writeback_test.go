@@ -0,0 +1,144 @@
+package mozilla
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/blob42/gosuki/internal/utils"
+)
+
+func TestURLHash(t *testing.T) {
+	// values from testdata/places.sqlite
+	assert.EqualValues(t, 125509244926400, URLHash("http://rust.org/"))
+	assert.EqualValues(t, 47356411089529, URLHash("https://www.mozilla.org/privacy/firefox/"))
+	assert.EqualValues(t, 47357795150914, URLHash("https://support.mozilla.org/en-US/products/firefox"))
+}
+
+func TestRevHost(t *testing.T) {
+	assert.Equal(t, "gro.allizom.www.", RevHost("https://www.Mozilla.org/about/"))
+	assert.Equal(t, ".", RevHost("about:blank"))
+}
+
+func TestWriteBackFolder(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, utils.CopyFileToDst("testdata/places.sqlite", filepath.Join(dir, PlacesFile)))
+
+	db, err := sqlx.Open("sqlite3", filepath.Join(dir, PlacesFile))
+	require.NoError(t, err)
+	defer db.Close()
+
+	// a gap left by a removed bookmark
+	_, err = db.Exec(`UPDATE moz_bookmarks SET position = 5 WHERE guid = 'Zf48osIfqY5X'`)
+	require.NoError(t, err)
+	var unfiledCounter int
+	require.NoError(t, db.Get(&unfiledCounter,
+		`SELECT syncChangeCounter FROM moz_bookmarks WHERE id = ?`, UnfiledFolderID))
+
+	entries := []WriteBackEntry{
+		{URL: "https://go.dev/", Title: "already bookmarked"},
+		{URL: "https://gosuki.net/", Title: "GoSuki"},
+		{URL: "https://www.mozilla.org/privacy/firefox/", Title: "existing place"},
+	}
+	now := time.Now()
+
+	changed, err := WriteBackFolder(db, "gosuki", entries, now)
+	require.NoError(t, err)
+	require.True(t, changed)
+
+	var folderID int64
+	require.NoError(t, db.Get(&folderID, QWriteBackFolderID))
+
+	var have []WriteBackEntry
+	require.NoError(t, db.Select(&have, qWriteBackEntries, folderID))
+	assert.Equal(t, entries[1:], have)
+
+	var folder struct {
+		Position   int `db:"position"`
+		SyncStatus int `db:"syncStatus"`
+	}
+	require.NoError(t, db.Get(&folder,
+		`SELECT position, syncStatus FROM moz_bookmarks WHERE id = ?`, folderID))
+	assert.Equal(t, 6, folder.Position)
+	assert.Equal(t, syncStatusNew, folder.SyncStatus)
+
+	var counter int
+	require.NoError(t, db.Get(&counter,
+		`SELECT syncChangeCounter FROM moz_bookmarks WHERE id = ?`, UnfiledFolderID))
+	assert.Equal(t, unfiledCounter+1, counter)
+
+	var place struct {
+		Hash    int64  `db:"url_hash"`
+		RevHost string `db:"rev_host"`
+		Count   int    `db:"foreign_count"`
+	}
+	require.NoError(t, db.Get(&place,
+		`SELECT url_hash, rev_host, foreign_count FROM moz_places WHERE url = ?`, "https://gosuki.net/"))
+	assert.Equal(t, URLHash("https://gosuki.net/"), place.Hash)
+	assert.Equal(t, "ten.ikusog.", place.RevHost)
+	assert.Equal(t, 1, place.Count)
+
+	t.Run("up to date", func(t *testing.T) {
+		changed, err := WriteBackFolder(db, "gosuki", entries, now)
+		require.NoError(t, err)
+		assert.False(t, changed)
+	})
+
+	tombstones := func(t *testing.T) []string {
+		t.Helper()
+		var guids []string
+		require.NoError(t, db.Select(&guids, `SELECT guid FROM moz_bookmarks_deleted ORDER BY guid`))
+		return guids
+	}
+
+	t.Run("removed bookmarks are recorded for sync", func(t *testing.T) {
+		// uploaded by firefox sync
+		_, err := db.Exec(`UPDATE moz_bookmarks SET syncStatus = ?, syncChangeCounter = 0
+			WHERE id = ? OR parent = ?`, syncStatusNormal, folderID, folderID)
+		require.NoError(t, err)
+
+		var synced []string
+		require.NoError(t, db.Select(&synced,
+			`SELECT guid FROM moz_bookmarks WHERE parent = ? ORDER BY guid`, folderID))
+
+		changed, err := WriteBackFolder(db, "gosuki", entries[1:2], now)
+		require.NoError(t, err)
+		assert.True(t, changed)
+		assert.Equal(t, synced, tombstones(t))
+
+		require.NoError(t, db.Get(&counter,
+			`SELECT syncChangeCounter FROM moz_bookmarks WHERE id = ?`, folderID))
+		assert.NotZero(t, counter)
+	})
+
+	t.Run("remove folder", func(t *testing.T) {
+		changed, err := WriteBackFolder(db, "gosuki", nil, now)
+		require.NoError(t, err)
+		assert.True(t, changed)
+
+		var count int
+		require.NoError(t, db.Get(&count, `SELECT count(*) FROM moz_bookmarks WHERE guid = ?`, WriteBackGUID))
+		assert.Zero(t, count)
+		assert.Contains(t, tombstones(t), WriteBackGUID)
+
+		require.NoError(t, db.Get(&count,
+			`SELECT foreign_count FROM moz_places WHERE url = ?`, "https://gosuki.net/"))
+		assert.Zero(t, count)
+	})
+}
+
+func TestCheckWritable(t *testing.T) {
+	dir := newVFSProfile(t, prefEnabled, "")
+	require.NoError(t, CheckWritable(dir))
+
+	// used by a browser with the VFS lock disabled
+	f, err := os.Open(filepath.Join(dir, PlacesFile))
+	require.NoError(t, err)
+	defer f.Close()
+	require.ErrorIs(t, CheckWritable(dir), ErrPlacesLocked)
+}
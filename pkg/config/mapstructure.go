@@ -32,6 +32,7 @@ var (
 	mapDecoderConfig = &mapstructure.DecoderConfig{
 		DecodeHook: mapstructure.ComposeDecodeHookFunc(
 			StringToDurationFunc(),
+			mapstructure.TextUnmarshallerHookFunc(),
 		),
 	}
 )
@@ -61,3 +61,8 @@ type LoadingCompleteMsg struct {
 type RunnerStarted struct {
 	watch.WatchRunner
 }
+
+// Stopped a [watch.Runner] instance, for example after its profile was removed
+type RunnerStopped struct {
+	watch.WatchRunner
+}
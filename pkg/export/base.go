@@ -70,6 +70,21 @@ func (be BookmarksExporter) ExportFromRows(rows *sqlx.Rows) error {
 	var err error
 	var rawBook db.RawBookmark
 
+	if _, ok := be.e.(nestedExporter); ok {
+		var bookmarks []*gosuki.Bookmark
+		for rows.Next() {
+			rawBook = db.RawBookmark{}
+			if err = rows.StructScan(&rawBook); err != nil {
+				return fmt.Errorf("scanning book: %w", err)
+			}
+			bookmarks = append(bookmarks, rawBook.AsBookmark())
+		}
+		if err = rows.Err(); err != nil {
+			return err
+		}
+		return be.e.ExportBookmarks(bookmarks, be.w)
+	}
+
 	if err = be.e.WriteHeader(be.w); err != nil {
 		return err
 	}
@@ -105,3 +120,18 @@ type Exporter interface {
 	WriteHeader(w io.Writer) error
 	WriteFooter(w io.Writer) error
 }
+
+// Exporters that group bookmarks in folders are given all the bookmarks at
+// once with ExportBookmarks instead of one row at a time
+type nestedExporter interface {
+	nested()
+}
+
+// addedAt returns the creation time of the bookmark, falling back to the
+// modification time for bookmarks saved before creation times were tracked
+func addedAt(book *gosuki.Bookmark) uint64 {
+	if book.Created > 0 {
+		return book.Created
+	}
+	return book.Modified
+}
@@ -45,7 +45,7 @@ type pinboardBookmark struct {
 }
 
 func jsonBookmark(book *gosuki.Bookmark) pinboardBookmark {
-	timeStr := time.Unix(int64(book.Modified), 0).UTC().Format(time.RFC3339)
+	timeStr := time.Unix(int64(addedAt(book)), 0).UTC().Format(time.RFC3339)
 
 	return pinboardBookmark{
 		Href:        book.URL,
@@ -26,6 +26,7 @@ import (
 	"fmt"
 	"html"
 	"io"
+	"slices"
 	"strings"
 
 	"github.com/blob42/gosuki"
@@ -39,7 +40,8 @@ func (ns *NetscapeHTMLExporter) WriteHeader(w io.Writer) error {
 <META HTTP-EQUIV="Content-Type" CONTENT="text/html; charset=UTF-8">
 <TITLE>Bookmarks</TITLE>
 <H1>Bookmarks</H1>
-<DL>`)
+<DL><p>
+`)
 	return err
 }
 
@@ -48,6 +50,9 @@ func (ns *NetscapeHTMLExporter) WriteFooter(w io.Writer) error {
 	return err
 }
 
+// ExportBookmarks writes the bookmarks in nested folders rebuilt from their
+// hierarchical tags (Work/ProjectA/Docs). A bookmark is written in each of its
+// deepest folders, bookmarks without folder tags are written at the top level.
 func (ns *NetscapeHTMLExporter) ExportBookmarks(bookmarks []*gosuki.Bookmark, w io.Writer) error {
 	var err error
 
@@ -55,26 +60,113 @@ func (ns *NetscapeHTMLExporter) ExportBookmarks(bookmarks []*gosuki.Bookmark, w
 		return err
 	}
 
+	root := &nsFolder{}
 	for _, book := range bookmarks {
-		if _, err = w.Write(ns.MarshalBookmark(book)); err != nil {
-			return err
+		paths := folderPaths(book.Tags)
+		if len(paths) == 0 {
+			root.bookmarks = append(root.bookmarks, book)
+		}
+		for _, path := range paths {
+			root.folder(path).bookmarks = append(root.folder(path).bookmarks, book)
 		}
 	}
 
+	if err = ns.writeFolder(w, root, 1); err != nil {
+		return err
+	}
+
 	return ns.WriteFooter(w)
 }
 
 func (ns *NetscapeHTMLExporter) MarshalBookmark(book *gosuki.Bookmark) []byte {
-	return fmt.Appendf([]byte{}, `    <DT><A HREF="%s" TAGS="%s" ADD_DATE="%d" LAST_MODIFIED="%d">%s</A>
+	return ns.marshalBookmark(book, 1)
+}
+
+func (ns *NetscapeHTMLExporter) marshalBookmark(book *gosuki.Bookmark, depth int) []byte {
+	return fmt.Appendf([]byte{}, `%s<DT><A HREF="%s" TAGS="%s" ADD_DATE="%d" LAST_MODIFIED="%d">%s</A>
 `,
+		indent(depth),
+
 		html.EscapeString(book.URL),
 
 		// this is not conform to netscape export format, but we still save tags here
 		strings.Join(book.Tags, db.TagSep),
 
-		book.Modified,
+		addedAt(book),
 		book.Modified,
 
 		html.EscapeString(book.Title),
 	)
 }
+
+func (ns *NetscapeHTMLExporter) writeFolder(w io.Writer, folder *nsFolder, depth int) error {
+	for _, sub := range folder.folders {
+		_, err := fmt.Fprintf(w, "%s<DT><H3>%s</H3>\n%s<DL><p>\n",
+			indent(depth), html.EscapeString(sub.name), indent(depth))
+		if err != nil {
+			return err
+		}
+		if err = ns.writeFolder(w, sub, depth+1); err != nil {
+			return err
+		}
+		if _, err = fmt.Fprintf(w, "%s</DL><p>\n", indent(depth)); err != nil {
+			return err
+		}
+	}
+
+	for _, book := range folder.bookmarks {
+		if _, err := w.Write(ns.marshalBookmark(book, depth)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// the whole list of bookmarks is needed to group them in folders
+func (ns *NetscapeHTMLExporter) nested() {}
+
+func indent(depth int) string {
+	return strings.Repeat("    ", depth)
+}
+
+// folder of the exported bookmark tree
+type nsFolder struct {
+	name      string
+	folders   []*nsFolder
+	bookmarks []*gosuki.Bookmark
+}
+
+// folder returns the sub folder at path, creating it if needed
+func (f *nsFolder) folder(path string) *nsFolder {
+	current := f
+	for name := range strings.SplitSeq(path, gosuki.FolderSep) {
+		idx := slices.IndexFunc(current.folders, func(sub *nsFolder) bool {
+			return sub.name == name
+		})
+		if idx < 0 {
+			current.folders = append(current.folders, &nsFolder{name: name})
+			idx = len(current.folders) - 1
+		}
+		current = current.folders[idx]
+	}
+	return current
+}
+
+// folderPaths returns the hierarchical tags that are not the parent of
+// another one
+func folderPaths(tags []string) []string {
+	var paths []string
+	for _, tag := range tags {
+		if !strings.Contains(tag, gosuki.FolderSep) || slices.ContainsFunc(tags, func(other string) bool {
+			return strings.HasPrefix(other, tag+gosuki.FolderSep)
+		}) {
+			continue
+		}
+		parts := strings.Split(tag, gosuki.FolderSep)
+		if slices.Contains(parts, "") || slices.Contains(paths, tag) {
+			continue
+		}
+		paths = append(paths, tag)
+	}
+	return paths
+}
@@ -0,0 +1,47 @@
+package export
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/blob42/gosuki"
+)
+
+func TestNetscapeNestedFolders(t *testing.T) {
+	bookmarks := []*gosuki.Bookmark{
+		{URL: "https://work.com/docs", Title: "Work docs", Tags: []string{"Work/ProjectA", "Work/ProjectA/Docs", "spec"}},
+		{URL: "https://work.com/a", Title: "Project A", Tags: []string{"Work/ProjectA"}},
+		{URL: "https://home.com/docs", Title: "Home docs", Tags: []string{"Personal/Docs"}},
+		{URL: "https://top.com", Title: "Top", Tags: []string{"toolbar"}},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, (&NetscapeHTMLExporter{}).ExportBookmarks(bookmarks, &buf))
+
+	body := buf.String()
+	body = body[strings.Index(body, "<DL><p>")+len("<DL><p>") : strings.LastIndex(body, "</DL>")]
+	require.Equal(t, `
+    <DT><H3>Work</H3>
+    <DL><p>
+        <DT><H3>ProjectA</H3>
+        <DL><p>
+            <DT><H3>Docs</H3>
+            <DL><p>
+                <DT><A HREF="https://work.com/docs" TAGS="Work/ProjectA,Work/ProjectA/Docs,spec" ADD_DATE="0" LAST_MODIFIED="0">Work docs</A>
+            </DL><p>
+            <DT><A HREF="https://work.com/a" TAGS="Work/ProjectA" ADD_DATE="0" LAST_MODIFIED="0">Project A</A>
+        </DL><p>
+    </DL><p>
+    <DT><H3>Personal</H3>
+    <DL><p>
+        <DT><H3>Docs</H3>
+        <DL><p>
+            <DT><A HREF="https://home.com/docs" TAGS="Personal/Docs" ADD_DATE="0" LAST_MODIFIED="0">Home docs</A>
+        </DL><p>
+    </DL><p>
+    <DT><A HREF="https://top.com" TAGS="toolbar" ADD_DATE="0" LAST_MODIFIED="0">Top</A>
+`, body)
+}
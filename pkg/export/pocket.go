@@ -72,7 +72,7 @@ func (pe *PocketHTMLExporter) MarshalBookmark(book *gosuki.Bookmark) []byte {
 	return fmt.Appendf([]byte{}, `<li><a href="%s" time_added="%d" tags="%s">%s</a></li>
 `,
 		escapedURL,
-		book.Modified,
+		addedAt(book),
 		tagStr,
 		escapedTitle)
 }
@@ -66,7 +66,7 @@ func (rs *RSSXMLExporter) ExportBookmarks(bookmarks []*gosuki.Bookmark, w io.Wri
 }
 
 func (rs *RSSXMLExporter) MarshalBookmark(book *gosuki.Bookmark) []byte {
-	pubDate := time.Unix(int64(book.Modified), 0).Format("Mon, 02 Jan 2006 15:04:05 -0700")
+	pubDate := time.Unix(int64(addedAt(book)), 0).Format("Mon, 02 Jan 2006 15:04:05 -0700")
 
 	return []byte(fmt.Appendf([]byte{}, `    <item>
       <title><![CDATA[%s]]></title>
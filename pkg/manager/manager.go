@@ -23,6 +23,7 @@ package manager
 
 import (
 	"fmt"
+	"maps"
 	"os"
 	"os/signal"
 	"reflect"
@@ -63,6 +64,7 @@ type UnitManager interface {
 
 type WorkUnitManager struct {
 	name         string
+	id           string // key of the unit in the manager
 	stop         chan bool
 	workerQuit   chan bool
 	unit         WorkUnit
@@ -109,19 +111,31 @@ type Manager struct {
 	Quit         chan bool                   // Channel to signal that the manager is done
 	ready        chan bool                   // Signal channel indicating all workers are running
 	panic        chan error                  // Channel for panicking goroutines, used to force shutdown
+	closing      bool                        // Set once the manager starts shutting down all units
 	mu           sync.Mutex
 }
 
+// closeUnits marks the manager as closing and returns the units that have to be
+// stopped. Units can no longer be added or stopped individually after this.
+func (m *Manager) closeUnits() map[string]*WorkUnitManager {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.closing = true
+	return maps.Clone(m.workers)
+}
+
 func (m *Manager) Shutdown() {
 	<-m.ready
+	workers := m.closeUnits()
+
 	// send shutdown event to all worker units
-	for name, w := range m.workers {
+	for name, w := range workers {
 		log.Debugf("stopping %s\n", name)
 		w.stop <- true
 	}
 
 	// Wait for all units to quit
-	for name, w := range m.workers {
+	for name, w := range workers {
 		<-w.workerQuit
 		log.Debugf("%s down", name)
 	}
@@ -159,7 +173,7 @@ func (m *Manager) Start() {
 
 		case p := <-m.panic:
 
-			for name, w := range m.workers {
+			for name, w := range m.closeUnits() {
 				if w.isPaniced {
 					log.Errorf("<%s> panicked: %s", name, p)
 				} else {
@@ -225,10 +239,15 @@ func (m *Manager) AddUnit(unit WorkUnit, name string) *WorkUnitManager {
 	unitName := fmt.Sprintf("%s[%s", name, unitClass)
 	unitID := idGenerator(unitName)
 	unitName = fmt.Sprintf("%s#%d]", unitName, unitID)
+	workUnitManager.id = unitName
 
 	log.Trace("adding unit ", "name", unitName)
 	m.mu.Lock()
 	defer m.mu.Unlock()
+	if m.closing {
+		log.Warn("manager shutting down, not starting", "unit", unitName)
+		return workUnitManager
+	}
 	m.workers[unitName] = workUnitManager
 
 	// Launch the unit's goroutine *immediatly*
@@ -237,6 +256,23 @@ func (m *Manager) AddUnit(unit WorkUnit, name string) *WorkUnitManager {
 	return workUnitManager
 }
 
+// StopUnit stops a single unit and removes it from the manager while the other
+// units keep running. It blocks until the unit has called Done.
+func (m *Manager) StopUnit(wum *WorkUnitManager) {
+	m.mu.Lock()
+	if m.closing || m.workers[wum.id] != wum {
+		m.mu.Unlock()
+		return
+	}
+	delete(m.workers, wum.id)
+	m.mu.Unlock()
+
+	log.Debugf("stopping %s", wum.id)
+	wum.stop <- true
+	<-wum.workerQuit
+	log.Debugf("%s down", wum.id)
+}
+
 func NewManager() *Manager {
 	return &Manager{
 		signalIn: make(chan os.Signal, 1),
@@ -90,3 +90,53 @@ func TestRunMain(t *testing.T) {
 		<-quit
 	}
 }
+
+type stopWorker struct {
+	stopped chan bool
+}
+
+func (w *stopWorker) Run(um UnitManager) {
+	<-um.ShouldStop()
+	w.stopped <- true
+	um.Done()
+}
+
+func TestStopUnit(t *testing.T) {
+	manager := NewManager()
+
+	keep := &stopWorker{stopped: make(chan bool, 1)}
+	drop := &stopWorker{stopped: make(chan bool, 1)}
+	manager.AddUnit(keep, "keep")
+	wum := manager.AddUnit(drop, "drop")
+
+	go manager.Start()
+
+	manager.StopUnit(wum)
+	select {
+	case <-drop.stopped:
+	case <-time.After(time.Second):
+		t.Fatal("unit was not stopped")
+	}
+
+	if _, ok := manager.Units()["drop"]; ok {
+		t.Error("stopped unit still registered")
+	}
+	if _, ok := manager.Units()["keep"]; !ok {
+		t.Error("other unit was removed")
+	}
+
+	// stopping twice is a no-op
+	manager.StopUnit(wum)
+
+	go manager.Shutdown()
+	select {
+	case <-manager.Quit:
+	case <-time.After(time.Second):
+		t.Fatal("manager did not shut down")
+	}
+	select {
+	case <-keep.stopped:
+	default:
+		t.Error("remaining unit was not stopped on shutdown")
+	}
+}
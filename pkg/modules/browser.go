@@ -23,7 +23,9 @@ package modules
 
 import (
 	"fmt"
+	"html"
 	"io/fs"
+	"slices"
 	"time"
 
 	"github.com/blob42/gosuki"
@@ -81,6 +83,30 @@ type ProfilePrefs struct {
 	Profile string `toml:"profile" mapstructure:"profile"`
 }
 
+// DeletionMode controls what happens to bookmarks that are removed from the
+// browser.
+type DeletionMode string
+
+const (
+	// Tombstone bookmarks deleted from the browser. Tombstoned bookmarks are
+	// kept in the database but hidden from queries.
+	MirrorDeletions DeletionMode = "mirror"
+
+	// Keep bookmarks deleted from the browser, gosuki acts as an archive.
+	ArchiveOnly DeletionMode = "archive"
+)
+
+// UnmarshalText rejects unknown deletion modes when the config is loaded
+func (m *DeletionMode) UnmarshalText(text []byte) error {
+	switch mode := DeletionMode(text); mode {
+	case MirrorDeletions, ArchiveOnly:
+		*m = mode
+		return nil
+	}
+	return fmt.Errorf("unknown deletion mode %q, expected %q or %q",
+		text, MirrorDeletions, ArchiveOnly)
+}
+
 // BrowserConfig is the main browser configuration shared by all browser modules.
 type BrowserConfig struct {
 	Name string
@@ -116,6 +142,9 @@ type BrowserConfig struct {
 
 	// Registered hooks
 	hooks []hooks.NamedHook
+
+	// urls seen during the last run, used to detect deleted bookmarks
+	lastURLs map[string]struct{}
 }
 
 func (b *BrowserConfig) GetWatcher() *watch.WatchDescriptor {
@@ -206,6 +235,161 @@ func (b BrowserConfig) RebuildIndex() {
 	log.Debugf("<%s> index rebuilt in %s", b.Name, time.Since(start))
 }
 
+// TrackDeletions compares the urls found by the current run with the ones found
+// by the previous run and returns the urls that were removed from the browser.
+// module is the module instance recorded in the bookmark sources, ex:
+// firefox_default. Removed urls are dropped from the URLIndex and from the
+// bookmark sources of the module. When mode is [MirrorDeletions] the urls held
+// by no other module or profile in the cache are also tombstoned, the other
+// urls are removed from the BufferDB.
+//
+// The first call compares against the urls module holds in the cache, to
+// catch the bookmarks removed while gosuki was not running.
+func (b *BrowserConfig) TrackDeletions(module string, current []string, mode DeletionMode) []string {
+	var deleted []string
+
+	seen := make(map[string]struct{}, len(current))
+	for _, url := range current {
+		seen[url] = struct{}{}
+	}
+
+	if b.lastURLs != nil {
+		for url := range b.lastURLs {
+			if _, ok := seen[url]; !ok {
+				deleted = append(deleted, url)
+			}
+		}
+	} else {
+		deleted = cachedDeletions(module, current)
+	}
+	b.lastURLs = seen
+
+	if len(deleted) == 0 {
+		return nil
+	}
+
+	log.Debug("bookmarks removed from browser", "module", b.Name, "count", len(deleted), "mode", mode)
+
+	if b.URLIndex != nil {
+		for _, url := range deleted {
+			b.URLIndex.Remove(url)
+		}
+	}
+
+	if b.BufferDB == nil {
+		return deleted
+	}
+
+	// profiles and flavours of this module holding the urls
+	modules, err := b.BufferDB.SourceModules(deleted...)
+	if err != nil {
+		log.Error("listing bookmark sources", "module", b.Name, "err", err)
+	}
+	if !slices.Contains(modules, module) {
+		modules = append(modules, module)
+	}
+
+	// archived bookmarks are no longer in the browser either
+	if err = b.BufferDB.RemoveSources(deleted...); err != nil {
+		log.Error("removing bookmark sources", "module", b.Name, "err", err)
+	}
+
+	tombstones, kept := b.splitDeleted(deleted, modules, mode)
+
+	if err = b.BufferDB.RemoveBookmarks(kept...); err != nil {
+		log.Error("removing bookmarks from buffer", "module", b.Name, "err", err)
+	}
+
+	if err = b.BufferDB.MarkDeleted(tombstones...); err != nil {
+		log.Error("tombstoning bookmarks", "module", b.Name, "err", err)
+	}
+
+	// bookmarks removed while gosuki was not running are not in the buffer
+	if database.Cache != nil && database.Cache.DB != nil {
+		if err = database.Cache.MarkDeleted(tombstones...); err != nil {
+			log.Error("tombstoning cached bookmarks", "module", b.Name, "err", err)
+		}
+	}
+
+	return deleted
+}
+
+// cachedDeletions returns the urls module holds in the cache that are not in
+// current. The cache is loaded from the database at startup.
+func cachedDeletions(module string, current []string) []string {
+	if database.Cache == nil || database.Cache.DB == nil {
+		return nil
+	}
+
+	cached, err := database.Cache.ModuleURLs(module)
+	if err != nil {
+		log.Error("listing cached bookmarks", "module", module, "err", err)
+		return nil
+	}
+
+	// urls are stored unescaped
+	seen := make(map[string]struct{}, len(current))
+	for _, url := range current {
+		seen[html.UnescapeString(url)] = struct{}{}
+	}
+
+	var deleted []string
+	for _, url := range cached {
+		if _, ok := seen[url]; !ok {
+			deleted = append(deleted, url)
+		}
+	}
+	return deleted
+}
+
+// splitDeleted separates the deleted urls to tombstone from the ones to keep
+// in the cache, either archived or still held by another source. The sources
+// of modules, the profiles and flavours of this module, are first removed
+// from the cache.
+func (b *BrowserConfig) splitDeleted(
+	deleted, modules []string,
+	mode DeletionMode,
+) (tombstones, kept []string) {
+	if database.Cache == nil || database.Cache.DB == nil {
+		if mode == ArchiveOnly {
+			return nil, deleted
+		}
+		return deleted, nil
+	}
+
+	err := database.Cache.RemoveModuleSources(modules, deleted...)
+	if err != nil {
+		log.Error("removing cached bookmark sources", "module", b.Name, "err", err)
+	}
+
+	if mode == ArchiveOnly {
+		return nil, deleted
+	}
+
+	held, err := database.Cache.HeldURLs(deleted...)
+	if err != nil {
+		log.Error("listing bookmark sources", "module", b.Name, "err", err)
+		return nil, deleted
+	}
+
+	isHeld := make(map[string]struct{}, len(held))
+	for _, url := range held {
+		isHeld[url] = struct{}{}
+	}
+	for _, url := range deleted {
+		if _, ok := isHeld[url]; ok {
+			kept = append(kept, url)
+		} else {
+			tombstones = append(tombstones, url)
+		}
+	}
+
+	if len(kept) > 0 {
+		log.Debug("removed bookmarks held by other sources", "module", b.Name, "count", len(kept))
+	}
+	return tombstones, kept
+}
+
 // SetupBrowser() is called for every browser module. It sets up the browser and calls
 // the following methods if they are implemented by the module:
 //
@@ -309,12 +493,11 @@ func SetupWatchers(browserConf *BrowserConfig, watches ...*watch.Watch) (bool, e
 		return false, nil
 	}
 
-	var bkPath string
-	if bkPath, err = browserConf.BookmarkPath(); err != nil {
+	if _, err = browserConf.BookmarkPath(); err != nil {
 		return false, err
 	}
 
-	browserConf.watcher, err = watch.NewWatcher(bkPath, watches...)
+	browserConf.watcher, err = watch.NewWatcher(browserConf.Name, watches...)
 	if err != nil {
 		return false, err
 	}
@@ -331,11 +514,10 @@ func SetupWatchersWithReducer(browserConf *BrowserConfig,
 		return false, nil
 	}
 
-	var bkPath string
-	if bkPath, err = browserConf.BookmarkPath(); err != nil {
+	if _, err = browserConf.BookmarkPath(); err != nil {
 		return false, err
 	}
-	browserConf.watcher, err = watch.NewWatcherWithReducer(bkPath, reducerChanLen, watches...)
+	browserConf.watcher, err = watch.NewWatcherWithReducer(browserConf.Name, reducerChanLen, watches...)
 	if err != nil {
 		return false, err
 	}
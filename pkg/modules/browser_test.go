@@ -0,0 +1,200 @@
+package modules
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/blob42/gosuki"
+	"github.com/blob42/gosuki/internal/database"
+	"github.com/blob42/gosuki/pkg/config"
+)
+
+func TestMain(m *testing.M) {
+	database.RegisterSqliteHooks()
+
+	cacheDB, err := database.NewDB(database.CacheName, "", database.DBTypeCacheDSN).Init()
+	if err != nil {
+		log.Fatal(err)
+	}
+	database.Cache = &database.CacheDB{DB: cacheDB}
+	database.Clock = &database.LamportClock{}
+
+	l2DB, err := database.NewDB(database.L2CacheName, "", database.DBTypeCacheDSN).Init()
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err = l2DB.InitSchema(context.Background()); err != nil {
+		log.Fatal(err)
+	}
+	database.L2Cache = &database.CacheDB{DB: l2DB}
+
+	os.Exit(m.Run())
+}
+
+// newTestBrowser returns a browser module holding urls, synced to the cache
+func newTestBrowser(t *testing.T, name string, urls ...string) *BrowserConfig {
+	t.Helper()
+	buffer, err := database.NewBuffer(name)
+	require.NoError(t, err)
+	t.Cleanup(func() { buffer.Close() })
+
+	b := &BrowserConfig{Name: name, BufferDB: buffer}
+	for _, url := range urls {
+		require.NoError(t, buffer.UpsertBookmark(&gosuki.Bookmark{
+			URL:     url,
+			Title:   url,
+			Module:  name,
+			Sources: []gosuki.Source{{Module: name}},
+		}))
+	}
+	require.Empty(t, b.TrackDeletions(name, urls, MirrorDeletions))
+	require.NoError(t, buffer.SyncToCache())
+	return b
+}
+
+func isDeleted(t *testing.T, url string) bool {
+	t.Helper()
+	var deleted bool
+	err := database.Cache.Handle.Get(&deleted,
+		"SELECT deleted FROM gskbookmarks WHERE URL = ?", url)
+	require.NoError(t, err)
+	return deleted
+}
+
+func TestTrackDeletions(t *testing.T) {
+	t.Run("urls held by another module are not tombstoned", func(t *testing.T) {
+		shared, own := "https://shared.example.com", "https://own.example.com"
+		firefox := newTestBrowser(t, "firefox_default", shared, own)
+		chrome := newTestBrowser(t, "chrome_Default", shared)
+
+		deleted := firefox.TrackDeletions(firefox.Name, nil, MirrorDeletions)
+		require.ElementsMatch(t, []string{shared, own}, deleted)
+		require.NoError(t, firefox.BufferDB.SyncToCache())
+
+		require.False(t, isDeleted(t, shared))
+		require.True(t, isDeleted(t, own))
+
+		sources, err := database.Cache.URLSources(t.Context(), shared)
+		require.NoError(t, err)
+		require.Len(t, sources, 1)
+		require.Equal(t, "chrome_Default", sources[0].Module)
+
+		// the last source removes it
+		require.Equal(t, []string{shared}, chrome.TrackDeletions(chrome.Name, nil, MirrorDeletions))
+		require.NoError(t, chrome.BufferDB.SyncToCache())
+		require.True(t, isDeleted(t, shared))
+	})
+
+	t.Run("bookmarks removed while not running", func(t *testing.T) {
+		kept, removed := "https://kept.example.com", "https://removed.example.com"
+		newTestBrowser(t, "falkon_default", kept, removed)
+
+		// a new run starts with an empty buffer
+		buffer, err := database.NewBuffer("falkon_restarted")
+		require.NoError(t, err)
+		t.Cleanup(func() { buffer.Close() })
+		restarted := &BrowserConfig{Name: "falkon_default", BufferDB: buffer}
+
+		deleted := restarted.TrackDeletions(restarted.Name, []string{kept}, MirrorDeletions)
+		require.Equal(t, []string{removed}, deleted)
+		require.NoError(t, restarted.BufferDB.SyncToCache())
+
+		require.False(t, isDeleted(t, kept))
+		require.True(t, isDeleted(t, removed))
+		require.Empty(t, restarted.TrackDeletions(restarted.Name, []string{kept}, MirrorDeletions))
+	})
+
+	t.Run("archive", func(t *testing.T) {
+		url := "https://archived.example.com"
+		qute := newTestBrowser(t, "qutebrowser", url)
+
+		require.Equal(t, []string{url}, qute.TrackDeletions(qute.Name, nil, ArchiveOnly))
+		require.NoError(t, qute.BufferDB.SyncToCache())
+		require.False(t, isDeleted(t, url))
+
+		sources, err := database.Cache.URLSources(t.Context(), url)
+		require.NoError(t, err)
+		require.Empty(t, sources)
+	})
+}
+
+// version and number of history revisions of url in the L2 cache
+func l2Version(t *testing.T, url string) (version, revisions uint64) {
+	t.Helper()
+	database.Cache.SyncTo(database.L2Cache.DB)
+	err := database.L2Cache.Handle.Get(&version,
+		"SELECT version FROM gskbookmarks WHERE URL = ?", url)
+	require.NoError(t, err)
+	err = database.L2Cache.Handle.Get(&revisions,
+		"SELECT count(*) FROM gskbookmarks_history WHERE url = ?", url)
+	require.NoError(t, err)
+	return version, revisions
+}
+
+func TestSharedDeletionIsStable(t *testing.T) {
+	url := "https://stable.example.com"
+	firefox := newTestBrowser(t, "firefox_work", url)
+	chrome := newTestBrowser(t, "chrome_Work", url)
+
+	sync := func() {
+		for range 3 {
+			firefox.TrackDeletions(firefox.Name, nil, MirrorDeletions)
+			require.NoError(t, firefox.BufferDB.SyncToCache())
+			chrome.TrackDeletions(chrome.Name, []string{url}, MirrorDeletions)
+			require.NoError(t, chrome.BufferDB.SyncToCache())
+		}
+	}
+
+	firefox.TrackDeletions(firefox.Name, nil, MirrorDeletions)
+	require.NoError(t, firefox.BufferDB.SyncToCache())
+	version, revisions := l2Version(t, url)
+
+	sync()
+	require.False(t, isDeleted(t, url))
+	v, r := l2Version(t, url)
+	require.Equal(t, version, v)
+	require.Equal(t, revisions, r)
+
+	t.Run("a module holding a deleted bookmark does not restore it", func(t *testing.T) {
+		require.NoError(t, database.Cache.MarkDeleted(url))
+		version, revisions := l2Version(t, url)
+
+		sync()
+		require.True(t, isDeleted(t, url))
+		v, r := l2Version(t, url)
+		require.Equal(t, version, v)
+		require.Equal(t, revisions, r)
+	})
+
+	t.Run("a bookmark added again is restored", func(t *testing.T) {
+		// sources are timestamped in seconds
+		time.Sleep(time.Second)
+		require.NoError(t, firefox.BufferDB.UpsertBookmark(&gosuki.Bookmark{
+			URL:     url,
+			Module:  firefox.Name,
+			Sources: []gosuki.Source{{Module: firefox.Name}},
+		}))
+		require.NoError(t, firefox.BufferDB.SyncToCache())
+		require.False(t, isDeleted(t, url))
+	})
+}
+
+func TestDeletionModeUnmarshalText(t *testing.T) {
+	var mode DeletionMode
+	require.NoError(t, mode.UnmarshalText([]byte("archive")))
+	require.Equal(t, ArchiveOnly, mode)
+	require.Error(t, mode.UnmarshalText([]byte("mirorr")))
+	require.Equal(t, ArchiveOnly, mode)
+
+	conf := &struct {
+		Deletions DeletionMode `mapstructure:"deletions"`
+	}{}
+	c := config.AsConfigurator(conf)
+	require.NoError(t, c.MapFrom(map[string]any{"deletions": "mirror"}))
+	require.Equal(t, MirrorDeletions, conf.Deletions)
+	require.Error(t, c.MapFrom(map[string]any{"deletions": "mirorr"}))
+}
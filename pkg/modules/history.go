@@ -0,0 +1,149 @@
+//
+//  Copyright (c) 2025 Chakib Ben Ziane <contact@blob42.xyz>  and [`gosuki` contributors](https://github.com/blob42/gosuki/graphs/contributors).
+//  All rights reserved.
+//
+//  SPDX-License-Identifier: AGPL-3.0-or-later
+//
+//  This file is part of GoSuki.
+//
+//  GoSuki is free software: you can redistribute it and/or modify
+//  it under the terms of the GNU Affero General Public License as
+//  published by the Free Software Foundation, either version 3 of the
+//  License, or (at your option) any later version.
+//
+//  GoSuki is distributed in the hope that it will be useful,
+//  but WITHOUT ANY WARRANTY; without even the implied warranty of
+//  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU Affero General Public License for more details.
+//
+//  You should have received a copy of the GNU Affero General Public License
+//  along with gosuki.  If not, see <http://www.gnu.org/licenses/>.
+//
+
+package modules
+
+// Reading the browsing history of browser profiles.
+//
+// Browser modules implementing [HistoryReader] report how often the urls of
+// their profiles were visited. The visits of bookmarked urls are stored to rank
+// bookmarks by frecency, see [database.SortFrecency].
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/blob42/gosuki/internal/database"
+	"github.com/blob42/gosuki/internal/utils"
+	"github.com/blob42/gosuki/pkg/browsers/mozilla"
+	"github.com/blob42/gosuki/pkg/profiles"
+)
+
+// HistoryReader is implemented by browser modules that can read the browsing
+// history of a profile.
+type HistoryReader interface {
+	BrowserModule
+
+	// ReadHistory returns the visited urls of profile p, or of the
+	// configured profile when p is nil
+	ReadHistory(ctx context.Context, p *profiles.Profile) ([]*database.Visit, error)
+}
+
+// ReadHistories reads the history of every profile of a history reader module.
+// The visits are returned by source, named after the module, flavour and
+// profile like the bookmark sources (firefox_default, chrome_brave_Work).
+func ReadHistories(ctx context.Context, mod BrowserModule) (map[string][]*database.Visit, error) {
+	result := map[string][]*database.Visit{}
+	// browsers are registered by value, readers are implemented on pointers
+	reader, ok := mod.ModInfo().New().(HistoryReader)
+	if !ok {
+		return nil, fmt.Errorf("<%s> does not read history", mod.ModInfo().ID)
+	}
+
+	forEachProfile(reader, func(source string, p *profiles.Profile) {
+		visits, err := reader.ReadHistory(ctx, p)
+		if err != nil {
+			log.Warn("reading history", "source", source, "err", err)
+			return
+		}
+		result[source] = visits
+	})
+
+	return result, nil
+}
+
+// forEachProfile calls fn with each profile of mod. Modules that do not manage
+// profiles are called once with a nil profile. The source names the profile
+// like the bookmark sources.
+//
+// Instances of a browser module share their config, the profiles are passed
+// along instead of calling [profiles.ProfileManager.UseProfile] which would
+// change the paths of the running instances.
+func forEachProfile(mod BrowserModule, fn func(source string, p *profiles.Profile)) {
+	pm, ok := mod.(profiles.ProfileManager)
+	if !ok {
+		fn(mod.Config().Name, nil)
+		return
+	}
+
+	for _, flav := range pm.ListFlavours() {
+		profs, err := pm.GetProfiles(flav.Flavour)
+		if err != nil {
+			log.Debug("no profiles found", "flavour", flav.Flavour, "err", err)
+			continue
+		}
+
+		for _, p := range profs {
+			fn(profileSource(mod.Config().Name, &flav, p), p)
+		}
+	}
+}
+
+// name of a browser profile as used by the bookmark sources
+func profileSource(name string, flav *profiles.BrowserDef, p *profiles.Profile) string {
+	if flav != nil && flav.Flavour != name {
+		name = fmt.Sprintf("%s_%s", name, flav.Flavour)
+	}
+	if p != nil {
+		name = fmt.Sprintf("%s_%s", name, p.Name)
+	}
+	return name
+}
+
+// OpenHistoryCopy copies the sqlite database at path and its journal files to a
+// temporary directory and opens the copy. Browsers keep their history locked
+// while running. The returned function closes and removes the copy.
+func OpenHistoryCopy(path string) (*database.DB, func(), error) {
+	exists, err := utils.CheckFileExists(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !exists {
+		return nil, nil, fmt.Errorf("%s: %w", path, os.ErrNotExist)
+	}
+
+	pc := mozilla.NewPlaceCopyJob()
+	clean := func() {
+		if err := pc.Clean(); err != nil {
+			log.Error("cleaning history copy", "path", pc.Path(), "err", err)
+		}
+	}
+
+	if err = utils.CopyFilesToTmpFolder(path+"*", pc.Path()); err != nil {
+		clean()
+		return nil, nil, fmt.Errorf("copying %s: %w", path, err)
+	}
+
+	hist, err := database.NewDB("history", filepath.Join(pc.Path(), filepath.Base(path)),
+		database.DBTypeFileDSN).Init()
+	if err != nil {
+		clean()
+		return nil, nil, err
+	}
+
+	return hist, func() {
+		hist.Close()
+		clean()
+	}, nil
+}
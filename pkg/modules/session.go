@@ -0,0 +1,73 @@
+//
+//  Copyright (c) 2025 Chakib Ben Ziane <contact@blob42.xyz>  and [`gosuki` contributors](https://github.com/blob42/gosuki/graphs/contributors).
+//  All rights reserved.
+//
+//  SPDX-License-Identifier: AGPL-3.0-or-later
+//
+//  This file is part of GoSuki.
+//
+//  GoSuki is free software: you can redistribute it and/or modify
+//  it under the terms of the GNU Affero General Public License as
+//  published by the Free Software Foundation, either version 3 of the
+//  License, or (at your option) any later version.
+//
+//  GoSuki is distributed in the hope that it will be useful,
+//  but WITHOUT ANY WARRANTY; without even the implied warranty of
+//  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU Affero General Public License for more details.
+//
+//  You should have received a copy of the GNU Affero General Public License
+//  along with gosuki.  If not, see <http://www.gnu.org/licenses/>.
+//
+
+package modules
+
+import (
+	"context"
+
+	"github.com/blob42/gosuki/internal/database"
+	"github.com/blob42/gosuki/pkg/profiles"
+)
+
+// SessionReader is implemented by browser modules that can read the windows
+// and tabs open in a profile. The profile is nil for the configured profile.
+type SessionReader interface {
+	BrowserModule
+
+	// SessionPath returns the session file of the profile, or the directory
+	// holding its session files. It is watched for changes.
+	SessionPath(p *profiles.Profile) (string, error)
+
+	// ReadSession returns the windows currently open in the profile
+	ReadSession(ctx context.Context, p *profiles.Profile) (*database.Session, error)
+}
+
+// ProfileSession is the session of a browser profile
+type ProfileSession struct {
+	SessionReader
+	Profile *profiles.Profile
+}
+
+func (ps ProfileSession) Path() (string, error) {
+	return ps.SessionPath(ps.Profile)
+}
+
+func (ps ProfileSession) Read(ctx context.Context) (*database.Session, error) {
+	return ps.ReadSession(ctx, ps.Profile)
+}
+
+// ProfileSessions returns the sessions of every profile of a browser module by
+// source name, see [ReadHistories]. Modules that do not read sessions have
+// none.
+func ProfileSessions(mod BrowserModule) map[string]ProfileSession {
+	result := map[string]ProfileSession{}
+	reader, ok := mod.ModInfo().New().(SessionReader)
+	if !ok {
+		return result
+	}
+
+	forEachProfile(reader, func(source string, p *profiles.Profile) {
+		result[source] = ProfileSession{SessionReader: reader, Profile: p}
+	})
+	return result
+}
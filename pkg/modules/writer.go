@@ -0,0 +1,176 @@
+//
+//  Copyright (c) 2025 Chakib Ben Ziane <contact@blob42.xyz>  and [`gosuki` contributors](https://github.com/blob42/gosuki/graphs/contributors).
+//  All rights reserved.
+//
+//  SPDX-License-Identifier: AGPL-3.0-or-later
+//
+//  This file is part of GoSuki.
+//
+//  GoSuki is free software: you can redistribute it and/or modify
+//  it under the terms of the GNU Affero General Public License as
+//  published by the Free Software Foundation, either version 3 of the
+//  License, or (at your option) any later version.
+//
+//  GoSuki is distributed in the hope that it will be useful,
+//  but WITHOUT ANY WARRANTY; without even the implied warranty of
+//  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU Affero General Public License for more details.
+//
+//  You should have received a copy of the GNU Affero General Public License
+//  along with gosuki.  If not, see <http://www.gnu.org/licenses/>.
+//
+
+package modules
+
+// Writing gosuki bookmarks back into browsers.
+//
+// Browser modules implementing [BookmarkWriter] can receive the bookmarks
+// stored in gosuki. They are written to a dedicated folder (named "gosuki" by
+// default) that is owned by gosuki: its content is replaced on every write and
+// it is ignored when parsing the browser bookmarks.
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/blob42/gosuki"
+	"github.com/blob42/gosuki/internal/database"
+	"github.com/blob42/gosuki/pkg/manager"
+)
+
+const DefaultWriteBackFolder = "gosuki"
+
+var (
+	// ErrBrowserBusy is returned by writers when the browser holds a lock on
+	// its bookmarks. The write is retried on the next interval.
+	ErrBrowserBusy = errors.New("browser is running")
+
+	// interval at which bookmarks are written back to browsers
+	WriteBackInterval = time.Minute
+)
+
+// WriteBackConfig holds the per module write back options
+type WriteBackConfig struct {
+	// Write gosuki bookmarks into the browser
+	Enabled bool `toml:"enabled" mapstructure:"enabled"`
+
+	// Name of the folder holding the bookmarks written by gosuki
+	Folder string `toml:"folder" mapstructure:"folder"`
+
+	// Only write bookmarks with any of these tags. Empty means all bookmarks.
+	Tags []string `toml:"tags" mapstructure:"tags"`
+}
+
+func DefaultWriteBack() WriteBackConfig {
+	return WriteBackConfig{
+		Folder: DefaultWriteBackFolder,
+		Tags:   []string{},
+	}
+}
+
+// FolderName returns the configured folder name or the default one
+func (c WriteBackConfig) FolderName() string {
+	if c.Folder == "" {
+		return DefaultWriteBackFolder
+	}
+	return c.Folder
+}
+
+// BookmarkWriter is implemented by browser modules that can write bookmarks
+// back into the browser.
+type BookmarkWriter interface {
+	BrowserModule
+
+	// Returns the write back options of the module
+	WriteConfig() *WriteBackConfig
+
+	// WriteBookmarks replaces the content of the gosuki folder with bks.
+	// Bookmarks already present elsewhere in the browser must be skipped.
+	// Implementations should not touch the browser data when the folder is
+	// already up to date.
+	WriteBookmarks(bks []*gosuki.Bookmark) error
+}
+
+// WriteBackBookmarks returns the bookmarks selected by the tag filter of cfg
+func WriteBackBookmarks(ctx context.Context, cfg *WriteBackConfig) ([]*gosuki.Bookmark, error) {
+	if database.DiskDB == nil {
+		return nil, errors.New("database not initialized")
+	}
+
+	q := database.NewBookmarkQuery()
+	if len(cfg.Tags) > 0 {
+		q.Where(database.MatchTags(database.TagOr, false, cfg.Tags...))
+	}
+
+	res, err := q.Run(ctx, &database.PaginationParams{
+		Page:    1,
+		Size:    -1,
+		SortBy:  "url",
+		SortAsc: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res.Bookmarks, nil
+}
+
+// WriteBack writes the gosuki bookmarks into the browser of w
+func WriteBack(ctx context.Context, w BookmarkWriter) error {
+	cfg := w.WriteConfig()
+	if cfg == nil || !cfg.Enabled {
+		return nil
+	}
+
+	bks, err := WriteBackBookmarks(ctx, cfg)
+	if err != nil {
+		return fmt.Errorf("selecting bookmarks: %w", err)
+	}
+
+	return w.WriteBookmarks(bks)
+}
+
+// WriteBackWork is a work unit periodically writing bookmarks back into a
+// browser
+type WriteBackWork struct {
+	Ctx context.Context
+	BookmarkWriter
+}
+
+func (ww WriteBackWork) Run(m manager.UnitManager) {
+	ctx, cancel := context.WithCancel(ww.Ctx)
+	name := ww.Config().Name
+
+	go func() {
+		defer func() {
+			if err := recover(); err != nil {
+				m.Panic(fmt.Errorf("%v", err))
+			}
+		}()
+
+		ticker := time.NewTicker(WriteBackInterval)
+		defer ticker.Stop()
+
+		for {
+			err := WriteBack(ctx, ww.BookmarkWriter)
+			if errors.Is(err, ErrBrowserBusy) {
+				log.Debug("browser busy, delaying write back", "module", name)
+			} else if err != nil {
+				log.Error("writing bookmarks to browser", "module", name, "err", err)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	<-m.ShouldStop()
+	cancel()
+	m.Done()
+}
+
+var _ manager.WorkUnit = (*WriteBackWork)(nil)
@@ -23,6 +23,8 @@ package tree
 
 import (
 	"fmt"
+	"slices"
+	"strings"
 
 	"github.com/blob42/gosuki"
 	"github.com/blob42/gosuki/internal/index"
@@ -51,6 +53,10 @@ type Node struct {
 	Tags       []string
 	Desc       string
 	Module     string
+	Flavour    string // browser flavour and profile the node was read from
+	Profile    string
+	Keyword    string // address bar keywords of the url, comma separated
+	Created    uint64 // creation time reported by the browser, unix seconds
 	HasChanged bool
 	NameHash   uint64 // hash of the metadata
 	Parent     *Node
@@ -111,6 +117,31 @@ func (node *Node) GetFolderParents() []*Node {
 	return parents
 }
 
+// FolderPath returns the folders containing node as a hierarchical tag joined
+// with [gosuki.FolderSep], ex: Work/ProjectA/Docs. The browser root folder
+// (toolbar, menu ...) is left out unless node is directly under it.
+func (node *Node) FolderPath() string {
+	return folderPath(node.GetFolderParents())
+}
+
+// folders are ordered from leaf to root as returned by GetFolderParents
+func folderPath(folders []*Node) string {
+	if len(folders) > 1 {
+		top := folders[len(folders)-1]
+		if top.Parent != nil && top.Parent.Type == RootNode {
+			folders = folders[:len(folders)-1]
+		}
+	}
+
+	names := make([]string, 0, len(folders))
+	for _, folder := range slices.Backward(folders) {
+		if name := strings.TrimSpace(folder.Title); name != "" {
+			names = append(names, name)
+		}
+	}
+	return strings.Join(names, gosuki.FolderSep)
+}
+
 // Recursively traverse the tree from a root and find all occurences of [url]
 // whose parent is a folder without using url.Parent as a reference
 // Returns a list of nodes that match the criteria
@@ -255,9 +286,9 @@ func MapNodeFunc(node *Node, nType NodeType, f func(*Node)) {
 
 // Get all possible tags for this url node The tags make sense only in the
 // context of a URL node This will traverse the three breadth first to find all
-// parent folders and add them as a tag. URL nodes should already be populated
-// with the list of tags that exist under the TAG tree. So we only need to find
-// the parent folders and turn them into tags.
+// parent folders and add their path as a tag. URL nodes should already be
+// populated with the list of tags that exist under the TAG tree. So we only
+// need to find the parent folders and turn them into tags.
 func (node *Node) getTags() []string {
 
 	if node.Type != URLNode {
@@ -268,8 +299,15 @@ func (node *Node) getTags() []string {
 	parentFolders := FindParents(root, node, FolderNode)
 	parentTags := FindParents(root, node, TagNode)
 
+	// the same url can be saved in several folders
 	for _, f := range parentFolders {
-		node.Tags = utils.Extends(node.Tags, f.Title)
+		if !node.DirectChildOf(f) {
+			continue
+		}
+		path := folderPath(append([]*Node{f}, f.GetFolderParents()...))
+		if path != "" {
+			node.Tags = utils.Extends(node.Tags, path)
+		}
 	}
 
 	for _, t := range parentTags {
@@ -285,11 +323,24 @@ func (node *Node) GetBookmark() *gosuki.Bookmark {
 		return nil
 	}
 
-	return &gosuki.Bookmark{
-		URL:    node.URL,
-		Title:  node.Title,
-		Desc:   node.Desc,
-		Tags:   node.getTags(),
-		Module: node.Module,
+	bk := &gosuki.Bookmark{
+		URL:     node.URL,
+		Title:   node.Title,
+		Desc:    node.Desc,
+		Tags:    node.getTags(),
+		Module:  node.Module,
+		Created: node.Created,
 	}
+
+	if node.Module != "" {
+		bk.Sources = []gosuki.Source{{
+			Module:  node.Module,
+			Flavour: node.Flavour,
+			Profile: node.Profile,
+			Folder:  node.FolderPath(),
+			Keyword: node.Keyword,
+		}}
+	}
+
+	return bk
 }
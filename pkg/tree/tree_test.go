@@ -4,6 +4,8 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+
+	"github.com/blob42/gosuki"
 )
 
 func Test_AddChild(t *testing.T) {
@@ -159,3 +161,57 @@ func Test_GetRoot(t *testing.T) {
 	foundRoot := url.GetRoot()
 	assert.Equal(t, root, foundRoot)
 }
+
+func TestFolderPathTags(t *testing.T) {
+	root := &Node{Type: RootNode, Title: "root"}
+	toolbar := &Node{Type: FolderNode, Title: "toolbar"}
+	work := &Node{Type: FolderNode, Title: "Work"}
+	project := &Node{Type: FolderNode, Title: "ProjectA"}
+	workDocs := &Node{Type: FolderNode, Title: "Docs"}
+	personal := &Node{Type: FolderNode, Title: "Personal"}
+	personalDocs := &Node{Type: FolderNode, Title: "Docs"}
+
+	AddChild(root, toolbar)
+	AddChild(toolbar, work)
+	AddChild(work, project)
+	AddChild(project, workDocs)
+	AddChild(toolbar, personal)
+	AddChild(personal, personalDocs)
+
+	url := &Node{Type: URLNode, Title: "spec", Tags: []string{"tag1"}}
+	AddChild(workDocs, url)
+	AddChild(personalDocs, url)
+
+	topURL := &Node{Type: URLNode, Title: "top"}
+	AddChild(toolbar, topURL)
+
+	assert.Equal(t, "Personal/Docs", url.FolderPath())
+	assert.Equal(t, "toolbar", topURL.FolderPath())
+	assert.ElementsMatch(t, []string{"tag1", "Work/ProjectA/Docs", "Personal/Docs"}, url.getTags())
+	assert.Equal(t, []string{"toolbar"}, topURL.getTags())
+}
+
+func TestGetBookmarkSource(t *testing.T) {
+	root := &Node{Type: RootNode, Title: "root"}
+	bar := &Node{Type: FolderNode, Title: "Bookmarks bar"}
+	work := &Node{Type: FolderNode, Title: "Work"}
+	AddChild(root, bar)
+	AddChild(bar, work)
+
+	url := &Node{
+		Type:    URLNode,
+		URL:     "https://example.com",
+		Module:  "chrome_brave_Work",
+		Flavour: "brave",
+		Profile: "Work",
+	}
+	AddChild(work, url)
+
+	bk := url.GetBookmark()
+	assert.Equal(t, []gosuki.Source{{
+		Module:  "chrome_brave_Work",
+		Flavour: "brave",
+		Profile: "Work",
+		Folder:  "Work",
+	}}, bk.Sources)
+}
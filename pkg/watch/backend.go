@@ -0,0 +1,306 @@
+//
+// Copyright (c) 2023-2025 Chakib Ben Ziane <contact@blob42.xyz> and [`GoSuki` contributors]
+// (https://github.com/blob42/gosuki/graphs/contributors).
+//
+// All rights reserved.
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+//
+// This file is part of GoSuki.
+//
+// GoSuki is free software: you can redistribute it and/or modify it under the terms of
+// the GNU Affero General Public License as published by the Free Software Foundation,
+// either version 3 of the License, or (at your option) any later version.
+//
+// GoSuki is distributed in the hope that it will be useful, but WITHOUT ANY WARRANTY;
+// without even the implied warranty of MERCHANTABILITY or FITNESS FOR A PARTICULAR
+// PURPOSE.  See the GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License along with
+// gosuki.  If not, see <http://www.gnu.org/licenses/>.
+
+package watch
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/OneOfOne/xxhash"
+	"github.com/fsnotify/fsnotify"
+)
+
+const (
+	// how long to wait for the event of a probe file
+	probeTimeout = time.Second
+
+	// mtime resolution of the coarsest filesystems (FAT, SMB). Files modified
+	// within it are hashed to catch writes that keep the same size and mtime.
+	mtimeGranularity = 2 * time.Second
+)
+
+// Backend delivers the filesystem events of a [WatchDescriptor]. Event names
+// are the full path of the changed file as with fsnotify.
+type Backend interface {
+	Add(path string) error
+	Events() <-chan fsnotify.Event
+	Errors() <-chan error
+	Close() error
+}
+
+type fsnotifyBackend struct {
+	w *fsnotify.Watcher
+}
+
+func (b fsnotifyBackend) Add(path string) error         { return b.w.Add(path) }
+func (b fsnotifyBackend) Events() <-chan fsnotify.Event { return b.w.Events }
+func (b fsnotifyBackend) Errors() <-chan error          { return b.w.Errors }
+func (b fsnotifyBackend) Close() error                  { return b.w.Close() }
+
+// probe creates and removes a file in dir and reports whether fsnotify
+// delivered both events. Directories that cannot be written to are assumed to
+// work.
+func (b fsnotifyBackend) probe(dir string) bool {
+	f, err := os.CreateTemp(dir, ".gosuki-probe-*")
+	if err != nil {
+		log.Debug("skipping watcher probe", "dir", dir, "err", err)
+		return true
+	}
+	path := f.Name()
+	f.Close()
+	defer os.Remove(path)
+
+	name := filepath.Base(path)
+	timeout := time.After(probeTimeout)
+	for {
+		select {
+		case event, ok := <-b.w.Events:
+			if !ok {
+				return false
+			}
+			if filepath.Base(event.Name) != name {
+				continue
+			}
+			if event.Has(fsnotify.Remove) {
+				return true
+			}
+			// wait for the removal to not leak the probe events
+			if event.Has(fsnotify.Create) {
+				os.Remove(path)
+			}
+		case <-b.w.Errors:
+		case <-timeout:
+			return false
+		}
+	}
+}
+
+type fileState struct {
+	info os.FileInfo
+	hash uint64 // zero until the file was hashed
+}
+
+// pollBackend detects changes by comparing the size, mtime and content hash of
+// the watched files at each interval. It is used on filesystems that do not
+// deliver inotify events like NFS, SMB or some FUSE mounts.
+type pollBackend struct {
+	interval  time.Duration
+	events    chan fsnotify.Event
+	errors    chan error
+	done      chan struct{}
+	closeOnce sync.Once
+
+	mu sync.Mutex
+	// files of each watched path keyed by their full path
+	paths    map[string]map[string]*fileState
+	lastPoll time.Time
+}
+
+func newPollBackend(interval time.Duration) *pollBackend {
+	b := &pollBackend{
+		interval: interval,
+		events:   make(chan fsnotify.Event),
+		errors:   make(chan error),
+		done:     make(chan struct{}),
+		paths:    make(map[string]map[string]*fileState),
+		lastPoll: time.Now(),
+	}
+	go b.run()
+	return b
+}
+
+func (b *pollBackend) Add(path string) error {
+	path = filepath.Clean(path)
+	if _, err := os.Stat(path); err != nil {
+		return err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	files, _, err := b.scan(path, nil)
+	if err != nil {
+		return err
+	}
+	b.paths[path] = files
+	return nil
+}
+
+func (b *pollBackend) Events() <-chan fsnotify.Event { return b.events }
+func (b *pollBackend) Errors() <-chan error          { return b.errors }
+
+func (b *pollBackend) Close() error {
+	b.closeOnce.Do(func() { close(b.done) })
+	return nil
+}
+
+func (b *pollBackend) run() {
+	defer close(b.errors)
+	defer close(b.events)
+
+	ticker := time.NewTicker(b.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-b.done:
+			return
+		case <-ticker.C:
+			for _, event := range b.poll() {
+				select {
+				case b.events <- event:
+				case <-b.done:
+					return
+				}
+			}
+		}
+	}
+}
+
+// poll rescans the watched paths and returns the changes since the last poll
+func (b *pollBackend) poll() []fsnotify.Event {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var events []fsnotify.Event
+	for path, files := range b.paths {
+		current, changes, err := b.scan(path, files)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			log.Error("polling watched path", "path", path, "err", err)
+			continue
+		}
+		b.paths[path] = current
+		events = append(events, changes...)
+	}
+	b.lastPoll = time.Now()
+	return events
+}
+
+// scan lists the files of path, a directory or a single file, and compares
+// them with the previous scan. A removed path has no files, it is still
+// polled in case it comes back.
+func (b *pollBackend) scan(path string, last map[string]*fileState) (map[string]*fileState, []fsnotify.Event, error) {
+	current := make(map[string]*fileState)
+	var events []fsnotify.Event
+
+	info, err := os.Stat(path)
+	switch {
+	case err != nil:
+	case info.IsDir():
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			return last, nil, err
+		}
+		for _, entry := range entries {
+			name := filepath.Join(path, entry.Name())
+			if info, err := entry.Info(); err == nil {
+				current[name] = &fileState{info: info}
+			}
+		}
+	default:
+		current[path] = &fileState{info: info}
+	}
+
+	names := make([]string, 0, len(current))
+	for name := range current {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	for _, name := range names {
+		state := current[name]
+		op := b.compare(name, last[name], state)
+		if last != nil && op != 0 {
+			events = append(events, fsnotify.Event{Name: name, Op: op})
+		}
+	}
+
+	for name := range last {
+		if _, ok := current[name]; !ok {
+			events = append(events, fsnotify.Event{Name: name, Op: fsnotify.Remove})
+		}
+	}
+
+	return current, events, err
+}
+
+// compare returns the operation that turned last into state, zero if the
+// file did not change. The hash of state is set when it is needed now or to
+// check the next poll.
+func (b *pollBackend) compare(name string, last, state *fileState) fsnotify.Op {
+	info := state.info
+	recent := info.ModTime().After(b.lastPoll.Add(-mtimeGranularity))
+
+	switch {
+	case last == nil:
+	// replaced by a rename, how most browsers save their files
+	case !os.SameFile(last.info, info):
+		last = nil
+	case info.IsDir():
+		return 0
+	case last.info.Size() != info.Size():
+		if recent {
+			state.hash = hashFile(name)
+		}
+		return fsnotify.Write
+	case !last.info.ModTime().Equal(info.ModTime()) || recent:
+		state.hash = hashFile(name)
+		if last.hash != 0 && last.hash == state.hash {
+			if last.info.ModTime().Equal(info.ModTime()) {
+				return 0
+			}
+			return fsnotify.Chmod
+		}
+		// without a hash of the last state only a new mtime is a change
+		if last.hash == 0 && last.info.ModTime().Equal(info.ModTime()) {
+			return 0
+		}
+		return fsnotify.Write
+	default:
+		state.hash = last.hash
+		return 0
+	}
+
+	if !info.IsDir() && recent {
+		state.hash = hashFile(name)
+	}
+	return fsnotify.Create
+}
+
+// hashFile returns the xxhash of the content of a file, zero if it cannot be
+// read
+func hashFile(name string) uint64 {
+	f, err := os.Open(name)
+	if err != nil {
+		return 0
+	}
+	defer f.Close()
+
+	h := xxhash.New64()
+	if _, err := io.Copy(h, f); err != nil {
+		return 0
+	}
+	return h.Sum64()
+}
@@ -0,0 +1,179 @@
+package watch
+
+import (
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testInterval = 10 * time.Millisecond
+
+func nextEvent(t *testing.T, b Backend) fsnotify.Event {
+	t.Helper()
+	select {
+	case event := <-b.Events():
+		return event
+	case <-time.After(time.Second):
+		t.Fatal("no event")
+	}
+	return fsnotify.Event{}
+}
+
+func noEvent(t *testing.T, b Backend) {
+	t.Helper()
+	select {
+	case event := <-b.Events():
+		t.Fatalf("unexpected event %s", event)
+	case <-time.After(10 * testInterval):
+	}
+}
+
+func TestPollBackend(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "Bookmarks")
+
+	b := newPollBackend(testInterval)
+	require.NoError(t, b.Add(dir))
+
+	require.NoError(t, os.WriteFile(file, []byte("aaaa"), 0o644))
+	assert.Equal(t, fsnotify.Event{Name: file, Op: fsnotify.Create}, nextEvent(t, b))
+	noEvent(t, b)
+
+	require.NoError(t, os.WriteFile(file, []byte("aaaaaa"), 0o644))
+	assert.Equal(t, fsnotify.Event{Name: file, Op: fsnotify.Write}, nextEvent(t, b))
+
+	t.Run("same size and mtime", func(t *testing.T) {
+		info, err := os.Stat(file)
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(file, []byte("bbbbbb"), 0o644))
+		require.NoError(t, os.Chtimes(file, info.ModTime(), info.ModTime()))
+		assert.Equal(t, fsnotify.Event{Name: file, Op: fsnotify.Write}, nextEvent(t, b))
+	})
+
+	t.Run("touch", func(t *testing.T) {
+		later := time.Now().Add(time.Minute)
+		require.NoError(t, os.Chtimes(file, later, later))
+		assert.Equal(t, fsnotify.Event{Name: file, Op: fsnotify.Chmod}, nextEvent(t, b))
+	})
+
+	t.Run("replaced", func(t *testing.T) {
+		tmp := filepath.Join(t.TempDir(), "Bookmarks.tmp")
+		require.NoError(t, os.WriteFile(tmp, []byte("cccccc"), 0o644))
+		require.NoError(t, os.Rename(tmp, file))
+		assert.Equal(t, fsnotify.Event{Name: file, Op: fsnotify.Create}, nextEvent(t, b))
+	})
+
+	require.NoError(t, os.Remove(file))
+	assert.Equal(t, fsnotify.Event{Name: file, Op: fsnotify.Remove}, nextEvent(t, b))
+
+	require.NoError(t, b.Close())
+	_, ok := <-b.Events()
+	assert.False(t, ok, "events are closed")
+}
+
+func TestPollBackendFile(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "places.sqlite-wal")
+	require.NoError(t, os.WriteFile(file, nil, 0o644))
+
+	b := newPollBackend(testInterval)
+	defer b.Close()
+	require.NoError(t, b.Add(file))
+	assert.Error(t, b.Add(filepath.Join(t.TempDir(), "missing")))
+
+	require.NoError(t, os.WriteFile(file, []byte("frame"), 0o644))
+	assert.Equal(t, fsnotify.Event{Name: file, Op: fsnotify.Write}, nextEvent(t, b))
+}
+
+func TestNewWatcher(t *testing.T) {
+	dir := t.TempDir()
+
+	// nothing is written to the watched dir
+	spy, err := fsnotify.NewWatcher()
+	require.NoError(t, err)
+	defer spy.Close()
+	require.NoError(t, spy.Add(dir))
+
+	watcher, err := NewWatcher("test", &Watch{Path: dir})
+	require.NoError(t, err)
+	defer watcher.W.Close()
+
+	select {
+	case event := <-spy.Events:
+		t.Fatalf("unexpected event %s", event)
+	case <-time.After(10 * testInterval):
+	}
+
+	if fstype, remote := remoteFS(dir); remote {
+		assert.IsType(t, &pollBackend{}, watcher.W, fstype)
+	} else {
+		assert.IsType(t, fsnotifyBackend{}, watcher.W)
+	}
+}
+
+type testRunner struct {
+	watcher *WatchDescriptor
+	runs    atomic.Int32
+}
+
+func (r *testRunner) Watch() *WatchDescriptor { return r.watcher }
+func (r *testRunner) Run()                    { r.runs.Add(1) }
+
+func TestPollWatcher(t *testing.T) {
+	Config.Poll = []string{"test"}
+	Config.PollInterval = testInterval
+	defer func() { Config.Poll = []string{} }()
+
+	dir := t.TempDir()
+	file := filepath.Join(dir, "Bookmarks")
+	w := &Watch{
+		Path:       dir,
+		EventTypes: []fsnotify.Op{fsnotify.Create},
+		EventNames: []string{file},
+	}
+
+	t.Run("runner", func(t *testing.T) {
+		watcher, err := NewWatcher("test", w)
+		require.NoError(t, err)
+		require.IsType(t, &pollBackend{}, watcher.W)
+
+		runner := &testRunner{watcher: watcher}
+		go WatchLoop(runner)
+		defer watcher.W.Close()
+
+		require.NoError(t, os.WriteFile(file, []byte("a"), 0o644))
+		assert.Eventually(t, func() bool { return runner.runs.Load() == 1 }, time.Second, testInterval)
+
+		// other files and event types are ignored
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "other"), nil, 0o644))
+		require.NoError(t, os.WriteFile(file, []byte("ab"), 0o644))
+		time.Sleep(10 * testInterval)
+		assert.EqualValues(t, 1, runner.runs.Load())
+		require.NoError(t, os.Remove(file))
+	})
+
+	t.Run("reducer", func(t *testing.T) {
+		watcher, err := NewWatcherWithReducer("test", 10, w)
+		require.NoError(t, err)
+
+		runner := &testRunner{watcher: watcher}
+		go WatchLoop(runner)
+		go ReduceEvents(5*testInterval, runner)
+		defer watcher.W.Close()
+
+		for i := range 3 {
+			tmp := filepath.Join(t.TempDir(), "Bookmarks.tmp")
+			require.NoError(t, os.WriteFile(tmp, []byte{byte(i)}, 0o644))
+			require.NoError(t, os.Rename(tmp, file))
+			time.Sleep(2 * testInterval)
+		}
+		assert.Eventually(t, func() bool { return runner.runs.Load() == 1 }, time.Second, testInterval)
+		time.Sleep(10 * testInterval)
+		assert.EqualValues(t, 1, runner.runs.Load())
+	})
+}
@@ -0,0 +1,56 @@
+//
+// Copyright (c) 2023-2025 Chakib Ben Ziane <contact@blob42.xyz> and [`GoSuki` contributors]
+// (https://github.com/blob42/gosuki/graphs/contributors).
+//
+// All rights reserved.
+//
+// SPDX-License-Identifier: AGPL-3.0-or-later
+//
+// This file is part of GoSuki.
+//
+// GoSuki is free software: you can redistribute it and/or modify it under the terms of
+// the GNU Affero General Public License as published by the Free Software Foundation,
+// either version 3 of the License, or (at your option) any later version.
+//
+// GoSuki is distributed in the hope that it will be useful, but WITHOUT ANY WARRANTY;
+// without even the implied warranty of MERCHANTABILITY or FITNESS FOR A PARTICULAR
+// PURPOSE.  See the GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License along with
+// gosuki.  If not, see <http://www.gnu.org/licenses/>.
+
+package watch
+
+import (
+	"slices"
+	"time"
+
+	"github.com/blob42/gosuki/pkg/config"
+)
+
+type watcherConfig struct {
+	// Modules watching their files by polling instead of filesystem events
+	Poll []string `toml:"poll" mapstructure:"poll"`
+
+	// How often polled files are checked for changes
+	PollInterval time.Duration `toml:"poll-interval" mapstructure:"poll-interval"`
+
+	// Write a probe file in watched directories to check that filesystem
+	// events are delivered, polling is used otherwise. Disabled by default as
+	// the watched directories belong to the browsers, network and FUSE
+	// filesystems are detected without it on linux.
+	Probe bool `toml:"probe" mapstructure:"probe"`
+}
+
+var Config = &watcherConfig{
+	Poll:         []string{},
+	PollInterval: 2 * time.Second,
+}
+
+func (c *watcherConfig) forcePoll(name string) bool {
+	return slices.Contains(c.Poll, name)
+}
+
+func init() {
+	config.RegisterConfigurator("watcher", config.AsConfigurator(Config))
+}
@@ -37,7 +37,11 @@ func ReduceEvents(interval time.Duration,
 
 	for {
 		select {
-		case <-eventsIn:
+		case _, ok := <-eventsIn:
+			if !ok {
+				log.Warnf("Events channel closed for %s", watch.ID)
+				return // Exit the function or handle the closed channel case
+			}
 			log.Trace("[reducuer] received event, resetting watch interval")
 			timer.Reset(interval)
 			events = append(events, true)
@@ -53,11 +57,6 @@ func ReduceEvents(interval time.Duration,
 				// Empty events queue
 				events = make([]bool, 0)
 			}
-		case _, ok := <-eventsIn:
-			if !ok {
-				log.Warnf("Events channel closed for %s", watch.ID)
-				return // Exit the function or handle the closed channel case
-			}
 		}
 	}
 }
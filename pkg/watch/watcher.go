@@ -24,6 +24,7 @@ package watch
 
 import (
 	"fmt"
+	"os"
 	"slices"
 	"time"
 
@@ -101,13 +102,14 @@ type StatMaker interface {
 	ResetStats()
 }
 
-// WatchDescriptor is a warpper around an fsnotify.Watcher that defines watch properties.
+// WatchDescriptor is a warpper around a watcher [Backend] that defines watch properties.
 type WatchDescriptor struct {
 	// ID is a unique identifier for the watch descriptor.
 	ID string
 
-	// W is the underlying fsnotify.Watcher that this wrapper uses to monitor file system events.
-	W *fsnotify.Watcher
+	// W is the underlying fsnotify or polling backend that this wrapper uses
+	// to monitor file system events.
+	W Backend
 
 	// Watches is a slice of pointers to Watch objects, which represent specific files or directories being watched.
 	Watches []*Watch
@@ -144,36 +146,82 @@ func NewWatcherWithReducer(name string, reducerLen int, watches ...*Watch) (*Wat
 	return w, nil
 }
 
+// NewWatcher creates a watcher for the given watches. Paths are watched with
+// fsnotify, or by polling when name is listed in the poll option of the
+// watcher config, when fsnotify cannot add a path, when a path is on a network
+// or FUSE filesystem or when the probe of a watched directory gets no event.
 func NewWatcher(name string, watches ...*Watch) (*WatchDescriptor, error) {
+	watcher := &WatchDescriptor{
+		ID:         name,
+		Watches:    watches,
+		eventsChan: nil,
+	}
+
+	if Config.forcePoll(name) {
+		log.Info("polling watched paths", "watcher", name, "interval", Config.PollInterval)
+		return watcher, watcher.pollPaths()
+	}
 
 	fswatcher, err := fsnotify.NewWatcher()
 	if err != nil {
 		return nil, fmt.Errorf("creating fsnotify watcher: %w", err)
 	}
+	backend := fsnotifyBackend{fswatcher}
+	watcher.W = backend
 
-	watchedMap := make(map[string]*Watch)
+	// Add all watched paths
 	for _, v := range watches {
-		watchedMap[v.Path] = v
+		err = watcher.W.Add(v.Path)
+		if err == nil {
+			continue
+		}
+		backend.Close()
+		if _, statErr := os.Stat(v.Path); statErr != nil {
+			return nil, fmt.Errorf("adding watch path: %s", v.Path)
+		}
+		log.Warn("falling back to polling", "watcher", name, "path", v.Path, "err", err)
+		return watcher, watcher.pollPaths()
 	}
 
-	watcher := &WatchDescriptor{
-		ID:         name,
-		W:          fswatcher,
-		Watches:    watches,
-		eventsChan: nil,
+	for _, v := range watches {
+		if fstype, remote := remoteFS(v.Path); remote {
+			log.Info("filesystem without events, falling back to polling",
+				"watcher", name, "path", v.Path, "fstype", fstype)
+			backend.Close()
+			return watcher, watcher.pollPaths()
+		}
 	}
 
-	// Add all watched paths
-	for _, v := range watches {
+	if !Config.Probe {
+		return watcher, nil
+	}
 
-		err = watcher.W.Add(v.Path)
-		if err != nil {
-			return nil, fmt.Errorf("adding watch path: %s", v.Path)
+	for _, v := range watches {
+		if info, err := os.Stat(v.Path); err != nil || !info.IsDir() {
+			continue
+		}
+		if !backend.probe(v.Path) {
+			log.Warn("no filesystem events, falling back to polling", "watcher", name, "path", v.Path)
+			backend.Close()
+			return watcher, watcher.pollPaths()
 		}
 	}
+
 	return watcher, nil
 }
 
+// pollPaths watches all the paths with a polling backend
+func (w *WatchDescriptor) pollPaths() error {
+	w.W = newPollBackend(Config.PollInterval)
+	for _, v := range w.Watches {
+		if err := w.W.Add(v.Path); err != nil {
+			w.W.Close()
+			return fmt.Errorf("adding watch path: %s", v.Path)
+		}
+	}
+	return nil
+}
+
 // Watch is a filesystem object that can be watched for changes.
 type Watch struct {
 	Path       string        // Path to watch for events
@@ -240,8 +288,20 @@ func watchRun(w Watcher, m manager.UnitManager) {
 	// wait for stop signal
 	<-m.ShouldStop()
 
+	// closing the watcher also ends the WatchLoop
+	if err := w.Watch().W.Close(); err != nil {
+		log.Error("closing watcher", "id", watcher.ID, "err", err)
+	}
+
 	// if module implements shutdowner
-	sht, ok := w.(Shutdowner)
+	var mod any = w
+	switch w := w.(type) {
+	case WatchLoad:
+		mod = w.WatchLoader
+	case WatchWork:
+		mod = w.WatchRunner
+	}
+	sht, ok := mod.(Shutdowner)
 	if ok {
 		if err := sht.Shutdown(); err != nil {
 			m.Panic(err)
@@ -300,7 +360,12 @@ watchloop:
 		select {
 		case <-beat:
 		// log.Debugf("main watch loop beat %s", watcher.ID)
-		case event := <-watch.W.Events:
+		case event, ok := <-watch.W.Events():
+			if !ok {
+				log.Debugf("<%s> watcher closed", watch.ID)
+				break watchloop
+			}
+
 			// Very verbose
 			log.Trace("event", "OP", event.Op, "eventName", event.Name)
 
@@ -367,7 +432,10 @@ watchloop:
 				}
 			}
 
-		case err := <-watch.W.Errors:
+		case err, ok := <-watch.W.Errors():
+			if !ok {
+				break watchloop
+			}
 			if err != nil {
 				log.Error(err)
 			}
@@ -14,6 +14,7 @@ type SeedBookmark struct {
 	Tags     []string
 	Desc     string
 	Modified int64 // Unix timestamp — controlled for sort testing
+	Created  int64 // Unix timestamp, defaults to Modified when zero
 	Module   string
 }
 
@@ -55,13 +56,18 @@ func TagsToDBFormat(tags []string) string {
 // BookmarkToInsertSQL generates an INSERT statement for a single bookmark.
 // The xhsum is a placeholder — in real usage it's computed from the bookmark content.
 func BookmarkToInsertSQL(bm SeedBookmark) string {
+	created := bm.Created
+	if created == 0 {
+		created = bm.Modified
+	}
 	return fmt.Sprintf(
-		"INSERT INTO gskbookmarks (URL, metadata, tags, desc, modified, flags, module, xhsum, version) VALUES ('%s', '%s', '%s', '%s', %d, 0, '%s', 'test-xhsum', 1)",
+		"INSERT INTO gskbookmarks (URL, metadata, tags, desc, modified, created, flags, module, xhsum, version) VALUES ('%s', '%s', '%s', '%s', %d, %d, 0, '%s', 'test-xhsum', 1)",
 		bm.URL,
 		bm.Title,
 		TagsToDBFormat(bm.Tags),
 		bm.Desc,
 		bm.Modified,
+		created,
 		bm.Module,
 	)
 }